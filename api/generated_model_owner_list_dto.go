@@ -16,4 +16,8 @@ type OwnerListDto struct {
 	Owners map[string]OwnerDto `yaml:"owners" json:"owners"`
 	// ISO-8601 UTC date time at which the list of owners was obtained from service-metadata
 	TimeStamp string `yaml:"-" json:"timeStamp"`
+	// The keys of the returned owners in the requested sort order. Only present if paging or sorting was requested.
+	Order []string `yaml:"order,omitempty" json:"order,omitempty"`
+	// Opaque cursor to pass as the cursor parameter to obtain the next page. Absent on the last page.
+	NextCursor *string `yaml:"nextCursor,omitempty" json:"nextCursor,omitempty"`
 }
@@ -31,4 +31,6 @@ type RepositoryDto struct {
 	JiraIssue string `yaml:"-" json:"jiraIssue"`
 	// A map of arbitrary string labels attached to this repository.
 	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	// The keys this repository had before it was renamed, oldest first. Managed by the rename operation, ignored on update.
+	PreviousNames []string `yaml:"previousNames,omitempty" json:"previousNames,omitempty"`
 }
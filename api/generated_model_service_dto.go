@@ -40,4 +40,8 @@ type ServiceDto struct {
 	JiraIssue string `yaml:"-" json:"jiraIssue"`
 	// The current phase of the service's development. A service usually starts off as 'experimental', then becomes 'operational' (i. e. can be reliably used and/or consumed). Once 'deprecated', the service doesn’t guarantee reliable use/consumption any longer and if 'decommissionable', the service will soon cease to exist.
 	Lifecycle *string `yaml:"lifecycle,omitempty" json:"lifecycle,omitempty"`
+	// The lifecycle changes of this service, oldest first. Managed by lifecycle changes, ignored on update.
+	LifecycleTransitions []ServiceLifecycleTransitionDto `yaml:"lifecycleTransitions,omitempty" json:"lifecycleTransitions,omitempty"`
+	// The names this service had before it was renamed, oldest first. Managed by the rename operation, ignored on update.
+	PreviousNames []string `yaml:"previousNames,omitempty" json:"previousNames,omitempty"`
 }
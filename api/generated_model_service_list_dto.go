@@ -16,4 +16,8 @@ type ServiceListDto struct {
 	Services map[string]ServiceDto `yaml:"services" json:"services"`
 	// ISO-8601 UTC date time at which the list of services was obtained from service-metadata
 	TimeStamp string `yaml:"-" json:"timeStamp"`
+	// The keys of the returned services in the requested sort order. Only present if paging or sorting was requested.
+	Order []string `yaml:"order,omitempty" json:"order,omitempty"`
+	// Opaque cursor to pass as the cursor parameter to obtain the next page. Absent on the last page.
+	NextCursor *string `yaml:"nextCursor,omitempty" json:"nextCursor,omitempty"`
 }
@@ -21,6 +21,9 @@ type CustomConfiguration interface {
 	AuthOidcTokenAudience() string
 	AuthGroupWrite() string
 
+	// GlobalPromoters are the usernames and group references that may promote any service.
+	GlobalPromoters() []string
+
 	MetadataRepoUrl() string
 	MetadataRepoMainline() string
 	MetadataRepoProject() string
@@ -48,6 +51,13 @@ type CustomConfiguration interface {
 	RepositoryTypes() []string
 	RepositoryKeySeparator() string
 
+	// ServiceLifecycleInitial is the lifecycle new services start in.
+	ServiceLifecycleInitial() string
+	// ServiceLifecycleTransitions maps each known lifecycle to the lifecycles a service may change to from it.
+	ServiceLifecycleTransitions() map[string][]string
+	// ServiceLifecycleNoNewDependents are the lifecycles in which a service may not gain new dependents.
+	ServiceLifecycleNoNewDependents() []string
+
 	NotificationConsumerConfigs() map[string]NotificationConsumerConfig
 
 	WebhooksProcessAsync() bool
@@ -105,6 +115,7 @@ const (
 	KeyAuthOidcKeySetUrl                  = "AUTH_OIDC_KEY_SET_URL"
 	KeyAuthOidcTokenAudience              = "AUTH_OIDC_TOKEN_AUDIENCE"
 	KeyAuthGroupWrite                     = "AUTH_GROUP_WRITE"
+	KeyGlobalPromoters                    = "GLOBAL_PROMOTERS"
 	KeyMetadataRepoUrl                    = "METADATA_REPO_URL"
 	KeyMetadataRepoMainline               = "METADATA_REPO_MAINLINE"
 	KeyUpdateJobIntervalMinutes           = "UPDATE_JOB_INTERVAL_MINUTES"
@@ -123,6 +134,9 @@ const (
 	KeyRepositoryNameMaxLength            = "REPOSITORY_NAME_MAX_LENGTH"
 	KeyRepositoryKeySeparator             = "REPOSITORY_KEY_SEPARATOR"
 	KeyRepositoryTypes                    = "REPOSITORY_TYPES"
+	KeyServiceLifecycleInitial            = "SERVICE_LIFECYCLE_INITIAL"
+	KeyServiceLifecycleTransitions        = "SERVICE_LIFECYCLE_TRANSITIONS"
+	KeyServiceLifecycleNoNewDependents    = "SERVICE_LIFECYCLE_NO_NEW_DEPENDENTS"
 	KeyNotificationConsumerConfigs        = "NOTIFICATION_CONSUMER_CONFIGS"
 	KeyRedisUrl                           = "REDIS_URL"
 	KeyRedisPassword                      = "REDIS_PASSWORD"
@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+)
+
+// DeletedController lists deleted owners, services and repositories and restores them from the git history
+type DeletedController interface {
+	IsDeletedController() bool
+
+	WireUp(ctx context.Context, router chi.Router)
+}
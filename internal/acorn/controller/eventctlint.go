@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+)
+
+// EventController provides a live stream of update events as server-sent events
+type EventController interface {
+	IsEventController() bool
+
+	WireUp(ctx context.Context, router chi.Router)
+}
@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+)
+
+// ExportController provides exports of the metadata in formats other tools can ingest
+type ExportController interface {
+	IsExportController() bool
+
+	WireUp(ctx context.Context, router chi.Router)
+}
@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+)
+
+// GraphController provides the service dependency graph as json, Graphviz DOT or Mermaid
+type GraphController interface {
+	IsGraphController() bool
+
+	WireUp(ctx context.Context, router chi.Router)
+}
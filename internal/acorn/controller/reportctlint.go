@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+)
+
+// ReportController provides reports about problems in the metadata
+type ReportController interface {
+	IsReportController() bool
+
+	WireUp(ctx context.Context, router chi.Router)
+}
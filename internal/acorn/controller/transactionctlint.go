@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+)
+
+// TransactionController applies changes to several owners, services and repositories in a single commit
+type TransactionController interface {
+	IsTransactionController() bool
+
+	WireUp(ctx context.Context, router chi.Router)
+}
@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"context"
+	"github.com/go-chi/chi/v5"
+)
+
+// UserController provides the user centric view of the metadata
+type UserController interface {
+	IsUserController() bool
+
+	WireUp(ctx context.Context, router chi.Router)
+}
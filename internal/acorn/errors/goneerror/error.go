@@ -0,0 +1,34 @@
+package goneerror
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Interhyp/go-backend-service-common/api"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+)
+
+// New creates an annotated error that is rendered as 410 Gone.
+//
+// The common library does not provide this status, so we build the annotated error ourselves. This allows
+// passing Is as an expected type to apierrors.HandleError.
+func New(message string, details string, wrapped error, timestamp time.Time) apierrors.AnnotatedError {
+	return &apierrors.AnnotatedErrorImpl{
+		VApiError: api.ErrorDto{
+			Details:   &details,
+			Message:   &message,
+			Timestamp: &timestamp,
+		},
+		VHttpStatus: http.StatusGone,
+		VWrapped:    wrapped,
+	}
+}
+
+func Is(err error) bool {
+	var ann apierrors.AnnotatedError
+	if !errors.As(err, &ann) {
+		return false
+	}
+	return ann.HttpStatus() == http.StatusGone
+}
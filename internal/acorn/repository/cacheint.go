@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
 )
 
 // Cache is the central in-memory metadata cache, present to speed up read access to the current metadata.
@@ -100,4 +101,16 @@ type Cache interface {
 	//
 	// This is an atomic operation.
 	DeleteRepository(ctx context.Context, key string) error
+
+	// --- redirect cache ---
+
+	// GetRedirects gives you a copy of the former names of renamed entities.
+	//
+	// Empty if nothing has been renamed.
+	GetRedirects(ctx context.Context) (types.Redirects, error)
+
+	// PutRedirects replaces the redirects.
+	//
+	// This is an atomic operation.
+	PutRedirects(ctx context.Context, redirects types.Redirects) error
 }
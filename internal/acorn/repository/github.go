@@ -8,10 +8,19 @@ import (
 type Github interface {
 	StartCheckRun(ctx context.Context, owner, repoName, checkName, sha string) (int64, error)
 	ConcludeCheckRun(ctx context.Context, owner, repoName, checkName string, checkRunId int64, conclusion CheckRunConclusion, details github.CheckRunOutput, actions ...*github.CheckRunAction) error
+	GetChangedFiles(ctx context.Context, owner, repoName string, pullRequest int, base, head string) (ChangedFiles, error)
 	GetUser(ctx context.Context, username string) (*github.User, error)
 	CreateInstallationToken(ctx context.Context, installationId int64) (*github.InstallationToken, *github.Response, error)
 }
 
+// ChangedFiles are the files changed on the way to a head commit, compared from its merge base with a base commit,
+// like a pull request does.
+type ChangedFiles struct {
+	MergeBaseSHA string
+	// Files are the paths of the changed files, renamed files are listed with their old and their new path.
+	Files []string
+}
+
 type CheckRunConclusion string
 
 type CheckRunDetails struct {
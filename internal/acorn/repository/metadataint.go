@@ -14,6 +14,21 @@ type CommitInfo struct {
 	FilesChanged []string
 }
 
+// FileChange is the change a single commit made to the files matched by a FileHistory query.
+type FileChange struct {
+	CommitInfo
+
+	Author string
+
+	// BeforePath is the path of the matching file before the commit, empty if there was none.
+	BeforePath string
+	Before     []byte
+
+	// AfterPath is the path of the matching file after the commit, empty if it was deleted.
+	AfterPath string
+	After     []byte
+}
+
 // Metadata is the central singleton representing the service-metadata git repository.
 //
 // All operations are protected by a mutex, but of course this does not prevent multiple
@@ -59,6 +74,9 @@ type Metadata interface {
 	// a Pull would not generate new information if this commit hash is in the pull.
 	IsCommitKnown(hash string) bool
 
+	// HeadCommitHash is the hash of the newest commit on the mainline that has been cloned, pulled or locally committed.
+	HeadCommitHash() string
+
 	// standard git-aware file operations on the current worktree
 
 	Stat(filename string) (os.FileInfo, error)
@@ -75,4 +93,44 @@ type Metadata interface {
 
 	// Mkdir creates a new directory (and potentially all directories leading up to it). Does nothing if already exists.
 	MkdirAll(path string) error
+
+	// read only access to past commits on the mainline
+
+	// ResolveCommitHash finds a known commit by its full or abbreviated hash.
+	//
+	// Returns an error wrapping os.ErrNotExist if no commit or more than one commit matches.
+	ResolveCommitHash(ctx context.Context, hashPrefix string) (CommitInfo, error)
+
+	// ResolveCommitTime finds the newest commit on the mainline that was committed at or before the given time.
+	//
+	// Returns an error wrapping os.ErrNotExist if the mainline history starts after the given time.
+	ResolveCommitTime(ctx context.Context, at time.Time) (CommitInfo, error)
+
+	// ReadDirAt is like ReadDir, but lists a directory in the tree as of the given commit hash.
+	ReadDirAt(ctx context.Context, commitHash string, path string) ([]os.FileInfo, error)
+
+	// StatAt is like Stat, but looks at the tree as of the given commit hash.
+	StatAt(ctx context.Context, commitHash string, filename string) (os.FileInfo, error)
+
+	// ReadFileAt is like ReadFile, but reads a file in the tree as of the given commit hash.
+	//
+	// The commit info is for the last change to the file at or before the given commit.
+	ReadFileAt(ctx context.Context, commitHash string, filename string) ([]byte, CommitInfo, error)
+
+	// FileHistory lists the changes to files whose path matches on the mainline, newest first.
+	//
+	// A commit that deletes one matching file and adds another, such as moving an entity to a different owner,
+	// is reported as a single change. Merge commits are skipped, their changes are reported for the merged commits.
+	//
+	// stop is called for each change found, in order, and the walk ends once it returns true, so callers that only
+	// need the newest changes do not pay for the whole history. A nil stop walks the whole history.
+	FileHistory(ctx context.Context, matches func(path string) bool, stop func(change FileChange) bool) ([]FileChange, error)
+
+	// FileDeletions lists the deletions of files whose path matches on the mainline, newest first.
+	//
+	// Unlike FileHistory, every deleted file is its own change, with BeforePath and Before set. A file that was
+	// moved is reported as deleted at its old path, FilesChanged tells which files the commit changed.
+	//
+	// Deletions are indexed as commits are cloned, pulled or committed, so this does not walk the history.
+	FileDeletions(ctx context.Context, matches func(path string) bool) ([]FileChange, error)
 }
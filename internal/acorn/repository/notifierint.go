@@ -18,4 +18,6 @@ type Notifier interface {
 	PublishModification(ctx context.Context, payloadName string, payload openapi.NotificationPayload) error
 
 	PublishDeletion(ctx context.Context, payloadName string, payloadType types.NotificationPayloadType)
+
+	PublishLifecycleChange(ctx context.Context, payloadName string, payload openapi.NotificationPayload) error
 }
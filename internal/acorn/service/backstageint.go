@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"github.com/Interhyp/metadata-service/internal/types"
+)
+
+// Backstage converts the metadata into entities of the Backstage software catalog.
+//
+// Owners become Group entities, services become Component entities with the source location of their
+// repositories, and the apis services provide become API entities.
+type Backstage interface {
+	IsBackstage() bool
+
+	Setup() error
+
+	// GetEntities returns the catalog entities, groups first, then components, then apis, each sorted by name.
+	//
+	// If ownerAlias is set, only the entities of that owner are returned. An unknown owner gives a not found error.
+	GetEntities(ctx context.Context, ownerAlias string) ([]types.BackstageEntity, error)
+}
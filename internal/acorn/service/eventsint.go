@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/types"
+)
+
+// Events distributes update events to the subscribers of the live event stream.
+//
+// The Updater publishes every event it learns about, including those that are not sent to Kafka, so this works
+// without Kafka. The most recent events are kept so subscribers can resume where they left off.
+type Events interface {
+	IsEvents() bool
+
+	Setup() error
+
+	// Publish passes an event to all subscribers whose filter it matches.
+	//
+	// Subscribers that do not keep up are dropped, they can resume from the last event they received.
+	Publish(ctx context.Context, event repository.UpdateEvent)
+
+	// Subscribe returns a channel that receives the matching events published from now on, and a function that
+	// ends the subscription. The channel is closed when the subscription ends.
+	//
+	// If lastEventId is the commit hash of a recent event, the matching events published after it are
+	// delivered first. If it is set but unknown, a gone error is returned, the subscriber then needs to
+	// start over by reading the current state.
+	Subscribe(ctx context.Context, lastEventId string, filter types.EventFilter) (<-chan repository.UpdateEvent, func(), error)
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
+)
+
+// Graph provides the dependency graph between services, built from the dependsOn, providesApis and consumesApis
+// relations in their spec.
+type Graph interface {
+	IsGraph() bool
+
+	Setup() error
+
+	// GetGraph returns the graph of all services and apis, or if rootService is set, the part of it that is
+	// reachable from that service in the given direction within depth steps. A depth of 0 means no limit.
+	GetGraph(ctx context.Context, rootService string, depth int, direction types.GraphDirection) (openapi.GraphDto, error)
+
+	// ValidateDependencies returns a bad request error if changing the spec of a service from current to candidate
+	// adds a dependsOn reference to a service whose lifecycle allows no new dependents. Added references to services
+	// that do not exist, or that close a dependsOn cycle, are allowed, but recorded for the response with
+	// types.AddWarning, and GetGraph reports them.
+	//
+	// current is nil for new services. References that were already present are not checked again, so existing
+	// problems do not block unrelated changes.
+	ValidateDependencies(ctx context.Context, serviceName string, current *openapi.ServiceSpecDto, candidate *openapi.ServiceSpecDto) error
+
+	// ValidateDependenciesIn is ValidateDependencies against the given services instead of the cached ones, for
+	// example the state a transaction would result in.
+	ValidateDependenciesIn(ctx context.Context, services map[string]openapi.ServiceDto, serviceName string, current *openapi.ServiceSpecDto, candidate *openapi.ServiceSpecDto) error
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/Interhyp/metadata-service/api"
 	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/types"
 )
 
 // Mapper translates between the git repo representation (yaml) and the business entities.
@@ -31,16 +32,100 @@ type Mapper interface {
 	DeleteOwner(ctx context.Context, ownerAlias string, jiraIssue string) (openapi.OwnerPatchDto, error)
 	IsOwnerEmpty(ctx context.Context, ownerAlias string) bool
 
+	// RenameOwner moves all files of an owner to the new alias in a single commit.
+	//
+	// Group references to the owner ("@old.group") are rewritten everywhere, and the old alias is added to the
+	// redirects. Returns the renamed owner with commit hash and timestamp, and the entities whose files changed.
+	RenameOwner(ctx context.Context, oldAlias string, newAlias string, jiraIssue string) (openapi.OwnerDto, repository.EventAffects, error)
+
+	// TransferOwnership moves services and repositories of one owner to another owner in a single commit.
+	//
+	// The files are moved unchanged. Unlike WriteServiceWithChangedOwner, the services do not take their repositories
+	// along, include them in repoKeys. Returns the commit info.
+	TransferOwnership(ctx context.Context, fromOwner string, toOwner string, serviceNames []string, repoKeys []string, jiraIssue string) (repository.CommitInfo, error)
+
+	// WriteTransaction writes, moves and deletes the files of all changed entities in a single commit, whose message
+	// is the jira issue followed by the description.
+	//
+	// The changes must already be consistent, nothing is validated here. Returns the commit info.
+	WriteTransaction(ctx context.Context, changes TransactionChanges, description string, jiraIssue string) (repository.CommitInfo, error)
+
 	GetSortedServiceNames(ctx context.Context) ([]string, error)
 	GetService(ctx context.Context, serviceName string) (openapi.ServiceDto, error)
 	WriteService(ctx context.Context, serviceName string, service openapi.ServiceDto) (openapi.ServiceDto, error)
 	DeleteService(ctx context.Context, serviceName string, jiraIssue string) (openapi.ServicePatchDto, error)
 
+	// RenameService moves a service to its new name in a single commit.
+	//
+	// The old name is added to the previous names of the service and to the redirects, and dependencies of other
+	// services are rewritten. Returns the renamed service with commit hash and timestamp, and the entities whose
+	// files changed.
+	RenameService(ctx context.Context, oldName string, newName string, jiraIssue string) (openapi.ServiceDto, repository.EventAffects, error)
+
 	GetSortedRepositoryKeys(ctx context.Context) ([]string, error)
 	GetRepository(ctx context.Context, repoKey string) (openapi.RepositoryDto, error)
 	WriteRepository(ctx context.Context, repoKey string, repository openapi.RepositoryDto) (openapi.RepositoryDto, error)
 	DeleteRepository(ctx context.Context, repoKey string, jiraIssue string) (openapi.RepositoryPatchDto, error)
 
+	// RenameRepository moves a repository to its new key in a single commit.
+	//
+	// The old key is added to the previous names of the repository and to the redirects, and the references from
+	// services are rewritten. Returns the renamed repository with commit hash and timestamp, and the entities whose
+	// files changed.
+	RenameRepository(ctx context.Context, oldKey string, newKey string, jiraIssue string) (openapi.RepositoryDto, repository.EventAffects, error)
+
+	// GetRedirects reads the former names of renamed entities.
+	GetRedirects(ctx context.Context) (types.Redirects, error)
+
+	// ResolveCommit finds the mainline commit for an abbreviated commit hash or an RFC3339 timestamp.
+	//
+	// A timestamp resolves to the newest commit at or before that time.
+	ResolveCommit(ctx context.Context, at string) (repository.CommitInfo, error)
+
+	// HeadCommitHash is the commit hash of the mainline head, which the cache reflects after an update.
+	HeadCommitHash(ctx context.Context) string
+
+	// GetAllAt reads all owners, services and repositories as of the given (full) commit hash.
+	GetAllAt(ctx context.Context, commitHash string) (map[string]openapi.OwnerDto, map[string]openapi.ServiceDto, map[string]openapi.RepositoryDto, error)
+
+	// GetOwnerAt reads a single owner as of the given (full) commit hash, without reading the rest of the tree.
+	//
+	// Not found if the owner did not exist at that commit, or could not be read then, as for GetAllAt.
+	GetOwnerAt(ctx context.Context, commitHash string, ownerAlias string) (openapi.OwnerDto, error)
+
+	// GetServiceAt reads a single service as of the given (full) commit hash, see GetOwnerAt.
+	GetServiceAt(ctx context.Context, commitHash string, serviceName string) (openapi.ServiceDto, error)
+
+	// GetRepositoryAt reads a single repository as of the given (full) commit hash, see GetOwnerAt.
+	GetRepositoryAt(ctx context.Context, commitHash string, repoKey string) (openapi.RepositoryDto, error)
+
+	// GetOwnerHistory lists the changes to an owner, newest first. Empty if the owner never existed.
+	//
+	// stop is called with each change found, and the list ends once it returns true, see util.HistoryStop.
+	// A nil stop lists the whole history.
+	GetOwnerHistory(ctx context.Context, ownerAlias string, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error)
+
+	// GetServiceHistory lists the changes to a service, newest first, including changes of owner, see GetOwnerHistory.
+	GetServiceHistory(ctx context.Context, serviceName string, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error)
+
+	// GetRepositoryHistory lists the changes to a repository, newest first, including changes of owner, see GetOwnerHistory.
+	GetRepositoryHistory(ctx context.Context, repoKey string, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error)
+
+	// GetDeletions lists the entities of a kind (see types.DeletedOwners etc.) that were deleted and do not exist now,
+	// most recently deleted first. Renamed entities are not listed.
+	GetDeletions(ctx context.Context, kind string) ([]openapi.DeletedEntityDto, error)
+
+	// GetDeletedOwner reads the last version of a deleted owner, as listed by GetDeletions.
+	//
+	// Returns an error wrapping os.ErrNotExist if the owner is not listed. Commit hash, timestamp and jira issue are empty.
+	GetDeletedOwner(ctx context.Context, ownerAlias string) (openapi.OwnerDto, error)
+
+	// GetDeletedService reads the last version of a deleted service, see GetDeletedOwner.
+	GetDeletedService(ctx context.Context, serviceName string) (openapi.ServiceDto, error)
+
+	// GetDeletedRepository reads the last version of a deleted repository, see GetDeletedOwner.
+	GetDeletedRepository(ctx context.Context, repoKey string) (openapi.RepositoryDto, error)
+
 	// WriteServiceWithChangedOwner groups the whole operation into a single commit.
 	//
 	// A service takes all its referenced repositories along, but unreferenced repositories will be missed and stay.
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
 )
 
 // Owners provides the business logic for owner metadata.
@@ -11,11 +12,36 @@ type Owners interface {
 
 	Setup() error
 
-	GetOwners(ctx context.Context) (openapi.OwnerListDto, error)
+	// GetOwners returns the owners selected by the page request, or all owners for the zero value.
+	//
+	// If at is set, the owners are read as of that commit hash or time, see Updater.ListSource.
+	GetOwners(ctx context.Context, at string, page types.PageRequest) (openapi.OwnerListDto, error)
 	GetOwner(ctx context.Context, ownerAlias string) (openapi.OwnerDto, error)
 
+	// GetOwnerAt is GetOwner as of a commit hash or time, see Updater.GetOwnerAt.
+	GetOwnerAt(ctx context.Context, ownerAlias string, at string) (openapi.OwnerDto, error)
+
+	// GetOwnerHistory returns the page of changes to an owner selected by the page request, newest first by default.
+	//
+	// Owners that have been deleted still have a history.
+	GetOwnerHistory(ctx context.Context, ownerAlias string, page types.PageRequest) (openapi.HistoryDto, error)
+
+	// GetOwnerDiff returns the field level changes to an owner between two commit hashes or times.
+	//
+	// from is required, an empty to means the current state. Not found if the owner existed at neither.
+	GetOwnerDiff(ctx context.Context, ownerAlias string, from string, to string) (openapi.DiffDto, error)
+
 	GetAllGroupMembers(ctx context.Context, groupOwner string, groupName string) []string
 
+	// ExpandUserGroups replaces all "@owner.group" references in the list with the group members, and removes duplicates.
+	ExpandUserGroups(ctx context.Context, userList []string) []string
+
+	// GetBrokenGroupReferences lists the "@owner.group" references in the members and promoters of owners and in the
+	// approvers, watchers and exemptions of repositories that do not resolve.
+	//
+	// Writes reject new broken references, but existing ones can break when an owner or group is removed or renamed.
+	GetBrokenGroupReferences(ctx context.Context) (openapi.BrokenGroupReferenceListDto, error)
+
 	// CreateOwner returns the owner as it was created, with commit hash and timestamp filled in.
 	CreateOwner(ctx context.Context, ownerAlias string, ownerDto openapi.OwnerCreateDto) (openapi.OwnerDto, error)
 
@@ -25,5 +51,41 @@ type Owners interface {
 	// PatchOwner returns the owner as it was committed, with commit hash and timestamp filled in.
 	PatchOwner(ctx context.Context, ownerAlias string, ownerPatchDto openapi.OwnerPatchDto) (openapi.OwnerDto, error)
 
+	// PatchOwnerDocument applies a merge patch or json patch to the current owner, then continues like UpdateOwner.
+	//
+	// commitHash, timeStamp and jiraIssue start out empty, the patch must set them.
+	PatchOwnerDocument(ctx context.Context, ownerAlias string, patch types.PatchDocument) (openapi.OwnerDto, error)
+
 	DeleteOwner(ctx context.Context, ownerAlias string, deletionInfo openapi.DeletionDto) error
+
+	// RenameOwner moves the owner with all its services and repositories to a new alias in a single commit.
+	//
+	// Group references to the owner are rewritten, and the old alias is kept as a redirect, see GetOwnerRedirect.
+	// Returns the owner under its new alias.
+	RenameOwner(ctx context.Context, ownerAlias string, renameDto openapi.OwnerRenameDto) (openapi.OwnerDto, error)
+
+	// TransferOwnership moves a selection of services and repositories, or all of them, to another owner in a
+	// single commit.
+	//
+	// Services take the repositories they reference along. Other repositories can only be transferred if no
+	// service references them. Returns what was (or, for a dry run, would be) transferred.
+	TransferOwnership(ctx context.Context, ownerAlias string, transferDto openapi.OwnerTransferDto) (openapi.OwnerTransferResultDto, error)
+
+	// ApplyTransactionOperation validates a single owner operation of a transaction against the state and applies it
+	// to the state, see Transactions.
+	//
+	// Unlike DeleteOwner, a deleted owner may still have services or repositories at this point, this is checked once
+	// the whole transaction is applied.
+	ApplyTransactionOperation(ctx context.Context, state *TransactionState, operation openapi.TransactionOperationDto) error
+
+	// GetDeletedOwners lists the owners that were deleted and can be restored, most recently deleted first.
+	GetDeletedOwners(ctx context.Context) (openapi.DeletedListDto, error)
+
+	// RestoreOwner recreates a deleted owner from its last version, which must pass the current validation rules.
+	//
+	// Fails with not found if the owner is not listed by GetDeletedOwners, and with a conflict if it exists again.
+	RestoreOwner(ctx context.Context, ownerAlias string, restoreDto openapi.RestoreDto) (openapi.OwnerDto, error)
+
+	// GetOwnerRedirect gives the current alias of an owner that was renamed, if there is one.
+	GetOwnerRedirect(ctx context.Context, ownerAlias string) (string, bool)
 }
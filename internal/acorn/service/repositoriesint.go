@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/Interhyp/go-backend-service-common/api/apierrors"
 	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
 )
 
 // Repositories provides the business logic for repository metadata.
@@ -14,12 +15,35 @@ type Repositories interface {
 	// ValidRepositoryKey checks validity of a repository key and returns an error describing the problem if invalid
 	ValidRepositoryKey(ctx context.Context, repoKey string) apierrors.AnnotatedError
 
+	// GetRepositories returns the repositories selected by the filters and page request, or all repositories for empty values.
+	//
+	// If at is set, the repositories are read as of that commit hash or time, see Updater.ListSource.
 	GetRepositories(ctx context.Context,
 		ownerAliasFilter string, serviceNameFilter string,
 		nameFilter string, typeFilter string,
-		urlFilter string) (openapi.RepositoryListDto, error)
+		urlFilter string, labelSelector string,
+		at string, page types.PageRequest) (openapi.RepositoryListDto, error)
 	GetRepository(ctx context.Context, repoKey string) (openapi.RepositoryDto, error)
 
+	// GetRepositoryAt is GetRepository as of a commit hash or time, see Updater.GetRepositoryAt.
+	//
+	// Group references are expanded using the current owner groups.
+	GetRepositoryAt(ctx context.Context, repoKey string, at string) (openapi.RepositoryDto, error)
+
+	// GetRepositoryServices returns the services that reference the repository, which may be none.
+	GetRepositoryServices(ctx context.Context, repoKey string) (openapi.ServiceListDto, error)
+
+	// GetRepositoryHistory returns the page of changes to a repository selected by the page request, newest first by default.
+	//
+	// Repositories that have been deleted still have a history, and it includes changes of owner.
+	GetRepositoryHistory(ctx context.Context, repoKey string, page types.PageRequest) (openapi.HistoryDto, error)
+
+	// GetRepositoryDiff returns the field level changes to a repository between two commit hashes or times.
+	//
+	// from is required, an empty to means the current state. Not found if the repository existed at neither.
+	// Group references are compared as written, without expanding them.
+	GetRepositoryDiff(ctx context.Context, repoKey string, from string, to string) (openapi.DiffDto, error)
+
 	// CreateRepository returns the repository as it was created, with commit hash and timestamp filled in.
 	CreateRepository(ctx context.Context, key string, repositoryDto openapi.RepositoryCreateDto) (openapi.RepositoryDto, error)
 
@@ -35,6 +59,43 @@ type Repositories interface {
 	// move the whole service (including its repositories).
 	PatchRepository(ctx context.Context, key string, repositoryPatchDto openapi.RepositoryPatchDto) (openapi.RepositoryDto, error)
 
+	// PatchRepositoryDocument applies a merge patch or json patch to the current repository, then continues like
+	// UpdateRepository. Group references are patched as written, not expanded.
+	//
+	// commitHash, timeStamp and jiraIssue start out empty, the patch must set them.
+	PatchRepositoryDocument(ctx context.Context, key string, patch types.PatchDocument) (openapi.RepositoryDto, error)
+
+	// PatchRepositories applies the patch to all repositories selected by the filters, in a single commit.
+	//
+	// At least one filter must be given, and the owner cannot be changed this way. Commit hash and timestamp of the
+	// patch are not used. Returns the keys of the repositories that changed, with the field level changes to each.
+	PatchRepositories(ctx context.Context, ownerAliasFilter string, typeFilter string, labelSelector string, repositoryPatchDto openapi.RepositoryPatchDto) (openapi.RepositoryBulkPatchResultDto, error)
+
 	// DeleteRepository will fail if the repo is still referenced by its service. Delete that one first.
 	DeleteRepository(ctx context.Context, key string, deletionInfo openapi.DeletionDto) error
+
+	// RenameRepository moves the repository to a new key in a single commit.
+	//
+	// References from services are rewritten, and the old key is added to the previous names of the repository
+	// and kept as a redirect, see GetRepositoryRedirect. Returns the repository under its new key.
+	RenameRepository(ctx context.Context, key string, renameDto openapi.RepositoryRenameDto) (openapi.RepositoryDto, error)
+
+	// ApplyTransactionOperation validates a single repository operation of a transaction against the state and
+	// applies it to the state, see Transactions.
+	//
+	// Unlike the single entity operations, the owner and references from services are checked once the whole
+	// transaction is applied.
+	ApplyTransactionOperation(ctx context.Context, state *TransactionState, operation openapi.TransactionOperationDto) error
+
+	// GetDeletedRepositories lists the repositories that were deleted and can be restored, most recently deleted first.
+	GetDeletedRepositories(ctx context.Context) (openapi.DeletedListDto, error)
+
+	// RestoreRepository recreates a deleted repository from its last version, under the owner it had when deleted.
+	//
+	// The repository must pass the current validation rules. Fails with not found if the repository is not listed by
+	// GetDeletedRepositories, and with a conflict if it exists again.
+	RestoreRepository(ctx context.Context, key string, restoreDto openapi.RestoreDto) (openapi.RepositoryDto, error)
+
+	// GetRepositoryRedirect gives the current key of a repository that was renamed, if there is one.
+	GetRepositoryRedirect(ctx context.Context, key string) (string, bool)
 }
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"github.com/Interhyp/metadata-service/api"
+)
+
+// Search provides full-text search across owners, services and repositories.
+//
+// The index is maintained by the Updater whenever it changes the cache, so searching never touches the metadata
+// repository.
+type Search interface {
+	IsSearch() bool
+
+	Setup() error
+
+	// -- index maintenance, called by Updater --
+
+	IndexOwner(ctx context.Context, ownerAlias string, owner openapi.OwnerDto)
+	RemoveOwner(ctx context.Context, ownerAlias string)
+
+	IndexService(ctx context.Context, serviceName string, service openapi.ServiceDto)
+	RemoveService(ctx context.Context, serviceName string)
+
+	IndexRepository(ctx context.Context, key string, repository openapi.RepositoryDto)
+	RemoveRepository(ctx context.Context, key string)
+
+	// -- queries --
+
+	// Search returns at most limit hits that contain all whitespace separated terms of the query, best first.
+	Search(ctx context.Context, query string, limit int) (openapi.SearchResultDto, error)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
 )
 
 // Services provides the business logic for service metadata.
@@ -11,9 +12,33 @@ type Services interface {
 
 	Setup() error
 
-	GetServices(ctx context.Context, ownerAliasFilter string) (openapi.ServiceListDto, error)
+	// GetServices returns the services selected by the filters and page request, or all services for empty values.
+	//
+	// If at is set, the services are read as of that commit hash or time, see Updater.ListSource.
+	GetServices(ctx context.Context, ownerAliasFilter string, labelSelector string, at string, page types.PageRequest) (openapi.ServiceListDto, error)
 	GetService(ctx context.Context, serviceName string) (openapi.ServiceDto, error)
 
+	// GetServiceAt is GetService as of a commit hash or time, see Updater.GetServiceAt.
+	GetServiceAt(ctx context.Context, serviceName string, at string) (openapi.ServiceDto, error)
+
+	// GetServiceHistory returns the page of changes to a service selected by the page request, newest first by default.
+	//
+	// Services that have been deleted still have a history, and it includes changes of owner.
+	GetServiceHistory(ctx context.Context, serviceName string, page types.PageRequest) (openapi.HistoryDto, error)
+
+	// GetServiceDiff returns the field level changes to a service between two commit hashes or times.
+	//
+	// from is required, an empty to means the current state. Not found if the service existed at neither.
+	GetServiceDiff(ctx context.Context, serviceName string, from string, to string) (openapi.DiffDto, error)
+
+	// GetServicePromoters returns the usernames that may promote a service, sorted.
+	//
+	// These are the promoters of the owning owner and the configured global promoters, with group references expanded.
+	GetServicePromoters(ctx context.Context, serviceName string) (openapi.ServicePromotersDto, error)
+
+	// IsServicePromoter tells whether the user is one of the promoters of a service, see GetServicePromoters.
+	IsServicePromoter(ctx context.Context, serviceName string, username string) (bool, error)
+
 	// CreateService returns the service as it was created, with commit hash and timestamp filled in.
 	CreateService(ctx context.Context, serviceName string, serviceDto openapi.ServiceCreateDto) (openapi.ServiceDto, error)
 
@@ -27,8 +52,38 @@ type Services interface {
 	// Changing the owner of a service is supported, and will also move any referenced repositories to the new owner.
 	PatchService(ctx context.Context, serviceName string, servicePatchDto openapi.ServicePatchDto) (openapi.ServiceDto, error)
 
+	// PatchServiceDocument applies a merge patch or json patch to the current service, then continues like UpdateService.
+	//
+	// commitHash, timeStamp and jiraIssue start out empty, the patch must set them.
+	PatchServiceDocument(ctx context.Context, serviceName string, patch types.PatchDocument) (openapi.ServiceDto, error)
+
 	// DeleteService deletes a service, but leaves its repositories behind
 	//
 	// Reason: they still need to be configured by bit-brother.
 	DeleteService(ctx context.Context, serviceName string, deletionInfo openapi.DeletionDto) error
+
+	// RenameService moves the service to a new name in a single commit.
+	//
+	// Dependencies of other services are rewritten, and the old name is added to the previous names of the service
+	// and kept as a redirect, see GetServiceRedirect. Returns the service under its new name.
+	RenameService(ctx context.Context, serviceName string, renameDto openapi.ServiceRenameDto) (openapi.ServiceDto, error)
+
+	// ApplyTransactionOperation validates a single service operation of a transaction against the state and applies it
+	// to the state, see Transactions.
+	//
+	// Unlike the single entity operations, the owner, repositories and dependencies are checked once the whole
+	// transaction is applied.
+	ApplyTransactionOperation(ctx context.Context, state *TransactionState, operation openapi.TransactionOperationDto) error
+
+	// GetDeletedServices lists the services that were deleted and can be restored, most recently deleted first.
+	GetDeletedServices(ctx context.Context) (openapi.DeletedListDto, error)
+
+	// RestoreService recreates a deleted service from its last version, under the owner it had when deleted.
+	//
+	// The service must pass the current validation rules, so its owner and the repositories it references must
+	// exist. Fails with not found if the service is not listed by GetDeletedServices, and with a conflict if it exists again.
+	RestoreService(ctx context.Context, serviceName string, restoreDto openapi.RestoreDto) (openapi.ServiceDto, error)
+
+	// GetServiceRedirect gives the current name of a service that was renamed, if there is one.
+	GetServiceRedirect(ctx context.Context, serviceName string) (string, bool)
 }
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"github.com/Interhyp/metadata-service/api"
+)
+
+// TransactionState is the state a transaction results in, built up while its operations are applied in order.
+//
+// It starts out as a copy of the cached owners, services and repositories.
+type TransactionState struct {
+	// JiraIssue is the jira issue of the transaction, used by operations whose body does not give one.
+	JiraIssue string
+
+	Owners       map[string]openapi.OwnerDto
+	Services     map[string]openapi.ServiceDto
+	Repositories map[string]openapi.RepositoryDto
+}
+
+// TransactionChanges are the entities a transaction writes. A nil value means the entity is deleted.
+type TransactionChanges struct {
+	Owners       map[string]*openapi.OwnerDto
+	Services     map[string]*openapi.ServiceDto
+	Repositories map[string]*openapi.RepositoryDto
+}
+
+// Transactions changes several owners, services and repositories together, in a single commit.
+type Transactions interface {
+	IsTransactions() bool
+
+	Setup() error
+
+	// ApplyTransaction applies the operations in order, then validates the references between the entities in
+	// the resulting state, and commits all changes at once with the jira issue of the transaction.
+	//
+	// If any operation fails, nothing is written, and the error details name the index of the operation.
+	// Returns what was (or, for a dry run, would be) changed.
+	ApplyTransaction(ctx context.Context, transactionDto openapi.TransactionDto) (openapi.TransactionResultDto, error)
+}
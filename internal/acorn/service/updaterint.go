@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
 )
 
 // Updater is the central orchestrator component that manages information flow.
@@ -27,12 +28,68 @@ type Updater interface {
 	// Any error closure returns is passed through, and the lock is finally released.
 	WithMetadataLock(ctx context.Context, closure func(context.Context) error) error
 
+	// -- Time travel --
+
+	// ListSource returns the cache a list is read from, and the commit hash it reflects.
+	//
+	// at is either empty for the current metadata, a (possibly abbreviated) commit hash or an RFC3339 timestamp,
+	// which resolves to the newest mainline commit at or before that time. Past commits are read into a read-only
+	// snapshot, whose list timestamps are the timestamp of the commit. Snapshots of recently requested commits are
+	// kept, so repeated requests are cheap, and concurrent requests for the same commit share one snapshot.
+	//
+	// snapshot is the commit hash a previous page of the list was read at, or empty for a first page. It takes
+	// precedence over at, so all pages of a list are read from the same commit. If at is empty and the snapshot
+	// is still the mainline head, this is the current cache.
+	//
+	// If the snapshot commit is no longer known, the error is rendered as 410 Gone.
+	//
+	// This does not take the metadata lock. Past commits never change, so time travel reads do not block writes.
+	ListSource(ctx context.Context, at string, snapshot string) (repository.Cache, string, error)
+
+	// GetOwnerAt reads a single owner as of a past mainline commit, with at as for ListSource.
+	//
+	// This uses the snapshot of the commit if we have one, but does not build one, it only reads the owner.
+	GetOwnerAt(ctx context.Context, at string, ownerAlias string) (openapi.OwnerDto, error)
+
+	// GetServiceAt reads a single service as of a past mainline commit, see GetOwnerAt.
+	GetServiceAt(ctx context.Context, at string, serviceName string) (openapi.ServiceDto, error)
+
+	// GetRepositoryAt reads a single repository as of a past mainline commit, see GetOwnerAt.
+	GetRepositoryAt(ctx context.Context, at string, repoKey string) (openapi.RepositoryDto, error)
+
+	// -- History --
+
+	// GetOwnerHistory lists the changes to an owner in the metadata repository, newest first, see Mapper.GetOwnerHistory.
+	//
+	// History reads only look at past commits, so they do not take the metadata lock.
+	GetOwnerHistory(ctx context.Context, ownerAlias string, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error)
+
+	// GetServiceHistory lists the changes to a service in the metadata repository, newest first, see GetOwnerHistory.
+	GetServiceHistory(ctx context.Context, serviceName string, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error)
+
+	// GetRepositoryHistory lists the changes to a repository in the metadata repository, newest first, see GetOwnerHistory.
+	GetRepositoryHistory(ctx context.Context, key string, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error)
+
+	// -- Deleted entities --
+
+	// GetDeletions lists the deleted entities of a kind that can be restored, see Mapper.GetDeletions.
+	GetDeletions(ctx context.Context, kind string) ([]openapi.DeletedEntityDto, error)
+
+	// GetDeletedOwner reads the last version of a deleted owner, see Mapper.GetDeletedOwner.
+	GetDeletedOwner(ctx context.Context, ownerAlias string) (openapi.OwnerDto, error)
+
+	// GetDeletedService reads the last version of a deleted service, see Mapper.GetDeletedService.
+	GetDeletedService(ctx context.Context, serviceName string) (openapi.ServiceDto, error)
+
+	// GetDeletedRepository reads the last version of a deleted repository, see Mapper.GetDeletedRepository.
+	GetDeletedRepository(ctx context.Context, key string) (openapi.RepositoryDto, error)
+
 	// -- these do lock unless used inside WithMetadataLock(), use that if you need to hold the lock longer --
 
 	// PerformFullUpdate is called by Trigger both for initial cache population and periodic updates.
 	//
 	// It does not send any kafka events - one situation where it might be called is when an event
-	// has been received.
+	// has been received. Events for new commits are still published to the live event stream (see Events).
 	//
 	// Both the git tree and all caches are updated.
 	PerformFullUpdate(ctx context.Context) error
@@ -44,6 +101,9 @@ type Updater interface {
 	// Both the git tree and all caches are updated.
 	PerformFullUpdateWithNotifications(ctx context.Context) error
 
+	// Write and delete operations do nothing in a dry run (see types.WithDryRun). Writes then return their
+	// input unchanged.
+
 	// WriteOwner returns the owner as written, with commit hash and timestamp filled in.
 	//
 	// Sends a kafka event and updates the cache.
@@ -56,6 +116,24 @@ type Updater interface {
 
 	CanDeleteOwner(ctx context.Context, ownerAlias string) bool
 
+	// RenameOwner moves an owner with all its services and repositories to a new alias in a single commit,
+	// see Mapper.RenameOwner. Returns the owner under its new alias.
+	//
+	// Sends a kafka event and updates all caches, including the redirects.
+	RenameOwner(ctx context.Context, oldAlias string, newAlias string, jiraIssue string) (openapi.OwnerDto, error)
+
+	// TransferOwnership moves services and repositories to another owner in a single commit,
+	// see Mapper.TransferOwnership. Returns what was transferred with commit hash and timestamp filled in.
+	//
+	// Sends a kafka event and updates the cache.
+	TransferOwnership(ctx context.Context, fromOwner string, toOwner string, serviceNames []string, repoKeys []string, jiraIssue string) (openapi.OwnerTransferResultDto, error)
+
+	// WriteTransaction writes all changes of a transaction in a single commit, see Mapper.WriteTransaction.
+	// Returns the names of the changed entities with commit hash and timestamp filled in.
+	//
+	// Sends a kafka event and updates all caches.
+	WriteTransaction(ctx context.Context, changes TransactionChanges, description string, jiraIssue string) (openapi.TransactionResultDto, error)
+
 	// WriteService returns the service as written, with commit hash and timestamp filled in.
 	//
 	// This supports changing the owner.
@@ -70,6 +148,12 @@ type Updater interface {
 	// Sends a kafka event and updates the cache.
 	DeleteService(ctx context.Context, serviceName string, deletionInfo openapi.DeletionDto) error
 
+	// RenameService moves a service to a new name in a single commit, see Mapper.RenameService.
+	// Returns the service under its new name.
+	//
+	// Sends a kafka event and updates the cache, including the redirects.
+	RenameService(ctx context.Context, oldName string, newName string, jiraIssue string) (openapi.ServiceDto, error)
+
 	// WriteRepository returns the repository as written, with commit hash and timestamp filled in.
 	//
 	// This supports changing the owner, unless the repository is referenced by a service, then you should not call this.
@@ -84,6 +168,12 @@ type Updater interface {
 	// Sends a kafka event and updates the cache.
 	DeleteRepository(ctx context.Context, key string, deletionInfo openapi.DeletionDto) error
 
+	// RenameRepository moves a repository to a new key in a single commit, see Mapper.RenameRepository.
+	// Returns the repository under its new key.
+	//
+	// Sends a kafka event and updates the cache, including the redirects.
+	RenameRepository(ctx context.Context, oldKey string, newKey string, jiraIssue string) (openapi.RepositoryDto, error)
+
 	// CanMoveOrDeleteRepository checks that no service still references the repository key.
 	//
 	// Expects a current cache and you must be holding the lock.
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+	"github.com/Interhyp/metadata-service/api"
+)
+
+// Users answers what a user is responsible for, collected from the owners and repositories.
+type Users interface {
+	IsUsers() bool
+
+	Setup() error
+
+	// GetUser returns the owners where the user has a role, the owner groups the user belongs to, and the
+	// repositories where the user is an approver or watcher. Group references are expanded using the current
+	// owner groups.
+	//
+	// Users are not stored, so an unknown user is not an error, the lists are just empty.
+	GetUser(ctx context.Context, username string) (openapi.UserDto, error)
+}
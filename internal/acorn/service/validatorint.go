@@ -7,6 +7,8 @@ import (
 
 type Check interface {
 	IsValidator() bool
-	PerformValidationCheckRun(ctx context.Context, owner, repo, sha string) error
+	// PerformValidationCheckRun validates the metadata at sha. With a pull request, only the files it changes
+	// are validated, without one all files are.
+	PerformValidationCheckRun(ctx context.Context, owner, repo string, pullRequest *github.PullRequest, sha string) error
 	PerformRequestedAction(ctx context.Context, requestedAction string, checkRun *github.CheckRun, requestingUser *github.User) error
 }
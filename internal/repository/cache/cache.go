@@ -6,6 +6,7 @@ import (
 	openapi "github.com/Interhyp/metadata-service/api"
 	"github.com/Interhyp/metadata-service/internal/acorn/config"
 	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/types"
 	libcache "github.com/Roshick/go-autumn-synchronisation/pkg/cache"
 	auzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
 	"time"
@@ -23,6 +24,7 @@ type Impl struct {
 	ServiceCache    libcache.Cache[openapi.ServiceDto]
 	RepositoryCache libcache.Cache[openapi.RepositoryDto]
 	TimestampCache  libcache.Cache[string]
+	RedirectCache   libcache.Cache[types.Redirects]
 }
 
 func New(
@@ -39,6 +41,28 @@ func New(
 	}
 }
 
+// NewInMemory constructs a cache that always keeps its entries in memory, regardless of the redis configuration.
+//
+// It is ready to use without calling Setup. Used for snapshots of past versions of the metadata.
+func NewInMemory(
+	configuration librepo.Configuration,
+	customConfig config.CustomConfiguration,
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+) repository.Cache {
+	return &Impl{
+		Configuration:       configuration,
+		CustomConfiguration: customConfig,
+		Logging:             logging,
+		Timestamp:           timestamp,
+		OwnerCache:          libcache.NewMemoryCache[openapi.OwnerDto](),
+		ServiceCache:        libcache.NewMemoryCache[openapi.ServiceDto](),
+		RepositoryCache:     libcache.NewMemoryCache[openapi.RepositoryDto](),
+		TimestampCache:      libcache.NewMemoryCache[string](),
+		RedirectCache:       libcache.NewMemoryCache[types.Redirects](),
+	}
+}
+
 func (s *Impl) IsCache() bool {
 	return true
 }
@@ -60,6 +84,7 @@ const (
 	serviceKeyPrefix    = "v1-service"
 	repositoryKeyPrefix = "v1-repository"
 	timestampKeyPrefix  = "v1-timestamp"
+	redirectKeyPrefix   = "v1-redirect"
 )
 
 func (s *Impl) SetupCache(ctx context.Context) error {
@@ -78,6 +103,9 @@ func (s *Impl) SetupCache(ctx context.Context) error {
 		if s.TimestampCache == nil {
 			s.TimestampCache = libcache.NewMemoryCache[string]()
 		}
+		if s.RedirectCache == nil {
+			s.RedirectCache = libcache.NewMemoryCache[types.Redirects]()
+		}
 	} else {
 		s.Logging.Logger().Ctx(ctx).Info().Printf("using redis at %s", redisUrl)
 		redisPassword := s.CustomConfiguration.RedisUrl()
@@ -109,6 +137,13 @@ func (s *Impl) SetupCache(ctx context.Context) error {
 			}
 			s.TimestampCache = cache
 		}
+		if s.RedirectCache == nil {
+			cache, err := libcache.NewRedisCache[types.Redirects](redisUrl, redisPassword, redirectKeyPrefix)
+			if err != nil {
+				return err
+			}
+			s.RedirectCache = cache
+		}
 	}
 	return nil
 }
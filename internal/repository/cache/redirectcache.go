@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/internal/types"
+)
+
+const redirectWhat = "redirect"
+
+const redirectKey = "redirects"
+
+func (s *Impl) GetRedirects(ctx context.Context) (types.Redirects, error) {
+	valPtr, err := s.RedirectCache.Get(ctx, redirectKey)
+	if err != nil {
+		details := "error reading redirects from cache"
+		s.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("%s: %s", details, err.Error())
+		return types.Redirects{}, apierrors.NewBadGatewayError("cache.redirect.error", details, err, s.Timestamp.Now())
+	}
+	if valPtr == nil {
+		// nothing has been renamed
+		return types.Redirects{}, nil
+	}
+	return *valPtr, nil
+}
+
+func (s *Impl) PutRedirects(ctx context.Context, redirects types.Redirects) error {
+	return putEntry(ctx, redirectWhat, s, s.RedirectCache, redirectKey, redirects)
+}
@@ -41,6 +41,16 @@ func (c *CustomConfigImpl) AuthGroupWrite() string {
 	return c.VAuthGroupWrite
 }
 
+func (c *CustomConfigImpl) GlobalPromoters() []string {
+	result := make([]string, 0)
+	for _, promoter := range strings.Split(c.VGlobalPromoters, ",") {
+		if promoter = strings.TrimSpace(promoter); promoter != "" {
+			result = append(result, promoter)
+		}
+	}
+	return result
+}
+
 func (c *CustomConfigImpl) KafkaGroupIdOverride() string {
 	return c.VKafkaGroupIdOverride
 }
@@ -120,6 +130,24 @@ func (c *CustomConfigImpl) RepositoryKeySeparator() string {
 	return c.VRepositoryKeySeparator
 }
 
+func (c *CustomConfigImpl) ServiceLifecycleInitial() string {
+	return c.VServiceLifecycleInitial
+}
+
+func (c *CustomConfigImpl) ServiceLifecycleTransitions() map[string][]string {
+	return c.VServiceLifecycleTransitions
+}
+
+func (c *CustomConfigImpl) ServiceLifecycleNoNewDependents() []string {
+	result := make([]string, 0)
+	for _, lifecycle := range strings.Split(c.VServiceLifecycleNoNewDependents, ",") {
+		if lifecycle = strings.TrimSpace(lifecycle); lifecycle != "" {
+			result = append(result, lifecycle)
+		}
+	}
+	return result
+}
+
 func (c *CustomConfigImpl) NotificationConsumerConfigs() map[string]config.NotificationConsumerConfig {
 	return c.VNotificationConsumerConfigs
 }
@@ -72,6 +72,13 @@ var CustomConfigItems = []auconfigapi.ConfigItem{
 		Description: "group name or id for write access to this service",
 		Validate:    auconfigapi.ConfigNeedsNoValidation,
 	},
+	{
+		Key:         config.KeyGlobalPromoters,
+		EnvName:     config.KeyGlobalPromoters,
+		Default:     "",
+		Description: "comma separated list of usernames or @owner.group references that may promote every service",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	},
 	{
 		Key:         config.KeyMetadataRepoUrl,
 		EnvName:     config.KeyMetadataRepoUrl,
@@ -197,6 +204,34 @@ var CustomConfigItems = []auconfigapi.ConfigItem{
 		Description: "single character used to separate repository name from repository type. repository name and repository type must not contain separator.",
 		Validate:    auconfigenv.ObtainSingleCharacterValidator(),
 	},
+	{
+		Key:         config.KeyServiceLifecycleInitial,
+		EnvName:     config.KeyServiceLifecycleInitial,
+		Default:     "experimental",
+		Description: "lifecycle of newly created services. Must be one of the lifecycles in SERVICE_LIFECYCLE_TRANSITIONS.",
+		Validate: func(key string) error {
+			value := auconfigenv.Get(key)
+			return validateServiceLifecycleInitial(value, auconfigenv.Get(config.KeyServiceLifecycleTransitions))
+		},
+	},
+	{
+		Key:         config.KeyServiceLifecycleTransitions,
+		EnvName:     config.KeyServiceLifecycleTransitions,
+		Default:     `{"experimental":["operational","deprecated"],"operational":["deprecated"],"deprecated":["operational","decommissionable"],"decommissionable":["deprecated"]}`,
+		Description: "JSON object mapping each service lifecycle to the list of lifecycles a service may change to from it.",
+		Validate: func(key string) error {
+			value := auconfigenv.Get(key)
+			_, err := parseServiceLifecycleTransitions(value)
+			return err
+		},
+	},
+	{
+		Key:         config.KeyServiceLifecycleNoNewDependents,
+		EnvName:     config.KeyServiceLifecycleNoNewDependents,
+		Default:     "deprecated,decommissionable",
+		Description: "comma separated list of service lifecycles in which no new dependsOn references to the service may be added.",
+		Validate:    auconfigapi.ConfigNeedsNoValidation,
+	},
 	{
 		Key:         config.KeyNotificationConsumerConfigs,
 		EnvName:     config.KeyNotificationConsumerConfigs,
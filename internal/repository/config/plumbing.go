@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"slices"
@@ -27,6 +28,7 @@ type CustomConfigImpl struct {
 	VAuthOidcKeySetUrl                  string
 	VAuthOidcTokenAudience              string
 	VAuthGroupWrite                     string
+	VGlobalPromoters                    string
 	VKafkaGroupIdOverride               string
 	VMetadataRepoUrl                    string
 	VMetadataRepoMainline               string
@@ -46,6 +48,9 @@ type CustomConfigImpl struct {
 	VRepositoryNameMaxLength            uint16
 	VRepositoryTypes                    string
 	VRepositoryKeySeparator             string
+	VServiceLifecycleInitial            string
+	VServiceLifecycleTransitions        map[string][]string
+	VServiceLifecycleNoNewDependents    string
 	VNotificationConsumerConfigs        map[string]config.NotificationConsumerConfig
 	VRedisUrl                           string
 	VRedisPassword                      string
@@ -92,6 +97,7 @@ func (c *CustomConfigImpl) Obtain(getter func(key string) string) {
 	c.VAuthOidcKeySetUrl = getter(config.KeyAuthOidcKeySetUrl)
 	c.VAuthOidcTokenAudience = getter(config.KeyAuthOidcTokenAudience)
 	c.VAuthGroupWrite = getter(config.KeyAuthGroupWrite)
+	c.VGlobalPromoters = getter(config.KeyGlobalPromoters)
 	c.VMetadataRepoUrl = getter(config.KeyMetadataRepoUrl)
 	c.VMetadataRepoMainline = getter(config.KeyMetadataRepoMainline)
 	c.VUpdateJobIntervalCronPart = getter(config.KeyUpdateJobIntervalMinutes)
@@ -110,6 +116,9 @@ func (c *CustomConfigImpl) Obtain(getter func(key string) string) {
 	c.VRepositoryNameMaxLength = toUint16(getter(config.KeyRepositoryNameMaxLength))
 	c.VRepositoryTypes = getter(config.KeyRepositoryTypes)
 	c.VRepositoryKeySeparator = getter(config.KeyRepositoryKeySeparator)
+	c.VServiceLifecycleInitial = getter(config.KeyServiceLifecycleInitial)
+	c.VServiceLifecycleTransitions, _ = parseServiceLifecycleTransitions(getter(config.KeyServiceLifecycleTransitions))
+	c.VServiceLifecycleNoNewDependents = getter(config.KeyServiceLifecycleNoNewDependents)
 	c.VNotificationConsumerConfigs, _ = parseNotificationConsumerConfigs(getter(config.KeyNotificationConsumerConfigs))
 	c.VRedisUrl = getter(config.KeyRedisUrl)
 	c.VRedisPassword = getter(config.KeyRedisPassword)
@@ -153,6 +162,50 @@ func toBoolean(value string) (bool, error) {
 	return boolValue, nil
 }
 
+func parseServiceLifecycleTransitions(rawJson string) (map[string][]string, error) {
+	result := make(map[string][]string)
+	if err := json.Unmarshal([]byte(rawJson), &result); err != nil {
+		return nil, err
+	}
+
+	messages := make([]string, 0)
+	for _, from := range sortedKeys(result) {
+		for _, to := range result[from] {
+			if _, ok := result[to]; !ok {
+				messages = append(messages, fmt.Sprintf("Service lifecycle '%s' has a transition to unknown lifecycle '%s'.", from, to))
+			}
+		}
+	}
+	if len(messages) > 0 {
+		return nil, errors.New(strings.Join(messages, " "))
+	}
+	return result, nil
+}
+
+// validateServiceLifecycleInitial checks that the lifecycle of new services is one of the configured lifecycles,
+// otherwise new services would be stuck in it.
+//
+// Transitions that fail to parse are reported for their own field.
+func validateServiceLifecycleInitial(initial string, rawTransitionsJson string) error {
+	transitions := make(map[string][]string)
+	if err := json.Unmarshal([]byte(rawTransitionsJson), &transitions); err != nil {
+		return nil
+	}
+	if _, ok := transitions[initial]; !ok {
+		return fmt.Errorf("Service lifecycle '%s' is not one of the lifecycles in %s.", initial, config.KeyServiceLifecycleTransitions)
+	}
+	return nil
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
 func parseNotificationConsumerConfigs(rawJson string) (map[string]config.NotificationConsumerConfig, error) {
 	result := make(map[string]config.NotificationConsumerConfig)
 	if rawJson == "" {
@@ -210,6 +263,9 @@ func parseNotificationConsumerConfigs(rawJson string) (map[string]config.Notific
 				case openapi.DeletedEvent.String():
 					types[key][openapi.DeletedEvent] = struct{}{}
 					break
+				case openapi.LifecycleChangedEvent.String():
+					types[key][openapi.LifecycleChangedEvent] = struct{}{}
+					break
 				default:
 					errors = append(errors, fmt.Sprintf("Notification consumer config '%s' contains invalid event type '%s'.", configIdentifier, eventCandidate))
 					continue
@@ -79,7 +79,7 @@ func TestValidate_LotsOfErrors(t *testing.T) {
 	_, err := tstSetupCutAndLogRecorder(t, "invalid-config-values.yaml")
 
 	require.NotNil(t, err)
-	require.Contains(t, err.Error(), "some configuration values failed to validate or parse. There were 23 error(s). See details above")
+	require.Contains(t, err.Error(), "some configuration values failed to validate or parse. There were 25 error(s). See details above")
 
 	actualLog := goauzerolog.RecordedLogForTesting.String()
 
@@ -106,6 +106,9 @@ func TestValidate_LotsOfErrors(t *testing.T) {
 	require.Contains(t, actualLog, "Notification consumer config 'caseInvalidEvents' contains invalid event type 'AGAIN_INVALID'.")
 	require.Contains(t, actualLog, "Notification consumer config 'caseMissingUrl' is missing url.")
 	require.Contains(t, actualLog, "Notification consumer config 'caseInvalidUrl' contains invalid url 'this-is-invalid'.")
+
+	require.Contains(t, actualLog, "failed to validate configuration field SERVICE_LIFECYCLE_TRANSITIONS: Service lifecycle 'new' has a transition to unknown lifecycle 'gone'.")
+	require.Contains(t, actualLog, "failed to validate configuration field SERVICE_LIFECYCLE_INITIAL: Service lifecycle 'newest' is not one of the lifecycles in SERVICE_LIFECYCLE_TRANSITIONS.")
 }
 
 func TestAccessors(t *testing.T) {
@@ -128,6 +131,7 @@ func TestAccessors(t *testing.T) {
 	require.Equal(t, "http://keyset", config.Custom(cut).AuthOidcKeySetUrl())
 	require.Equal(t, "some-audience", config.Custom(cut).AuthOidcTokenAudience())
 	require.Equal(t, "admin", config.Custom(cut).AuthGroupWrite())
+	require.Equal(t, []string{"release-bot", "@some-owner.users"}, config.Custom(cut).GlobalPromoters())
 	require.Equal(t, "http://metadata", config.Custom(cut).MetadataRepoUrl())
 	require.Equal(t, "5", config.Custom(cut).UpdateJobIntervalCronPart())
 	require.Equal(t, uint16(30), config.Custom(cut).UpdateJobTimeoutSeconds())
@@ -145,4 +149,7 @@ func TestAccessors(t *testing.T) {
 	require.Equal(t, ";", config.Custom(cut).RepositoryKeySeparator())
 	require.Equal(t, []string{"some-type", "some-other-type"}, config.Custom(cut).RepositoryTypes())
 	require.Equal(t, []string{"some-type", "some-other-type"}, config.Custom(cut).RepositoryTypes())
+	require.Equal(t, "new", config.Custom(cut).ServiceLifecycleInitial())
+	require.Equal(t, map[string][]string{"new": {"old"}, "old": {}}, config.Custom(cut).ServiceLifecycleTransitions())
+	require.Equal(t, []string{"old"}, config.Custom(cut).ServiceLifecycleNoNewDependents())
 }
@@ -89,6 +89,64 @@ func (r *Impl) ConcludeCheckRun(ctx context.Context, owner, repoName, checkName
 	return errors.Join(errs...)
 }
 
+// compareFilesLimit is the maximum number of files GitHub lists in a comparison.
+const compareFilesLimit = 300
+
+// pullRequestFilesLimit is the maximum number of files GitHub lists for a pull request.
+const pullRequestFilesLimit = 3000
+
+// GetChangedFiles lists the files changed between the merge base of base and head, and head.
+//
+// A comparison lists at most 300 files. If there are more, they are listed page by page from the pull request,
+// which lists up to 3000 files. If the list may still be incomplete, this is an error.
+func (r *Impl) GetChangedFiles(ctx context.Context, owner, repoName string, pullRequest int, base, head string) (repository.ChangedFiles, error) {
+	// the files are only listed once, on the first page, the pages are about the commits
+	comparison, _, err := r.client.Repositories.CompareCommits(ctx, owner, repoName, base, head, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return repository.ChangedFiles{}, err
+	}
+	result := repository.ChangedFiles{
+		MergeBaseSHA: comparison.GetMergeBaseCommit().GetSHA(),
+	}
+	if len(comparison.Files) < compareFilesLimit {
+		result.Files = commitFileNames(comparison.Files)
+		return result, nil
+	}
+	if pullRequest == 0 {
+		return repository.ChangedFiles{}, fmt.Errorf("comparing %s/%s %s...%s lists %d files, the list may be incomplete", owner, repoName, base, head, len(comparison.Files))
+	}
+
+	files := make([]*github.CommitFile, 0, len(comparison.Files))
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, response, err := r.client.PullRequests.ListFiles(ctx, owner, repoName, pullRequest, opts)
+		if err != nil {
+			return repository.ChangedFiles{}, err
+		}
+		files = append(files, page...)
+		if response.NextPage == 0 {
+			break
+		}
+		opts.Page = response.NextPage
+	}
+	if len(files) >= pullRequestFilesLimit {
+		return repository.ChangedFiles{}, fmt.Errorf("pull request %s/%s#%d lists %d files, the list may be incomplete", owner, repoName, pullRequest, len(files))
+	}
+	result.Files = commitFileNames(files)
+	return result, nil
+}
+
+func commitFileNames(files []*github.CommitFile) []string {
+	result := make([]string, 0, len(files))
+	for _, file := range files {
+		result = append(result, file.GetFilename())
+		if file.GetPreviousFilename() != "" {
+			result = append(result, file.GetPreviousFilename())
+		}
+	}
+	return result
+}
+
 func (r *Impl) GetUser(ctx context.Context, username string) (*github.User, error) {
 	user, _, err := r.client.Users.Get(ctx, username)
 	return user, err
@@ -0,0 +1,443 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// historyCacheSize is the number of past commits for which we remember the commit info of the files read at them.
+//
+// Finding the commit info of a file requires a walk through its history, so we do not want to do it on every read.
+const historyCacheSize = 4
+
+func commitInfoOf(c *object.Commit) repository.CommitInfo {
+	return repository.CommitInfo{
+		CommitHash: c.Hash.String(),
+		TimeStamp:  c.Author.When,
+		Message:    c.Message,
+	}
+}
+
+func notFoundAt(what string, commitHash string) error {
+	return fmt.Errorf("%s not found at commit %s: %w", what, commitHash, os.ErrNotExist)
+}
+
+func (r *Impl) ResolveCommitHash(_ context.Context, hashPrefix string) (repository.CommitInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hashPrefix = strings.ToLower(hashPrefix)
+	found := ""
+	for hash := range r.KnownCommits {
+		if strings.HasPrefix(hash, hashPrefix) {
+			if found != "" {
+				return repository.CommitInfo{}, fmt.Errorf("commit hash prefix %s is ambiguous: %w", hashPrefix, os.ErrNotExist)
+			}
+			found = hash
+		}
+	}
+	if found == "" {
+		return repository.CommitInfo{}, notFoundAt("commit", hashPrefix)
+	}
+
+	c, err := r.GitRepo.CommitObject(plumbing.NewHash(found))
+	if err != nil {
+		return repository.CommitInfo{}, err
+	}
+	return commitInfoOf(c), nil
+}
+
+func (r *Impl) ResolveCommitTime(_ context.Context, at time.Time) (repository.CommitInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	headRef, err := r.GitRepo.Head()
+	if err != nil {
+		return repository.CommitInfo{}, err
+	}
+
+	commitIterator, err := r.GitRepo.Log(&git.LogOptions{
+		From:  headRef.Hash(),
+		Order: git.LogOrderCommitterTime,
+	})
+	if err != nil {
+		return repository.CommitInfo{}, err
+	}
+
+	var result *object.Commit
+	err = commitIterator.ForEach(func(c *object.Commit) error {
+		if !c.Committer.When.After(at) {
+			result = c
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return repository.CommitInfo{}, err
+	}
+	if result == nil {
+		return repository.CommitInfo{}, fmt.Errorf("no commit at or before %s: %w", at.Format(time.RFC3339), os.ErrNotExist)
+	}
+	return commitInfoOf(result), nil
+}
+
+func (r *Impl) treeAtMustHoldMutex(commitHash string) (*object.Tree, error) {
+	if !r.KnownCommits[commitHash] {
+		return nil, notFoundAt("commit", commitHash)
+	}
+
+	c, err := r.GitRepo.CommitObject(plumbing.NewHash(commitHash))
+	if err != nil {
+		return nil, err
+	}
+	return c.Tree()
+}
+
+func (r *Impl) ReadDirAt(_ context.Context, commitHash string, path string) ([]os.FileInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tree, err := r.treeAtMustHoldMutex(commitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	path = strings.Trim(path, "/")
+	if path != "" {
+		tree, err = tree.Tree(path)
+		if err != nil {
+			if errors.Is(err, object.ErrDirectoryNotFound) {
+				return nil, notFoundAt(path, commitHash)
+			}
+			return nil, err
+		}
+	}
+
+	result := make([]os.FileInfo, 0, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		info, err := r.treeEntryInfo(entry)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+func (r *Impl) StatAt(_ context.Context, commitHash string, filename string) (os.FileInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tree, err := r.treeAtMustHoldMutex(commitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := tree.FindEntry(strings.Trim(filename, "/"))
+	if err != nil {
+		if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+			return nil, notFoundAt(filename, commitHash)
+		}
+		return nil, err
+	}
+	return r.treeEntryInfo(*entry)
+}
+
+func (r *Impl) ReadFileAt(ctx context.Context, commitHash string, filename string) ([]byte, repository.CommitInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tree, err := r.treeAtMustHoldMutex(commitHash)
+	if err != nil {
+		return nil, repository.CommitInfo{}, err
+	}
+
+	file, err := tree.File(filename)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, repository.CommitInfo{}, notFoundAt(filename, commitHash)
+		}
+		return nil, repository.CommitInfo{}, err
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, repository.CommitInfo{}, err
+	}
+
+	commitInfo, err := r.commitInfoAtMustHoldMutex(ctx, commitHash, filename)
+	if err != nil {
+		return nil, repository.CommitInfo{}, err
+	}
+
+	return []byte(contents), commitInfo, nil
+}
+
+// commitInfoAtMustHoldMutex is the equivalent of CommitCacheByFilePath for a file at a past commit.
+//
+// Only the history of the file is walked, from the given commit to the first commit that touched it, so the cost
+// depends on the age of the file, not the size of the tree. Results are remembered for a few commits.
+func (r *Impl) commitInfoAtMustHoldMutex(ctx context.Context, commitHash string, filename string) (repository.CommitInfo, error) {
+	cached, ok := r.HistoryCacheByCommit[commitHash]
+	if ok {
+		if info, ok := cached[filename]; ok {
+			return info, nil
+		}
+	}
+
+	commitIterator, err := r.GitRepo.Log(&git.LogOptions{
+		From:  plumbing.NewHash(commitHash),
+		Order: git.LogOrderCommitterTime,
+		PathFilter: func(path string) bool {
+			return path == filename
+		},
+	})
+	if err != nil {
+		return repository.CommitInfo{}, err
+	}
+
+	var found *object.Commit
+	err = commitIterator.ForEach(func(c *object.Commit) error {
+		found = c
+		return storer.ErrStop
+	})
+	if err != nil {
+		return repository.CommitInfo{}, err
+	}
+	if found == nil {
+		return repository.CommitInfo{}, fmt.Errorf("failed to find commit info on %s at commit %s", filename, commitHash)
+	}
+
+	pathsTouched, _, err := r.pathsTouchedInCommit(ctx, found)
+	if err != nil {
+		return repository.CommitInfo{}, err
+	}
+	info := commitInfoOf(found)
+	info.FilesChanged = pathsTouched
+
+	if !ok {
+		if len(r.HistoryCacheOrder) >= historyCacheSize {
+			delete(r.HistoryCacheByCommit, r.HistoryCacheOrder[0])
+			r.HistoryCacheOrder = r.HistoryCacheOrder[1:]
+		}
+		cached = make(map[string]repository.CommitInfo)
+		r.HistoryCacheByCommit[commitHash] = cached
+		r.HistoryCacheOrder = append(r.HistoryCacheOrder, commitHash)
+	}
+	cached[filename] = info
+
+	return info, nil
+}
+
+func (r *Impl) treeEntryInfo(entry object.TreeEntry) (os.FileInfo, error) {
+	info := treeEntryFileInfo{
+		name: entry.Name,
+		mode: entry.Mode,
+	}
+	if entry.Mode.IsFile() {
+		blob, err := r.GitRepo.BlobObject(entry.Hash)
+		if err != nil {
+			return nil, err
+		}
+		info.size = blob.Size
+	}
+	return info, nil
+}
+
+// treeEntryFileInfo implements os.FileInfo for an entry of a git tree.
+type treeEntryFileInfo struct {
+	name string
+	mode filemode.FileMode
+	size int64
+}
+
+func (i treeEntryFileInfo) Name() string {
+	return i.name
+}
+
+func (i treeEntryFileInfo) Size() int64 {
+	return i.size
+}
+
+func (i treeEntryFileInfo) Mode() fs.FileMode {
+	mode, err := i.mode.ToOSFileMode()
+	if err != nil {
+		return 0
+	}
+	return mode
+}
+
+func (i treeEntryFileInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (i treeEntryFileInfo) IsDir() bool {
+	return i.mode == filemode.Dir
+}
+
+func (i treeEntryFileInfo) Sys() any {
+	return nil
+}
+
+func (r *Impl) FileHistory(ctx context.Context, matches func(path string) bool, stop func(change repository.FileChange) bool) ([]repository.FileChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]repository.FileChange, 0)
+
+	headRef, err := r.GitRepo.Head()
+	if err != nil {
+		return result, err
+	}
+
+	// the path filter only yields commits that touch a matching file, so we do not read the contents of the others
+	commitIterator, err := r.GitRepo.Log(&git.LogOptions{
+		From:       headRef.Hash(),
+		Order:      git.LogOrderCommitterTime,
+		PathFilter: matches,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = commitIterator.ForEach(func(c *object.Commit) error {
+		if c.NumParents() > 1 {
+			return nil
+		}
+
+		change, found, err := r.fileChangeInCommit(ctx, c, matches)
+		if err != nil {
+			return err
+		}
+		if found {
+			result = append(result, change)
+			if stop != nil && stop(change) {
+				return storer.ErrStop
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (r *Impl) fileChangeInCommit(ctx context.Context, c *object.Commit, matches func(path string) bool) (repository.FileChange, bool, error) {
+	result := repository.FileChange{
+		CommitInfo: commitInfoOf(c),
+		Author:     c.Author.Name,
+	}
+
+	changes, err := changesInCommit(ctx, c)
+	if err != nil {
+		return result, false, err
+	}
+
+	found := false
+	for _, change := range changes {
+		fromMatches := change.From.Name != "" && matches(change.From.Name)
+		toMatches := change.To.Name != "" && matches(change.To.Name)
+		if !fromMatches && !toMatches {
+			continue
+		}
+		found = true
+		result.FilesChanged = append(result.FilesChanged, change.To.Name+change.From.Name)
+
+		from, to, err := change.Files()
+		if err != nil {
+			return result, false, err
+		}
+		if fromMatches && from != nil {
+			contents, err := from.Contents()
+			if err != nil {
+				return result, false, err
+			}
+			result.BeforePath = change.From.Name
+			result.Before = []byte(contents)
+		}
+		if toMatches && to != nil {
+			contents, err := to.Contents()
+			if err != nil {
+				return result, false, err
+			}
+			result.AfterPath = change.To.Name
+			result.After = []byte(contents)
+		}
+	}
+	return result, found, nil
+}
+
+// deletedFile is an entry in the index of deleted files, see FileDeletions.
+type deletedFile struct {
+	info       repository.CommitInfo
+	author     string
+	path       string
+	parentHash plumbing.Hash
+}
+
+func (r *Impl) FileDeletions(_ context.Context, matches func(path string) bool) ([]repository.FileChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the index is kept up to date by every clone, pull and commit, so we never need to walk the history here
+	result := make([]repository.FileChange, 0)
+	for _, deleted := range r.Deletions {
+		if !matches(deleted.path) {
+			continue
+		}
+
+		parent, err := r.GitRepo.CommitObject(deleted.parentHash)
+		if err != nil {
+			return result, err
+		}
+		file, err := parent.File(deleted.path)
+		if err != nil {
+			return result, err
+		}
+		contents, err := file.Contents()
+		if err != nil {
+			return result, err
+		}
+
+		result = append(result, repository.FileChange{
+			CommitInfo: deleted.info,
+			Author:     deleted.author,
+			BeforePath: deleted.path,
+			Before:     []byte(contents),
+		})
+	}
+	return result, nil
+}
+
+// changesInCommit compares the tree of a commit with that of its first parent.
+func changesInCommit(ctx context.Context, c *object.Commit) (object.Changes, error) {
+	toTree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	fromTree := &object.Tree{}
+	if c.NumParents() != 0 {
+		firstParent, err := c.Parents().Next()
+		if err != nil {
+			return nil, err
+		}
+
+		fromTree, err = firstParent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return object.DiffTreeContext(ctx, fromTree, toTree)
+}
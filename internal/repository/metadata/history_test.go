@@ -0,0 +1,253 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/go-backend-service-common/repository/logging"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	goauzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/require"
+)
+
+var tstHistoryStart = time.Date(2022, 11, 6, 12, 0, 0, 0, time.UTC)
+
+type tstHistory struct {
+	impl    *Impl
+	commits []string
+}
+
+func tstCommit(t *testing.T, tree *git.Worktree, when time.Time, message string) string {
+	signature := &object.Signature{Name: "someone", Email: "someone@some-organisation.com", When: when}
+	hash, err := tree.Commit(message, &git.CommitOptions{All: true, Author: signature, Committer: signature})
+	require.Nil(t, err)
+	return hash.String()
+}
+
+func tstWrite(t *testing.T, tree *git.Worktree, filename string, contents string) {
+	f, err := tree.Filesystem.Create(filename)
+	require.Nil(t, err)
+	_, err = f.Write([]byte(contents))
+	require.Nil(t, err)
+	require.Nil(t, f.Close())
+	_, err = tree.Add(filename)
+	require.Nil(t, err)
+}
+
+// tstSetupHistory creates three commits an hour apart:
+// the first adds owner a with a service, the second changes owner a and adds owner b, the third removes owner b.
+func tstSetupHistory(t *testing.T) tstHistory {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	require.Nil(t, err)
+	tree, err := repo.Worktree()
+	require.Nil(t, err)
+
+	result := tstHistory{}
+
+	tstWrite(t, tree, "owners/a/owner.info.yaml", "contact: v1\n")
+	tstWrite(t, tree, "owners/a/services/s.yaml", "description: service\n")
+	result.commits = append(result.commits, tstCommit(t, tree, tstHistoryStart, "ISSUE-1: add a"))
+
+	tstWrite(t, tree, "owners/a/owner.info.yaml", "contact: v2\n")
+	tstWrite(t, tree, "owners/b/owner.info.yaml", "contact: b\n")
+	result.commits = append(result.commits, tstCommit(t, tree, tstHistoryStart.Add(time.Hour), "ISSUE-2: change a, add b"))
+
+	_, err = tree.Remove("owners/b/owner.info.yaml")
+	require.Nil(t, err)
+	result.commits = append(result.commits, tstCommit(t, tree, tstHistoryStart.Add(2*time.Hour), "ISSUE-3: remove b"))
+
+	logRecorder := logging.New().(librepo.Logging)
+	goauzerolog.RecordedLogForTesting = new(bytes.Buffer)
+	logRecorder.(*logging.LoggingImpl).SetupForTesting()
+
+	result.impl = &Impl{
+		Logging:               logRecorder,
+		GitRepo:               repo,
+		CommitCacheByFilePath: make(map[string]repository.CommitInfo),
+		KnownCommits:          make(map[string]bool),
+		HistoryCacheByCommit:  make(map[string]map[string]repository.CommitInfo),
+	}
+	require.Nil(t, result.impl.updateCommitCacheMustHoldMutex(tstCtx(), false))
+	return result
+}
+
+func tstCtx() context.Context {
+	return log.Logger.WithContext(context.Background())
+}
+
+func TestResolveCommitTime(t *testing.T) {
+	docs.Description("commit times resolve to the newest commit at or before them")
+	h := tstSetupHistory(t)
+
+	info, err := h.impl.ResolveCommitTime(tstCtx(), tstHistoryStart.Add(90*time.Minute))
+	require.Nil(t, err)
+	require.Equal(t, h.commits[1], info.CommitHash)
+	require.Equal(t, "ISSUE-2: change a, add b", info.Message)
+
+	info, err = h.impl.ResolveCommitTime(tstCtx(), tstHistoryStart)
+	require.Nil(t, err)
+	require.Equal(t, h.commits[0], info.CommitHash)
+
+	_, err = h.impl.ResolveCommitTime(tstCtx(), tstHistoryStart.Add(-time.Second))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestResolveCommitHash(t *testing.T) {
+	docs.Description("abbreviated commit hashes resolve to the full hash")
+	h := tstSetupHistory(t)
+
+	info, err := h.impl.ResolveCommitHash(tstCtx(), h.commits[2][:8])
+	require.Nil(t, err)
+	require.Equal(t, h.commits[2], info.CommitHash)
+	require.Equal(t, tstHistoryStart.Add(2*time.Hour), info.TimeStamp.UTC())
+
+	_, err = h.impl.ResolveCommitHash(tstCtx(), "")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestReadDirAt(t *testing.T) {
+	docs.Description("directories are listed as of the commit")
+	h := tstSetupHistory(t)
+
+	infos, err := h.impl.ReadDirAt(tstCtx(), h.commits[1], "owners/")
+	require.Nil(t, err)
+	require.Equal(t, 2, len(infos))
+	require.Equal(t, "a", infos[0].Name())
+	require.True(t, infos[0].IsDir())
+	require.Equal(t, "b", infos[1].Name())
+
+	infos, err = h.impl.ReadDirAt(tstCtx(), h.commits[2], "owners")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(infos))
+
+	_, err = h.impl.ReadDirAt(tstCtx(), h.commits[0], "owners/b")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestReadFileAt(t *testing.T) {
+	docs.Description("files are read as of the commit, with the commit info of their last change at that point")
+	h := tstSetupHistory(t)
+
+	contents, info, err := h.impl.ReadFileAt(tstCtx(), h.commits[0], "owners/a/owner.info.yaml")
+	require.Nil(t, err)
+	require.Equal(t, "contact: v1\n", string(contents))
+	require.Equal(t, h.commits[0], info.CommitHash)
+
+	contents, info, err = h.impl.ReadFileAt(tstCtx(), h.commits[2], "owners/a/owner.info.yaml")
+	require.Nil(t, err)
+	require.Equal(t, "contact: v2\n", string(contents))
+	require.Equal(t, h.commits[1], info.CommitHash)
+
+	_, info, err = h.impl.ReadFileAt(tstCtx(), h.commits[2], "owners/a/services/s.yaml")
+	require.Nil(t, err)
+	require.Equal(t, h.commits[0], info.CommitHash)
+	cached := h.impl.HistoryCacheByCommit[h.commits[2]]
+	require.Equal(t, 2, len(cached), "only the commit info of the files read is looked up")
+	require.Equal(t, h.commits[1], cached["owners/a/owner.info.yaml"].CommitHash)
+
+	_, _, err = h.impl.ReadFileAt(tstCtx(), h.commits[2], "owners/b/owner.info.yaml")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	stat, err := h.impl.StatAt(tstCtx(), h.commits[1], "owners/b/owner.info.yaml")
+	require.Nil(t, err)
+	require.False(t, stat.IsDir())
+	require.Equal(t, int64(len("contact: b\n")), stat.Size())
+}
+
+func TestFileHistory(t *testing.T) {
+	docs.Description("the history of a file lists the commits that changed it, newest first, including its deletion")
+	h := tstSetupHistory(t)
+
+	changes, err := h.impl.FileHistory(tstCtx(), func(path string) bool {
+		return path == "owners/b/owner.info.yaml"
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(changes))
+
+	require.Equal(t, h.commits[2], changes[0].CommitHash)
+	require.Equal(t, "owners/b/owner.info.yaml", changes[0].BeforePath)
+	require.Equal(t, "contact: b\n", string(changes[0].Before))
+	require.Equal(t, "", changes[0].AfterPath)
+
+	require.Equal(t, h.commits[1], changes[1].CommitHash)
+	require.Equal(t, "", changes[1].BeforePath)
+	require.Equal(t, "contact: b\n", string(changes[1].After))
+	require.Equal(t, "someone", changes[1].Author)
+
+	changes, err = h.impl.FileHistory(tstCtx(), func(path string) bool {
+		return path == "owners/a/services/s.yaml"
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(changes))
+	require.Equal(t, h.commits[0], changes[0].CommitHash)
+}
+
+func TestFileHistory_Stop(t *testing.T) {
+	docs.Description("the history walk ends as soon as the caller has seen enough changes")
+	h := tstSetupHistory(t)
+
+	changes, err := h.impl.FileHistory(tstCtx(), func(path string) bool {
+		return path == "owners/b/owner.info.yaml"
+	}, func(change repository.FileChange) bool {
+		return true
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(changes))
+	require.Equal(t, h.commits[2], changes[0].CommitHash)
+}
+
+func TestFileDeletions(t *testing.T) {
+	docs.Description("file deletions list every deleted matching file, newest first, with its contents before the deletion")
+	h := tstSetupHistory(t)
+
+	deletions, err := h.impl.FileDeletions(tstCtx(), func(path string) bool {
+		return strings.HasSuffix(path, "/owner.info.yaml")
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(deletions))
+	require.Equal(t, h.commits[2], deletions[0].CommitHash)
+	require.Equal(t, "owners/b/owner.info.yaml", deletions[0].BeforePath)
+	require.Equal(t, "contact: b\n", string(deletions[0].Before))
+	require.Equal(t, "", deletions[0].AfterPath)
+	require.Equal(t, "someone", deletions[0].Author)
+	require.Equal(t, []string{"owners/b/owner.info.yaml"}, deletions[0].FilesChanged)
+
+	deletions, err = h.impl.FileDeletions(tstCtx(), func(path string) bool {
+		return strings.HasSuffix(path, ".yaml") && !strings.HasSuffix(path, "/owner.info.yaml")
+	})
+	require.Nil(t, err)
+	require.Equal(t, 0, len(deletions))
+}
+
+func TestFileDeletions_IndexFollowsNewCommits(t *testing.T) {
+	docs.Description("the index of deleted files is extended by new commits without walking the whole history again")
+	h := tstSetupHistory(t)
+
+	tree, err := h.impl.GitRepo.Worktree()
+	require.Nil(t, err)
+	_, err = tree.Remove("owners/a/services/s.yaml")
+	require.Nil(t, err)
+	newest := tstCommit(t, tree, tstHistoryStart.Add(3*time.Hour), "ISSUE-4: remove s")
+	require.Nil(t, h.impl.updateCommitCacheMustHoldMutex(tstCtx(), false))
+
+	deletions, err := h.impl.FileDeletions(tstCtx(), func(path string) bool {
+		return strings.HasSuffix(path, ".yaml")
+	})
+	require.Nil(t, err)
+	require.Equal(t, 2, len(deletions))
+	require.Equal(t, newest, deletions[0].CommitHash)
+	require.Equal(t, "owners/a/services/s.yaml", deletions[0].BeforePath)
+	require.Equal(t, "description: service\n", string(deletions[0].Before))
+	require.Equal(t, h.commits[2], deletions[1].CommitHash)
+}
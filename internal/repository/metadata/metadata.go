@@ -47,6 +47,16 @@ type Impl struct {
 	// AlreadySeenCommit is the commit hash of the newest commit that we have already cached
 	AlreadySeenCommit string
 
+	// HistoryCacheByCommit holds the equivalent of CommitCacheByFilePath for the files read at a few past commits,
+	// keyed by commit hash
+	HistoryCacheByCommit map[string]map[string]repository.CommitInfo
+
+	// HistoryCacheOrder lists the keys of HistoryCacheByCommit, oldest entry first
+	HistoryCacheOrder []string
+
+	// Deletions lists the files deleted on the mainline, newest first, maintained together with the commit cache
+	Deletions []deletedFile
+
 	mu       sync.Mutex
 	LastPull time.Time
 
@@ -70,6 +80,7 @@ func New(
 		CommitCacheByFilePath: make(map[string]repository.CommitInfo),
 		NewCommits:            make([]repository.CommitInfo, 0),
 		KnownCommits:          make(map[string]bool),
+		HistoryCacheByCommit:  make(map[string]map[string]repository.CommitInfo),
 	}
 }
 
@@ -94,31 +105,33 @@ func (r *Impl) Teardown() {
 	r.Discard(ctx)
 }
 
-func (r *Impl) pathsTouchedInCommit(ctx context.Context, commit *object.Commit) ([]string, error) {
+// pathsTouchedInCommit compares a commit with its first parent, and returns all paths touched and the paths deleted.
+func (r *Impl) pathsTouchedInCommit(ctx context.Context, commit *object.Commit) ([]string, []string, error) {
 	result := make([]string, 0)
+	deleted := make([]string, 0)
 
 	// adapted code from object.StatsContext() because it fails to handle renames and binary files correctly
 	fromTree, err := commit.Tree()
 	if err != nil {
-		return result, err
+		return result, deleted, err
 	}
 
 	toTree := &object.Tree{}
 	if commit.NumParents() != 0 {
 		firstParent, err := commit.Parents().Next()
 		if err != nil {
-			return result, err
+			return result, deleted, err
 		}
 
 		toTree, err = firstParent.Tree()
 		if err != nil {
-			return result, err
+			return result, deleted, err
 		}
 	}
 
 	patch, err := toTree.PatchContext(ctx, fromTree)
 	if err != nil {
-		return result, err
+		return result, deleted, err
 	}
 
 	filePatches := patch.FilePatches()
@@ -132,6 +145,7 @@ func (r *Impl) pathsTouchedInCommit(ctx context.Context, commit *object.Commit)
 		} else if to == nil {
 			// File is deleted.
 			path = from.Path()
+			deleted = append(deleted, path)
 		} else if from.Path() != to.Path() {
 			// File is renamed
 			path = to.Path()
@@ -145,7 +159,7 @@ func (r *Impl) pathsTouchedInCommit(ctx context.Context, commit *object.Commit)
 		}
 	}
 
-	return result, nil
+	return result, deleted, nil
 }
 
 func (r *Impl) updateCommitCacheMustHoldMutex(ctx context.Context, collectNewCommits bool) error {
@@ -172,6 +186,7 @@ func (r *Impl) updateCommitCacheMustHoldMutex(ctx context.Context, collectNewCom
 	r.Logging.Logger().Ctx(ctx).Debug().Print("git log worked - console output was: ", r.sanitizedConsoleOutput())
 
 	seenFileThisRun := make(map[string]bool)
+	deletionsThisRun := make([]deletedFile, 0)
 
 	err = commitIterator.ForEach(func(c *object.Commit) error {
 		commitHash := c.Hash.String()
@@ -188,13 +203,25 @@ func (r *Impl) updateCommitCacheMustHoldMutex(ctx context.Context, collectNewCom
 
 		r.KnownCommits[commitHash] = true
 
-		pathsTouched, err := r.pathsTouchedInCommit(ctx, c)
+		pathsTouched, pathsDeleted, err := r.pathsTouchedInCommit(ctx, c)
 		if err != nil {
 			return err
 		}
 
 		info.FilesChanged = pathsTouched
 
+		// merge commits are reported for the merged commits, and the initial commit cannot delete anything
+		if c.NumParents() == 1 {
+			for _, path := range pathsDeleted {
+				deletionsThisRun = append(deletionsThisRun, deletedFile{
+					info:       info,
+					author:     c.Author.Name,
+					path:       path,
+					parentHash: c.ParentHashes[0],
+				})
+			}
+		}
+
 		for _, path := range pathsTouched {
 			_, hasNewer := seenFileThisRun[path]
 			if !hasNewer {
@@ -210,6 +237,7 @@ func (r *Impl) updateCommitCacheMustHoldMutex(ctx context.Context, collectNewCom
 		return nil
 	})
 
+	r.Deletions = append(deletionsThisRun, r.Deletions...)
 	r.AlreadySeenCommit = headRef.Hash().String()
 	return nil
 }
@@ -225,6 +253,10 @@ func (r *Impl) Clone(ctx context.Context) error {
 	r.CommitCacheByFilePath = make(map[string]repository.CommitInfo)
 	r.NewCommits = make([]repository.CommitInfo, 0)
 	r.KnownCommits = make(map[string]bool)
+	r.HistoryCacheByCommit = make(map[string]map[string]repository.CommitInfo)
+	r.HistoryCacheOrder = nil
+	r.Deletions = nil
+	r.AlreadySeenCommit = ""
 
 	childCtxWithTimeout, cancel := context.WithTimeout(ctx, 1*time.Minute)
 	defer cancel()
@@ -404,6 +436,10 @@ func (r *Impl) Discard(ctx context.Context) {
 	r.CommitCacheByFilePath = make(map[string]repository.CommitInfo)
 	r.NewCommits = make([]repository.CommitInfo, 0)
 	r.KnownCommits = make(map[string]bool)
+	r.HistoryCacheByCommit = make(map[string]map[string]repository.CommitInfo)
+	r.HistoryCacheOrder = nil
+	r.Deletions = nil
+	r.AlreadySeenCommit = ""
 }
 
 func (r *Impl) logContextErrorDetails(ctx context.Context, operation string, contextName string) {
@@ -440,6 +476,13 @@ func (r *Impl) IsCommitKnown(hash string) bool {
 	return ok
 }
 
+func (r *Impl) HeadCommitHash() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.AlreadySeenCommit
+}
+
 func (r *Impl) Stat(filename string) (os.FileInfo, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -117,6 +117,15 @@ func (r *Impl) PublishDeletion(ctx context.Context, name string, payloadType typ
 	r.publish(ctx, name, types.DeletedEvent, payloadType, nil)
 }
 
+func (r *Impl) PublishLifecycleChange(ctx context.Context, name string, payload openapi.NotificationPayload) error {
+	notificationType := determineType(payload)
+	if notificationType == nil {
+		return fmt.Errorf("unable to determine payload type")
+	}
+	r.publish(ctx, name, types.LifecycleChangedEvent, *notificationType, &payload)
+	return nil
+}
+
 func determineType(payload openapi.NotificationPayload) *types.NotificationPayloadType {
 	owner := payload.Owner
 	service := payload.Service
@@ -0,0 +1,295 @@
+package backstage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/types"
+	auzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
+)
+
+const apiVersion = "backstage.io/v1alpha1"
+
+const (
+	KindGroup     = "Group"
+	KindComponent = "Component"
+	KindApi       = "API"
+)
+
+const (
+	AnnotationSourceLocation = "backstage.io/source-location"
+	AnnotationProjectSlug    = "github.com/project-slug"
+)
+
+// lifecycleUnknown is used for services without a lifecycle, Backstage requires one.
+const lifecycleUnknown = "unknown"
+
+// apiTypeUnknown is used for all apis, we only know their names.
+const apiTypeUnknown = "unknown"
+
+type Impl struct {
+	Configuration librepo.Configuration
+	Logging       librepo.Logging
+	Timestamp     librepo.Timestamp
+	Cache         repository.Cache
+}
+
+func New(
+	configuration librepo.Configuration,
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+	cache repository.Cache,
+) service.Backstage {
+	return &Impl{
+		Configuration: configuration,
+		Logging:       logging,
+		Timestamp:     timestamp,
+		Cache:         cache,
+	}
+}
+
+func (s *Impl) IsBackstage() bool {
+	return true
+}
+
+func (s *Impl) Setup() error {
+	ctx := auzerolog.AddLoggerToCtx(context.Background())
+
+	s.Logging.Logger().Ctx(ctx).Info().Print("successfully set up backstage business component")
+	return nil
+}
+
+func (s *Impl) GetEntities(ctx context.Context, ownerAlias string) ([]types.BackstageEntity, error) {
+	if ownerAlias != "" {
+		if _, err := s.Cache.GetOwner(ctx, ownerAlias); err != nil {
+			return nil, apierrors.NewNotFoundError("owner.notfound", fmt.Sprintf("owner %s not found", ownerAlias), err, s.Timestamp.Now())
+		}
+	}
+
+	groups := make([]types.BackstageEntity, 0)
+	aliases, err := s.Cache.GetSortedOwnerAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, alias := range aliases {
+		if ownerAlias != "" && alias != ownerAlias {
+			continue
+		}
+		owner, err := s.Cache.GetOwner(ctx, alias)
+		if err != nil {
+			// deleted while we were reading
+			continue
+		}
+		groups = append(groups, groupEntity(alias, owner))
+	}
+
+	components := make([]types.BackstageEntity, 0)
+	apis := make(map[string]types.BackstageEntity)
+	names, err := s.Cache.GetSortedServiceNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		theService, err := s.Cache.GetService(ctx, name)
+		if err != nil || (ownerAlias != "" && theService.Owner != ownerAlias) {
+			continue
+		}
+		components = append(components, s.componentEntity(ctx, name, theService))
+
+		// the first provider in name order owns the api
+		if theService.Spec != nil {
+			for _, api := range theService.Spec.ProvidesApis {
+				if _, exists := apis[api]; !exists {
+					apis[api] = apiEntity(api, name, theService)
+				}
+			}
+		}
+	}
+
+	result := append(groups, components...)
+	apiNames := make([]string, 0, len(apis))
+	for api := range apis {
+		apiNames = append(apiNames, api)
+	}
+	sort.Strings(apiNames)
+	for _, api := range apiNames {
+		result = append(result, apis[api])
+	}
+	return result, nil
+}
+
+func groupEntity(alias string, owner openapi.OwnerDto) types.BackstageEntity {
+	metadata := types.BackstageMetadata{
+		Name:  alias,
+		Links: links(owner.Links),
+	}
+	spec := types.BackstageGroupSpec{
+		Type:     "team",
+		Children: []string{},
+		Members:  owner.Members,
+	}
+	profile := types.BackstageGroupProfile{}
+	if owner.DisplayName != nil {
+		metadata.Title = *owner.DisplayName
+		profile.DisplayName = *owner.DisplayName
+	}
+	if strings.Contains(owner.Contact, "@") {
+		profile.Email = owner.Contact
+	}
+	if profile != (types.BackstageGroupProfile{}) {
+		spec.Profile = &profile
+	}
+	return types.BackstageEntity{
+		ApiVersion: apiVersion,
+		Kind:       KindGroup,
+		Metadata:   metadata,
+		Spec:       spec,
+	}
+}
+
+func (s *Impl) componentEntity(ctx context.Context, name string, theService openapi.ServiceDto) types.BackstageEntity {
+	metadata := types.BackstageMetadata{
+		Name:        name,
+		Description: deref(theService.Description),
+		Labels:      theService.Labels,
+		Annotations: s.sourceAnnotations(ctx, theService.Repositories),
+		Tags:        theService.Tags,
+	}
+	for _, quicklink := range theService.Quicklinks {
+		if quicklink.Url != nil && *quicklink.Url != "" {
+			metadata.Links = append(metadata.Links, types.BackstageLink{Url: *quicklink.Url, Title: deref(quicklink.Title)})
+		}
+	}
+
+	spec := types.BackstageComponentSpec{
+		Type:      "service",
+		Lifecycle: lifecycle(theService),
+		Owner:     groupRef(theService.Owner),
+	}
+	if theService.Spec != nil {
+		spec.System = deref(theService.Spec.System)
+		for _, target := range theService.Spec.DependsOn {
+			spec.DependsOn = append(spec.DependsOn, "component:"+target)
+		}
+		spec.ProvidesApis = theService.Spec.ProvidesApis
+		spec.ConsumesApis = theService.Spec.ConsumesApis
+	}
+	return types.BackstageEntity{
+		ApiVersion: apiVersion,
+		Kind:       KindComponent,
+		Metadata:   metadata,
+		Spec:       spec,
+	}
+}
+
+func apiEntity(api string, providerName string, provider openapi.ServiceDto) types.BackstageEntity {
+	spec := types.BackstageApiSpec{
+		Type:       apiTypeUnknown,
+		Lifecycle:  lifecycle(provider),
+		Owner:      groupRef(provider.Owner),
+		Definition: fmt.Sprintf("provided by component:%s, the definition is not known to the metadata service", providerName),
+	}
+	if provider.Spec != nil {
+		spec.System = deref(provider.Spec.System)
+	}
+	return types.BackstageEntity{
+		ApiVersion: apiVersion,
+		Kind:       KindApi,
+		Metadata:   types.BackstageMetadata{Name: api},
+		Spec:       spec,
+	}
+}
+
+// sourceAnnotations points to the implementation repository, or the first repository if there is none.
+func (s *Impl) sourceAnnotations(ctx context.Context, repoKeys []string) map[string]string {
+	chosen := ""
+	for _, key := range repoKeys {
+		if strings.HasSuffix(key, ".implementation") {
+			chosen = key
+			break
+		}
+	}
+	if chosen == "" && len(repoKeys) > 0 {
+		chosen = repoKeys[0]
+	}
+	if chosen == "" {
+		return nil
+	}
+
+	theRepository, err := s.Cache.GetRepository(ctx, chosen)
+	if err != nil {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("skipping source location of missing repository %s", chosen)
+		return nil
+	}
+	host, path, ok := parseRepositoryUrl(theRepository.Url)
+	if !ok {
+		return nil
+	}
+
+	result := map[string]string{
+		AnnotationSourceLocation: fmt.Sprintf("url:https://%s/%s/tree/%s/", host, path, theRepository.Mainline),
+	}
+	if host == "github.com" {
+		result[AnnotationProjectSlug] = path
+	}
+	return result
+}
+
+// parseRepositoryUrl gives the host and the path without .git of ssh, scp style and http(s) git urls.
+func parseRepositoryUrl(repoUrl string) (string, string, bool) {
+	if !strings.Contains(repoUrl, "://") {
+		// scp style, git@github.com:some-org/some-repo.git
+		userHost, path, found := strings.Cut(repoUrl, ":")
+		if !found {
+			return "", "", false
+		}
+		repoUrl = "ssh://" + userHost + "/" + path
+	}
+	parsed, err := url.Parse(repoUrl)
+	if err != nil || parsed.Hostname() == "" {
+		return "", "", false
+	}
+	path := strings.TrimSuffix(strings.Trim(parsed.Path, "/"), ".git")
+	if path == "" {
+		return "", "", false
+	}
+	return parsed.Hostname(), path, true
+}
+
+// --- helpers
+
+func links(ownerLinks []openapi.Link) []types.BackstageLink {
+	var result []types.BackstageLink
+	for _, link := range ownerLinks {
+		if link.Url != nil && *link.Url != "" {
+			result = append(result, types.BackstageLink{Url: *link.Url, Title: deref(link.Title)})
+		}
+	}
+	return result
+}
+
+func lifecycle(theService openapi.ServiceDto) string {
+	if theService.Lifecycle == nil || *theService.Lifecycle == "" {
+		return lifecycleUnknown
+	}
+	return *theService.Lifecycle
+}
+
+func groupRef(ownerAlias string) string {
+	return "group:" + ownerAlias
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
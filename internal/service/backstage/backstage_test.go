@@ -0,0 +1,148 @@
+package backstage
+
+import (
+	"context"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/go-backend-service-common/repository/logging"
+	"github.com/Interhyp/go-backend-service-common/repository/timestamp"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/repository/cache"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func ptr(in string) *string {
+	return &in
+}
+
+func tstInstance(t *testing.T) *Impl {
+	ctx := context.Background()
+	theLogging := logging.New().(*logging.LoggingImpl)
+	theCache := cache.NewInMemory(nil, nil, theLogging, timestamp.NewNoAcorn(time.Now))
+	require.Nil(t, theCache.PutOwner(ctx, "unicorns", openapi.OwnerDto{
+		Contact:     "unicorns@some-organisation.com",
+		DisplayName: ptr("The Unicorn Squad"),
+		Members:     []string{"ulli"},
+	}))
+	require.Nil(t, theCache.PutOwner(ctx, "dragons", openapi.OwnerDto{Contact: "Dragon Riders"}))
+	require.Nil(t, theCache.PutService(ctx, "unicorn-finder", openapi.ServiceDto{
+		Owner:        "unicorns",
+		Description:  ptr("Finds unicorns in the wild"),
+		Quicklinks:   []openapi.Quicklink{{Url: ptr("https://unicorns.example.com"), Title: ptr("Home")}},
+		Repositories: []string{"unicorn-finder.helm-deployment", "unicorn-finder.implementation"},
+		Lifecycle:    ptr("operational"),
+		Spec: &openapi.ServiceSpecDto{
+			System:       ptr("fairytale"),
+			DependsOn:    []string{"dragon-feeder"},
+			ProvidesApis: []string{"unicorn-api"},
+			ConsumesApis: []string{"feeding-api"},
+		},
+	}))
+	require.Nil(t, theCache.PutService(ctx, "dragon-feeder", openapi.ServiceDto{
+		Owner:        "dragons",
+		Repositories: []string{"dragon-feeder.implementation"},
+		Spec:         &openapi.ServiceSpecDto{ProvidesApis: []string{"feeding-api"}},
+	}))
+	require.Nil(t, theCache.PutRepository(ctx, "unicorn-finder.implementation", openapi.RepositoryDto{
+		Owner:    "unicorns",
+		Url:      "ssh://git@github.com/some-org/unicorn-finder.git",
+		Mainline: "main",
+	}))
+	require.Nil(t, theCache.PutRepository(ctx, "dragon-feeder.implementation", openapi.RepositoryDto{
+		Owner:    "dragons",
+		Url:      "git@git.some-organisation.com:dragons/dragon-feeder.git",
+		Mainline: "master",
+	}))
+	return New(nil, theLogging, timestamp.NewNoAcorn(time.Now), theCache).(*Impl)
+}
+
+func tstKindsAndNames(entities []types.BackstageEntity) []string {
+	result := make([]string, 0)
+	for _, entity := range entities {
+		result = append(result, entity.Kind+":"+entity.Metadata.Name)
+	}
+	return result
+}
+
+func TestGetEntities_All(t *testing.T) {
+	docs.Description("owners become groups, services components and provided apis api entities")
+
+	entities, err := tstInstance(t).GetEntities(context.Background(), "")
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		"Group:dragons",
+		"Group:unicorns",
+		"Component:dragon-feeder",
+		"Component:unicorn-finder",
+		"API:feeding-api",
+		"API:unicorn-api",
+	}, tstKindsAndNames(entities))
+
+	require.Equal(t, types.BackstageGroupSpec{
+		Type:     "team",
+		Profile:  &types.BackstageGroupProfile{DisplayName: "The Unicorn Squad", Email: "unicorns@some-organisation.com"},
+		Children: []string{},
+		Members:  []string{"ulli"},
+	}, entities[1].Spec)
+	require.Nil(t, entities[0].Spec.(types.BackstageGroupSpec).Profile, "contacts that are no email address are left out")
+
+	require.Equal(t, types.BackstageMetadata{
+		Name:        "unicorn-finder",
+		Description: "Finds unicorns in the wild",
+		Annotations: map[string]string{
+			AnnotationSourceLocation: "url:https://github.com/some-org/unicorn-finder/tree/main/",
+			AnnotationProjectSlug:    "some-org/unicorn-finder",
+		},
+		Links: []types.BackstageLink{{Url: "https://unicorns.example.com", Title: "Home"}},
+	}, entities[3].Metadata)
+	require.Equal(t, types.BackstageComponentSpec{
+		Type:         "service",
+		Lifecycle:    "operational",
+		Owner:        "group:unicorns",
+		System:       "fairytale",
+		DependsOn:    []string{"component:dragon-feeder"},
+		ProvidesApis: []string{"unicorn-api"},
+		ConsumesApis: []string{"feeding-api"},
+	}, entities[3].Spec)
+
+	require.Equal(t, "url:https://git.some-organisation.com/dragons/dragon-feeder/tree/master/", entities[2].Metadata.Annotations[AnnotationSourceLocation])
+	require.Equal(t, "unknown", entities[2].Spec.(types.BackstageComponentSpec).Lifecycle)
+
+	require.Equal(t, "group:dragons", entities[4].Spec.(types.BackstageApiSpec).Owner)
+}
+
+func TestGetEntities_Owner(t *testing.T) {
+	docs.Description("the export can be limited to the entities of one owner")
+
+	entities, err := tstInstance(t).GetEntities(context.Background(), "dragons")
+	require.Nil(t, err)
+	require.Equal(t, []string{"Group:dragons", "Component:dragon-feeder", "API:feeding-api"}, tstKindsAndNames(entities))
+}
+
+func TestParseRepositoryUrl(t *testing.T) {
+	docs.Description("repository urls in ssh, scp and https style are understood")
+
+	for _, repoUrl := range []string{
+		"ssh://git@github.com/some-org/some-repo.git",
+		"git@github.com:some-org/some-repo.git",
+		"https://github.com/some-org/some-repo",
+	} {
+		host, path, ok := parseRepositoryUrl(repoUrl)
+		require.True(t, ok, repoUrl)
+		require.Equal(t, "github.com", host, repoUrl)
+		require.Equal(t, "some-org/some-repo", path, repoUrl)
+	}
+
+	_, _, ok := parseRepositoryUrl("not a url")
+	require.False(t, ok)
+}
+
+func TestGetEntities_UnknownOwner(t *testing.T) {
+	docs.Description("the export for an unknown owner fails with not found")
+
+	_, err := tstInstance(t).GetEntities(context.Background(), "griffins")
+	require.True(t, apierrors.IsNotFoundError(err))
+}
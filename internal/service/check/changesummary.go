@@ -0,0 +1,344 @@
+package check
+
+import (
+	"fmt"
+	"github.com/Interhyp/metadata-service/api"
+	serviceutil "github.com/Interhyp/metadata-service/internal/service/util"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const changeSummaryTitle = "## Summary of changes"
+
+// metadataSnapshot is what the files in the owners directory describe at one commit.
+type metadataSnapshot struct {
+	owners       map[string]ownedFile[openapi.OwnerDto]
+	services     map[string]ownedFile[openapi.ServiceDto]
+	repositories map[string]ownedFile[openapi.RepositoryDto]
+}
+
+// ownedFile is the contents of a file below an owner. Files that cannot be parsed are still there, but their
+// contents are not compared, the validation reports them.
+type ownedFile[T any] struct {
+	owner    string
+	parsed   bool
+	contents T
+}
+
+type changeLine struct {
+	text  string
+	risky bool
+}
+
+func readMetadataSnapshot(filesys billy.Filesystem) (metadataSnapshot, error) {
+	snapshot := metadataSnapshot{
+		owners:       make(map[string]ownedFile[openapi.OwnerDto]),
+		services:     make(map[string]ownedFile[openapi.ServiceDto]),
+		repositories: make(map[string]ownedFile[openapi.RepositoryDto]),
+	}
+	err := util.Walk(filesys, "/", func(path string, info fs.FileInfo, err error) error {
+		// errors are recorded by the validation walk
+		if err != nil || info.IsDir() || !strings.HasSuffix(info.Name(), ".yaml") {
+			return nil
+		}
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		if parts[0] != "owners" {
+			return nil
+		}
+		contents, err := util.ReadFile(filesys, path)
+		if err != nil {
+			return nil
+		}
+		if len(parts) == 3 && parts[2] == "owner.info.yaml" {
+			snapshot.owners[parts[1]] = parseOwnedFile[openapi.OwnerDto](parts[1], contents)
+		} else if len(parts) == 4 && parts[2] == "services" {
+			snapshot.services[strings.TrimSuffix(parts[3], ".yaml")] = parseOwnedFile[openapi.ServiceDto](parts[1], contents)
+		} else if len(parts) == 4 && parts[2] == "repositories" {
+			snapshot.repositories[strings.TrimSuffix(parts[3], ".yaml")] = parseOwnedFile[openapi.RepositoryDto](parts[1], contents)
+		}
+		return nil
+	})
+	return snapshot, err
+}
+
+func parseOwnedFile[T any](owner string, contents []byte) ownedFile[T] {
+	result := ownedFile[T]{owner: owner}
+	result.parsed = yaml.Unmarshal(contents, &result.contents) == nil
+	return result
+}
+
+// expand replaces group references by the members of the group, as far as the owners of the snapshot know them.
+func (s metadataSnapshot) expand(userList []string) []string {
+	result := make([]string, 0)
+	for _, user := range userList {
+		if isGroup, groupOwner, groupName := serviceutil.ParseGroupOwnerAndGroupName(user); isGroup {
+			if owner, ok := s.owners[groupOwner]; ok && owner.parsed {
+				if members, ok := owner.contents.Groups[groupName]; ok {
+					result = append(result, members...)
+					continue
+				}
+			}
+		}
+		result = append(result, user)
+	}
+	return serviceutil.RemoveDuplicateStr(result)
+}
+
+// summarizeChanges describes the differences between two snapshots for the reviewers of a change, in markdown.
+func summarizeChanges(before metadataSnapshot, after metadataSnapshot) string {
+	sections := []struct {
+		title string
+		lines []changeLine
+	}{
+		{title: "Owners", lines: ownerChanges(before.owners, after.owners)},
+		{title: "Services", lines: ownedFileChanges("service", before.services, after.services)},
+		{title: "Repositories", lines: ownedFileChanges("repository", before.repositories, after.repositories)},
+		{title: "Approvers and watchers", lines: userChanges(before, after)},
+		{title: "Ref protections and required conditions", lines: protectionChanges(before, after)},
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(changeSummaryTitle + "\n\n")
+	riskyCount := 0
+	changeCount := 0
+	for _, section := range sections {
+		for _, line := range section.lines {
+			changeCount++
+			if line.risky {
+				riskyCount++
+			}
+		}
+	}
+	if changeCount == 0 {
+		sb.WriteString("There are no changes to owners, services, repositories, approvers, watchers, ref protections or required conditions.\n")
+		return sb.String()
+	}
+	if riskyCount > 0 {
+		sb.WriteString(fmt.Sprintf(":warning: **%d of these changes are risky, they are marked below.**\n\n", riskyCount))
+	}
+	for _, section := range sections {
+		if len(section.lines) == 0 {
+			continue
+		}
+		sb.WriteString("### " + section.title + "\n\n")
+		for _, line := range section.lines {
+			if line.risky {
+				sb.WriteString("- :warning: **" + line.text + "**\n")
+			} else {
+				sb.WriteString("- " + line.text + "\n")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func ownerChanges(before map[string]ownedFile[openapi.OwnerDto], after map[string]ownedFile[openapi.OwnerDto]) []changeLine {
+	lines := make([]changeLine, 0)
+	for _, alias := range sortedKeys(before, after) {
+		_, inBefore := before[alias]
+		_, inAfter := after[alias]
+		if !inBefore {
+			lines = append(lines, changeLine{text: fmt.Sprintf("added owner `%s`", alias)})
+		} else if !inAfter {
+			lines = append(lines, changeLine{text: fmt.Sprintf("removed owner `%s`", alias)})
+		}
+	}
+	return lines
+}
+
+func ownedFileChanges[T any](kind string, before map[string]ownedFile[T], after map[string]ownedFile[T]) []changeLine {
+	lines := make([]changeLine, 0)
+	for _, name := range sortedKeys(before, after) {
+		beforeFile, inBefore := before[name]
+		afterFile, inAfter := after[name]
+		if !inBefore {
+			lines = append(lines, changeLine{text: fmt.Sprintf("added %s `%s` to owner `%s`", kind, name, afterFile.owner)})
+		} else if !inAfter {
+			lines = append(lines, changeLine{text: fmt.Sprintf("removed %s `%s` from owner `%s`", kind, name, beforeFile.owner)})
+		} else if beforeFile.owner != afterFile.owner {
+			lines = append(lines, changeLine{text: fmt.Sprintf("moved %s `%s` from owner `%s` to owner `%s`", kind, name, beforeFile.owner, afterFile.owner)})
+		}
+	}
+	return lines
+}
+
+// userChanges compares the approvers and watchers of the repositories that exist before and after, with the
+// groups of either side expanded, so changes to group members show up for every repository that uses the group.
+func userChanges(before metadataSnapshot, after metadataSnapshot) []changeLine {
+	lines := make([]changeLine, 0)
+	for _, key := range sortedKeys(before.repositories, after.repositories) {
+		beforeConfig, afterConfig, comparable := repositoryConfigurations(before.repositories[key], after.repositories[key])
+		if !comparable {
+			continue
+		}
+		for _, name := range sortedKeys(beforeConfig.Approvers, afterConfig.Approvers) {
+			if change := userListChange(before.expand(beforeConfig.Approvers[name]), after.expand(afterConfig.Approvers[name])); change != "" {
+				lines = append(lines, changeLine{text: fmt.Sprintf("`%s` approvers `%s`: %s", key, name, change)})
+			}
+		}
+		if change := userListChange(before.expand(beforeConfig.Watchers), after.expand(afterConfig.Watchers)); change != "" {
+			lines = append(lines, changeLine{text: fmt.Sprintf("`%s` watchers: %s", key, change)})
+		}
+	}
+	return lines
+}
+
+// protectionChanges compares the ref protections and required conditions of the repositories that exist before
+// and after. Removing a protection or a condition, and adding exemptions to one, is risky.
+func protectionChanges(before metadataSnapshot, after metadataSnapshot) []changeLine {
+	lines := make([]changeLine, 0)
+	for _, key := range sortedKeys(before.repositories, after.repositories) {
+		beforeConfig, afterConfig, comparable := repositoryConfigurations(before.repositories[key], after.repositories[key])
+		if !comparable {
+			continue
+		}
+
+		beforeRefs := protectedRefsByKind(beforeConfig.RefProtections)
+		afterRefs := protectedRefsByKind(afterConfig.RefProtections)
+		for _, kind := range sortedKeys(beforeRefs, afterRefs) {
+			for _, pattern := range sortedKeys(beforeRefs[kind], afterRefs[kind]) {
+				beforeRef, inBefore := beforeRefs[kind][pattern]
+				afterRef, inAfter := afterRefs[kind][pattern]
+				protection := fmt.Sprintf("`%s` protection of `%s`", kind, pattern)
+				if !inBefore {
+					lines = append(lines, changeLine{text: fmt.Sprintf("`%s`: added %s", key, protection)})
+				} else if !inAfter {
+					lines = append(lines, changeLine{text: fmt.Sprintf("`%s`: removed %s", key, protection), risky: true})
+				} else {
+					lines = append(lines, exemptionChanges(key, protection, before.expand(beforeRef.Exemptions), after.expand(afterRef.Exemptions))...)
+				}
+			}
+		}
+
+		for _, name := range sortedKeys(beforeConfig.RequireConditions, afterConfig.RequireConditions) {
+			beforeCondition, inBefore := beforeConfig.RequireConditions[name]
+			afterCondition, inAfter := afterConfig.RequireConditions[name]
+			condition := fmt.Sprintf("required condition `%s`", name)
+			if !inBefore {
+				lines = append(lines, changeLine{text: fmt.Sprintf("`%s`: added %s for `%s`", key, condition, afterCondition.RefMatcher)})
+			} else if !inAfter {
+				lines = append(lines, changeLine{text: fmt.Sprintf("`%s`: removed %s for `%s`", key, condition, beforeCondition.RefMatcher), risky: true})
+			} else {
+				if beforeCondition.RefMatcher != afterCondition.RefMatcher {
+					lines = append(lines, changeLine{text: fmt.Sprintf("`%s`: %s now applies to `%s` instead of `%s`", key, condition, afterCondition.RefMatcher, beforeCondition.RefMatcher)})
+				}
+				lines = append(lines, exemptionChanges(key, condition, before.expand(beforeCondition.Exemptions), after.expand(afterCondition.Exemptions))...)
+			}
+		}
+	}
+	return lines
+}
+
+func exemptionChanges(key string, subject string, before []string, after []string) []changeLine {
+	lines := make([]changeLine, 0)
+	added, removed := addedAndRemoved(before, after)
+	if len(added) > 0 {
+		lines = append(lines, changeLine{text: fmt.Sprintf("`%s`: %s exempts %s now", key, subject, codeList(added)), risky: true})
+	}
+	if len(removed) > 0 {
+		lines = append(lines, changeLine{text: fmt.Sprintf("`%s`: %s no longer exempts %s", key, subject, codeList(removed))})
+	}
+	return lines
+}
+
+// repositoryConfigurations gives the configurations of a repository before and after, if it exists on both sides
+// and both files could be parsed.
+func repositoryConfigurations(before ownedFile[openapi.RepositoryDto], after ownedFile[openapi.RepositoryDto]) (openapi.RepositoryConfigurationDto, openapi.RepositoryConfigurationDto, bool) {
+	if !before.parsed || !after.parsed {
+		return openapi.RepositoryConfigurationDto{}, openapi.RepositoryConfigurationDto{}, false
+	}
+	beforeConfig := openapi.RepositoryConfigurationDto{}
+	if before.contents.Configuration != nil {
+		beforeConfig = *before.contents.Configuration
+	}
+	afterConfig := openapi.RepositoryConfigurationDto{}
+	if after.contents.Configuration != nil {
+		afterConfig = *after.contents.Configuration
+	}
+	return beforeConfig, afterConfig, true
+}
+
+// protectedRefsByKind maps the kind of protection, such as branches.requirePR, to the protected refs by pattern.
+func protectedRefsByKind(protections *openapi.RefProtections) map[string]map[string]openapi.ProtectedRef {
+	result := make(map[string]map[string]openapi.ProtectedRef)
+	if protections == nil {
+		return result
+	}
+	add := func(kind string, protectedRefs []openapi.ProtectedRef) {
+		for _, protectedRef := range protectedRefs {
+			if result[kind] == nil {
+				result[kind] = make(map[string]openapi.ProtectedRef)
+			}
+			result[kind][protectedRef.Pattern] = protectedRef
+		}
+	}
+	if branches := protections.Branches; branches != nil {
+		add("branches.requirePR", branches.RequirePR)
+		add("branches.preventAllChanges", branches.PreventAllChanges)
+		add("branches.preventCreation", branches.PreventCreation)
+		add("branches.preventDeletion", branches.PreventDeletion)
+		add("branches.preventPush", branches.PreventPush)
+		add("branches.preventForcePush", branches.PreventForcePush)
+	}
+	if tags := protections.Tags; tags != nil {
+		add("tags.preventAllChanges", tags.PreventAllChanges)
+		add("tags.preventCreation", tags.PreventCreation)
+		add("tags.preventDeletion", tags.PreventDeletion)
+		add("tags.preventForcePush", tags.PreventForcePush)
+	}
+	return result
+}
+
+func userListChange(before []string, after []string) string {
+	added, removed := addedAndRemoved(before, after)
+	parts := make([]string, 0, 2)
+	if len(added) > 0 {
+		parts = append(parts, "added "+codeList(added))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, "removed "+codeList(removed))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func addedAndRemoved(before []string, after []string) ([]string, []string) {
+	added := make([]string, 0)
+	for _, entry := range after {
+		if !slices.Contains(before, entry) {
+			added = append(added, entry)
+		}
+	}
+	removed := make([]string, 0)
+	for _, entry := range before {
+		if !slices.Contains(after, entry) {
+			removed = append(removed, entry)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func codeList(entries []string) string {
+	return "`" + strings.Join(entries, "`, `") + "`"
+}
+
+func sortedKeys[V any](before map[string]V, after map[string]V) []string {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
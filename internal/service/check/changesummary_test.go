@@ -0,0 +1,128 @@
+package check
+
+import (
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSummarizeChanges(t *testing.T) {
+	before := tstSnapshot(t, map[string]string{
+		"owners/a/owner.info.yaml": `contact: a@mail.com
+groups:
+  reviewers:
+    - userA
+    - userB
+`,
+		"owners/b/owner.info.yaml":     `contact: b@mail.com`,
+		"owners/a/services/moved.yaml": `alertTarget: a@mail.com`,
+		"owners/a/services/gone.yaml":  `alertTarget: a@mail.com`,
+		"owners/a/repositories/some.implementation.yaml": `url: ssh://some-url/some.git
+mainline: main
+configuration:
+  approvers:
+    testing:
+      - '@a.reviewers'
+  watchers:
+    - watcherA
+  refProtections:
+    branches:
+      requirePR:
+        - pattern: ':MAINLINE:'
+      preventDeletion:
+        - pattern: 'release/.*'
+          exemptions:
+            - userA
+  requireConditions:
+    sonar:
+      refMatcher: ':MAINLINE:'
+    build:
+      refMatcher: ':MAINLINE:'
+`,
+		"owners/a/repositories/gone.api.yaml": `url: ssh://some-url/gone.git
+mainline: main
+`,
+	})
+	after := tstSnapshot(t, map[string]string{
+		"owners/a/owner.info.yaml": `contact: a@mail.com
+groups:
+  reviewers:
+    - userA
+    - userC
+`,
+		"owners/b/owner.info.yaml":     `contact: b@mail.com`,
+		"owners/c/owner.info.yaml":     `contact: c@mail.com`,
+		"owners/b/services/moved.yaml": `alertTarget: a@mail.com`,
+		"owners/c/services/new.yaml":   `alertTarget: c@mail.com`,
+		"owners/a/repositories/some.implementation.yaml": `url: ssh://some-url/some.git
+mainline: main
+configuration:
+  approvers:
+    testing:
+      - '@a.reviewers'
+  watchers:
+    - watcherA
+    - watcherB
+  refProtections:
+    branches:
+      preventDeletion:
+        - pattern: 'release/.*'
+          exemptions:
+            - userB
+  requireConditions:
+    build:
+      refMatcher: 'release/.*'
+    audit:
+      refMatcher: ':MAINLINE:'
+`,
+		"owners/c/repositories/new.api.yaml": `url: ssh://some-url/new.git
+mainline: main
+`,
+	})
+
+	expected := "## Summary of changes\n\n" +
+		":warning: **3 of these changes are risky, they are marked below.**\n\n" +
+		"### Owners\n\n" +
+		"- added owner `c`\n\n" +
+		"### Services\n\n" +
+		"- removed service `gone` from owner `a`\n" +
+		"- moved service `moved` from owner `a` to owner `b`\n" +
+		"- added service `new` to owner `c`\n\n" +
+		"### Repositories\n\n" +
+		"- removed repository `gone.api` from owner `a`\n" +
+		"- added repository `new.api` to owner `c`\n\n" +
+		"### Approvers and watchers\n\n" +
+		"- `some.implementation` approvers `testing`: added `userC`, removed `userB`\n" +
+		"- `some.implementation` watchers: added `watcherB`\n\n" +
+		"### Ref protections and required conditions\n\n" +
+		"- :warning: **`some.implementation`: `branches.preventDeletion` protection of `release/.*` exempts `userB` now**\n" +
+		"- `some.implementation`: `branches.preventDeletion` protection of `release/.*` no longer exempts `userA`\n" +
+		"- :warning: **`some.implementation`: removed `branches.requirePR` protection of `:MAINLINE:`**\n" +
+		"- `some.implementation`: added required condition `audit` for `:MAINLINE:`\n" +
+		"- `some.implementation`: required condition `build` now applies to `release/.*` instead of `:MAINLINE:`\n" +
+		"- :warning: **`some.implementation`: removed required condition `sonar` for `:MAINLINE:`**\n"
+	require.Equal(t, expected, summarizeChanges(before, after))
+}
+
+func TestSummarizeChanges_Unchanged(t *testing.T) {
+	files := map[string]string{
+		"owners/a/owner.info.yaml":     `contact: a@mail.com`,
+		"owners/a/services/some.yaml":  `alertTarget: a@mail.com`,
+		"owners/a/repositories/x.yaml": `broken: [`,
+	}
+
+	require.Equal(t, "## Summary of changes\n\nThere are no changes to owners, services, repositories, approvers, watchers, ref protections or required conditions.\n",
+		summarizeChanges(tstSnapshot(t, files), tstSnapshot(t, files)))
+}
+
+func tstSnapshot(t *testing.T, files map[string]string) metadataSnapshot {
+	var filesys billy.Filesystem = memfs.New()
+	for path, contents := range files {
+		require.Nil(t, util.WriteFile(filesys, path, []byte(contents), 0644))
+	}
+	snapshot, err := readMetadataSnapshot(filesys)
+	require.Nil(t, err)
+	return snapshot
+}
@@ -103,7 +103,10 @@ func (h *Impl) IsValidator() bool {
 	return true
 }
 
-func (h *Impl) PerformValidationCheckRun(ctx context.Context, owner, repo, sha string) error {
+// PerformValidationCheckRun validates the metadata at sha. With a pull request, the per-file rules only run on the
+// files it changes, findings that already existed are reported without failing the check run, and the details
+// summarize the changes for the reviewers.
+func (h *Impl) PerformValidationCheckRun(ctx context.Context, owner, repo string, pullRequest *github.PullRequest, sha string) error {
 	aulogging.Logger.Ctx(ctx).Info().Printf("received webhook for %s/%s @ %s", owner, repo, sha)
 	independentCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ValidationTimeout)
 	defer cancel()
@@ -116,7 +119,7 @@ func (h *Impl) PerformValidationCheckRun(ctx context.Context, owner, repo, sha s
 	}
 
 	aulogging.Logger.Ctx(ctx).Debug().Printf("starting validation of %s/%s @ %s", owner, repo, sha)
-	result := h.validate(independentCtx, sha)
+	result := h.validate(independentCtx, owner, repo, pullRequest, sha)
 	aulogging.Logger.Ctx(ctx).Debug().Printf("finished validation of %s/%s @ %s", owner, repo, sha)
 
 	h.concludeCheckRunSafely(independentCtx, checkId, result.conclusion, result.output, result.actions)
@@ -125,20 +128,77 @@ func (h *Impl) PerformValidationCheckRun(ctx context.Context, owner, repo, sha s
 	return nil
 }
 
-func (h *Impl) validate(ctx context.Context, sha string) CheckResult {
+func (h *Impl) validate(ctx context.Context, owner, repo string, pullRequest *github.PullRequest, sha string) CheckResult {
 	fileSys, err := h.CheckoutFunction(ctx, h.AuthProvider, h.CustomConfiguration.MetadataRepoUrl(), sha)
 	if err != nil {
 		return checkRunErrorResult(ctx, "Failed to checkout service-metadata repository.", err)
 	}
 
-	result, err := h.validateFiles(ctx, fileSys)
+	changed, err := h.changedFiles(ctx, owner, repo, pullRequest, sha)
+	var changedFiles []string
+	if changed != nil {
+		changedFiles = changed.Files
+	}
+	result, validateErr := h.validateFiles(ctx, fileSys, changedFiles)
+	if validateErr != nil {
+		return checkRunErrorResult(ctx, "Failed to validate files.", validateErr)
+	}
 	if err != nil {
-		return checkRunErrorResult(ctx, "Failed to validate files.", err)
+		// without this note, findings in files the pull request does not touch would fail it for no apparent reason
+		result.output.Summary = github.Ptr(result.output.GetSummary() + "\n" + fmt.Sprintf("Could not determine the files changed by this pull request, so all files were validated: %s", err.Error()))
+	}
+
+	// like the changed files, the summary starts from the merge base, so changes on the base branch do not show up
+	if changed != nil && changed.MergeBaseSHA != "" {
+		if summary := h.changeSummary(ctx, changed.MergeBaseSHA, fileSys); summary != "" {
+			text := summary
+			if result.output.Text != nil {
+				text = *result.output.Text + "\n" + summary
+			}
+			result.output.Text = github.Ptr(text)
+		}
 	}
 
 	return result
 }
 
+// changeSummary describes what changed since baseSha, or gives "" if the base cannot be read.
+func (h *Impl) changeSummary(ctx context.Context, baseSha string, fileSys billy.Filesystem) string {
+	baseFileSys, err := h.CheckoutFunction(ctx, h.AuthProvider, h.CustomConfiguration.MetadataRepoUrl(), baseSha)
+	if err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Printf("failed to checkout base %s, leaving out the summary of changes", baseSha)
+		return ""
+	}
+	before, err := readMetadataSnapshot(baseFileSys)
+	if err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Printf("failed to read base %s, leaving out the summary of changes", baseSha)
+		return ""
+	}
+	after, err := readMetadataSnapshot(fileSys)
+	if err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Printf("failed to read the changed files, leaving out the summary of changes")
+		return ""
+	}
+	return summarizeChanges(before, after)
+}
+
+// changedFiles compares the pull request up to sha, nil means that all files are validated.
+//
+// An error means the pull request could not be compared, and all files are validated anyway.
+func (h *Impl) changedFiles(ctx context.Context, owner, repo string, pullRequest *github.PullRequest, sha string) (*repository.ChangedFiles, error) {
+	baseSha := pullRequest.GetBase().GetSHA()
+	if baseSha == "" {
+		return nil, nil
+	}
+	changed, err := h.Github.GetChangedFiles(ctx, owner, repo, pullRequest.GetNumber(), baseSha, sha)
+	if err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Printf("failed to get the files changed in %s/%s between %s and %s, validating all files", owner, repo, baseSha, sha)
+		return nil, err
+	}
+	aulogging.Logger.Ctx(ctx).Debug().Printf("%d files changed in %s/%s between %s and %s", len(changed.Files), owner, repo, changed.MergeBaseSHA, sha)
+	return &changed, nil
+}
+
 func checkRunErrorResult(ctx context.Context, summary string, err error) CheckResult {
 	aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Printf(summary)
 	return CheckResult{
@@ -152,13 +212,17 @@ func checkRunErrorResult(ctx context.Context, summary string, err error) CheckRe
 	}
 }
 
-func (h *Impl) validateFiles(ctx context.Context, fs billy.Filesystem) (CheckResult, error) {
-	johnnie := MetadataYamlFileWalker(fs,
+func (h *Impl) validateFiles(ctx context.Context, fs billy.Filesystem, changedFiles []string) (CheckResult, error) {
+	options := []Option{
 		WithIndentation(h.CustomConfiguration.YamlIndentation()),
 		WithExpectedRequiredConditions(h.CustomConfiguration.CheckExpectedRequiredConditions()),
 		WithExpectedExemptions(h.CustomConfiguration.CheckedExpectedExemptions()),
 		WithMainlinePrProtection(h.CustomConfiguration.CheckWarnMissingMainlineProtection()),
-	)
+	}
+	if changedFiles != nil {
+		options = append(options, WithChangedFiles(changedFiles))
+	}
+	johnnie := MetadataYamlFileWalker(fs, options...)
 	err := johnnie.ValidateMetadata()
 	if err != nil {
 		return CheckResult{}, err
@@ -179,7 +243,7 @@ func walkerToCheckRunOutput(johnnie *MetadataWalker) CheckResult {
 	title := SuccessValidationTitle
 	summary := "All changed files are valid."
 	var details *string
-	if hasFailureAnnotations(johnnie) {
+	if hasFailureAnnotations(johnnie.Annotations) {
 		result.conclusion = repository.CheckRunFailure
 		summary = "There were files failing the validation. See Annotations."
 		title = FailedValidationTitle
@@ -195,6 +259,18 @@ func walkerToCheckRunOutput(johnnie *MetadataWalker) CheckResult {
 		}
 		details = github.Ptr(fmt.Sprintf("The following validation errors occurred:\n%s", errorsToMarkdownList(johnnie.Errors)))
 	}
+	if len(johnnie.PreExisting) > 0 {
+		// these must not block the change, but should not go unnoticed either
+		if result.conclusion == repository.CheckRunSuccess && hasFailureAnnotations(johnnie.PreExisting) {
+			result.conclusion = repository.CheckRunNeutral
+		}
+		summary += "\n" + fmt.Sprintf("Files not touched by this change already had findings (%d). See Details, they do not fail this check.", len(johnnie.PreExisting))
+		preExisting := fmt.Sprintf("The following findings already existed before this change:\n%s", annotationsToMarkdownList(johnnie.PreExisting))
+		if details != nil {
+			preExisting = *details + "\n" + preExisting
+		}
+		details = github.Ptr(preExisting)
+	}
 
 	result.output = github.CheckRunOutput{
 		Title:       github.Ptr(title),
@@ -221,8 +297,8 @@ func walkerToCheckRunOutput(johnnie *MetadataWalker) CheckResult {
 	return result
 }
 
-func hasFailureAnnotations(johnnie *MetadataWalker) bool {
-	for _, annotation := range johnnie.Annotations {
+func hasFailureAnnotations(annotations []*github.CheckRunAnnotation) bool {
+	for _, annotation := range annotations {
 		if annotation.AnnotationLevel != nil && *annotation.AnnotationLevel == "failure" {
 			return true
 		}
@@ -243,6 +319,14 @@ func errorsToMarkdownList(errors map[string]error) string {
 	return sb.String()
 }
 
+func annotationsToMarkdownList(annotations []*github.CheckRunAnnotation) string {
+	sb := strings.Builder{}
+	for _, annotation := range annotations {
+		sb.WriteString(fmt.Sprintf("- %s:%d (%s): %s\n", annotation.GetPath(), annotation.GetStartLine(), annotation.GetAnnotationLevel(), annotation.GetMessage()))
+	}
+	return sb.String()
+}
+
 func (h *Impl) concludeCheckRunSafely(
 	ctx context.Context,
 	checkRunId int64,
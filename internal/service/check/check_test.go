@@ -2,6 +2,7 @@ package check
 
 import (
 	"fmt"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
 	"github.com/google/go-github/v70/github"
 	"reflect"
 	"testing"
@@ -114,6 +115,50 @@ func Test_walkerToCheckRunOutput(t *testing.T) {
 	}
 }
 
+func Test_walkerToCheckRunOutput_PreExisting(t *testing.T) {
+	preExisting := []*github.CheckRunAnnotation{
+		{
+			Path:            github.Ptr("some/path/to/an/unchanged/file.yaml"),
+			StartLine:       github.Ptr(3),
+			EndLine:         github.Ptr(3),
+			AnnotationLevel: github.Ptr("failure"),
+			Message:         github.Ptr("test message"),
+		},
+	}
+	johnnie := &MetadataWalker{
+		Annotations: make([]*github.CheckRunAnnotation, 0),
+		PreExisting: preExisting,
+		Errors:      make(map[string]error),
+	}
+
+	got := walkerToCheckRunOutput(johnnie)
+	if got.conclusion != repository.CheckRunNeutral {
+		t.Errorf("walkerToCheckRunOutput() conclusion = %s, want %s", got.conclusion, repository.CheckRunNeutral)
+	}
+	want := github.CheckRunOutput{
+		Title:       github.Ptr("Passed YAML validation"),
+		Summary:     github.Ptr("All changed files are valid.\nFiles not touched by this change already had findings (1). See Details, they do not fail this check."),
+		Text:        github.Ptr("The following findings already existed before this change:\n- some/path/to/an/unchanged/file.yaml:3 (failure): test message\n"),
+		Annotations: make([]*github.CheckRunAnnotation, 0),
+	}
+	if !reflect.DeepEqual(got.output, want) {
+		t.Errorf("walkerToCheckRunOutput() = %+v, want %+v", printOutput(got.output), printOutput(want))
+	}
+
+	johnnie.Annotations = []*github.CheckRunAnnotation{
+		{
+			Path:            github.Ptr("some/path/to/a/changed/file.yaml"),
+			StartLine:       github.Ptr(1),
+			EndLine:         github.Ptr(1),
+			AnnotationLevel: github.Ptr("failure"),
+			Message:         github.Ptr("test message"),
+		},
+	}
+	if got := walkerToCheckRunOutput(johnnie); got.conclusion != repository.CheckRunFailure {
+		t.Errorf("walkerToCheckRunOutput() conclusion = %s, want %s", got.conclusion, repository.CheckRunFailure)
+	}
+}
+
 func printOutput(in github.CheckRunOutput) string {
 	return fmt.Sprintf("{Title: %s, Summary: %s, Text: %s, Annotations: %v}", ptrStr(in.Title), ptrStr(in.Summary), ptrStr(in.Text), in.Annotations)
 }
@@ -20,9 +20,13 @@ type walkedRepos struct {
 type MetadataWalker struct {
 	fs                                    billy.Filesystem
 	Annotations                           []*github.CheckRunAnnotation
+	PreExisting                           []*github.CheckRunAnnotation
 	Errors                                map[string]error
 	IgnoredWithReason                     map[string]string
 	walkedRepos                           walkedRepos
+	walkedOwners                          map[string]map[string][]string
+	walkedServices                        walkedServices
+	relatedPaths                          map[*github.CheckRunAnnotation][]string
 	fmtEngine                             yamlfmt.Engine
 	hasFormatErrors                       bool
 	hasMissingRequiredConditionExemptions []MissingRequiredConditionExemption
@@ -41,6 +45,7 @@ type Config struct {
 	requireMainlinePrProtection bool
 	expectedRequiredConditions  []config.CheckedRequiredConditions
 	expectedExemptions          []config.CheckedExpectedExemption
+	changedFiles                map[string]bool
 }
 
 type Option = func(config *Config)
@@ -75,6 +80,17 @@ func WithExpectedExemptions(expectedExemptions []config.CheckedExpectedExemption
 	}
 }
 
+// WithChangedFiles limits the per-file rules to the given files. The cross-file rules still run on the whole tree,
+// their findings that do not involve any of the given files are reported in PreExisting.
+func WithChangedFiles(changedFiles []string) Option {
+	return func(config *Config) {
+		config.changedFiles = make(map[string]bool)
+		for _, path := range changedFiles {
+			config.changedFiles[path] = true
+		}
+	}
+}
+
 const lineBreakStyle = yamlfmt.LineBreakStyleLF
 const lineSeparatorCharacter = "\n"
 
@@ -110,14 +126,20 @@ func MetadataYamlFileWalker(filesys billy.Filesystem, options ...Option) *Metada
 	validator := MetadataWalker{
 		fs:                filesys,
 		Annotations:       make([]*github.CheckRunAnnotation, 0),
+		PreExisting:       make([]*github.CheckRunAnnotation, 0),
 		Errors:            make(map[string]error),
 		IgnoredWithReason: make(map[string]string),
 		walkedRepos: walkedRepos{
 			urlToPath: make(map[string]string),
 			keyToPath: make(map[string]string),
 		},
-		fmtEngine: fmtEngine,
-		config:    walkerConf,
+		walkedOwners: make(map[string]map[string][]string),
+		walkedServices: walkedServices{
+			nameToPath: make(map[string]string),
+		},
+		relatedPaths: make(map[*github.CheckRunAnnotation][]string),
+		fmtEngine:    fmtEngine,
+		config:       walkerConf,
 	}
 	return &validator
 }
@@ -0,0 +1,61 @@
+package check
+
+import (
+	"github.com/google/go-github/v70/github"
+	"sort"
+	"strings"
+)
+
+// isChanged tells whether the per-file rules apply to the file. Without a list of changed files, all files count
+// as changed.
+func (v *MetadataWalker) isChanged(path string) bool {
+	return v.config.changedFiles == nil || v.config.changedFiles[path]
+}
+
+// relate records the other files a cross-file finding depends on, so it can be attributed to a change of any of them.
+func (v *MetadataWalker) relate(annotation *github.CheckRunAnnotation, paths ...string) *github.CheckRunAnnotation {
+	if annotation != nil && len(paths) > 0 {
+		v.relatedPaths[annotation] = append(v.relatedPaths[annotation], paths...)
+	}
+	return annotation
+}
+
+// changedFilesEndingWith finds the changed files a reference may have pointed at, including deleted ones.
+func (v *MetadataWalker) changedFilesEndingWith(suffix string) []string {
+	result := make([]string, 0)
+	for path := range v.config.changedFiles {
+		if strings.HasSuffix(path, suffix) {
+			result = append(result, path)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// separatePreExisting moves the findings that are neither in a changed file nor depend on one to PreExisting.
+func (v *MetadataWalker) separatePreExisting() {
+	if v.config.changedFiles == nil {
+		return
+	}
+	introduced := make([]*github.CheckRunAnnotation, 0, len(v.Annotations))
+	for _, annotation := range v.Annotations {
+		if v.involvesChange(annotation) {
+			introduced = append(introduced, annotation)
+		} else {
+			v.PreExisting = append(v.PreExisting, annotation)
+		}
+	}
+	v.Annotations = introduced
+}
+
+func (v *MetadataWalker) involvesChange(annotation *github.CheckRunAnnotation) bool {
+	if v.config.changedFiles[annotation.GetPath()] {
+		return true
+	}
+	for _, path := range v.relatedPaths[annotation] {
+		if v.config.changedFiles[path] {
+			return true
+		}
+	}
+	return false
+}
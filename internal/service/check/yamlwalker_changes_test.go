@@ -0,0 +1,90 @@
+package check
+
+import (
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/google/go-github/v70/github"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMetadataYamlFileWalker_WithChangedFiles(t *testing.T) {
+	filesys := memfs.New()
+	files := map[string]string{
+		"owners/some-owner/owner.info.yaml": `contact: some@mail.com
+unknown: not checked, the file is not changed
+groups:
+  users:
+    - userA
+`,
+		"owners/some-owner/repositories/legacy.implementation.yaml": `url: ssh://some-url/legacy.git
+mainline: master
+configuration:
+  watchers:
+    - '@some-owner.nope'
+`,
+		"owners/some-owner/repositories/new.implementation.yaml": `url: ssh://some-url/legacy.git
+mainline: master
+unknown: checked, the file is changed
+`,
+		"owners/some-owner/services/legacy-service.yaml": `repositories:
+  - never.implementation
+alertTarget: some@mail.com
+`,
+		"owners/some-owner/services/old-service.yaml": `repositories:
+  - gone.implementation
+alertTarget: some@mail.com
+`,
+	}
+	for path, contents := range files {
+		require.Nil(t, util.WriteFile(filesys, path, []byte(contents), 0644))
+	}
+
+	v := MetadataYamlFileWalker(filesys, WithChangedFiles([]string{
+		"owners/some-owner/repositories/new.implementation.yaml",
+		// deleted
+		"owners/some-owner/repositories/gone.implementation.yaml",
+	}))
+	require.Nil(t, v.ValidateMetadata())
+
+	expected := []*github.CheckRunAnnotation{
+		{
+			Path:            github.Ptr("owners/some-owner/repositories/new.implementation.yaml"),
+			StartLine:       github.Ptr(3),
+			EndLine:         github.Ptr(3),
+			AnnotationLevel: github.Ptr("failure"),
+			Message:         github.Ptr("field unknown not found in type openapi.RepositoryDto"),
+		},
+		{
+			Path:            github.Ptr("owners/some-owner/repositories/new.implementation.yaml"),
+			StartLine:       github.Ptr(1),
+			EndLine:         github.Ptr(1),
+			AnnotationLevel: github.Ptr("failure"),
+			Message:         github.Ptr("Repository url already used by owners/some-owner/repositories/legacy.implementation.yaml"),
+		},
+		referenceAnnotation(serviceReference{path: "owners/some-owner/services/old-service.yaml", line: 2}, "failure", "missing repository",
+			"Repository gone.implementation does not exist, there is no file gone.implementation.yaml in any owners/*/repositories/ directory."),
+	}
+	require.Equal(t, printAnnotations(expected), printAnnotations(v.Annotations))
+
+	expectedPreExisting := []*github.CheckRunAnnotation{
+		groupAnnotation("owners/some-owner/repositories/legacy.implementation.yaml", 5, "group reference @some-owner.nope points at group nope, which owner some-owner does not have"),
+		referenceAnnotation(serviceReference{path: "owners/some-owner/services/legacy-service.yaml", line: 2}, "failure", "missing repository",
+			"Repository never.implementation does not exist, there is no file never.implementation.yaml in any owners/*/repositories/ directory."),
+	}
+	require.Equal(t, printAnnotations(expectedPreExisting), printAnnotations(v.PreExisting))
+}
+
+func TestMetadataYamlFileWalker_WithoutChangedFiles(t *testing.T) {
+	filesys := memfs.New()
+	require.Nil(t, util.WriteFile(filesys, "owners/some-owner/services/legacy-service.yaml", []byte(`repositories:
+  - never.implementation
+alertTarget: some@mail.com
+`), 0644))
+
+	v := MetadataYamlFileWalker(filesys)
+	require.Nil(t, v.ValidateMetadata())
+
+	require.Equal(t, 1, len(v.Annotations))
+	require.Equal(t, 0, len(v.PreExisting))
+}
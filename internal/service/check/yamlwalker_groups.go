@@ -0,0 +1,75 @@
+package check
+
+import (
+	"github.com/Interhyp/metadata-service/api"
+	serviceutil "github.com/Interhyp/metadata-service/internal/service/util"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/google/go-github/v70/github"
+	"gopkg.in/yaml.v3"
+	"io/fs"
+	"strings"
+)
+
+// collectOwners records the groups of all owners, so group references can be checked no matter in which order the
+// files are validated.
+func (v *MetadataWalker) collectOwners() error {
+	return util.Walk(v.fs, v.config.rootDir, func(path string, info fs.FileInfo, err error) error {
+		// errors are recorded by the validation walk
+		if err != nil || info.IsDir() || info.Name() != "owner.info.yaml" {
+			return nil
+		}
+		trimmed := strings.Trim(path, "/")
+		ownerAlias, found := strings.CutSuffix(strings.TrimPrefix(trimmed, "owners/"), "/owner.info.yaml")
+		if !found || strings.Contains(ownerAlias, "/") {
+			return nil
+		}
+		contents, err := util.ReadFile(v.fs, path)
+		if err != nil {
+			return nil
+		}
+		owner := openapi.OwnerDto{}
+		if err := yaml.Unmarshal(contents, &owner); err != nil {
+			// the owner exists, but its groups are unknown
+			v.walkedOwners[ownerAlias] = nil
+			return nil
+		}
+		v.walkedOwners[ownerAlias] = owner.Groups
+		return nil
+	})
+}
+
+// checkGroupReferences annotates every group reference in the user lists of the file that does not resolve, at
+// the line it is on. References to owners whose file could not be parsed are not checked.
+func (v *MetadataWalker) checkGroupReferences(path string, contents string) []*github.CheckRunAnnotation {
+	root := yaml.Node{}
+	if err := yaml.Unmarshal([]byte(contents), &root); err != nil {
+		// already reported by parseStrict
+		return nil
+	}
+
+	groupsOf := func(ownerAlias string) (map[string][]string, bool) {
+		groups, ok := v.walkedOwners[ownerAlias]
+		return groups, ok
+	}
+	annotations := make([]*github.CheckRunAnnotation, 0)
+	for _, node := range serviceutil.UserListEntries(&root) {
+		related := make([]string, 0)
+		if _, groupOwner, _ := serviceutil.ParseGroupOwnerAndGroupName(node.Value); groupOwner != "" {
+			if groups, ok := v.walkedOwners[groupOwner]; ok && groups == nil {
+				continue
+			}
+			related = append(related, "owners/"+groupOwner+"/owner.info.yaml")
+		}
+		if problem := serviceutil.GroupReferenceProblem(node.Value, groupsOf); problem != "" {
+			annotations = append(annotations, v.relate(&github.CheckRunAnnotation{
+				Path:            github.Ptr(path),
+				StartLine:       github.Ptr(node.Line),
+				EndLine:         github.Ptr(node.Line),
+				AnnotationLevel: github.Ptr("failure"),
+				Message:         github.Ptr(problem),
+				Title:           github.Ptr("broken group reference"),
+			}, related...))
+		}
+	}
+	return annotations
+}
@@ -0,0 +1,74 @@
+package check
+
+import (
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/google/go-github/v70/github"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMetadataYamlFileWalker_checkGroupReferences(t *testing.T) {
+	filesys := memfs.New()
+	files := map[string]string{
+		// sorts before the owner it references, so the owners must be collected first
+		"owners/a-owner/owner.info.yaml": `contact: a@mail.com
+promoters:
+  - '@some-owner.users'
+  - '@some-owner.nope'
+`,
+		"owners/a-owner/repositories/repository.helm-deployment.yaml": `url: some-url
+mainline: master
+configuration:
+  approvers:
+    testing:
+      - some-user
+      - '@unknown.users'
+  watchers:
+    - '@some-owner.users'
+  refProtections:
+    branches:
+      requirePR:
+        - pattern: ':MAINLINE:'
+          exemptions:
+            - '@broken'
+            - '@unparsable.users'
+`,
+		"owners/some-owner/owner.info.yaml": `contact: some@mail.com
+groups:
+  users:
+    - userA
+`,
+		"owners/unparsable/owner.info.yaml": `contact: [`,
+	}
+	for path, contents := range files {
+		require.Nil(t, util.WriteFile(filesys, path, []byte(contents), 0644))
+	}
+
+	v := MetadataYamlFileWalker(filesys)
+	require.Nil(t, v.ValidateMetadata())
+
+	actual := make([]*github.CheckRunAnnotation, 0)
+	for _, annotation := range v.Annotations {
+		if annotation.Title != nil && *annotation.Title == "broken group reference" {
+			actual = append(actual, annotation)
+		}
+	}
+	expected := []*github.CheckRunAnnotation{
+		groupAnnotation("owners/a-owner/owner.info.yaml", 4, "group reference @some-owner.nope points at group nope, which owner some-owner does not have"),
+		groupAnnotation("owners/a-owner/repositories/repository.helm-deployment.yaml", 7, "group reference @unknown.users points at owner unknown, which does not exist"),
+		groupAnnotation("owners/a-owner/repositories/repository.helm-deployment.yaml", 15, "@broken is not a valid group reference, it must have the form @owner.group"),
+	}
+	require.Equal(t, printAnnotations(expected), printAnnotations(actual))
+}
+
+func groupAnnotation(path string, line int, message string) *github.CheckRunAnnotation {
+	return &github.CheckRunAnnotation{
+		Path:            github.Ptr(path),
+		StartLine:       github.Ptr(line),
+		EndLine:         github.Ptr(line),
+		AnnotationLevel: github.Ptr("failure"),
+		Message:         github.Ptr(message),
+		Title:           github.Ptr("broken group reference"),
+	}
+}
@@ -0,0 +1,143 @@
+package check
+
+import (
+	"fmt"
+	"github.com/google/go-github/v70/github"
+	"gopkg.in/yaml.v3"
+	"sort"
+	"strings"
+)
+
+// serviceReference is an entry of the repositories or the dependsOn of a service file, with the line it is on.
+type serviceReference struct {
+	service string
+	path    string
+	line    int
+	target  string
+}
+
+type walkedServices struct {
+	nameToPath   map[string]string
+	repositories []serviceReference
+	dependsOn    []serviceReference
+}
+
+// recordServiceReferences remembers the references of a service file, they can only be checked once all files
+// have been walked.
+func (v *MetadataWalker) recordServiceReferences(path string, contents string) {
+	_, after, found := strings.Cut(path, "/services/")
+	name, isYaml := strings.CutSuffix(after, ".yaml")
+	if !found || !isYaml {
+		return
+	}
+	if _, exists := v.walkedServices.nameToPath[name]; !exists {
+		v.walkedServices.nameToPath[name] = path
+	}
+
+	root := yaml.Node{}
+	if err := yaml.Unmarshal([]byte(contents), &root); err != nil || len(root.Content) == 0 {
+		// already reported by parseStrict
+		return
+	}
+	for _, node := range sequenceEntries(root.Content[0], "repositories") {
+		v.walkedServices.repositories = append(v.walkedServices.repositories, serviceReference{service: name, path: path, line: node.Line, target: node.Value})
+	}
+	for _, node := range sequenceEntries(root.Content[0], "spec", "dependsOn") {
+		v.walkedServices.dependsOn = append(v.walkedServices.dependsOn, serviceReference{service: name, path: path, line: node.Line, target: node.Value})
+	}
+}
+
+// checkReferences is the whole tree pass. Services must only reference repositories and services that exist,
+// and a repository must not be referenced by more than one service.
+func (v *MetadataWalker) checkReferences() []*github.CheckRunAnnotation {
+	annotations := make([]*github.CheckRunAnnotation, 0)
+
+	servicesOf := make(map[string][]string)
+	for _, reference := range v.walkedServices.repositories {
+		if !containsString(servicesOf[reference.target], reference.service) {
+			servicesOf[reference.target] = append(servicesOf[reference.target], reference.service)
+		}
+	}
+
+	for _, reference := range v.walkedServices.repositories {
+		if _, exists := v.walkedRepos.keyToPath[reference.target]; !exists {
+			annotations = append(annotations, v.relate(referenceAnnotation(reference, "failure", "missing repository",
+				fmt.Sprintf("Repository %s does not exist, there is no file %s.yaml in any owners/*/repositories/ directory.", reference.target, reference.target)),
+				v.changedFilesEndingWith("/repositories/"+reference.target+".yaml")...))
+			continue
+		}
+		others := make([]string, 0)
+		otherPaths := make([]string, 0)
+		for _, other := range servicesOf[reference.target] {
+			if other != reference.service {
+				others = append(others, other)
+				otherPaths = append(otherPaths, v.walkedServices.nameToPath[other])
+			}
+		}
+		if len(others) > 0 {
+			sort.Strings(others)
+			annotations = append(annotations, v.relate(referenceAnnotation(reference, "failure", "shared repository",
+				fmt.Sprintf("Repository %s is also referenced by service %s.", reference.target, strings.Join(others, ", "))),
+				otherPaths...))
+		}
+	}
+
+	for _, reference := range v.walkedServices.dependsOn {
+		if _, exists := v.walkedServices.nameToPath[reference.target]; !exists {
+			annotations = append(annotations, v.relate(referenceAnnotation(reference, "failure", "missing service",
+				fmt.Sprintf("Service %s does not exist, there is no file %s.yaml in any owners/*/services/ directory.", reference.target, reference.target)),
+				v.changedFilesEndingWith("/services/"+reference.target+".yaml")...))
+		}
+	}
+
+	return annotations
+}
+
+func referenceAnnotation(reference serviceReference, level string, title string, message string) *github.CheckRunAnnotation {
+	return &github.CheckRunAnnotation{
+		Path:            github.Ptr(reference.path),
+		StartLine:       github.Ptr(reference.line),
+		EndLine:         github.Ptr(reference.line),
+		AnnotationLevel: github.Ptr(level),
+		Message:         github.Ptr(message),
+		Title:           github.Ptr(title),
+	}
+}
+
+// sequenceEntries follows the keys through nested mappings and gives the scalar entries of the sequence at the end.
+func sequenceEntries(node *yaml.Node, keys ...string) []*yaml.Node {
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				value = node.Content[i+1]
+			}
+		}
+		if value == nil {
+			return nil
+		}
+		node = value
+	}
+	if node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	result := make([]*yaml.Node, 0)
+	for _, entry := range node.Content {
+		if entry.Kind == yaml.ScalarNode && entry.Value != "" {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+func containsString(values []string, candidate string) bool {
+	for _, value := range values {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package check
+
+import (
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/google/go-github/v70/github"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMetadataYamlFileWalker_checkReferences(t *testing.T) {
+	filesys := memfs.New()
+	files := map[string]string{
+		"owners/some-owner/owner.info.yaml": `contact: some@mail.com
+`,
+		"owners/some-owner/services/a-service.yaml": `description: references repositories of another owner, which are walked later
+repositories:
+  - a-service.helm-deployment
+  - shared.implementation
+  - missing.implementation
+alertTarget: some@mail.com
+spec:
+  dependsOn:
+    - b-service
+    - missing-service
+`,
+		"owners/some-owner/repositories/a-service.helm-deployment.yaml": `url: ssh://some-url/a-service-deployment.git
+mainline: master
+`,
+		"owners/zz-owner/owner.info.yaml": `contact: some@mail.com
+`,
+		"owners/zz-owner/services/b-service.yaml": `repositories:
+  - shared.implementation
+alertTarget: some@mail.com
+`,
+		"owners/zz-owner/repositories/shared.implementation.yaml": `url: ssh://some-url/shared.git
+mainline: master
+`,
+	}
+	for path, contents := range files {
+		require.Nil(t, util.WriteFile(filesys, path, []byte(contents), 0644))
+	}
+
+	v := MetadataYamlFileWalker(filesys)
+	require.Nil(t, v.ValidateMetadata())
+
+	actual := make([]*github.CheckRunAnnotation, 0)
+	for _, annotation := range v.Annotations {
+		if annotation.Title != nil && (*annotation.Title == "missing repository" || *annotation.Title == "shared repository" || *annotation.Title == "missing service") {
+			actual = append(actual, annotation)
+		}
+	}
+	expected := []*github.CheckRunAnnotation{
+		referenceAnnotation(serviceReference{path: "owners/some-owner/services/a-service.yaml", line: 4}, "failure", "shared repository",
+			"Repository shared.implementation is also referenced by service b-service."),
+		referenceAnnotation(serviceReference{path: "owners/some-owner/services/a-service.yaml", line: 5}, "failure", "missing repository",
+			"Repository missing.implementation does not exist, there is no file missing.implementation.yaml in any owners/*/repositories/ directory."),
+		referenceAnnotation(serviceReference{path: "owners/zz-owner/services/b-service.yaml", line: 2}, "failure", "shared repository",
+			"Repository shared.implementation is also referenced by service a-service."),
+		referenceAnnotation(serviceReference{path: "owners/some-owner/services/a-service.yaml", line: 10}, "failure", "missing service",
+			"Service missing-service does not exist, there is no file missing-service.yaml in any owners/*/services/ directory."),
+	}
+	require.Equal(t, printAnnotations(expected), printAnnotations(actual))
+
+	result := walkerToCheckRunOutput(v)
+	require.Equal(t, "failure", string(result.conclusion))
+}
@@ -23,7 +23,15 @@ const (
 )
 
 func (v *MetadataWalker) ValidateMetadata() error {
-	return util.Walk(v.fs, v.config.rootDir, v.validateWalkFunc)
+	if err := v.collectOwners(); err != nil {
+		return err
+	}
+	if err := util.Walk(v.fs, v.config.rootDir, v.validateWalkFunc); err != nil {
+		return err
+	}
+	v.Annotations = append(v.Annotations, v.checkReferences()...)
+	v.separatePreExisting()
+	return nil
 }
 
 func (v *MetadataWalker) validateWalkFunc(path string, info fs.FileInfo, err error) error {
@@ -41,16 +49,26 @@ func (v *MetadataWalker) validateWalkFunc(path string, info fs.FileInfo, err err
 func (v *MetadataWalker) validateSingleYamlFile(path string, contents string) []*github.CheckRunAnnotation {
 	if strings.HasPrefix(path, "owners/") && strings.HasSuffix(path, ".yaml") {
 		var annotations []*github.CheckRunAnnotation
+		changed := v.isChanged(path)
 		if strings.Contains(path, "owner.info.yaml") {
-			annotations = parseStrict(path, contents, &openapi.OwnerDto{})
+			if changed {
+				annotations = parseStrict(path, contents, &openapi.OwnerDto{})
+			}
+			annotations = append(annotations, v.checkGroupReferences(path, contents)...)
 		} else if strings.Contains(path, "/services/") {
-			annotations = parseStrict(path, contents, &openapi.ServiceDto{})
+			if changed {
+				annotations = parseStrict(path, contents, &openapi.ServiceDto{})
+			}
+			v.recordServiceReferences(path, contents)
 		} else if strings.Contains(path, "/repositories/") {
 			annotations = v.validateRepositoryFile(path, contents)
 		} else {
 			v.IgnoredWithReason[path] = "file is neither owner info, nor service nor repository"
 			return nil
 		}
+		if !changed {
+			return annotations
+		}
 		if lintAnnotation := v.checkFormatting(path, contents); lintAnnotation != nil {
 			annotations = append(annotations, lintAnnotation)
 		}
@@ -121,8 +139,12 @@ func errorToAnnotation(path string, err error) *github.CheckRunAnnotation {
 }
 
 func (v *MetadataWalker) validateRepositoryFile(path string, contents string) []*github.CheckRunAnnotation {
+	changed := v.isChanged(path)
 	repositoryDto := &openapi.RepositoryDto{}
-	parseAnnotations := parseStrict(path, contents, repositoryDto)
+	var parseAnnotations []*github.CheckRunAnnotation
+	if changed {
+		parseAnnotations = parseStrict(path, contents, repositoryDto)
+	}
 	_, after, found := strings.Cut(path, "/repositories/")
 	repoKey, isYaml := strings.CutSuffix(after, ".yaml")
 	if found && isYaml {
@@ -132,10 +154,15 @@ func (v *MetadataWalker) validateRepositoryFile(path string, contents string) []
 		if annotation := v.checkUrlDuplication(path, contents); annotation != nil {
 			parseAnnotations = append(parseAnnotations, annotation)
 		}
-		if annotation := v.checkMainlineProtection(path, repositoryDto); annotation != nil {
-			parseAnnotations = append(parseAnnotations, annotation)
+		if changed {
+			if annotation := v.checkMainlineProtection(path, repositoryDto); annotation != nil {
+				parseAnnotations = append(parseAnnotations, annotation)
+			}
+			if annotations := v.checkRequiredConditions(path, repositoryDto); len(annotations) > 0 {
+				parseAnnotations = append(parseAnnotations, annotations...)
+			}
 		}
-		if annotations := v.checkRequiredConditions(path, repositoryDto); len(annotations) > 0 {
+		if annotations := v.checkGroupReferences(path, contents); len(annotations) > 0 {
 			parseAnnotations = append(parseAnnotations, annotations...)
 		}
 	}
@@ -146,13 +173,13 @@ func (v *MetadataWalker) validateRepositoryFile(path string, contents string) []
 func (v *MetadataWalker) checkKeyDuplication(path string, repoKey string) *github.CheckRunAnnotation {
 	var annotation *github.CheckRunAnnotation
 	if otherFile, isDuplicatedKey := v.walkedRepos.keyToPath[repoKey]; isDuplicatedKey {
-		annotation = &github.CheckRunAnnotation{
+		annotation = v.relate(&github.CheckRunAnnotation{
 			Path:            github.Ptr(path),
 			StartLine:       github.Ptr(1),
 			EndLine:         github.Ptr(1),
 			AnnotationLevel: github.Ptr("failure"),
 			Message:         github.Ptr(fmt.Sprintf("Repository key already used by %s", otherFile)),
-		}
+		}, otherFile)
 	} else {
 		v.walkedRepos.keyToPath[repoKey] = path
 	}
@@ -164,13 +191,13 @@ func (v *MetadataWalker) checkUrlDuplication(path string, contents string) *gith
 		if strings.HasPrefix(line, "url: ") {
 			url := strings.TrimSpace(strings.ReplaceAll(line, "url: ", ""))
 			if otherFile, isDuplicatedUrl := v.walkedRepos.urlToPath[url]; isDuplicatedUrl {
-				return &github.CheckRunAnnotation{
+				return v.relate(&github.CheckRunAnnotation{
 					Path:            github.Ptr(path),
 					StartLine:       github.Ptr(lineNum + 1),
 					EndLine:         github.Ptr(lineNum + 1),
 					AnnotationLevel: github.Ptr("failure"),
 					Message:         github.Ptr(fmt.Sprintf("Repository url already used by %s", otherFile)),
-				}
+				}, otherFile)
 			} else {
 				v.walkedRepos.urlToPath[url] = path
 			}
@@ -0,0 +1,159 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/goneerror"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/types"
+	auzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
+)
+
+// recentEventsSize is the number of events we keep for subscribers that resume.
+const recentEventsSize = 256
+
+// subscriberBufferSize is the number of events a subscriber may fall behind before it is dropped.
+const subscriberBufferSize = 64
+
+type subscriber struct {
+	filter types.EventFilter
+	events chan repository.UpdateEvent
+}
+
+type Impl struct {
+	Configuration librepo.Configuration
+	Logging       librepo.Logging
+	Timestamp     librepo.Timestamp
+
+	mu           sync.Mutex
+	recent       []repository.UpdateEvent
+	subscribers  map[int]*subscriber
+	nextSubToken int
+}
+
+func New(
+	configuration librepo.Configuration,
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+) service.Events {
+	return &Impl{
+		Configuration: configuration,
+		Logging:       logging,
+		Timestamp:     timestamp,
+		recent:        make([]repository.UpdateEvent, 0, recentEventsSize),
+		subscribers:   make(map[int]*subscriber),
+	}
+}
+
+func (s *Impl) IsEvents() bool {
+	return true
+}
+
+func (s *Impl) Setup() error {
+	ctx := auzerolog.AddLoggerToCtx(context.Background())
+
+	s.Logging.Logger().Ctx(ctx).Info().Print("successfully set up events business component")
+	return nil
+}
+
+func (s *Impl) Publish(ctx context.Context, event repository.UpdateEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.recent) == recentEventsSize {
+		s.recent = append(s.recent[:0], s.recent[1:]...)
+	}
+	s.recent = append(s.recent, event)
+
+	for token, sub := range s.subscribers {
+		if !matches(event, sub.filter) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			s.Logging.Logger().Ctx(ctx).Info().Printf("dropping event stream subscriber that fell behind at commit %s", event.CommitHash)
+			s.unsubscribeMustHoldMutex(token)
+		}
+	}
+}
+
+func (s *Impl) Subscribe(_ context.Context, lastEventId string, filter types.EventFilter) (<-chan repository.UpdateEvent, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	replay, err := s.eventsAfterMustHoldMutex(lastEventId, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sub := &subscriber{
+		filter: filter,
+		events: make(chan repository.UpdateEvent, len(replay)+subscriberBufferSize),
+	}
+	for _, event := range replay {
+		sub.events <- event
+	}
+
+	token := s.nextSubToken
+	s.nextSubToken++
+	s.subscribers[token] = sub
+
+	unsubscribe := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.unsubscribeMustHoldMutex(token)
+	}
+	return sub.events, unsubscribe, nil
+}
+
+// eventsAfterMustHoldMutex finds the matching events after the newest event with the given commit hash.
+func (s *Impl) eventsAfterMustHoldMutex(lastEventId string, filter types.EventFilter) ([]repository.UpdateEvent, error) {
+	if lastEventId == "" {
+		return nil, nil
+	}
+
+	for i := len(s.recent) - 1; i >= 0; i-- {
+		if s.recent[i].CommitHash == lastEventId {
+			result := make([]repository.UpdateEvent, 0)
+			for _, event := range s.recent[i+1:] {
+				if matches(event, filter) {
+					result = append(result, event)
+				}
+			}
+			return result, nil
+		}
+	}
+	return nil, goneerror.New("events.resume.gone", fmt.Sprintf("event %s is no longer known - please read the current state and subscribe without Last-Event-ID", lastEventId), nil, s.Timestamp.Now())
+}
+
+func (s *Impl) unsubscribeMustHoldMutex(token int) {
+	if sub, ok := s.subscribers[token]; ok {
+		close(sub.events)
+		delete(s.subscribers, token)
+	}
+}
+
+func matches(event repository.UpdateEvent, filter types.EventFilter) bool {
+	if filter.IsEmpty() {
+		return true
+	}
+	return containsAny(event.Affected.OwnerAliases, filter.OwnerAliases) ||
+		containsAny(event.Affected.ServiceNames, filter.ServiceNames) ||
+		containsAny(event.Affected.RepositoryKeys, filter.RepositoryKeys)
+}
+
+func containsAny(values []string, wanted []string) bool {
+	for _, value := range values {
+		for _, w := range wanted {
+			if value == w {
+				return true
+			}
+		}
+	}
+	return false
+}
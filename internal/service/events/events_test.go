@@ -0,0 +1,105 @@
+package events
+
+import (
+	"context"
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/go-backend-service-common/repository/logging"
+	"github.com/Interhyp/go-backend-service-common/repository/timestamp"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/goneerror"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func tstInstance() *Impl {
+	return New(nil, logging.New().(*logging.LoggingImpl), timestamp.NewNoAcorn(time.Now)).(*Impl)
+}
+
+func tstEvent(commitHash string, serviceNames ...string) repository.UpdateEvent {
+	return repository.UpdateEvent{
+		Affected: repository.EventAffects{
+			OwnerAliases:   []string{},
+			ServiceNames:   serviceNames,
+			RepositoryKeys: []string{},
+		},
+		TimeStamp:  "2022-11-06T18:14:10Z",
+		CommitHash: commitHash,
+	}
+}
+
+func tstReceived(events <-chan repository.UpdateEvent) []string {
+	result := make([]string, 0)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return append(result, "closed")
+			}
+			result = append(result, event.CommitHash)
+		default:
+			return result
+		}
+	}
+}
+
+func TestPublish_Filter(t *testing.T) {
+	docs.Description("subscribers only receive the events that match their filter")
+	ctx := context.Background()
+	instance := tstInstance()
+
+	all, _, err := instance.Subscribe(ctx, "", types.EventFilter{})
+	require.Nil(t, err)
+	unicorns, _, err := instance.Subscribe(ctx, "", types.EventFilter{ServiceNames: []string{"unicorn-finder"}})
+	require.Nil(t, err)
+
+	instance.Publish(ctx, tstEvent("c1", "unicorn-finder"))
+	instance.Publish(ctx, tstEvent("c2", "dragon-feeder"))
+
+	require.Equal(t, []string{"c1", "c2"}, tstReceived(all))
+	require.Equal(t, []string{"c1"}, tstReceived(unicorns))
+}
+
+func TestSubscribe_Resume(t *testing.T) {
+	docs.Description("subscribers resuming from a known event receive the matching events after it first")
+	ctx := context.Background()
+	instance := tstInstance()
+
+	instance.Publish(ctx, tstEvent("c1", "unicorn-finder"))
+	instance.Publish(ctx, tstEvent("c2", "dragon-feeder"))
+	instance.Publish(ctx, tstEvent("c3", "unicorn-finder"))
+
+	events, unsubscribe, err := instance.Subscribe(ctx, "c1", types.EventFilter{ServiceNames: []string{"unicorn-finder"}})
+	require.Nil(t, err)
+	instance.Publish(ctx, tstEvent("c4", "unicorn-finder"))
+	require.Equal(t, []string{"c3", "c4"}, tstReceived(events))
+
+	unsubscribe()
+	require.Equal(t, []string{"closed"}, tstReceived(events))
+}
+
+func TestSubscribe_ResumeUnknown(t *testing.T) {
+	docs.Description("resuming from an event that is no longer known fails with gone")
+	instance := tstInstance()
+
+	_, _, err := instance.Subscribe(context.Background(), "c0", types.EventFilter{})
+	require.True(t, goneerror.Is(err))
+}
+
+func TestPublish_SlowSubscriber(t *testing.T) {
+	docs.Description("subscribers that fall too far behind are dropped")
+	ctx := context.Background()
+	instance := tstInstance()
+
+	events, _, err := instance.Subscribe(ctx, "", types.EventFilter{})
+	require.Nil(t, err)
+	for i := 0; i <= subscriberBufferSize; i++ {
+		instance.Publish(ctx, tstEvent("c"))
+	}
+
+	received := tstReceived(events)
+	require.Equal(t, subscriberBufferSize+1, len(received))
+	require.Equal(t, "closed", received[subscriberBufferSize])
+	require.Equal(t, 0, len(instance.subscribers))
+}
@@ -0,0 +1,458 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/config"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/types"
+	auzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
+)
+
+type Impl struct {
+	Configuration       librepo.Configuration
+	CustomConfiguration config.CustomConfiguration
+	Logging             librepo.Logging
+	Timestamp           librepo.Timestamp
+	Cache               repository.Cache
+}
+
+func New(
+	configuration librepo.Configuration,
+	customConfig config.CustomConfiguration,
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+	cache repository.Cache,
+) service.Graph {
+	return &Impl{
+		Configuration:       configuration,
+		CustomConfiguration: customConfig,
+		Logging:             logging,
+		Timestamp:           timestamp,
+		Cache:               cache,
+	}
+}
+
+func (s *Impl) IsGraph() bool {
+	return true
+}
+
+func (s *Impl) Setup() error {
+	ctx := auzerolog.AddLoggerToCtx(context.Background())
+
+	s.Logging.Logger().Ctx(ctx).Info().Print("successfully set up graph business component")
+	return nil
+}
+
+func (s *Impl) GetGraph(ctx context.Context, rootService string, depth int, direction types.GraphDirection) (openapi.GraphDto, error) {
+	if !direction.IsValid() {
+		return openapi.GraphDto{}, apierrors.NewBadRequestError("graph.invalid.direction", fmt.Sprintf("direction must be one of %s, %s or %s", types.GraphDirectionBoth, types.GraphDirectionDependencies, types.GraphDirectionDependents), nil, s.Timestamp.Now())
+	}
+
+	m, err := s.loadModel(ctx)
+	if err != nil {
+		return openapi.GraphDto{}, err
+	}
+
+	if rootService == "" {
+		return m.toDto(nil), nil
+	}
+	if _, ok := m.services[rootService]; !ok {
+		return openapi.GraphDto{}, apierrors.NewNotFoundError("service.notfound", fmt.Sprintf("service %s not found", rootService), nil, s.Timestamp.Now())
+	}
+	return m.toDto(m.reachable(serviceId(rootService), depth, direction)), nil
+}
+
+func (s *Impl) ValidateDependencies(ctx context.Context, serviceName string, current *openapi.ServiceSpecDto, candidate *openapi.ServiceSpecDto) error {
+	added := addedDependencies(current, candidate)
+	if len(added) == 0 {
+		return nil
+	}
+
+	m, err := s.loadModel(ctx)
+	if err != nil {
+		return err
+	}
+	return s.validateAddedDependencies(ctx, m, serviceName, added, candidate)
+}
+
+func (s *Impl) ValidateDependenciesIn(ctx context.Context, services map[string]openapi.ServiceDto, serviceName string, current *openapi.ServiceSpecDto, candidate *openapi.ServiceSpecDto) error {
+	added := addedDependencies(current, candidate)
+	if len(added) == 0 {
+		return nil
+	}
+
+	return s.validateAddedDependencies(ctx, newModel(services), serviceName, added, candidate)
+}
+
+func (s *Impl) validateAddedDependencies(ctx context.Context, m *model, serviceName string, added []string, candidate *openapi.ServiceSpecDto) error {
+	m.dependsOn[serviceName] = dependsOn(candidate)
+
+	messages := make([]string, 0)
+	// unknown services and cycles do not fail the write, they are reported as warnings and the graph lists them
+	warnings := make([]string, 0)
+	for _, target := range added {
+		if target == serviceName {
+			warnings = append(warnings, fmt.Sprintf("dependsOn %s creates a cycle: %s -> %s", target, serviceName, serviceName))
+			continue
+		}
+		targetService, ok := m.services[target]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("dependsOn references unknown service %s", target))
+			continue
+		}
+		if lifecycle := targetService.Lifecycle; lifecycle != nil && slices.Contains(s.CustomConfiguration.ServiceLifecycleNoNewDependents(), *lifecycle) {
+			messages = append(messages, fmt.Sprintf("dependsOn %s is not allowed, the service is %s", target, *lifecycle))
+			continue
+		}
+		if path := m.dependencyPath(target, serviceName); path != nil {
+			warnings = append(warnings, fmt.Sprintf("dependsOn %s creates a cycle: %s -> %s", target, serviceName, strings.Join(path, " -> ")))
+		}
+	}
+
+	if len(warnings) > 0 {
+		s.Logging.Logger().Ctx(ctx).Warn().Printf("service %s has dependency problems: %s", serviceName, strings.Join(warnings, ", "))
+		for _, warning := range warnings {
+			types.AddWarning(ctx, warning)
+		}
+	}
+
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("service dependencies invalid: %s", details)
+		return apierrors.NewBadRequestError("service.invalid.dependency", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+func (s *Impl) loadModel(ctx context.Context) (*model, error) {
+	names, err := s.Cache.GetSortedServiceNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make(map[string]openapi.ServiceDto, len(names))
+	for _, name := range names {
+		theService, err := s.Cache.GetService(ctx, name)
+		if err != nil {
+			// deleted while we were reading
+			continue
+		}
+		services[name] = theService
+	}
+	return newModel(services), nil
+}
+
+// --- model ---
+
+type model struct {
+	services map[string]openapi.ServiceDto
+
+	dependsOn  map[string][]string // service -> services it depends on
+	dependents map[string][]string // service -> services depending on it, may be unknown services
+	providers  map[string][]string // api -> services providing it
+	consumers  map[string][]string // api -> services consuming it
+}
+
+func newModel(services map[string]openapi.ServiceDto) *model {
+	m := &model{
+		services:   services,
+		dependsOn:  make(map[string][]string),
+		dependents: make(map[string][]string),
+		providers:  make(map[string][]string),
+		consumers:  make(map[string][]string),
+	}
+	for _, name := range sortedKeys(services) {
+		spec := services[name].Spec
+		if spec == nil {
+			continue
+		}
+		m.dependsOn[name] = dependsOn(spec)
+		for _, target := range m.dependsOn[name] {
+			m.dependents[target] = append(m.dependents[target], name)
+		}
+		for _, api := range unique(spec.ProvidesApis) {
+			m.providers[api] = append(m.providers[api], name)
+		}
+		for _, api := range unique(spec.ConsumesApis) {
+			m.consumers[api] = append(m.consumers[api], name)
+		}
+	}
+	return m
+}
+
+func serviceId(name string) string {
+	return types.GraphNodeTypeService + ":" + name
+}
+
+func apiId(name string) string {
+	return types.GraphNodeTypeApi + ":" + name
+}
+
+// neighbours gives the ids of the nodes one step away from id in the given direction.
+//
+// A service depends on the services in its dependsOn and on the apis it consumes, an api depends on its providers.
+func (m *model) neighbours(id string, direction types.GraphDirection) []string {
+	result := make([]string, 0)
+	nodeType, name, _ := strings.Cut(id, ":")
+	forward := direction != types.GraphDirectionDependents
+	backward := direction != types.GraphDirectionDependencies
+	if nodeType == types.GraphNodeTypeService {
+		if forward {
+			for _, target := range m.dependsOn[name] {
+				result = append(result, serviceId(target))
+			}
+			if theService, ok := m.services[name]; ok && theService.Spec != nil {
+				for _, api := range unique(theService.Spec.ConsumesApis) {
+					result = append(result, apiId(api))
+				}
+			}
+		}
+		if backward {
+			for _, source := range m.dependents[name] {
+				result = append(result, serviceId(source))
+			}
+			if theService, ok := m.services[name]; ok && theService.Spec != nil {
+				for _, api := range unique(theService.Spec.ProvidesApis) {
+					result = append(result, apiId(api))
+				}
+			}
+		}
+	} else {
+		if forward {
+			for _, provider := range m.providers[name] {
+				result = append(result, serviceId(provider))
+			}
+		}
+		if backward {
+			for _, consumer := range m.consumers[name] {
+				result = append(result, serviceId(consumer))
+			}
+		}
+	}
+	return result
+}
+
+// reachable gives the set of node ids reachable from root within depth steps, 0 meaning no limit.
+func (m *model) reachable(root string, depth int, direction types.GraphDirection) map[string]bool {
+	result := map[string]bool{root: true}
+	frontier := []string{root}
+	for step := 0; len(frontier) > 0 && (depth == 0 || step < depth); step++ {
+		next := make([]string, 0)
+		for _, id := range frontier {
+			for _, neighbour := range m.neighbours(id, direction) {
+				if !result[neighbour] {
+					result[neighbour] = true
+					next = append(next, neighbour)
+				}
+			}
+		}
+		frontier = next
+	}
+	return result
+}
+
+// dependencyPath finds a shortest dependsOn path from one service to another, or nil if there is none.
+func (m *model) dependencyPath(from string, to string) []string {
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			path := make([]string, 0)
+			for name := to; name != ""; name = previous[name] {
+				path = append([]string{name}, path...)
+			}
+			return path
+		}
+		for _, target := range m.dependsOn[current] {
+			if _, seen := previous[target]; !seen {
+				previous[target] = current
+				queue = append(queue, target)
+			}
+		}
+	}
+	return nil
+}
+
+// toDto converts the nodes in include, or all nodes if include is nil, and the edges between them.
+func (m *model) toDto(include map[string]bool) openapi.GraphDto {
+	nodes := make(map[string]openapi.GraphNodeDto)
+	edges := make([]openapi.GraphEdgeDto, 0)
+	addNode := func(node openapi.GraphNodeDto) {
+		if include == nil || include[node.Id] {
+			nodes[node.Id] = node
+		}
+	}
+	addEdge := func(edge openapi.GraphEdgeDto) {
+		if include == nil || (include[edge.From] && include[edge.To]) {
+			edges = append(edges, edge)
+		}
+	}
+
+	for _, name := range sortedKeys(m.services) {
+		theService := m.services[name]
+		node := openapi.GraphNodeDto{Id: serviceId(name), Type: types.GraphNodeTypeService, Name: name, Owner: &theService.Owner}
+		if theService.Spec != nil {
+			node.System = theService.Spec.System
+		}
+		addNode(node)
+
+		for _, target := range m.dependsOn[name] {
+			if _, ok := m.services[target]; !ok {
+				addNode(openapi.GraphNodeDto{Id: serviceId(target), Type: types.GraphNodeTypeUnknown, Name: target})
+			}
+			addEdge(openapi.GraphEdgeDto{From: serviceId(name), To: serviceId(target), Type: types.GraphEdgeTypeDependsOn})
+		}
+		if theService.Spec != nil {
+			for _, api := range unique(theService.Spec.ProvidesApis) {
+				addNode(openapi.GraphNodeDto{Id: apiId(api), Type: types.GraphNodeTypeApi, Name: api})
+				addEdge(openapi.GraphEdgeDto{From: serviceId(name), To: apiId(api), Type: types.GraphEdgeTypeProvidesApi})
+			}
+			for _, api := range unique(theService.Spec.ConsumesApis) {
+				addNode(openapi.GraphNodeDto{Id: apiId(api), Type: types.GraphNodeTypeApi, Name: api})
+				addEdge(openapi.GraphEdgeDto{From: serviceId(name), To: apiId(api), Type: types.GraphEdgeTypeConsumesApi})
+			}
+		}
+	}
+
+	result := openapi.GraphDto{
+		Nodes:  make([]openapi.GraphNodeDto, 0, len(nodes)),
+		Edges:  edges,
+		Cycles: m.cycles(include),
+	}
+	for _, id := range sortedKeys(nodes) {
+		result.Nodes = append(result.Nodes, nodes[id])
+	}
+	sort.Slice(result.Edges, func(i, j int) bool {
+		a, b := result.Edges[i], result.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Type < b.Type
+	})
+	return result
+}
+
+// cycles finds the strongly connected components of the dependsOn relation among the included services
+// that contain a cycle (Tarjan's algorithm).
+func (m *model) cycles(include map[string]bool) [][]string {
+	included := func(name string) bool {
+		_, exists := m.services[name]
+		return exists && (include == nil || include[serviceId(name)])
+	}
+
+	index := make(map[string]int)
+	lowLink := make(map[string]int)
+	onStack := make(map[string]bool)
+	stack := make([]string, 0)
+	result := make([][]string, 0)
+
+	var connect func(name string)
+	connect = func(name string) {
+		index[name] = len(index)
+		lowLink[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+
+		selfLoop := false
+		for _, target := range m.dependsOn[name] {
+			if !included(target) {
+				continue
+			}
+			if target == name {
+				selfLoop = true
+			}
+			if _, visited := index[target]; !visited {
+				connect(target)
+				lowLink[name] = min(lowLink[name], lowLink[target])
+			} else if onStack[target] {
+				lowLink[name] = min(lowLink[name], index[target])
+			}
+		}
+
+		if lowLink[name] == index[name] {
+			component := make([]string, 0)
+			for {
+				member := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[member] = false
+				component = append(component, member)
+				if member == name {
+					break
+				}
+			}
+			if len(component) > 1 || selfLoop {
+				sort.Strings(component)
+				result = append(result, component)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(m.services) {
+		if _, visited := index[name]; !visited && included(name) {
+			connect(name)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+	return result
+}
+
+// --- helpers
+
+func dependsOn(spec *openapi.ServiceSpecDto) []string {
+	if spec == nil {
+		return nil
+	}
+	return unique(spec.DependsOn)
+}
+
+// addedDependencies gives the dependsOn entries of candidate that are not in current.
+func addedDependencies(current *openapi.ServiceSpecDto, candidate *openapi.ServiceSpecDto) []string {
+	existing := make(map[string]bool)
+	for _, target := range dependsOn(current) {
+		existing[target] = true
+	}
+	result := make([]string, 0)
+	for _, target := range dependsOn(candidate) {
+		if !existing[target] {
+			result = append(result, target)
+		}
+	}
+	return result
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	result := make([]string, 0, len(values))
+	for key := range values {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
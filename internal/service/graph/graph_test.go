@@ -0,0 +1,185 @@
+package graph
+
+import (
+	"context"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/go-backend-service-common/repository/logging"
+	"github.com/Interhyp/go-backend-service-common/repository/timestamp"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/repository/cache"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"github.com/Interhyp/metadata-service/test/mock/configmock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func p(v string) *string {
+	return &v
+}
+
+func tstService(owner string, dependsOn []string, provides []string, consumes []string) openapi.ServiceDto {
+	return openapi.ServiceDto{
+		Owner: owner,
+		Spec: &openapi.ServiceSpecDto{
+			DependsOn:    dependsOn,
+			ProvidesApis: provides,
+			ConsumesApis: consumes,
+		},
+	}
+}
+
+// tstServices: the finder depends on the feeder, the feeder on the stable, and the finder consumes the
+// feeding api that the stable provides. The stable depends on a service that does not exist.
+func tstServices() map[string]openapi.ServiceDto {
+	return map[string]openapi.ServiceDto{
+		"unicorn-finder": tstService("unicorns", []string{"dragon-feeder"}, nil, []string{"feeding"}),
+		"dragon-feeder":  tstService("dragons", []string{"stable"}, nil, nil),
+		"stable":         tstService("dragons", []string{"gone"}, []string{"feeding"}, nil),
+		"loner":          {Owner: "unicorns"},
+	}
+}
+
+func tstInstance(t *testing.T, services map[string]openapi.ServiceDto) *Impl {
+	ctx := context.Background()
+	theLogging := logging.New().(*logging.LoggingImpl)
+	theCache := cache.NewInMemory(nil, nil, theLogging, timestamp.NewNoAcorn(time.Now))
+	for name, theService := range services {
+		require.Nil(t, theCache.PutService(ctx, name, theService))
+	}
+	return New(nil, &configmock.MockConfig{}, theLogging, timestamp.NewNoAcorn(time.Now), theCache).(*Impl)
+}
+
+func tstNodeIds(graph openapi.GraphDto) []string {
+	result := make([]string, 0)
+	for _, node := range graph.Nodes {
+		result = append(result, node.Id)
+	}
+	return result
+}
+
+func TestGetGraph_All(t *testing.T) {
+	docs.Description("the full graph contains all services, apis and unknown references, with sorted edges")
+
+	graph, err := tstInstance(t, tstServices()).GetGraph(context.Background(), "", 0, types.GraphDirectionBoth)
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		"api:feeding",
+		"service:dragon-feeder",
+		"service:gone",
+		"service:loner",
+		"service:stable",
+		"service:unicorn-finder",
+	}, tstNodeIds(graph))
+	require.Equal(t, types.GraphNodeTypeUnknown, graph.Nodes[2].Type)
+	require.Equal(t, []openapi.GraphEdgeDto{
+		{From: "service:dragon-feeder", To: "service:stable", Type: types.GraphEdgeTypeDependsOn},
+		{From: "service:stable", To: "api:feeding", Type: types.GraphEdgeTypeProvidesApi},
+		{From: "service:stable", To: "service:gone", Type: types.GraphEdgeTypeDependsOn},
+		{From: "service:unicorn-finder", To: "api:feeding", Type: types.GraphEdgeTypeConsumesApi},
+		{From: "service:unicorn-finder", To: "service:dragon-feeder", Type: types.GraphEdgeTypeDependsOn},
+	}, graph.Edges)
+	require.Equal(t, [][]string{}, graph.Cycles)
+}
+
+func TestGetGraph_Rooted(t *testing.T) {
+	docs.Description("a rooted graph follows the requested direction up to the requested depth")
+	instance := tstInstance(t, tstServices())
+	ctx := context.Background()
+
+	graph, err := instance.GetGraph(ctx, "unicorn-finder", 1, types.GraphDirectionDependencies)
+	require.Nil(t, err)
+	require.Equal(t, []string{"api:feeding", "service:dragon-feeder", "service:unicorn-finder"}, tstNodeIds(graph))
+
+	graph, err = instance.GetGraph(ctx, "unicorn-finder", 0, types.GraphDirectionDependencies)
+	require.Nil(t, err)
+	require.Equal(t, []string{"api:feeding", "service:dragon-feeder", "service:gone", "service:stable", "service:unicorn-finder"}, tstNodeIds(graph))
+
+	graph, err = instance.GetGraph(ctx, "stable", 0, types.GraphDirectionDependents)
+	require.Nil(t, err)
+	require.Equal(t, []string{"api:feeding", "service:dragon-feeder", "service:stable", "service:unicorn-finder"}, tstNodeIds(graph))
+
+	graph, err = instance.GetGraph(ctx, "loner", 0, types.GraphDirectionBoth)
+	require.Nil(t, err)
+	require.Equal(t, []string{"service:loner"}, tstNodeIds(graph))
+	require.Equal(t, 0, len(graph.Edges))
+}
+
+func TestGetGraph_Invalid(t *testing.T) {
+	docs.Description("a rooted graph needs an existing service and a valid direction")
+	instance := tstInstance(t, tstServices())
+	ctx := context.Background()
+
+	_, err := instance.GetGraph(ctx, "unknown", 0, types.GraphDirectionBoth)
+	require.True(t, apierrors.IsNotFoundError(err))
+
+	_, err = instance.GetGraph(ctx, "", 0, "sideways")
+	require.True(t, apierrors.IsBadRequestError(err))
+}
+
+func TestGetGraph_Cycles(t *testing.T) {
+	docs.Description("dependsOn cycles are reported, including services depending on themselves")
+	services := tstServices()
+	services["stable"] = tstService("dragons", []string{"unicorn-finder"}, nil, nil)
+	services["loner"] = tstService("unicorns", []string{"loner"}, nil, nil)
+
+	graph, err := tstInstance(t, services).GetGraph(context.Background(), "", 0, types.GraphDirectionBoth)
+	require.Nil(t, err)
+	require.Equal(t, [][]string{
+		{"dragon-feeder", "stable", "unicorn-finder"},
+		{"loner"},
+	}, graph.Cycles)
+}
+
+func TestValidateDependencies(t *testing.T) {
+	docs.Description("adding dependsOn references to unknown services or that close a cycle is allowed, but reported as warnings")
+	instance := tstInstance(t, tstServices())
+	ctx := types.WithWarnings(context.Background())
+	current := tstServices()["stable"].Spec
+
+	err := instance.ValidateDependencies(ctx, "stable", current, &openapi.ServiceSpecDto{DependsOn: []string{"gone", "unicorn-finder", "missing"}})
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		"dependsOn unicorn-finder creates a cycle: stable -> unicorn-finder -> dragon-feeder -> stable",
+		"dependsOn references unknown service missing",
+	}, types.Warnings(ctx), "gone was referenced before")
+
+	ctx = types.WithWarnings(context.Background())
+	err = instance.ValidateDependencies(ctx, "new-service", nil, &openapi.ServiceSpecDto{DependsOn: []string{"new-service"}})
+	require.Nil(t, err)
+	require.Equal(t, []string{"dependsOn new-service creates a cycle: new-service -> new-service"}, types.Warnings(ctx))
+}
+
+func TestValidateDependenciesIn(t *testing.T) {
+	docs.Description("dependencies can be validated against other services than the cached ones")
+	services := tstServices()
+	stable := services["stable"]
+	stable.Lifecycle = p("deprecated")
+	services["stable"] = stable
+	instance := tstInstance(t, tstServices())
+	ctx := context.Background()
+
+	err := instance.ValidateDependencies(ctx, "loner", nil, &openapi.ServiceSpecDto{DependsOn: []string{"stable"}})
+	require.Nil(t, err)
+	err = instance.ValidateDependenciesIn(ctx, services, "loner", nil, &openapi.ServiceSpecDto{DependsOn: []string{"stable"}})
+	require.True(t, apierrors.IsBadRequestError(err), "stable is deprecated in the given services")
+}
+
+func TestValidateDependencies_Deprecated(t *testing.T) {
+	docs.Description("a service whose lifecycle allows no new dependents cannot gain them, but keeps existing ones")
+	services := tstServices()
+	stable := services["stable"]
+	stable.Lifecycle = p("deprecated")
+	services["stable"] = stable
+	instance := tstInstance(t, services)
+	ctx := context.Background()
+
+	err := instance.ValidateDependencies(ctx, "loner", nil, &openapi.ServiceSpecDto{DependsOn: []string{"stable"}})
+	require.True(t, apierrors.IsBadRequestError(err))
+	require.Equal(t, "validation error: dependsOn stable is not allowed, the service is deprecated", *err.(apierrors.AnnotatedError).ApiError().Details)
+
+	current := services["dragon-feeder"].Spec
+	err = instance.ValidateDependencies(ctx, "dragon-feeder", current, &openapi.ServiceSpecDto{DependsOn: []string{"stable", "loner"}})
+	require.Nil(t, err, "existing dependents are not affected")
+}
@@ -0,0 +1,187 @@
+package mapper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/types"
+	internalutil "github.com/Interhyp/metadata-service/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// deletion is the newest deletion of an entity, with the last version of its file.
+type deletion struct {
+	entry  openapi.DeletedEntityDto
+	change repository.FileChange
+}
+
+func (s *Impl) GetDeletions(ctx context.Context, kind string) ([]openapi.DeletedEntityDto, error) {
+	deletions, err := s.deletions(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]openapi.DeletedEntityDto, 0, len(deletions))
+	for _, d := range deletions {
+		result = append(result, d.entry)
+	}
+	return result, nil
+}
+
+func (s *Impl) GetDeletedOwner(ctx context.Context, ownerAlias string) (openapi.OwnerDto, error) {
+	result := openapi.OwnerDto{}
+	d, err := s.deletion(ctx, types.DeletedOwners, ownerAlias)
+	if err != nil {
+		return result, err
+	}
+	if err := yaml.Unmarshal(d.change.Before, &result); err != nil {
+		return result, fmt.Errorf("failed to parse deleted %s as yaml from metadata: %s", d.change.BeforePath, err.Error())
+	}
+
+	if result.Groups != nil {
+		s.processGroupMap(ctx, result.Groups)
+	}
+	return result, nil
+}
+
+func (s *Impl) GetDeletedService(ctx context.Context, serviceName string) (openapi.ServiceDto, error) {
+	result := openapi.ServiceDto{}
+	d, err := s.deletion(ctx, types.DeletedServices, serviceName)
+	if err != nil {
+		return result, err
+	}
+	if err := yaml.Unmarshal(d.change.Before, &result); err != nil {
+		return result, fmt.Errorf("failed to parse deleted %s as yaml from metadata: %s", d.change.BeforePath, err.Error())
+	}
+
+	result.Repositories = transformKeys(result.Repositories, "/", ".")
+	result.Owner = d.entry.Owner
+	return result, nil
+}
+
+func (s *Impl) GetDeletedRepository(ctx context.Context, repoKey string) (openapi.RepositoryDto, error) {
+	result := openapi.RepositoryDto{}
+	d, err := s.deletion(ctx, types.DeletedRepositories, repoKey)
+	if err != nil {
+		return result, err
+	}
+	if err := yaml.Unmarshal(d.change.Before, &result); err != nil {
+		return result, fmt.Errorf("failed to parse deleted %s as yaml from metadata: %s", d.change.BeforePath, err.Error())
+	}
+
+	splitKey := strings.Split(repoKey, ".")
+	if len(splitKey) > 1 {
+		result.Type = internalutil.Ptr(splitKey[1])
+	}
+	if result.Configuration != nil && result.Configuration.Approvers != nil {
+		s.processGroupMap(ctx, result.Configuration.Approvers)
+	}
+	result.Owner = d.entry.Owner
+	return result, nil
+}
+
+func (s *Impl) deletion(ctx context.Context, kind string, name string) (deletion, error) {
+	deletions, err := s.deletions(ctx, kind)
+	if err != nil {
+		return deletion{}, err
+	}
+	for _, d := range deletions {
+		if d.entry.Name == name {
+			return d, nil
+		}
+	}
+	return deletion{}, fmt.Errorf("no deleted %s named %s: %w", kind, name, os.ErrNotExist)
+}
+
+// deletions finds the entities of a kind that were deleted and do not exist now, most recently deleted first.
+//
+// Only the newest deletion of each entity counts. Entities that were moved to another owner are deleted at their old
+// path, but still exist, and entities that were renamed are redirects, so neither is listed.
+func (s *Impl) deletions(ctx context.Context, kind string) ([]deletion, error) {
+	changes, err := s.Metadata.FileDeletions(ctx, func(path string) bool {
+		return deletedEntityName(kind, path) != ""
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	redirects, err := s.GetRedirects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]deletion, 0)
+	seen := make(map[string]bool)
+	for _, change := range changes {
+		name := deletedEntityName(kind, change.BeforePath)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if isRedirect(redirects, kind, name) || s.exists(ctx, kind, name) {
+			continue
+		}
+
+		entry := openapi.DeletedEntityDto{
+			Name:       name,
+			CommitHash: change.CommitHash,
+			TimeStamp:  timeStamp(change.TimeStamp),
+			Author:     change.Author,
+			JiraIssue:  jiraIssue(change.Message),
+		}
+		if kind != types.DeletedOwners {
+			entry.Owner = ownerFromPath(change.BeforePath)
+		}
+		result = append(result, deletion{entry: entry, change: change})
+	}
+	return result, nil
+}
+
+// deletedEntityName gives the name of the entity of the given kind a file belongs to, or the empty string.
+func deletedEntityName(kind string, path string) string {
+	components := strings.Split(path, "/")
+	if len(components) < 3 || components[0] != "owners" {
+		return ""
+	}
+	if kind == types.DeletedOwners {
+		if len(components) == 3 && components[2] == "owner.info.yaml" {
+			return components[1]
+		}
+		return ""
+	}
+	if len(components) == 4 && components[2] == kind && strings.HasSuffix(components[3], ".yaml") {
+		return strings.TrimSuffix(components[3], ".yaml")
+	}
+	return ""
+}
+
+func isRedirect(redirects types.Redirects, kind string, name string) bool {
+	var ok bool
+	switch kind {
+	case types.DeletedOwners:
+		_, ok = redirects.Owners[name]
+	case types.DeletedServices:
+		_, ok = redirects.Services[name]
+	default:
+		_, ok = redirects.Repositories[name]
+	}
+	return ok
+}
+
+func (s *Impl) exists(ctx context.Context, kind string, name string) bool {
+	var err error
+	switch kind {
+	case types.DeletedOwners:
+		_, err = s.Metadata.Stat("owners/" + name + "/owner.info.yaml")
+	case types.DeletedServices:
+		_, err = s.lookupServiceOwnerWithRefresh(ctx, name)
+	default:
+		_, err = s.lookupRepositoryOwnerWithRefresh(ctx, name)
+	}
+	return err == nil
+}
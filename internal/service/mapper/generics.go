@@ -69,7 +69,11 @@ func SetJiraIssue(dto interface{}, commitMessage string) {
 }
 
 func GetT[T Dtos](_ context.Context, s *Impl, resultPtr *T, fullPath string) error {
-	yamlBytes, commitInfo, err := s.Metadata.ReadFile(fullPath)
+	return getT[T](s.Metadata, resultPtr, fullPath)
+}
+
+func getT[T Dtos](tree metadataTree, resultPtr *T, fullPath string) error {
+	yamlBytes, commitInfo, err := tree.ReadFile(fullPath)
 	if err != nil {
 		return fmt.Errorf("failed to read %s from metadata: %s", fullPath, err.Error())
 	}
@@ -0,0 +1,124 @@
+package mapper
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+func (s *Impl) GetOwnerHistory(ctx context.Context, ownerAlias string, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error) {
+	fullPath := "owners/" + ownerAlias + "/owner.info.yaml"
+	return s.history(ctx, func(path string) bool {
+		return path == fullPath
+	}, stop)
+}
+
+func (s *Impl) GetServiceHistory(ctx context.Context, serviceName string, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error) {
+	return s.history(ctx, entityPathMatcher("services", serviceName), stop)
+}
+
+func (s *Impl) GetRepositoryHistory(ctx context.Context, repoKey string, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error) {
+	return s.history(ctx, entityPathMatcher("repositories", repoKey), stop)
+}
+
+// entityPathMatcher matches the file of an entity under any owner, so the history survives owner changes.
+func entityPathMatcher(kindDir string, name string) func(path string) bool {
+	fileName := name + ".yaml"
+	return func(path string) bool {
+		components := strings.Split(path, "/")
+		return len(components) == 4 && components[0] == "owners" && components[2] == kindDir && components[3] == fileName
+	}
+}
+
+func (s *Impl) history(ctx context.Context, matches func(path string) bool, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error) {
+	var stopAtChange func(change repository.FileChange) bool
+	if stop != nil {
+		stopAtChange = func(change repository.FileChange) bool {
+			return stop(change.CommitHash, timeStamp(change.TimeStamp))
+		}
+	}
+
+	changes, err := s.Metadata.FileHistory(ctx, matches, stopAtChange)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]openapi.HistoryEntryDto, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, historyEntry(change))
+	}
+	return result, nil
+}
+
+func historyEntry(change repository.FileChange) openapi.HistoryEntryDto {
+	entry := openapi.HistoryEntryDto{
+		CommitHash:    change.CommitHash,
+		TimeStamp:     timeStamp(change.TimeStamp),
+		Author:        change.Author,
+		JiraIssue:     jiraIssue(change.Message),
+		ChangedFields: changedFields(change.Before, change.After),
+	}
+
+	beforeOwner := ownerFromPath(change.BeforePath)
+	afterOwner := ownerFromPath(change.AfterPath)
+	switch {
+	case change.BeforePath == "":
+		entry.Change = ChangeCreated
+		entry.Owner = afterOwner
+	case change.AfterPath == "":
+		entry.Change = ChangeDeleted
+		entry.Owner = beforeOwner
+	default:
+		entry.Change = ChangeUpdated
+		entry.Owner = afterOwner
+		if beforeOwner != afterOwner {
+			entry.ChangedFields = append(entry.ChangedFields, "owner")
+			sort.Strings(entry.ChangedFields)
+		}
+	}
+	return entry
+}
+
+func ownerFromPath(path string) string {
+	components := strings.Split(path, "/")
+	if len(components) > 2 && components[0] == "owners" {
+		return components[1]
+	}
+	return ""
+}
+
+// changedFields compares the top level fields of two yaml documents, missing documents count as empty.
+//
+// Documents that fail to parse also count as empty, the history must still be shown for them.
+func changedFields(before []byte, after []byte) []string {
+	beforeFields := make(map[string]interface{})
+	afterFields := make(map[string]interface{})
+	_ = yaml.Unmarshal(before, &beforeFields)
+	_ = yaml.Unmarshal(after, &afterFields)
+
+	result := make([]string, 0)
+	for key, beforeValue := range beforeFields {
+		afterValue, ok := afterFields[key]
+		if !ok || !reflect.DeepEqual(beforeValue, afterValue) {
+			result = append(result, key)
+		}
+	}
+	for key := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			result = append(result, key)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -2,13 +2,22 @@ package mapper
 
 import (
 	"context"
+	"fmt"
 	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/nochangeserror"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
 	"github.com/Interhyp/metadata-service/internal/service/util"
+	"github.com/Interhyp/metadata-service/internal/types"
 	"sort"
+	"strings"
 )
 
 func (s *Impl) GetSortedOwnerAliases(_ context.Context) ([]string, error) {
-	fileInfos, err := s.Metadata.ReadDir("owners/")
+	return s.sortedOwnerAliases(s.Metadata)
+}
+
+func (s *Impl) sortedOwnerAliases(tree metadataTree) ([]string, error) {
+	fileInfos, err := tree.ReadDir("owners/")
 	if err != nil {
 		return []string{}, err
 	}
@@ -18,7 +27,7 @@ func (s *Impl) GetSortedOwnerAliases(_ context.Context) ([]string, error) {
 		alias := fileInfos[i].Name()
 		if fileInfos[i].IsDir() {
 			// check presence of owner.info.yaml to be sure
-			_, err := s.Metadata.Stat("owners/" + alias + "/owner.info.yaml")
+			_, err := tree.Stat("owners/" + alias + "/owner.info.yaml")
 			if err == nil {
 				if s.CustomConfiguration.OwnerFilterAliasRegex().MatchString(alias) {
 					result = append(result, alias)
@@ -32,10 +41,14 @@ func (s *Impl) GetSortedOwnerAliases(_ context.Context) ([]string, error) {
 }
 
 func (s *Impl) GetOwner(ctx context.Context, ownerAlias string) (openapi.OwnerDto, error) {
+	return s.readOwner(ctx, s.Metadata, ownerAlias)
+}
+
+func (s *Impl) readOwner(ctx context.Context, tree metadataTree, ownerAlias string) (openapi.OwnerDto, error) {
 	result := openapi.OwnerDto{}
 
 	fullPath := "owners/" + ownerAlias + "/owner.info.yaml"
-	err := GetT[openapi.OwnerDto](ctx, s, &result, fullPath)
+	err := getT[openapi.OwnerDto](tree, &result, fullPath)
 
 	if nil == err {
 		if result.Groups != nil {
@@ -89,6 +102,212 @@ func (s *Impl) DeleteOwner(ctx context.Context, ownerAlias string, jiraIssue str
 	return result, err
 }
 
+func (s *Impl) RenameOwner(ctx context.Context, oldAlias string, newAlias string, jiraIssue string) (openapi.OwnerDto, repository.EventAffects, error) {
+	err := s.Metadata.Pull(ctx)
+	if err != nil {
+		return openapi.OwnerDto{}, repository.EventAffects{}, err
+	}
+
+	filesChanged, err := s.renameOwnerFiles(oldAlias, newAlias)
+	if err != nil {
+		s.resetLocalClone(ctx)
+		return openapi.OwnerDto{}, repository.EventAffects{}, err
+	}
+
+	message := fmt.Sprintf("%s: rename owner %s to %s", jiraIssue, oldAlias, newAlias)
+	commitInfo, err := s.commitRename(ctx, func(redirects *types.Redirects) {
+		redirects.AddOwner(oldAlias, newAlias)
+	}, message)
+	if err != nil {
+		return openapi.OwnerDto{}, repository.EventAffects{}, err
+	}
+
+	owner, err := s.GetOwner(ctx, newAlias)
+	if err != nil {
+		return openapi.OwnerDto{}, repository.EventAffects{}, err
+	}
+	SetCommitHash(&owner, commitInfo.CommitHash)
+	SetTimeStamp(&owner, commitInfo.TimeStamp)
+	SetJiraIssue(&owner, commitInfo.Message)
+
+	return owner, affectedByFiles(filesChanged), nil
+}
+
+func (s *Impl) TransferOwnership(ctx context.Context, fromOwner string, toOwner string, serviceNames []string, repoKeys []string, jiraIssue string) (repository.CommitInfo, error) {
+	err := s.Metadata.Pull(ctx)
+	if err != nil {
+		return repository.CommitInfo{}, err
+	}
+
+	// rebuild the owner caches after pull
+	if _, err := s.GetSortedServiceNames(ctx); err != nil {
+		return repository.CommitInfo{}, err
+	}
+	if _, err := s.GetSortedRepositoryKeys(ctx); err != nil {
+		return repository.CommitInfo{}, err
+	}
+
+	for _, serviceName := range serviceNames {
+		ownerAlias, err := s.lookupServiceOwnerWithRefresh(ctx, serviceName)
+		if err != nil {
+			return repository.CommitInfo{}, err
+		}
+		if ownerAlias != fromOwner {
+			return repository.CommitInfo{}, fmt.Errorf("internal error - service %s does not belong to owner %s", serviceName, fromOwner)
+		}
+	}
+	for _, repoKey := range repoKeys {
+		ownerAlias, err := s.lookupRepositoryOwnerWithRefresh(ctx, repoKey)
+		if err != nil {
+			return repository.CommitInfo{}, err
+		}
+		if ownerAlias != fromOwner {
+			return repository.CommitInfo{}, fmt.Errorf("internal error - repository %s does not belong to owner %s", repoKey, fromOwner)
+		}
+	}
+
+	// move the files unchanged, the owner is not part of them
+
+	for _, serviceName := range serviceNames {
+		err = s.moveFile(
+			fmt.Sprintf("owners/%s/services/%s.yaml", fromOwner, serviceName),
+			fmt.Sprintf("owners/%s/services", toOwner),
+			serviceName+".yaml")
+		if err != nil {
+			s.resetLocalClone(ctx)
+			return repository.CommitInfo{}, err
+		}
+	}
+	for _, repoKey := range repoKeys {
+		err = s.moveFile(
+			fmt.Sprintf("owners/%s/repositories/%s.yaml", fromOwner, repoKey),
+			fmt.Sprintf("owners/%s/repositories", toOwner),
+			repoKey+".yaml")
+		if err != nil {
+			s.resetLocalClone(ctx)
+			return repository.CommitInfo{}, err
+		}
+	}
+
+	// commit and push
+
+	message := fmt.Sprintf("%s: transfer %d services and %d repositories from owner %s to owner %s", jiraIssue, len(serviceNames), len(repoKeys), fromOwner, toOwner)
+	commitInfo, err := s.Metadata.Commit(ctx, message)
+	if err != nil {
+		if !nochangeserror.Is(err) {
+			// empty commits need no re-clone
+			s.resetLocalClone(ctx)
+		}
+		return repository.CommitInfo{}, err
+	}
+
+	err = s.Metadata.Push(ctx)
+	if err != nil {
+		s.resetLocalClone(ctx)
+		return repository.CommitInfo{}, err
+	}
+
+	// rebuild the owner caches after the move
+	if _, err := s.GetSortedServiceNames(ctx); err != nil {
+		return repository.CommitInfo{}, err
+	}
+	if _, err := s.GetSortedRepositoryKeys(ctx); err != nil {
+		return repository.CommitInfo{}, err
+	}
+	return commitInfo, nil
+}
+
+// moveFile moves a file of the local copy to another directory without changing its contents.
+func (s *Impl) moveFile(oldFullPath string, newPath string, newFileNameNoPath string) error {
+	contents, _, err := s.Metadata.ReadFile(oldFullPath)
+	if err != nil {
+		return err
+	}
+
+	err = s.Metadata.DeleteFile(oldFullPath)
+	if err != nil {
+		return err
+	}
+
+	err = s.Metadata.MkdirAll(newPath)
+	if err != nil {
+		return err
+	}
+
+	return s.Metadata.WriteFile(newPath+"/"+newFileNameNoPath, contents)
+}
+
+// renameOwnerFiles moves all files of the owner to the new alias, and replaces "@old.group" references with
+// "@new.group" in the user lists of all files below owners/.
+//
+// Returns the paths of all files that were written or deleted.
+func (s *Impl) renameOwnerFiles(oldAlias string, newAlias string) ([]string, error) {
+	oldPrefix := "owners/" + oldAlias + "/"
+	paths, err := s.filesBelow("owners")
+	if err != nil {
+		return nil, err
+	}
+
+	filesChanged := make([]string, 0)
+	for _, path := range paths {
+		if !strings.HasSuffix(path, ".yaml") && !strings.HasPrefix(path, oldPrefix) {
+			continue
+		}
+
+		contents, _, err := s.Metadata.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		newContents := contents
+		if strings.HasSuffix(path, ".yaml") {
+			newContents = util.RenameGroupOwner(contents, oldAlias, newAlias)
+		}
+
+		if strings.HasPrefix(path, oldPrefix) {
+			newPath := "owners/" + newAlias + "/" + strings.TrimPrefix(path, oldPrefix)
+			if err := s.Metadata.DeleteFile(path); err != nil {
+				return nil, err
+			}
+			if err := s.Metadata.MkdirAll(newPath[:strings.LastIndex(newPath, "/")]); err != nil {
+				return nil, err
+			}
+			if err := s.Metadata.WriteFile(newPath, newContents); err != nil {
+				return nil, err
+			}
+			filesChanged = append(filesChanged, path, newPath)
+		} else if string(newContents) != string(contents) {
+			if err := s.Metadata.WriteFile(path, newContents); err != nil {
+				return nil, err
+			}
+			filesChanged = append(filesChanged, path)
+		}
+	}
+	return filesChanged, nil
+}
+
+// filesBelow lists the paths of all files below a directory of the local copy, recursively.
+func (s *Impl) filesBelow(path string) ([]string, error) {
+	fileInfos, err := s.Metadata.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0)
+	for _, fileInfo := range fileInfos {
+		fullPath := path + "/" + fileInfo.Name()
+		if fileInfo.IsDir() {
+			below, err := s.filesBelow(fullPath)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, below...)
+		} else {
+			result = append(result, fullPath)
+		}
+	}
+	return result, nil
+}
+
 func (s *Impl) IsOwnerEmpty(_ context.Context, ownerAlias string) bool {
 	s.muOwnerCaches.Lock()
 	defer s.muOwnerCaches.Unlock()
@@ -0,0 +1,96 @@
+package mapper
+
+import (
+	"context"
+	"fmt"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/nochangeserror"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/service/util"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"gopkg.in/yaml.v3"
+	"os"
+)
+
+func (s *Impl) GetRedirects(_ context.Context) (types.Redirects, error) {
+	return s.readRedirects()
+}
+
+func (s *Impl) readRedirects() (types.Redirects, error) {
+	result := types.Redirects{}
+
+	if _, err := s.Metadata.Stat(types.RedirectsPath); err != nil {
+		if os.IsNotExist(err) {
+			// nothing has been renamed
+			return result, nil
+		}
+		return result, err
+	}
+
+	yamlBytes, _, err := s.Metadata.ReadFile(types.RedirectsPath)
+	if err != nil {
+		return result, fmt.Errorf("failed to read %s from metadata: %s", types.RedirectsPath, err.Error())
+	}
+	if err := yaml.Unmarshal(yamlBytes, &result); err != nil {
+		return result, fmt.Errorf("failed to parse %s as yaml from metadata: %s", types.RedirectsPath, err.Error())
+	}
+	return result, nil
+}
+
+// writeRedirects writes the redirects file to the local copy, you still need to commit.
+func (s *Impl) writeRedirects(redirects types.Redirects) error {
+	yamlBytes, err := marshalYAML(redirects, s.CustomConfiguration.YamlIndentation())
+	if err != nil {
+		return err
+	}
+	return s.Metadata.WriteFile(types.RedirectsPath, yamlBytes)
+}
+
+// commitRename records the redirect for a rename whose files have already been changed in the local copy,
+// then commits and pushes everything.
+func (s *Impl) commitRename(ctx context.Context, addRedirect func(redirects *types.Redirects), message string) (repository.CommitInfo, error) {
+	redirects, err := s.readRedirects()
+	if err != nil {
+		s.resetLocalClone(ctx)
+		return repository.CommitInfo{}, err
+	}
+	addRedirect(&redirects)
+	err = s.writeRedirects(redirects)
+	if err != nil {
+		s.resetLocalClone(ctx)
+		return repository.CommitInfo{}, err
+	}
+
+	commitInfo, err := s.Metadata.Commit(ctx, message)
+	if err != nil {
+		if !nochangeserror.Is(err) {
+			// empty commits need no re-clone
+			s.resetLocalClone(ctx)
+		}
+		return repository.CommitInfo{}, err
+	}
+
+	err = s.Metadata.Push(ctx)
+	if err != nil {
+		s.resetLocalClone(ctx)
+		return repository.CommitInfo{}, err
+	}
+
+	// rebuild the owner caches after the move
+	if _, err := s.GetSortedServiceNames(ctx); err != nil {
+		return repository.CommitInfo{}, err
+	}
+	if _, err := s.GetSortedRepositoryKeys(ctx); err != nil {
+		return repository.CommitInfo{}, err
+	}
+	return commitInfo, nil
+}
+
+// affectedByFiles determines the entities whose files were written or deleted.
+func affectedByFiles(filesChanged []string) repository.EventAffects {
+	changes := repository.CommitInfo{FilesChanged: filesChanged}
+	return repository.EventAffects{
+		OwnerAliases:   util.RemoveDuplicateStr(ownerAliasesFromCommitInfo(changes)),
+		ServiceNames:   util.RemoveDuplicateStr(serviceNamesFromCommitInfo(changes)),
+		RepositoryKeys: util.RemoveDuplicateStr(repoKeysFromCommitInfo(changes)),
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/Interhyp/go-backend-service-common/api/apierrors"
 	"github.com/Interhyp/metadata-service/api"
 	"github.com/Interhyp/metadata-service/internal/acorn/errors/nochangeserror"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
 	"github.com/Interhyp/metadata-service/internal/service/util"
+	"github.com/Interhyp/metadata-service/internal/types"
 	internalutil "github.com/Interhyp/metadata-service/internal/util"
 	"sort"
 	"strings"
@@ -34,10 +36,18 @@ func (s *Impl) GetSortedRepositoryKeys(ctx context.Context) ([]string, error) {
 		return []string{}, err
 	}
 
+	result, newCache := s.sortedRepositoryKeys(s.Metadata, ownerAliases)
+
+	s.replaceRepositoryOwnerCache(newCache)
+	return result, nil
+}
+
+// sortedRepositoryKeys also returns the owner alias for each repository key.
+func (s *Impl) sortedRepositoryKeys(tree metadataTree, ownerAliases []string) ([]string, map[string]string) {
 	result := make([]string, 0)
-	newCache := make(map[string]string)
+	owners := make(map[string]string)
 	for _, ownerAlias := range ownerAliases {
-		fileInfos, err := s.Metadata.ReadDir(fmt.Sprintf("owners/%s/repositories", ownerAlias))
+		fileInfos, err := tree.ReadDir(fmt.Sprintf("owners/%s/repositories", ownerAlias))
 		if err == nil {
 			// acceptable to not have a repositories dir
 			for i := range fileInfos {
@@ -45,15 +55,14 @@ func (s *Impl) GetSortedRepositoryKeys(ctx context.Context) ([]string, error) {
 				if !fileInfos[i].IsDir() && strings.HasSuffix(repoKey, ".yaml") {
 					repoKey = repoKey[:len(repoKey)-len(".yaml")]
 					result = append(result, repoKey)
-					newCache[repoKey] = ownerAlias
+					owners[repoKey] = ownerAlias
 				}
 			}
 		}
 	}
 
-	s.replaceRepositoryOwnerCache(newCache)
 	sort.Strings(result)
-	return result, nil
+	return result, owners
 }
 
 func (s *Impl) lookupRepositoryOwnerWithRefresh(ctx context.Context, repoKey string) (string, error) {
@@ -79,8 +88,14 @@ func (s *Impl) GetRepository(ctx context.Context, repoKey string) (openapi.Repos
 		return result, err
 	}
 
+	return s.readRepository(ctx, s.Metadata, ownerAlias, repoKey)
+}
+
+func (s *Impl) readRepository(ctx context.Context, tree metadataTree, ownerAlias string, repoKey string) (openapi.RepositoryDto, error) {
+	result := openapi.RepositoryDto{}
+
 	fullPath := fmt.Sprintf("owners/%s/repositories/%s.yaml", ownerAlias, repoKey)
-	err = GetT[openapi.RepositoryDto](ctx, s, &result, fullPath)
+	err := getT[openapi.RepositoryDto](tree, &result, fullPath)
 
 	splitKey := strings.Split(repoKey, ".")
 	if len(splitKey) > 1 {
@@ -210,3 +225,84 @@ func (s *Impl) WriteRepositoryWithChangedOwner(ctx context.Context, repoKey stri
 
 	return repository, nil
 }
+
+func (s *Impl) RenameRepository(ctx context.Context, oldKey string, newKey string, jiraIssue string) (openapi.RepositoryDto, repository.EventAffects, error) {
+	err := s.Metadata.Pull(ctx)
+	if err != nil {
+		return openapi.RepositoryDto{}, repository.EventAffects{}, err
+	}
+
+	// rebuild the owner caches after pull
+	serviceNames, err := s.GetSortedServiceNames(ctx)
+	if err != nil {
+		return openapi.RepositoryDto{}, repository.EventAffects{}, err
+	}
+	_, err = s.GetSortedRepositoryKeys(ctx)
+	if err != nil {
+		return openapi.RepositoryDto{}, repository.EventAffects{}, err
+	}
+
+	ownerAlias, err := s.lookupRepositoryOwnerWithRefresh(ctx, oldKey)
+	if err != nil {
+		return openapi.RepositoryDto{}, repository.EventAffects{}, err
+	}
+
+	// move repository
+
+	oldFullPath := fmt.Sprintf("owners/%s/repositories/%s.yaml", ownerAlias, oldKey)
+	repo := openapi.RepositoryDto{}
+	err = GetT[openapi.RepositoryDto](ctx, s, &repo, oldFullPath)
+	if err != nil {
+		return openapi.RepositoryDto{}, repository.EventAffects{}, err
+	}
+	repo.PreviousNames = append(repo.PreviousNames, oldKey)
+
+	newPath := fmt.Sprintf("owners/%s/repositories", ownerAlias)
+	err = Move(ctx, s, repo, oldFullPath, newPath, newKey+".yaml")
+	if err != nil {
+		s.resetLocalClone(ctx)
+		return openapi.RepositoryDto{}, repository.EventAffects{}, err
+	}
+	filesChanged := []string{oldFullPath, newPath + "/" + newKey + ".yaml"}
+
+	// update references from services, which use the file representation of the key
+
+	newFileKey := transformKeys([]string{newKey}, ".", "/")[0]
+	for _, serviceName := range serviceNames {
+		path, changed, err := s.rewriteServiceFile(ctx, serviceName, func(service *openapi.ServiceDto) bool {
+			replaced := false
+			for i, repoKey := range transformKeys(service.Repositories, "/", ".") {
+				if repoKey == oldKey {
+					service.Repositories[i] = newFileKey
+					replaced = true
+				}
+			}
+			return replaced
+		})
+		if err != nil {
+			s.resetLocalClone(ctx)
+			return openapi.RepositoryDto{}, repository.EventAffects{}, err
+		}
+		if changed {
+			filesChanged = append(filesChanged, path)
+		}
+	}
+
+	message := fmt.Sprintf("%s: rename repository %s to %s", jiraIssue, oldKey, newKey)
+	commitInfo, err := s.commitRename(ctx, func(redirects *types.Redirects) {
+		redirects.AddRepository(oldKey, newKey)
+	}, message)
+	if err != nil {
+		return openapi.RepositoryDto{}, repository.EventAffects{}, err
+	}
+
+	result, err := s.GetRepository(ctx, newKey)
+	if err != nil {
+		return openapi.RepositoryDto{}, repository.EventAffects{}, err
+	}
+	SetCommitHash(&result, commitInfo.CommitHash)
+	SetTimeStamp(&result, commitInfo.TimeStamp)
+	SetJiraIssue(&result, commitInfo.Message)
+
+	return result, affectedByFiles(filesChanged), nil
+}
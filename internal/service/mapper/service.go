@@ -7,6 +7,8 @@ import (
 	"github.com/Interhyp/go-backend-service-common/api/apierrors"
 	"github.com/Interhyp/metadata-service/api"
 	"github.com/Interhyp/metadata-service/internal/acorn/errors/nochangeserror"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/types"
 	"sort"
 	"strings"
 )
@@ -32,10 +34,18 @@ func (s *Impl) GetSortedServiceNames(ctx context.Context) ([]string, error) {
 		return []string{}, err
 	}
 
+	result, newCache := s.sortedServiceNames(s.Metadata, ownerAliases)
+
+	s.replaceServiceOwnerCache(newCache)
+	return result, nil
+}
+
+// sortedServiceNames also returns the owner alias for each service name.
+func (s *Impl) sortedServiceNames(tree metadataTree, ownerAliases []string) ([]string, map[string]string) {
 	result := make([]string, 0)
-	newCache := make(map[string]string)
+	owners := make(map[string]string)
 	for _, ownerAlias := range ownerAliases {
-		fileInfos, err := s.Metadata.ReadDir(fmt.Sprintf("owners/%s/services", ownerAlias))
+		fileInfos, err := tree.ReadDir(fmt.Sprintf("owners/%s/services", ownerAlias))
 		if err == nil {
 			// acceptable to not have a services dir
 			for i := range fileInfos {
@@ -43,15 +53,14 @@ func (s *Impl) GetSortedServiceNames(ctx context.Context) ([]string, error) {
 				if !fileInfos[i].IsDir() && strings.HasSuffix(name, ".yaml") {
 					name = name[:len(name)-len(".yaml")]
 					result = append(result, name)
-					newCache[name] = ownerAlias
+					owners[name] = ownerAlias
 				}
 			}
 		}
 	}
 
-	s.replaceServiceOwnerCache(newCache)
 	sort.Strings(result)
-	return result, nil
+	return result, owners
 }
 
 func (s *Impl) lookupServiceOwnerWithRefresh(ctx context.Context, serviceName string) (string, error) {
@@ -90,8 +99,14 @@ func (s *Impl) GetService(ctx context.Context, serviceName string) (openapi.Serv
 		return result, err
 	}
 
+	return s.readService(ctx, s.Metadata, ownerAlias, serviceName)
+}
+
+func (s *Impl) readService(_ context.Context, tree metadataTree, ownerAlias string, serviceName string) (openapi.ServiceDto, error) {
+	result := openapi.ServiceDto{}
+
 	fullPath := fmt.Sprintf("owners/%s/services/%s.yaml", ownerAlias, serviceName)
-	err = GetT[openapi.ServiceDto](ctx, s, &result, fullPath)
+	err := getT[openapi.ServiceDto](tree, &result, fullPath)
 
 	result.Repositories = transformKeys(result.Repositories, "/", ".")
 	result.Owner = ownerAlias
@@ -237,3 +252,115 @@ func (s *Impl) WriteServiceWithChangedOwner(ctx context.Context, serviceName str
 
 	return service, nil
 }
+
+func (s *Impl) RenameService(ctx context.Context, oldName string, newName string, jiraIssue string) (openapi.ServiceDto, repository.EventAffects, error) {
+	err := s.Metadata.Pull(ctx)
+	if err != nil {
+		return openapi.ServiceDto{}, repository.EventAffects{}, err
+	}
+
+	// rebuild the owner cache after pull
+	serviceNames, err := s.GetSortedServiceNames(ctx)
+	if err != nil {
+		return openapi.ServiceDto{}, repository.EventAffects{}, err
+	}
+
+	ownerAlias, err := s.lookupServiceOwnerWithRefresh(ctx, oldName)
+	if err != nil {
+		return openapi.ServiceDto{}, repository.EventAffects{}, err
+	}
+
+	// move service, repository keys stay in their file representation
+
+	oldFullPath := fmt.Sprintf("owners/%s/services/%s.yaml", ownerAlias, oldName)
+	service := openapi.ServiceDto{}
+	err = GetT[openapi.ServiceDto](ctx, s, &service, oldFullPath)
+	if err != nil {
+		return openapi.ServiceDto{}, repository.EventAffects{}, err
+	}
+	service.PreviousNames = append(service.PreviousNames, oldName)
+
+	newPath := fmt.Sprintf("owners/%s/services", ownerAlias)
+	err = Move(ctx, s, service, oldFullPath, newPath, newName+".yaml")
+	if err != nil {
+		s.resetLocalClone(ctx)
+		return openapi.ServiceDto{}, repository.EventAffects{}, err
+	}
+	filesChanged := []string{oldFullPath, newPath + "/" + newName + ".yaml"}
+
+	// update dependencies of other services
+
+	for _, serviceName := range serviceNames {
+		if serviceName == oldName {
+			continue
+		}
+		path, changed, err := s.rewriteServiceFile(ctx, serviceName, func(service *openapi.ServiceDto) bool {
+			return service.Spec != nil && replaceAll(service.Spec.DependsOn, oldName, newName)
+		})
+		if err != nil {
+			s.resetLocalClone(ctx)
+			return openapi.ServiceDto{}, repository.EventAffects{}, err
+		}
+		if changed {
+			filesChanged = append(filesChanged, path)
+		}
+	}
+
+	message := fmt.Sprintf("%s: rename service %s to %s", jiraIssue, oldName, newName)
+	commitInfo, err := s.commitRename(ctx, func(redirects *types.Redirects) {
+		redirects.AddService(oldName, newName)
+	}, message)
+	if err != nil {
+		return openapi.ServiceDto{}, repository.EventAffects{}, err
+	}
+
+	result, err := s.GetService(ctx, newName)
+	if err != nil {
+		return openapi.ServiceDto{}, repository.EventAffects{}, err
+	}
+	SetCommitHash(&result, commitInfo.CommitHash)
+	SetTimeStamp(&result, commitInfo.TimeStamp)
+	SetJiraIssue(&result, commitInfo.Message)
+
+	return result, affectedByFiles(filesChanged), nil
+}
+
+// rewriteServiceFile applies a change to the file representation of a service in the local copy.
+//
+// The file is only written if change reports that it modified the service. Returns the path of the file
+// and whether it was written.
+func (s *Impl) rewriteServiceFile(ctx context.Context, serviceName string, change func(service *openapi.ServiceDto) bool) (string, bool, error) {
+	ownerAlias, err := s.lookupServiceOwnerWithRefresh(ctx, serviceName)
+	if err != nil {
+		return "", false, err
+	}
+
+	fullPath := fmt.Sprintf("owners/%s/services/%s.yaml", ownerAlias, serviceName)
+	service := openapi.ServiceDto{}
+	err = GetT[openapi.ServiceDto](ctx, s, &service, fullPath)
+	if err != nil {
+		return fullPath, false, err
+	}
+
+	if !change(&service) {
+		return fullPath, false, nil
+	}
+
+	yamlBytes, err := marshalYAML(service, s.CustomConfiguration.YamlIndentation())
+	if err != nil {
+		return fullPath, false, err
+	}
+	return fullPath, true, s.Metadata.WriteFile(fullPath, yamlBytes)
+}
+
+// replaceAll replaces every occurrence of a value in a slice, reporting whether there was any.
+func replaceAll(values []string, old string, new string) bool {
+	replaced := false
+	for i := range values {
+		if values[i] == old {
+			values[i] = new
+			replaced = true
+		}
+	}
+	return replaced
+}
@@ -0,0 +1,180 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+)
+
+// metadataTree is read access to one version of the metadata tree.
+//
+// Metadata itself provides the current working tree, commitTree provides the tree as of a past commit.
+type metadataTree interface {
+	Stat(filename string) (os.FileInfo, error)
+	ReadDir(path string) ([]os.FileInfo, error)
+	ReadFile(filename string) ([]byte, repository.CommitInfo, error)
+}
+
+type commitTree struct {
+	ctx        context.Context
+	metadata   repository.Metadata
+	commitHash string
+}
+
+func (t commitTree) Stat(filename string) (os.FileInfo, error) {
+	return t.metadata.StatAt(t.ctx, t.commitHash, filename)
+}
+
+func (t commitTree) ReadDir(path string) ([]os.FileInfo, error) {
+	return t.metadata.ReadDirAt(t.ctx, t.commitHash, path)
+}
+
+func (t commitTree) ReadFile(filename string) ([]byte, repository.CommitInfo, error) {
+	return t.metadata.ReadFileAt(t.ctx, t.commitHash, filename)
+}
+
+var commitHashPrefixRegex = regexp.MustCompile(`^[0-9a-fA-F]{4,40}$`)
+
+func (s *Impl) ResolveCommit(ctx context.Context, at string) (repository.CommitInfo, error) {
+	var commitInfo repository.CommitInfo
+	var err error
+	if atTime, parseErr := time.Parse(time.RFC3339, at); parseErr == nil {
+		commitInfo, err = s.Metadata.ResolveCommitTime(ctx, atTime)
+	} else if commitHashPrefixRegex.MatchString(at) {
+		commitInfo, err = s.Metadata.ResolveCommitHash(ctx, at)
+	} else {
+		return commitInfo, apierrors.NewBadRequestError("at.invalid", "at must be a (possibly abbreviated) commit hash of at least 4 characters or an RFC3339 timestamp", nil, s.Timestamp.Now())
+	}
+
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return commitInfo, apierrors.NewNotFoundError("at.notfound", fmt.Sprintf("no unique commit found on the mainline for %s", at), err, s.Timestamp.Now())
+		}
+		return commitInfo, err
+	}
+	return commitInfo, nil
+}
+
+func (s *Impl) HeadCommitHash(_ context.Context) string {
+	return s.Metadata.HeadCommitHash()
+}
+
+func (s *Impl) GetAllAt(ctx context.Context, commitHash string) (map[string]openapi.OwnerDto, map[string]openapi.ServiceDto, map[string]openapi.RepositoryDto, error) {
+	tree := commitTree{ctx: ctx, metadata: s.Metadata, commitHash: commitHash}
+
+	owners := make(map[string]openapi.OwnerDto)
+	services := make(map[string]openapi.ServiceDto)
+	repositories := make(map[string]openapi.RepositoryDto)
+
+	ownerAliases, err := s.sortedOwnerAliases(tree)
+	if err != nil {
+		return owners, services, repositories, err
+	}
+
+	// entries that fail to parse are skipped, past commits may well contain files that were invalid at the time
+
+	for _, ownerAlias := range ownerAliases {
+		owner, err := s.readOwner(ctx, tree, ownerAlias)
+		if err != nil {
+			s.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("skipping owner %s at commit %s", ownerAlias, commitHash)
+			continue
+		}
+		owners[ownerAlias] = owner
+	}
+
+	serviceNames, serviceOwners := s.sortedServiceNames(tree, ownerAliases)
+	for _, serviceName := range serviceNames {
+		service, err := s.readService(ctx, tree, serviceOwners[serviceName], serviceName)
+		if err != nil {
+			s.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("skipping service %s at commit %s", serviceName, commitHash)
+			continue
+		}
+		services[serviceName] = service
+	}
+
+	repoKeys, repositoryOwners := s.sortedRepositoryKeys(tree, ownerAliases)
+	for _, repoKey := range repoKeys {
+		repository, err := s.readRepository(ctx, tree, repositoryOwners[repoKey], repoKey)
+		if err != nil {
+			s.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("skipping repository %s at commit %s", repoKey, commitHash)
+			continue
+		}
+		repositories[repoKey] = repository
+	}
+
+	return owners, services, repositories, nil
+}
+
+func (s *Impl) GetOwnerAt(ctx context.Context, commitHash string, ownerAlias string) (openapi.OwnerDto, error) {
+	tree := commitTree{ctx: ctx, metadata: s.Metadata, commitHash: commitHash}
+
+	ownerAliases, err := s.sortedOwnerAliases(tree)
+	if err != nil {
+		return openapi.OwnerDto{}, err
+	}
+	if !slices.Contains(ownerAliases, ownerAlias) {
+		return openapi.OwnerDto{}, s.notFoundAt("owner", ownerAlias)
+	}
+
+	owner, err := s.readOwner(ctx, tree, ownerAlias)
+	if err != nil {
+		s.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("skipping owner %s at commit %s", ownerAlias, commitHash)
+		return openapi.OwnerDto{}, s.notFoundAt("owner", ownerAlias)
+	}
+	return owner, nil
+}
+
+func (s *Impl) GetServiceAt(ctx context.Context, commitHash string, serviceName string) (openapi.ServiceDto, error) {
+	tree := commitTree{ctx: ctx, metadata: s.Metadata, commitHash: commitHash}
+
+	ownerAliases, err := s.sortedOwnerAliases(tree)
+	if err != nil {
+		return openapi.ServiceDto{}, err
+	}
+	_, serviceOwners := s.sortedServiceNames(tree, ownerAliases)
+	ownerAlias, ok := serviceOwners[serviceName]
+	if !ok {
+		return openapi.ServiceDto{}, s.notFoundAt("service", serviceName)
+	}
+
+	service, err := s.readService(ctx, tree, ownerAlias, serviceName)
+	if err != nil {
+		s.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("skipping service %s at commit %s", serviceName, commitHash)
+		return openapi.ServiceDto{}, s.notFoundAt("service", serviceName)
+	}
+	return service, nil
+}
+
+func (s *Impl) GetRepositoryAt(ctx context.Context, commitHash string, repoKey string) (openapi.RepositoryDto, error) {
+	tree := commitTree{ctx: ctx, metadata: s.Metadata, commitHash: commitHash}
+
+	ownerAliases, err := s.sortedOwnerAliases(tree)
+	if err != nil {
+		return openapi.RepositoryDto{}, err
+	}
+	_, repositoryOwners := s.sortedRepositoryKeys(tree, ownerAliases)
+	ownerAlias, ok := repositoryOwners[repoKey]
+	if !ok {
+		return openapi.RepositoryDto{}, s.notFoundAt("repository", repoKey)
+	}
+
+	repository, err := s.readRepository(ctx, tree, ownerAlias, repoKey)
+	if err != nil {
+		s.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("skipping repository %s at commit %s", repoKey, commitHash)
+		return openapi.RepositoryDto{}, s.notFoundAt("repository", repoKey)
+	}
+	return repository, nil
+}
+
+// notFoundAt is the same error a snapshot of the commit gives for a missing entry.
+func (s *Impl) notFoundAt(what string, key string) error {
+	return apierrors.NewNotFoundError(what+".notfound", fmt.Sprintf("%s %s not found", what, key), nil, s.Timestamp.Now())
+}
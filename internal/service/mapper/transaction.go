@@ -0,0 +1,135 @@
+package mapper
+
+import (
+	"context"
+	"fmt"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/nochangeserror"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"sort"
+)
+
+func (s *Impl) WriteTransaction(ctx context.Context, changes service.TransactionChanges, description string, jiraIssue string) (repository.CommitInfo, error) {
+	err := s.Metadata.Pull(ctx)
+	if err != nil {
+		return repository.CommitInfo{}, err
+	}
+
+	// rebuild the owner caches after pull
+	if _, err := s.GetSortedServiceNames(ctx); err != nil {
+		return repository.CommitInfo{}, err
+	}
+	if _, err := s.GetSortedRepositoryKeys(ctx); err != nil {
+		return repository.CommitInfo{}, err
+	}
+
+	if err := s.writeTransactionFiles(ctx, changes); err != nil {
+		s.resetLocalClone(ctx)
+		return repository.CommitInfo{}, err
+	}
+
+	// commit and push
+
+	message := fmt.Sprintf("%s: %s", jiraIssue, description)
+	commitInfo, err := s.Metadata.Commit(ctx, message)
+	if err != nil {
+		if !nochangeserror.Is(err) {
+			// empty commits need no re-clone
+			s.resetLocalClone(ctx)
+		}
+		return repository.CommitInfo{}, err
+	}
+
+	err = s.Metadata.Push(ctx)
+	if err != nil {
+		s.resetLocalClone(ctx)
+		return repository.CommitInfo{}, err
+	}
+
+	// rebuild the owner caches after the changes
+	if _, err := s.GetSortedServiceNames(ctx); err != nil {
+		return repository.CommitInfo{}, err
+	}
+	if _, err := s.GetSortedRepositoryKeys(ctx); err != nil {
+		return repository.CommitInfo{}, err
+	}
+	return commitInfo, nil
+}
+
+// writeTransactionFiles writes, moves and deletes the files of the local copy without committing.
+//
+// Owners are written first and deleted last, so services and repositories never end up below a deleted owner.
+func (s *Impl) writeTransactionFiles(ctx context.Context, changes service.TransactionChanges) error {
+	for _, ownerAlias := range sortedKeys(changes.Owners) {
+		if owner := changes.Owners[ownerAlias]; owner != nil {
+			if err := s.writeYAML("owners/"+ownerAlias, "owner.info.yaml", *owner); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, serviceName := range sortedKeys(changes.Services) {
+		theService := changes.Services[serviceName]
+		currentOwner, err := s.lookupServiceOwnerWithRefresh(ctx, serviceName)
+		if err == nil && (theService == nil || theService.Owner != currentOwner) {
+			if err := s.Metadata.DeleteFile(fmt.Sprintf("owners/%s/services/%s.yaml", currentOwner, serviceName)); err != nil {
+				return err
+			}
+		}
+		if theService != nil {
+			toWrite := *theService
+			toWrite.Repositories = transformKeys(toWrite.Repositories, ".", "/")
+			if err := s.writeYAML(fmt.Sprintf("owners/%s/services", toWrite.Owner), serviceName+".yaml", toWrite); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, repoKey := range sortedKeys(changes.Repositories) {
+		theRepository := changes.Repositories[repoKey]
+		currentOwner, err := s.lookupRepositoryOwnerWithRefresh(ctx, repoKey)
+		if err == nil && (theRepository == nil || theRepository.Owner != currentOwner) {
+			if err := s.Metadata.DeleteFile(fmt.Sprintf("owners/%s/repositories/%s.yaml", currentOwner, repoKey)); err != nil {
+				return err
+			}
+		}
+		if theRepository != nil {
+			if err := s.writeYAML(fmt.Sprintf("owners/%s/repositories", theRepository.Owner), repoKey+".yaml", *theRepository); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ownerAlias := range sortedKeys(changes.Owners) {
+		if changes.Owners[ownerAlias] == nil {
+			if err := s.Metadata.DeleteFile("owners/" + ownerAlias + "/owner.info.yaml"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeYAML writes v to a file of the local copy without committing.
+func (s *Impl) writeYAML(path string, fileNameNoPath string, v interface{}) error {
+	yamlBytes, err := marshalYAML(v, s.CustomConfiguration.YamlIndentation())
+	if err != nil {
+		return err
+	}
+
+	err = s.Metadata.MkdirAll(path)
+	if err != nil {
+		return err
+	}
+
+	return s.Metadata.WriteFile(path+"/"+fileNameNoPath, yamlBytes)
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -2,11 +2,16 @@ package owners
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/Interhyp/metadata-service/api"
 	"github.com/Interhyp/metadata-service/internal/acorn/repository"
 	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/service/util"
+	"github.com/Interhyp/metadata-service/internal/types"
 	auzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
+	"os"
+	"sort"
 	"strings"
 
 	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
@@ -50,32 +55,63 @@ func (s *Impl) Setup() error {
 	return nil
 }
 
-func (s *Impl) GetOwners(ctx context.Context) (openapi.OwnerListDto, error) {
+// ownerSortFields are the fields the owner list can be sorted by, the first one is the default.
+var ownerSortFields = []string{util.SortByName, util.SortByTimeStamp}
+
+func (s *Impl) GetOwners(ctx context.Context, at string, page types.PageRequest) (openapi.OwnerListDto, error) {
 	result := openapi.OwnerListDto{
 		Owners: make(map[string]openapi.OwnerDto),
 	}
 
-	stamp, err := s.Cache.GetOwnerListTimestamp(ctx)
+	pager, err := util.NewPager(page, ownerSortFields, s.Timestamp.Now())
+	if err != nil {
+		return result, err
+	}
+
+	source, commitHash, err := s.Updater.ListSource(ctx, at, pager.Snapshot())
+	if err != nil {
+		return result, err
+	}
+
+	stamp, err := source.GetOwnerListTimestamp(ctx)
 	if err != nil {
 		return result, err
 	}
 	result.TimeStamp = stamp
+	pager.ListedAt(stamp, commitHash)
 
-	names, err := s.Cache.GetSortedOwnerAliases(ctx)
+	names, err := source.GetSortedOwnerAliases(ctx)
 	if err != nil {
 		return result, err
 	}
-	for _, name := range names {
-		owner, err := s.GetOwner(ctx, name)
+	for _, name := range pager.Keys(names) {
+		if pager.Full() {
+			break
+		}
+		owner, err := source.GetOwner(ctx, name)
 		if err != nil {
 			// owner not found errors are ok, the cache may have been changed concurrently, just drop the entry
 			if !apierrors.IsNotFoundError(err) {
 				return openapi.OwnerListDto{}, err
 			}
 		} else {
-			result.Owners[name] = owner
+			if pager.Keeps() {
+				result.Owners[name] = owner
+			}
+			pager.Add(name, owner.TimeStamp)
 		}
 	}
+
+	order, nextCursor := pager.Page()
+	result.Owners, order, err = util.PageEntries(result.Owners, order, func(name string) (openapi.OwnerDto, error) {
+		return source.GetOwner(ctx, name)
+	})
+	if err != nil {
+		return openapi.OwnerListDto{}, err
+	}
+	if page.IsActive() {
+		result.Order, result.NextCursor = order, nextCursor
+	}
 	return result, nil
 }
 
@@ -83,6 +119,68 @@ func (s *Impl) GetOwner(ctx context.Context, ownerAlias string) (openapi.OwnerDt
 	return s.Cache.GetOwner(ctx, ownerAlias)
 }
 
+func (s *Impl) GetOwnerAt(ctx context.Context, ownerAlias string, at string) (openapi.OwnerDto, error) {
+	if at == "" {
+		return s.Cache.GetOwner(ctx, ownerAlias)
+	}
+	return s.Updater.GetOwnerAt(ctx, at, ownerAlias)
+}
+
+func (s *Impl) GetOwnerHistory(ctx context.Context, ownerAlias string, page types.PageRequest) (openapi.HistoryDto, error) {
+	stop, err := util.HistoryStop(page, s.Timestamp.Now())
+	if err != nil {
+		return openapi.HistoryDto{}, err
+	}
+	entries, err := s.Updater.GetOwnerHistory(ctx, ownerAlias, stop)
+	if err != nil {
+		return openapi.HistoryDto{}, err
+	}
+	if len(entries) == 0 {
+		return openapi.HistoryDto{}, apierrors.NewNotFoundError("owner.notfound", fmt.Sprintf("owner %s not found", ownerAlias), nil, s.Timestamp.Now())
+	}
+	return util.PageHistory(entries, page, s.Timestamp.Now())
+}
+
+func (s *Impl) GetOwnerDiff(ctx context.Context, ownerAlias string, from string, to string) (openapi.DiffDto, error) {
+	if from == "" {
+		return openapi.DiffDto{}, apierrors.NewBadRequestError("from.missing", "from is required, a (possibly abbreviated) commit hash or an RFC3339 timestamp", nil, s.Timestamp.Now())
+	}
+
+	before, err := s.ownerOrNil(ctx, ownerAlias, from)
+	if err != nil {
+		return openapi.DiffDto{}, err
+	}
+	after, err := s.ownerOrNil(ctx, ownerAlias, to)
+	if err != nil {
+		return openapi.DiffDto{}, err
+	}
+	if before == nil && after == nil {
+		return openapi.DiffDto{}, apierrors.NewNotFoundError("owner.notfound", fmt.Sprintf("owner %s not found", ownerAlias), nil, s.Timestamp.Now())
+	}
+
+	result := openapi.DiffDto{}
+	if before != nil {
+		result.FromCommitHash = before.CommitHash
+	}
+	if after != nil {
+		result.ToCommitHash = after.CommitHash
+	}
+	result.Operations, err = util.Diff(before, after)
+	return result, err
+}
+
+// ownerOrNil reads the owner as of at, or currently if at is empty. nil means it did not exist.
+func (s *Impl) ownerOrNil(ctx context.Context, ownerAlias string, at string) (*openapi.OwnerDto, error) {
+	owner, err := s.GetOwnerAt(ctx, ownerAlias, at)
+	if err != nil {
+		if apierrors.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &owner, nil
+}
+
 func (s *Impl) GetAllGroupMembers(ctx context.Context, groupOwner string, groupName string) []string {
 	allGroups := make(map[string][]string, 0)
 	// iterate over cache directly
@@ -96,6 +194,77 @@ func (s *Impl) GetAllGroupMembers(ctx context.Context, groupOwner string, groupN
 	return allGroups[groupName]
 }
 
+func (s *Impl) ExpandUserGroups(ctx context.Context, userList []string) []string {
+	result := make([]string, 0)
+	for _, user := range userList {
+		isGroup, groupOwner, groupName := util.ParseGroupOwnerAndGroupName(user)
+		if isGroup {
+			result = append(result, s.GetAllGroupMembers(ctx, groupOwner, groupName)...)
+		} else {
+			result = append(result, user)
+		}
+	}
+	return util.RemoveDuplicateStr(result)
+}
+
+func (s *Impl) GetBrokenGroupReferences(ctx context.Context) (openapi.BrokenGroupReferenceListDto, error) {
+	result := openapi.BrokenGroupReferenceListDto{
+		References: make([]openapi.BrokenGroupReferenceDto, 0),
+	}
+	groupsOf := util.CachedGroupsOf(ctx, s.Cache)
+
+	aliases, err := s.Cache.GetSortedOwnerAliases(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, alias := range aliases {
+		owner, err := s.Cache.GetOwner(ctx, alias)
+		if err != nil {
+			// owner not found errors are ok, the cache may have been changed concurrently, just drop the entry
+			if apierrors.IsNotFoundError(err) {
+				continue
+			}
+			return result, err
+		}
+		result.References = appendBrokenGroupReferences(result.References, types.GroupReferenceInOwner, alias, util.OwnerUserLists(owner), groupsOf)
+	}
+
+	keys, err := s.Cache.GetSortedRepositoryKeys(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, key := range keys {
+		repo, err := s.Cache.GetRepository(ctx, key)
+		if err != nil {
+			// repository not found errors are ok, the cache may have been changed concurrently, just drop the entry
+			if apierrors.IsNotFoundError(err) {
+				continue
+			}
+			return result, err
+		}
+		result.References = appendBrokenGroupReferences(result.References, types.GroupReferenceInRepository, key, util.RepositoryUserLists(repo), groupsOf)
+	}
+
+	return result, nil
+}
+
+func appendBrokenGroupReferences(references []openapi.BrokenGroupReferenceDto, kind string, name string, userLists map[string][]string, groupsOf util.GroupsOf) []openapi.BrokenGroupReferenceDto {
+	for _, field := range util.SortedUserListFields(userLists) {
+		for _, userOrGroup := range userLists[field] {
+			if problem := util.GroupReferenceProblem(userOrGroup, groupsOf); problem != "" {
+				references = append(references, openapi.BrokenGroupReferenceDto{
+					Kind:      kind,
+					Name:      name,
+					Field:     field,
+					Reference: userOrGroup,
+					Problem:   problem,
+				})
+			}
+		}
+	}
+	return references
+}
+
 func (s *Impl) CreateOwner(ctx context.Context, ownerAlias string, ownerCreateDto openapi.OwnerCreateDto) (openapi.OwnerDto, error) {
 	ownerDto := s.mapOwnerCreateDtoToOwnerDto(ownerCreateDto)
 	if err := s.validateOwnerCreateDto(ctx, ownerCreateDto); err != nil {
@@ -116,6 +285,10 @@ func (s *Impl) CreateOwner(ctx context.Context, ownerAlias string, ownerCreateDt
 			return apierrors.NewConflictErrorWithResponse("owner.conflict.alreadyexists", fmt.Sprintf("owner %s already exists - cannot create", ownerAlias), nil, result, s.Timestamp.Now())
 		}
 
+		if err := s.validateGroupReferences(subCtx, ownerAlias, openapi.OwnerDto{}, ownerDto); err != nil {
+			return err
+		}
+
 		ownerWritten, err := s.Updater.WriteOwner(subCtx, ownerAlias, ownerDto)
 		if err != nil {
 			return err
@@ -183,6 +356,10 @@ func (s *Impl) UpdateOwner(ctx context.Context, ownerAlias string, ownerDto open
 			return apierrors.NewConflictErrorWithResponse("owner.conflict.concurrentlyupdated", fmt.Sprintf("owner %v was concurrently updated", ownerAlias), nil, result, s.Timestamp.Now())
 		}
 
+		if err := s.validateGroupReferences(subCtx, ownerAlias, current, ownerDto); err != nil {
+			return err
+		}
+
 		ownerWritten, err := s.Updater.WriteOwner(subCtx, ownerAlias, ownerDto)
 		if err != nil {
 			return err
@@ -240,6 +417,9 @@ func (s *Impl) PatchOwner(ctx context.Context, ownerAlias string, ownerPatchDto
 		}
 
 		ownerDto := patchOwner(current, ownerPatchDto)
+		if err := s.validateGroupReferences(subCtx, ownerAlias, current, ownerDto); err != nil {
+			return err
+		}
 
 		ownerWritten, err := s.Updater.WriteOwner(subCtx, ownerAlias, ownerDto)
 		if err != nil {
@@ -252,6 +432,26 @@ func (s *Impl) PatchOwner(ctx context.Context, ownerAlias string, ownerPatchDto
 	return result, err
 }
 
+func (s *Impl) PatchOwnerDocument(ctx context.Context, ownerAlias string, patch types.PatchDocument) (openapi.OwnerDto, error) {
+	current, err := s.Cache.GetOwner(ctx, ownerAlias)
+	if err != nil {
+		return openapi.OwnerDto{}, err
+	}
+
+	// the patch must supply these, so concurrent updates are detected and the commit gets a jira issue, just like a PUT
+	current.CommitHash = ""
+	current.TimeStamp = ""
+	current.JiraIssue = ""
+
+	patched := openapi.OwnerDto{}
+	if err := util.ApplyPatch(current, patch, &patched, s.Timestamp.Now()); err != nil {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("patch of owner %s failed: %s", ownerAlias, err.Error())
+		return openapi.OwnerDto{}, err
+	}
+
+	return s.UpdateOwner(ctx, ownerAlias, patched)
+}
+
 func (s *Impl) validateOwnerPatchDto(ctx context.Context, ownerPatchDto openapi.OwnerPatchDto) error {
 	messages := make([]string, 0)
 	if ownerPatchDto.Contact != nil && *ownerPatchDto.Contact == "" {
@@ -376,6 +576,366 @@ func (s *Impl) DeleteOwner(ctx context.Context, ownerAlias string, deletionInfo
 	})
 }
 
+func (s *Impl) RenameOwner(ctx context.Context, ownerAlias string, renameDto openapi.OwnerRenameDto) (openapi.OwnerDto, error) {
+	if err := s.validateOwnerRenameDto(ctx, ownerAlias, renameDto); err != nil {
+		return openapi.OwnerDto{}, err
+	}
+
+	var result openapi.OwnerDto
+	err := s.Updater.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		err := s.Updater.PerformFullUpdate(subCtx)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.Cache.GetOwner(subCtx, ownerAlias)
+		if err != nil {
+			return err
+		}
+
+		existing, err := s.Cache.GetOwner(subCtx, renameDto.NewAlias)
+		if err == nil {
+			s.Logging.Logger().Ctx(ctx).Info().Printf("tried to rename owner %v to existing owner %v", ownerAlias, renameDto.NewAlias)
+			return apierrors.NewConflictErrorWithResponse("owner.conflict.alreadyexists", fmt.Sprintf("owner %s already exists - cannot rename", renameDto.NewAlias), nil, existing, s.Timestamp.Now())
+		}
+
+		result, err = s.Updater.RenameOwner(subCtx, ownerAlias, renameDto.NewAlias, renameDto.JiraIssue)
+		return err
+	})
+	return result, err
+}
+
+func (s *Impl) validateOwnerRenameDto(ctx context.Context, ownerAlias string, dto openapi.OwnerRenameDto) error {
+	messages := make([]string, 0)
+	if dto.NewAlias == "" {
+		messages = append(messages, "field newAlias is mandatory")
+	} else if dto.NewAlias == ownerAlias {
+		messages = append(messages, "field newAlias must differ from the current alias")
+	}
+	if dto.JiraIssue == "" {
+		messages = append(messages, "field jiraIssue is mandatory")
+	}
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("owner rename values invalid: %s", details)
+		return apierrors.NewBadRequestError("owner.invalid.values", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+func (s *Impl) TransferOwnership(ctx context.Context, ownerAlias string, transferDto openapi.OwnerTransferDto) (openapi.OwnerTransferResultDto, error) {
+	if err := s.validateOwnerTransferDto(ctx, ownerAlias, transferDto); err != nil {
+		return openapi.OwnerTransferResultDto{}, err
+	}
+
+	var result openapi.OwnerTransferResultDto
+	err := s.Updater.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		err := s.Updater.PerformFullUpdate(subCtx)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.Cache.GetOwner(subCtx, ownerAlias)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.Cache.GetOwner(subCtx, transferDto.TargetOwner)
+		if err != nil {
+			details := fmt.Sprintf("no such owner: %s", transferDto.TargetOwner)
+			s.Logging.Logger().Ctx(ctx).Info().Printf(details)
+			return apierrors.NewBadRequestError("owner.invalid.missing.owner", details, err, s.Timestamp.Now())
+		}
+
+		serviceNames, repoKeys, err := s.selectForTransfer(subCtx, ownerAlias, transferDto)
+		if err != nil {
+			return err
+		}
+
+		result, err = s.Updater.TransferOwnership(subCtx, ownerAlias, transferDto.TargetOwner, serviceNames, repoKeys, transferDto.JiraIssue)
+		return err
+	})
+	return result, err
+}
+
+func (s *Impl) validateOwnerTransferDto(ctx context.Context, ownerAlias string, dto openapi.OwnerTransferDto) error {
+	messages := make([]string, 0)
+	if dto.TargetOwner == "" {
+		messages = append(messages, "field targetOwner is mandatory")
+	} else if dto.TargetOwner == ownerAlias {
+		messages = append(messages, "field targetOwner must differ from the current owner")
+	}
+	if dto.All && (len(dto.Services) > 0 || len(dto.Repositories) > 0) {
+		messages = append(messages, "field all cannot be combined with services or repositories")
+	} else if !dto.All && len(dto.Services) == 0 && len(dto.Repositories) == 0 {
+		messages = append(messages, "one of the fields all, services or repositories is mandatory")
+	}
+	if dto.JiraIssue == "" {
+		messages = append(messages, "field jiraIssue is mandatory")
+	}
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("owner transfer values invalid: %s", details)
+		return apierrors.NewBadRequestError("owner.invalid.values", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+// selectForTransfer determines the sorted service names and repository keys to transfer.
+//
+// Services take the repositories of the owner they reference along, just like when the owner of a single service
+// is changed. Any other repository must not be referenced by a service, see Updater.CanMoveOrDeleteRepository.
+//
+// Expects a current cache and you must be holding the lock.
+func (s *Impl) selectForTransfer(ctx context.Context, ownerAlias string, dto openapi.OwnerTransferDto) ([]string, []string, error) {
+	selectedServices := dto.Services
+	selectedRepositories := dto.Repositories
+	if dto.All {
+		allServices, err := s.Cache.GetSortedServiceNames(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, name := range allServices {
+			if theService, err := s.Cache.GetService(ctx, name); err == nil && theService.Owner == ownerAlias {
+				selectedServices = append(selectedServices, name)
+			}
+		}
+		allRepositories, err := s.Cache.GetSortedRepositoryKeys(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, key := range allRepositories {
+			if theRepository, err := s.Cache.GetRepository(ctx, key); err == nil && theRepository.Owner == ownerAlias {
+				selectedRepositories = append(selectedRepositories, key)
+			}
+		}
+	}
+
+	messages := make([]string, 0)
+	serviceNames := make([]string, 0)
+	takenAlong := make(map[string]bool)
+	for _, name := range util.RemoveDuplicateStr(selectedServices) {
+		theService, err := s.Cache.GetService(ctx, name)
+		if err != nil || theService.Owner != ownerAlias {
+			messages = append(messages, fmt.Sprintf("service %s does not belong to owner %s", name, ownerAlias))
+			continue
+		}
+		serviceNames = append(serviceNames, name)
+		for _, key := range theService.Repositories {
+			if theRepository, err := s.Cache.GetRepository(ctx, key); err == nil && theRepository.Owner == ownerAlias {
+				takenAlong[key] = true
+			}
+		}
+	}
+
+	repoKeys := make([]string, 0)
+	referenced := make([]string, 0)
+	for _, key := range util.RemoveDuplicateStr(selectedRepositories) {
+		if takenAlong[key] {
+			continue
+		}
+		theRepository, err := s.Cache.GetRepository(ctx, key)
+		if err != nil || theRepository.Owner != ownerAlias {
+			messages = append(messages, fmt.Sprintf("repository %s does not belong to owner %s", key, ownerAlias))
+			continue
+		}
+		allowed, err := s.Updater.CanMoveOrDeleteRepository(ctx, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !allowed {
+			referenced = append(referenced, key)
+			continue
+		}
+		repoKeys = append(repoKeys, key)
+	}
+
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("owner transfer values invalid: %s", details)
+		return nil, nil, apierrors.NewBadRequestError("owner.invalid.values", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	if len(referenced) > 0 {
+		details := fmt.Sprintf("still referenced by services that are not being transferred: %s", strings.Join(referenced, ", "))
+		s.Logging.Logger().Ctx(ctx).Info().Printf("tried to transfer repositories %s", details)
+		return nil, nil, apierrors.NewConflictError("owner.conflict.referenced", details, nil, s.Timestamp.Now())
+	}
+
+	for key := range takenAlong {
+		repoKeys = append(repoKeys, key)
+	}
+	if len(serviceNames) == 0 && len(repoKeys) == 0 {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("owner %s has nothing to transfer", ownerAlias)
+		return nil, nil, apierrors.NewBadRequestError("owner.invalid.values", fmt.Sprintf("validation error: owner %s has no services or repositories to transfer", ownerAlias), nil, s.Timestamp.Now())
+	}
+
+	sort.Strings(serviceNames)
+	sort.Strings(repoKeys)
+	return serviceNames, repoKeys, nil
+}
+
+func (s *Impl) ApplyTransactionOperation(ctx context.Context, state *service.TransactionState, operation openapi.TransactionOperationDto) error {
+	ownerAlias := operation.Name
+	current, exists := state.Owners[ownerAlias]
+
+	switch operation.Operation {
+	case types.TransactionCreate:
+		ownerCreateDto := openapi.OwnerCreateDto{}
+		if err := util.DecodeTransactionBody(operation.Body, state.JiraIssue, &ownerCreateDto, s.Timestamp.Now()); err != nil {
+			return err
+		}
+		if err := s.validateOwnerCreateDto(ctx, ownerCreateDto); err != nil {
+			return err
+		}
+		if exists {
+			s.Logging.Logger().Ctx(ctx).Info().Printf("owner %v already exists", ownerAlias)
+			return apierrors.NewConflictError("owner.conflict.alreadyexists", fmt.Sprintf("owner %s already exists - cannot create", ownerAlias), nil, s.Timestamp.Now())
+		}
+		state.Owners[ownerAlias] = s.mapOwnerCreateDtoToOwnerDto(ownerCreateDto)
+	case types.TransactionUpdate:
+		ownerDto := openapi.OwnerDto{}
+		if err := util.DecodeTransactionBody(operation.Body, state.JiraIssue, &ownerDto, s.Timestamp.Now()); err != nil {
+			return err
+		}
+		if err := s.validateExistingOwnerDto(ctx, ownerDto); err != nil {
+			return err
+		}
+		if err := s.checkTransactionOwner(ctx, ownerAlias, exists, current, ownerDto.TimeStamp, ownerDto.CommitHash); err != nil {
+			return err
+		}
+		state.Owners[ownerAlias] = ownerDto
+	case types.TransactionPatch:
+		ownerPatchDto := openapi.OwnerPatchDto{}
+		if err := util.DecodeTransactionBody(operation.Body, state.JiraIssue, &ownerPatchDto, s.Timestamp.Now()); err != nil {
+			return err
+		}
+		if err := s.validateOwnerPatchDto(ctx, ownerPatchDto); err != nil {
+			return err
+		}
+		if err := s.checkTransactionOwner(ctx, ownerAlias, exists, current, ownerPatchDto.TimeStamp, ownerPatchDto.CommitHash); err != nil {
+			return err
+		}
+		state.Owners[ownerAlias] = patchOwner(current, ownerPatchDto)
+	case types.TransactionDelete:
+		if !exists {
+			s.Logging.Logger().Ctx(ctx).Info().Printf("owner %v not found", ownerAlias)
+			return apierrors.NewNotFoundError("owner.notfound", fmt.Sprintf("owner %s not found", ownerAlias), nil, s.Timestamp.Now())
+		}
+		delete(state.Owners, ownerAlias)
+	default:
+		return apierrors.NewBadRequestError("transaction.invalid.values", fmt.Sprintf("validation error: unknown operation %s", operation.Operation), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+// checkTransactionOwner verifies the owner exists in the transaction state and was not concurrently updated.
+func (s *Impl) checkTransactionOwner(ctx context.Context, ownerAlias string, exists bool, current openapi.OwnerDto, timeStamp string, commitHash string) error {
+	if !exists {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("owner %v not found", ownerAlias)
+		return apierrors.NewNotFoundError("owner.notfound", fmt.Sprintf("owner %s not found", ownerAlias), nil, s.Timestamp.Now())
+	}
+	if current.TimeStamp != timeStamp || current.CommitHash != commitHash {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("owner %v was concurrently updated", ownerAlias)
+		return apierrors.NewConflictErrorWithResponse("owner.conflict.concurrentlyupdated", fmt.Sprintf("owner %v was concurrently updated", ownerAlias), nil, current, s.Timestamp.Now())
+	}
+	return nil
+}
+
+func (s *Impl) GetDeletedOwners(ctx context.Context) (openapi.DeletedListDto, error) {
+	deleted, err := s.Updater.GetDeletions(ctx, types.DeletedOwners)
+	if err != nil {
+		return openapi.DeletedListDto{}, err
+	}
+	return openapi.DeletedListDto{Deleted: deleted}, nil
+}
+
+func (s *Impl) RestoreOwner(ctx context.Context, ownerAlias string, restoreDto openapi.RestoreDto) (openapi.OwnerDto, error) {
+	if err := s.validateRestoreDto(ctx, restoreDto); err != nil {
+		return openapi.OwnerDto{}, err
+	}
+
+	var result openapi.OwnerDto
+	err := s.Updater.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		err := s.Updater.PerformFullUpdate(subCtx)
+		if err != nil {
+			return err
+		}
+
+		current, err := s.Cache.GetOwner(subCtx, ownerAlias)
+		if err == nil {
+			result = current
+			s.Logging.Logger().Ctx(ctx).Info().Printf("owner %v already exists", ownerAlias)
+			return apierrors.NewConflictErrorWithResponse("owner.conflict.alreadyexists", fmt.Sprintf("owner %s already exists - cannot restore", ownerAlias), nil, result, s.Timestamp.Now())
+		}
+
+		ownerDto, err := s.Updater.GetDeletedOwner(subCtx, ownerAlias)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				s.Logging.Logger().Ctx(ctx).Info().Printf("no deleted owner %v", ownerAlias)
+				return apierrors.NewNotFoundError("owner.notfound", fmt.Sprintf("no deleted owner %s found", ownerAlias), err, s.Timestamp.Now())
+			}
+			return err
+		}
+		ownerDto.JiraIssue = restoreDto.JiraIssue
+
+		if err := s.validateRestoredOwnerDto(ctx, ownerDto); err != nil {
+			return err
+		}
+		if err := s.validateGroupReferences(subCtx, ownerAlias, openapi.OwnerDto{}, ownerDto); err != nil {
+			return err
+		}
+
+		ownerWritten, err := s.Updater.WriteOwner(subCtx, ownerAlias, ownerDto)
+		if err != nil {
+			return err
+		}
+		result = ownerWritten
+		return nil
+	})
+	return result, err
+}
+
+func (s *Impl) validateRestoredOwnerDto(ctx context.Context, dto openapi.OwnerDto) error {
+	messages := make([]string, 0)
+	if dto.Contact == "" {
+		messages = append(messages, "field contact is mandatory")
+	}
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("restored owner values invalid: %s", details)
+		return apierrors.NewBadRequestError("owner.invalid.values", fmt.Sprintf("validation error: the last version of the owner is no longer valid: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+// validateGroupReferences rejects group references added to the members or promoters of an owner that do not
+// resolve, see util.AddedGroupReferenceProblems. References to the owner itself resolve against its new groups.
+func (s *Impl) validateGroupReferences(ctx context.Context, ownerAlias string, current openapi.OwnerDto, candidate openapi.OwnerDto) error {
+	cachedGroupsOf := util.CachedGroupsOf(ctx, s.Cache)
+	groupsOf := func(groupOwner string) (map[string][]string, bool) {
+		if groupOwner == ownerAlias {
+			return candidate.Groups, true
+		}
+		return cachedGroupsOf(groupOwner)
+	}
+	messages := util.AddedGroupReferenceProblems(util.OwnerUserLists(current), util.OwnerUserLists(candidate), groupsOf)
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("owner group references invalid: %s", details)
+		return apierrors.NewBadRequestError("owner.invalid.groupreference", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+func (s *Impl) GetOwnerRedirect(ctx context.Context, ownerAlias string) (string, bool) {
+	redirects, err := s.Cache.GetRedirects(ctx)
+	if err != nil {
+		return "", false
+	}
+	newAlias, ok := redirects.Owners[ownerAlias]
+	return newAlias, ok
+}
+
 func (s *Impl) validateDeletionDto(ctx context.Context, deletionInfo openapi.DeletionDto) error {
 	messages := make([]string, 0)
 	if deletionInfo.JiraIssue == "" {
@@ -388,3 +948,16 @@ func (s *Impl) validateDeletionDto(ctx context.Context, deletionInfo openapi.Del
 	}
 	return nil
 }
+
+func (s *Impl) validateRestoreDto(ctx context.Context, restoreDto openapi.RestoreDto) error {
+	messages := make([]string, 0)
+	if restoreDto.JiraIssue == "" {
+		messages = append(messages, "field jiraIssue is mandatory for restoring")
+	}
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("restore values invalid: %s", details)
+		return apierrors.NewBadRequestError("restore.invalid.values", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
@@ -24,6 +24,15 @@ func TestGetAllGroupMembers(t *testing.T) {
 	groupMembers = instance.GetAllGroupMembers(context.Background(), "someOwner", "someGroupName")
 	require.Equal(t, 0, len(groupMembers))
 }
+func TestExpandUserGroups(t *testing.T) {
+	docs.Description("group references are replaced by their members, duplicates are removed")
+	instance := Impl{
+		Cache: &ownersmock.Mock{},
+	}
+	result := instance.ExpandUserGroups(context.Background(), []string{"username2", "@ownerWithGroup.someGroupName", "@someOwner.someGroupName", "other"})
+	require.Exactly(t, []string{"username2", "username1", "other"}, result)
+}
+
 func ptr(in string) *string {
 	return &in
 }
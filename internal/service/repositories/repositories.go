@@ -2,9 +2,11 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	internalutil "github.com/Interhyp/metadata-service/internal/util"
 	"net/url"
+	"os"
 	"slices"
 	"strings"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/Interhyp/metadata-service/internal/acorn/repository"
 	"github.com/Interhyp/metadata-service/internal/acorn/service"
 	"github.com/Interhyp/metadata-service/internal/service/util"
+	"github.com/Interhyp/metadata-service/internal/types"
 	auzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
 )
 
@@ -92,25 +95,45 @@ func (s *Impl) validRepositoryType(repoType string) bool {
 	return false
 }
 
+// repositorySortFields are the fields the repository list can be sorted by, the first one is the default.
+var repositorySortFields = []string{util.SortByName, util.SortByOwner, util.SortByTimeStamp}
+
 func (s *Impl) GetRepositories(ctx context.Context,
 	ownerAliasFilter string, serviceNameFilter string,
 	nameFilter string, typeFilter string,
-	urlFilter string,
+	urlFilter string, labelSelector string,
+	at string, page types.PageRequest,
 ) (openapi.RepositoryListDto, error) {
 	result := openapi.RepositoryListDto{
 		Repositories: make(map[string]openapi.RepositoryDto),
 	}
 
-	stamp, err := s.Cache.GetRepositoryListTimestamp(ctx)
+	selector, err := util.ParseLabelSelector(labelSelector, s.Timestamp.Now())
+	if err != nil {
+		return result, err
+	}
+
+	pager, err := util.NewPager(page, repositorySortFields, s.Timestamp.Now())
+	if err != nil {
+		return result, err
+	}
+
+	source, commitHash, err := s.Updater.ListSource(ctx, at, pager.Snapshot())
+	if err != nil {
+		return result, err
+	}
+
+	stamp, err := source.GetRepositoryListTimestamp(ctx)
 	if err != nil {
 		return result, err
 	}
 	result.TimeStamp = stamp
+	pager.ListedAt(stamp, commitHash)
 
 	useReferencedRepositoriesMap := false
 	referencedRepositoriesMap := make(map[string]bool, 0)
 	if serviceNameFilter != "" {
-		svc, err := s.Cache.GetService(ctx, serviceNameFilter)
+		svc, err := source.GetService(ctx, serviceNameFilter)
 		if err != nil {
 			return result, err
 		}
@@ -120,13 +143,17 @@ func (s *Impl) GetRepositories(ctx context.Context,
 		}
 	}
 
-	keys, err := s.Cache.GetSortedRepositoryKeys(ctx)
+	keys, err := source.GetSortedRepositoryKeys(ctx)
 	if err != nil {
 		return openapi.RepositoryListDto{}, err
 	}
-	for _, key := range keys {
+	for _, key := range pager.Keys(keys) {
+		if pager.Full() {
+			break
+		}
 		if !useReferencedRepositoriesMap || referencedRepositoriesMap[key] {
-			repo, err := s.GetRepository(ctx, key)
+			// group expansion is only needed for the entries that end up on the page, see below
+			repo, err := source.GetRepository(ctx, key)
 			if err != nil {
 				// repository not found errors are ok, the cache may have been changed concurrently, just drop the entry
 				if !apierrors.IsNotFoundError(err) {
@@ -144,8 +171,11 @@ func (s *Impl) GetRepositories(ctx context.Context,
 				if urlFilter == "" || urlFilter == repo.Url {
 					if ownerAliasFilter == "" || ownerAliasFilter == repo.Owner {
 						if nameFilter == "" || nameFilter == keyName {
-							if typeFilter == "" || typeFilter == keyType {
-								result.Repositories[key] = repo
+							if (typeFilter == "" || typeFilter == keyType) && selector.Matches(repo.Labels, nil) {
+								if pager.Keeps() {
+									result.Repositories[key] = repo
+								}
+								pager.Add(key, repositorySortValue(pager, repo))
 							}
 						}
 					}
@@ -153,13 +183,148 @@ func (s *Impl) GetRepositories(ctx context.Context,
 			}
 		}
 	}
+
+	order, nextCursor := pager.Page()
+	result.Repositories, order, err = util.PageEntries(result.Repositories, order, func(key string) (openapi.RepositoryDto, error) {
+		return source.GetRepository(ctx, key)
+	})
+	if err != nil {
+		return openapi.RepositoryListDto{}, err
+	}
+	for key, repo := range result.Repositories {
+		result.Repositories[key] = s.expandRepository(ctx, repo)
+	}
+	if page.IsActive() {
+		result.Order, result.NextCursor = order, nextCursor
+	}
 	return result, nil
 }
 
+func repositorySortValue(pager *util.Pager, repo openapi.RepositoryDto) string {
+	if pager.SortField() == util.SortByOwner {
+		return repo.Owner
+	}
+	return repo.TimeStamp
+}
+
 func (s *Impl) GetRepository(ctx context.Context, repoKey string) (openapi.RepositoryDto, error) {
-	repositoryDto, err := s.Cache.GetRepository(ctx, repoKey)
+	return s.getRepositoryFrom(ctx, s.Cache, repoKey)
+}
+
+func (s *Impl) GetRepositoryAt(ctx context.Context, repoKey string, at string) (openapi.RepositoryDto, error) {
+	if at == "" {
+		return s.GetRepository(ctx, repoKey)
+	}
+	repositoryDto, err := s.Updater.GetRepositoryAt(ctx, at, repoKey)
+	if err != nil {
+		return repositoryDto, err
+	}
+	return s.expandRepository(ctx, repositoryDto), nil
+}
+
+func (s *Impl) GetRepositoryServices(ctx context.Context, repoKey string) (openapi.ServiceListDto, error) {
+	if _, err := s.Cache.GetRepository(ctx, repoKey); err != nil {
+		return openapi.ServiceListDto{}, err
+	}
+
+	stamp, err := s.Cache.GetServiceListTimestamp(ctx)
+	if err != nil {
+		return openapi.ServiceListDto{}, err
+	}
+	result := openapi.ServiceListDto{
+		Services:  make(map[string]openapi.ServiceDto),
+		TimeStamp: stamp,
+	}
+	names, err := s.Cache.GetSortedServiceNames(ctx)
+	if err != nil {
+		return openapi.ServiceListDto{}, err
+	}
+	for _, name := range names {
+		theService, err := s.Cache.GetService(ctx, name)
+		if err != nil {
+			// service not found errors are ok, the cache may have been changed concurrently, just drop the entry
+			if !apierrors.IsNotFoundError(err) {
+				return openapi.ServiceListDto{}, err
+			}
+		} else if slices.Contains(theService.Repositories, repoKey) {
+			result.Services[name] = theService
+		}
+	}
+	return result, nil
+}
+
+func (s *Impl) GetRepositoryHistory(ctx context.Context, repoKey string, page types.PageRequest) (openapi.HistoryDto, error) {
+	stop, err := util.HistoryStop(page, s.Timestamp.Now())
+	if err != nil {
+		return openapi.HistoryDto{}, err
+	}
+	entries, err := s.Updater.GetRepositoryHistory(ctx, repoKey, stop)
+	if err != nil {
+		return openapi.HistoryDto{}, err
+	}
+	if len(entries) == 0 {
+		return openapi.HistoryDto{}, apierrors.NewNotFoundError("repository.notfound", fmt.Sprintf("repository %s not found", repoKey), nil, s.Timestamp.Now())
+	}
+	return util.PageHistory(entries, page, s.Timestamp.Now())
+}
+
+func (s *Impl) GetRepositoryDiff(ctx context.Context, repoKey string, from string, to string) (openapi.DiffDto, error) {
+	if from == "" {
+		return openapi.DiffDto{}, apierrors.NewBadRequestError("from.missing", "from is required, a (possibly abbreviated) commit hash or an RFC3339 timestamp", nil, s.Timestamp.Now())
+	}
 
-	if err == nil && repositoryDto.Configuration != nil {
+	before, err := s.repositoryOrNil(ctx, repoKey, from)
+	if err != nil {
+		return openapi.DiffDto{}, err
+	}
+	after, err := s.repositoryOrNil(ctx, repoKey, to)
+	if err != nil {
+		return openapi.DiffDto{}, err
+	}
+	if before == nil && after == nil {
+		return openapi.DiffDto{}, apierrors.NewNotFoundError("repository.notfound", fmt.Sprintf("repository %s not found", repoKey), nil, s.Timestamp.Now())
+	}
+
+	result := openapi.DiffDto{}
+	if before != nil {
+		result.FromCommitHash = before.CommitHash
+	}
+	if after != nil {
+		result.ToCommitHash = after.CommitHash
+	}
+	result.Operations, err = util.Diff(before, after)
+	return result, err
+}
+
+// repositoryOrNil reads the repository as of at, or currently if at is empty. nil means it did not exist.
+func (s *Impl) repositoryOrNil(ctx context.Context, repoKey string, at string) (*openapi.RepositoryDto, error) {
+	var repository openapi.RepositoryDto
+	var err error
+	if at == "" {
+		repository, err = s.Cache.GetRepository(ctx, repoKey)
+	} else {
+		repository, err = s.Updater.GetRepositoryAt(ctx, at, repoKey)
+	}
+	if err != nil {
+		if apierrors.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &repository, nil
+}
+
+func (s *Impl) getRepositoryFrom(ctx context.Context, source repository.Cache, repoKey string) (openapi.RepositoryDto, error) {
+	repositoryDto, err := source.GetRepository(ctx, repoKey)
+	if err != nil {
+		return repositoryDto, err
+	}
+	return s.expandRepository(ctx, repositoryDto), nil
+}
+
+// expandRepository replaces the user groups in the configuration by their members, keeping the raw lists.
+func (s *Impl) expandRepository(ctx context.Context, repositoryDto openapi.RepositoryDto) openapi.RepositoryDto {
+	if repositoryDto.Configuration != nil {
 		repoConfig := *repositoryDto.Configuration
 		repoConfig.RawApprovers = s.copyApprovers(repoConfig.Approvers)
 		s.expandApprovers(ctx, repoConfig.Approvers)
@@ -172,8 +337,7 @@ func (s *Impl) GetRepository(ctx context.Context, repoKey string) (openapi.Repos
 		}
 		repositoryDto.Configuration = &repoConfig
 	}
-
-	return repositoryDto, err
+	return repositoryDto
 }
 
 func (s *Impl) expandApprovers(ctx context.Context, approvers map[string][]string) {
@@ -187,17 +351,7 @@ func (s *Impl) expandApprovers(ctx context.Context, approvers map[string][]strin
 // expandUserGroups replaces all occurrences of "@owner.group" in the given list with the members of the respective
 // group.
 func (s *Impl) expandUserGroups(ctx context.Context, userList []string) []string {
-	filteredApprovers := make([]string, 0)
-	for _, approver := range userList {
-		isGroup, groupOwner, groupName := util.ParseGroupOwnerAndGroupName(approver)
-		if isGroup {
-			groupMembers := s.Owners.GetAllGroupMembers(ctx, groupOwner, groupName)
-			filteredApprovers = append(filteredApprovers, groupMembers...)
-		} else {
-			filteredApprovers = append(filteredApprovers, approver)
-		}
-	}
-	return util.RemoveDuplicateStr(filteredApprovers)
+	return s.Owners.ExpandUserGroups(ctx, userList)
 }
 
 func (s *Impl) copyApprovers(approvers map[string][]string) map[string][]string {
@@ -247,6 +401,10 @@ func (s *Impl) CreateRepository(ctx context.Context, key string, repositoryCreat
 			return apierrors.NewBadRequestError("repository.invalid.missing.owner", details, err, s.Timestamp.Now())
 		}
 
+		if err := s.validateGroupReferences(subCtx, openapi.RepositoryDto{}, repositoryDto); err != nil {
+			return err
+		}
+
 		repositoryWritten, err := s.Updater.WriteRepository(subCtx, key, repositoryDto)
 		if err != nil {
 			return err
@@ -320,6 +478,13 @@ func (s *Impl) UpdateRepository(ctx context.Context, key string, repositoryDto o
 			return apierrors.NewConflictErrorWithResponse("repository.conflict.concurrentlyupdated", fmt.Sprintf("repository %v was concurrently updated", key), nil, result, s.Timestamp.Now())
 		}
 
+		// only a rename changes the previous names
+		repositoryDto.PreviousNames = current.PreviousNames
+
+		if err := s.validateGroupReferences(subCtx, current, repositoryDto); err != nil {
+			return err
+		}
+
 		repositoryWritten, err := s.Updater.WriteRepository(subCtx, key, repositoryDto)
 		if err != nil {
 			return err
@@ -361,6 +526,28 @@ func (s *Impl) validateExistingRepositoryDto(ctx context.Context, key string, dt
 	return nil
 }
 
+func (s *Impl) PatchRepositoryDocument(ctx context.Context, key string, patch types.PatchDocument) (openapi.RepositoryDto, error) {
+	current, err := s.Cache.GetRepository(ctx, key)
+	if err != nil {
+		return openapi.RepositoryDto{}, err
+	}
+
+	// the cached repository has its group references unexpanded, which is what must be written back
+
+	// the patch must supply these, so concurrent updates are detected and the commit gets a jira issue, just like a PUT
+	current.CommitHash = ""
+	current.TimeStamp = ""
+	current.JiraIssue = ""
+
+	patched := openapi.RepositoryDto{}
+	if err := util.ApplyPatch(current, patch, &patched, s.Timestamp.Now()); err != nil {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("patch of repository %s failed: %s", key, err.Error())
+		return openapi.RepositoryDto{}, err
+	}
+
+	return s.UpdateRepository(ctx, key, patched)
+}
+
 func (s *Impl) PatchRepository(ctx context.Context, key string, repositoryPatchDto openapi.RepositoryPatchDto) (openapi.RepositoryDto, error) {
 	result, err := s.GetRepository(ctx, key)
 	if err != nil {
@@ -397,6 +584,10 @@ func (s *Impl) PatchRepository(ctx context.Context, key string, repositoryPatchD
 			return apierrors.NewConflictErrorWithResponse("repository.conflict.concurrentlyupdated", fmt.Sprintf("repository %v was concurrently updated", key), nil, result, s.Timestamp.Now())
 		}
 
+		if err := s.validateGroupReferences(subCtx, current, repositoryDto); err != nil {
+			return err
+		}
+
 		repositoryWritten, err := s.Updater.WriteRepository(subCtx, key, repositoryDto)
 		if err != nil {
 			return err
@@ -436,6 +627,125 @@ func (s *Impl) validateRepositoryPatchDto(ctx context.Context, key string, patch
 	return nil
 }
 
+func (s *Impl) PatchRepositories(ctx context.Context, ownerAliasFilter string, typeFilter string, labelSelector string, repositoryPatchDto openapi.RepositoryPatchDto) (openapi.RepositoryBulkPatchResultDto, error) {
+	result := openapi.RepositoryBulkPatchResultDto{
+		Repositories: make([]string, 0),
+		Diffs:        make(map[string][]openapi.DiffOperationDto),
+	}
+
+	if err := s.validateRepositoryBulkPatch(ctx, ownerAliasFilter, typeFilter, labelSelector, repositoryPatchDto); err != nil {
+		return result, err
+	}
+
+	selector, err := util.ParseLabelSelector(labelSelector, s.Timestamp.Now())
+	if err != nil {
+		return result, err
+	}
+
+	err = s.Updater.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		err := s.Updater.PerformFullUpdate(subCtx)
+		if err != nil {
+			return err
+		}
+
+		keys, err := s.Cache.GetSortedRepositoryKeys(subCtx)
+		if err != nil {
+			return err
+		}
+
+		changes := make(map[string]*openapi.RepositoryDto)
+		messages := make([]string, 0)
+		for _, key := range keys {
+			current, err := s.Cache.GetRepository(subCtx, key)
+			if err != nil {
+				// repository not found errors are ok, the cache may have been changed concurrently, just drop the entry
+				if !apierrors.IsNotFoundError(err) {
+					return err
+				}
+				continue
+			}
+
+			keyType := ""
+			if keyComponents := strings.Split(key, "."); len(keyComponents) == 2 {
+				keyType = keyComponents[1]
+			}
+			if (ownerAliasFilter != "" && ownerAliasFilter != current.Owner) ||
+				(typeFilter != "" && typeFilter != keyType) ||
+				!selector.Matches(current.Labels, nil) {
+				continue
+			}
+
+			patched := patchRepository(current, repositoryPatchDto)
+			diffs, err := util.Diff(current, patched)
+			if err != nil {
+				return err
+			}
+			if len(diffs) == 0 {
+				continue
+			}
+
+			repositoryMessages := validateUrl(make([]string, 0), patched.Url)
+			repositoryMessages = validateMainline(repositoryMessages, patched.Mainline)
+			repositoryMessages = validateConfiguration(repositoryMessages, patched.Configuration)
+			repositoryMessages = append(repositoryMessages, util.AddedGroupReferenceProblems(util.RepositoryUserLists(current), util.RepositoryUserLists(patched), util.CachedGroupsOf(subCtx, s.Cache))...)
+			for _, message := range repositoryMessages {
+				messages = append(messages, fmt.Sprintf("repository %s: %s", key, message))
+			}
+
+			changes[key] = &patched
+			result.Repositories = append(result.Repositories, key)
+			result.Diffs[key] = diffs
+		}
+
+		if len(messages) > 0 {
+			details := strings.Join(messages, ", ")
+			s.Logging.Logger().Ctx(ctx).Info().Printf("repository values invalid: %s", details)
+			return apierrors.NewBadRequestError("repository.invalid.values", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+		}
+
+		if len(changes) == 0 {
+			s.Logging.Logger().Ctx(ctx).Info().Printf("bulk patch changes no repositories")
+			return nil
+		}
+
+		description := fmt.Sprintf("bulk patch %d repositories", len(changes))
+		written, err := s.Updater.WriteTransaction(subCtx, service.TransactionChanges{Repositories: changes}, description, repositoryPatchDto.JiraIssue)
+		if err != nil {
+			return err
+		}
+		result.TimeStamp = written.TimeStamp
+		result.CommitHash = written.CommitHash
+		result.JiraIssue = written.JiraIssue
+		return nil
+	})
+	return result, err
+}
+
+func (s *Impl) validateRepositoryBulkPatch(ctx context.Context, ownerAliasFilter string, typeFilter string, labelSelector string, patchDto openapi.RepositoryPatchDto) error {
+	messages := make([]string, 0)
+
+	if ownerAliasFilter == "" && typeFilter == "" && strings.TrimSpace(labelSelector) == "" {
+		messages = append(messages, "at least one of the filters owner, type or labelSelector is mandatory for bulk patching")
+	}
+	if patchDto.Owner != nil {
+		messages = append(messages, "field owner cannot be changed by bulk patching")
+	}
+	// there is no single version of the matching repositories the patch could be based on
+	if patchDto.CommitHash != "" || patchDto.TimeStamp != "" {
+		messages = append(messages, "fields commitHash and timeStamp cannot be used for bulk patching")
+	}
+	if patchDto.JiraIssue == "" {
+		messages = append(messages, "field jiraIssue is mandatory for patching")
+	}
+
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("repository bulk patch values invalid: %s", details)
+		return apierrors.NewBadRequestError("repository.invalid.values", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
 func patchRepository(current openapi.RepositoryDto, patch openapi.RepositoryPatchDto) openapi.RepositoryDto {
 	return openapi.RepositoryDto{
 		Type:          current.Type,
@@ -448,6 +758,7 @@ func patchRepository(current openapi.RepositoryDto, patch openapi.RepositoryPatc
 		TimeStamp:     patch.TimeStamp,
 		CommitHash:    patch.CommitHash,
 		JiraIssue:     patch.JiraIssue,
+		PreviousNames: current.PreviousNames,
 	}
 }
 
@@ -742,6 +1053,214 @@ func (s *Impl) DeleteRepository(ctx context.Context, key string, deletionInfo op
 	})
 }
 
+func (s *Impl) RenameRepository(ctx context.Context, key string, renameDto openapi.RepositoryRenameDto) (openapi.RepositoryDto, error) {
+	if err := s.validateRepositoryRenameDto(ctx, key, renameDto); err != nil {
+		return openapi.RepositoryDto{}, err
+	}
+
+	var result openapi.RepositoryDto
+	err := s.Updater.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		err := s.Updater.PerformFullUpdate(subCtx)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.Cache.GetRepository(subCtx, key)
+		if err != nil {
+			s.Logging.Logger().Ctx(ctx).Info().Printf("repository %v not found", key)
+			return apierrors.NewNotFoundError("repository.notfound", fmt.Sprintf("repository %s not found", key), nil, s.Timestamp.Now())
+		}
+
+		existing, err := s.Cache.GetRepository(subCtx, renameDto.NewKey)
+		if err == nil {
+			s.Logging.Logger().Ctx(ctx).Info().Printf("tried to rename repository %v to existing repository %v", key, renameDto.NewKey)
+			return apierrors.NewConflictErrorWithResponse("repository.conflict.alreadyexists", fmt.Sprintf("repository %s already exists - cannot rename", renameDto.NewKey), nil, existing, s.Timestamp.Now())
+		}
+
+		result, err = s.Updater.RenameRepository(subCtx, key, renameDto.NewKey, renameDto.JiraIssue)
+		return err
+	})
+	return result, err
+}
+
+func (s *Impl) validateRepositoryRenameDto(ctx context.Context, key string, dto openapi.RepositoryRenameDto) error {
+	messages := make([]string, 0)
+	if dto.NewKey == "" {
+		messages = append(messages, "field newKey is mandatory")
+	} else if dto.NewKey == key {
+		messages = append(messages, "field newKey must differ from the current key")
+	}
+	if dto.JiraIssue == "" {
+		messages = append(messages, "field jiraIssue is mandatory")
+	}
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("repository rename values invalid: %s", details)
+		return apierrors.NewBadRequestError("repository.invalid.values", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+func (s *Impl) ApplyTransactionOperation(ctx context.Context, state *service.TransactionState, operation openapi.TransactionOperationDto) error {
+	key := operation.Name
+	current, exists := state.Repositories[key]
+
+	switch operation.Operation {
+	case types.TransactionCreate:
+		if err := s.ValidRepositoryKey(ctx, key); err != nil {
+			return err
+		}
+		repositoryCreateDto := openapi.RepositoryCreateDto{}
+		if err := util.DecodeTransactionBody(operation.Body, state.JiraIssue, &repositoryCreateDto, s.Timestamp.Now()); err != nil {
+			return err
+		}
+		if err := s.validateRepositoryCreateDto(ctx, key, repositoryCreateDto); err != nil {
+			return err
+		}
+		if exists {
+			s.Logging.Logger().Ctx(ctx).Info().Printf("repository %v already exists", key)
+			return apierrors.NewConflictError("repository.conflict.alreadyexists", fmt.Sprintf("repository %s already exists - cannot create", key), nil, s.Timestamp.Now())
+		}
+		state.Repositories[key] = s.mapRepoCreateDtoToRepoDto(repositoryCreateDto)
+	case types.TransactionUpdate:
+		repositoryDto := openapi.RepositoryDto{}
+		if err := util.DecodeTransactionBody(operation.Body, state.JiraIssue, &repositoryDto, s.Timestamp.Now()); err != nil {
+			return err
+		}
+		if err := s.validateExistingRepositoryDto(ctx, key, repositoryDto); err != nil {
+			return err
+		}
+		if err := s.checkTransactionRepository(ctx, key, exists, current, repositoryDto.TimeStamp, repositoryDto.CommitHash); err != nil {
+			return err
+		}
+		// only a rename changes the previous names
+		repositoryDto.PreviousNames = current.PreviousNames
+		state.Repositories[key] = repositoryDto
+	case types.TransactionPatch:
+		repositoryPatchDto := openapi.RepositoryPatchDto{}
+		if err := util.DecodeTransactionBody(operation.Body, state.JiraIssue, &repositoryPatchDto, s.Timestamp.Now()); err != nil {
+			return err
+		}
+		if err := s.checkTransactionRepository(ctx, key, exists, current, repositoryPatchDto.TimeStamp, repositoryPatchDto.CommitHash); err != nil {
+			return err
+		}
+		if err := s.validateRepositoryPatchDto(ctx, key, repositoryPatchDto, current); err != nil {
+			return err
+		}
+		state.Repositories[key] = patchRepository(current, repositoryPatchDto)
+	case types.TransactionDelete:
+		if !exists {
+			s.Logging.Logger().Ctx(ctx).Info().Printf("repository %v not found", key)
+			return apierrors.NewNotFoundError("repository.notfound", fmt.Sprintf("repository %s not found", key), nil, s.Timestamp.Now())
+		}
+		delete(state.Repositories, key)
+	default:
+		return apierrors.NewBadRequestError("transaction.invalid.values", fmt.Sprintf("validation error: unknown operation %s", operation.Operation), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+// checkTransactionRepository verifies the repository exists in the transaction state and was not concurrently updated.
+func (s *Impl) checkTransactionRepository(ctx context.Context, key string, exists bool, current openapi.RepositoryDto, timeStamp string, commitHash string) error {
+	if !exists {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("repository %v not found", key)
+		return apierrors.NewNotFoundError("repository.notfound", fmt.Sprintf("repository %s not found", key), nil, s.Timestamp.Now())
+	}
+	if current.TimeStamp != timeStamp || current.CommitHash != commitHash {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("repository %v was concurrently updated", key)
+		return apierrors.NewConflictErrorWithResponse("repository.conflict.concurrentlyupdated", fmt.Sprintf("repository %v was concurrently updated", key), nil, current, s.Timestamp.Now())
+	}
+	return nil
+}
+
+func (s *Impl) GetDeletedRepositories(ctx context.Context) (openapi.DeletedListDto, error) {
+	deleted, err := s.Updater.GetDeletions(ctx, types.DeletedRepositories)
+	if err != nil {
+		return openapi.DeletedListDto{}, err
+	}
+	return openapi.DeletedListDto{Deleted: deleted}, nil
+}
+
+func (s *Impl) RestoreRepository(ctx context.Context, key string, restoreDto openapi.RestoreDto) (openapi.RepositoryDto, error) {
+	if err := s.validateRestoreDto(ctx, restoreDto); err != nil {
+		return openapi.RepositoryDto{}, err
+	}
+
+	var result openapi.RepositoryDto
+	err := s.Updater.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		err := s.Updater.PerformFullUpdate(subCtx)
+		if err != nil {
+			return err
+		}
+
+		current, err := s.Cache.GetRepository(subCtx, key)
+		if err == nil {
+			result = current
+			s.Logging.Logger().Ctx(ctx).Info().Printf("repository %v already exists", key)
+			return apierrors.NewConflictErrorWithResponse("repository.conflict.alreadyexists", fmt.Sprintf("repository %s already exists - cannot restore", key), nil, result, s.Timestamp.Now())
+		}
+
+		repositoryDto, err := s.Updater.GetDeletedRepository(subCtx, key)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				s.Logging.Logger().Ctx(ctx).Info().Printf("no deleted repository %v", key)
+				return apierrors.NewNotFoundError("repository.notfound", fmt.Sprintf("no deleted repository %s found", key), err, s.Timestamp.Now())
+			}
+			return err
+		}
+		repositoryDto.JiraIssue = restoreDto.JiraIssue
+
+		if err := s.validateRestoredRepositoryDto(ctx, repositoryDto); err != nil {
+			return err
+		}
+
+		_, err = s.Cache.GetOwner(subCtx, repositoryDto.Owner)
+		if err != nil {
+			details := fmt.Sprintf("no such owner: %s", repositoryDto.Owner)
+			s.Logging.Logger().Ctx(ctx).Info().Printf(details)
+			return apierrors.NewBadRequestError("repository.invalid.missing.owner", details, err, s.Timestamp.Now())
+		}
+
+		if err := s.validateGroupReferences(subCtx, openapi.RepositoryDto{}, repositoryDto); err != nil {
+			return err
+		}
+
+		repositoryWritten, err := s.Updater.WriteRepository(subCtx, key, repositoryDto)
+		if err != nil {
+			return err
+		}
+
+		result = repositoryWritten
+		return nil
+	})
+	return result, err
+}
+
+func (s *Impl) validateRestoredRepositoryDto(ctx context.Context, dto openapi.RepositoryDto) error {
+	messages := make([]string, 0)
+
+	messages = validateOwner(messages, dto.Owner)
+	messages = validateUrl(messages, dto.Url)
+	messages = validateMainline(messages, dto.Mainline)
+	messages = validateConfiguration(messages, dto.Configuration)
+
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("restored repository values invalid: %s", details)
+		return apierrors.NewBadRequestError("repository.invalid.values", fmt.Sprintf("validation error: the last version of the repository is no longer valid: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+func (s *Impl) GetRepositoryRedirect(ctx context.Context, key string) (string, bool) {
+	redirects, err := s.Cache.GetRedirects(ctx)
+	if err != nil {
+		return "", false
+	}
+	newKey, ok := redirects.Repositories[key]
+	return newKey, ok
+}
+
 func (s *Impl) validateDeletionDto(ctx context.Context, deletionInfo openapi.DeletionDto) error {
 	messages := make([]string, 0)
 	if deletionInfo.JiraIssue == "" {
@@ -755,8 +1274,33 @@ func (s *Impl) validateDeletionDto(ctx context.Context, deletionInfo openapi.Del
 	return nil
 }
 
+func (s *Impl) validateRestoreDto(ctx context.Context, restoreDto openapi.RestoreDto) error {
+	messages := make([]string, 0)
+	if restoreDto.JiraIssue == "" {
+		messages = append(messages, "field jiraIssue is mandatory for restoring")
+	}
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("restore values invalid: %s", details)
+		return apierrors.NewBadRequestError("restore.invalid.values", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
 // -- validation --
 
+// validateGroupReferences rejects group references added to the approvers, watchers or exemptions of a repository
+// that do not resolve, see util.AddedGroupReferenceProblems.
+func (s *Impl) validateGroupReferences(ctx context.Context, current openapi.RepositoryDto, candidate openapi.RepositoryDto) error {
+	messages := util.AddedGroupReferenceProblems(util.RepositoryUserLists(current), util.RepositoryUserLists(candidate), util.CachedGroupsOf(ctx, s.Cache))
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("repository group references invalid: %s", details)
+		return apierrors.NewBadRequestError("repository.invalid.groupreference", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
 func validateOwner(messages []string, ownerAlias string) []string {
 	if ownerAlias == "" {
 		messages = append(messages, "field owner is mandatory")
@@ -0,0 +1,296 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	auzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
+)
+
+const (
+	TypeOwner      = "owner"
+	TypeService    = "service"
+	TypeRepository = "repository"
+)
+
+// field weights, a match in a more specific field ranks higher
+const (
+	weightName        = 10
+	weightDisplayName = 8
+	weightLabels      = 5
+	weightQuicklinks  = 4
+	weightUrl         = 4
+	weightDescription = 2
+)
+
+// match qualities, multiplied with the field weight
+const (
+	qualityExact     = 3
+	qualityWordStart = 2
+	qualityContained = 1
+)
+
+type field struct {
+	name   string
+	weight int
+	values []string
+}
+
+type document struct {
+	hitType string
+	key     string
+	owner   string
+	href    string
+	fields  []field
+}
+
+type Impl struct {
+	Configuration librepo.Configuration
+	Logging       librepo.Logging
+	Timestamp     librepo.Timestamp
+
+	mu        sync.RWMutex
+	documents map[string]document
+}
+
+func New(
+	configuration librepo.Configuration,
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+) service.Search {
+	return &Impl{
+		Configuration: configuration,
+		Logging:       logging,
+		Timestamp:     timestamp,
+		documents:     make(map[string]document),
+	}
+}
+
+func (s *Impl) IsSearch() bool {
+	return true
+}
+
+func (s *Impl) Setup() error {
+	ctx := auzerolog.AddLoggerToCtx(context.Background())
+
+	// nothing to do, the updater fills the index during initial cache population
+
+	s.Logging.Logger().Ctx(ctx).Info().Print("successfully set up search business component")
+	return nil
+}
+
+// --- index maintenance ---
+
+func (s *Impl) IndexOwner(_ context.Context, ownerAlias string, owner openapi.OwnerDto) {
+	s.put(document{
+		hitType: TypeOwner,
+		key:     ownerAlias,
+		owner:   ownerAlias,
+		href:    "/rest/api/v1/owners/" + url.PathEscape(ownerAlias),
+		fields: []field{
+			newField("alias", weightName, ownerAlias),
+			newField("displayName", weightDisplayName, deref(owner.DisplayName)),
+		},
+	})
+}
+
+func (s *Impl) RemoveOwner(_ context.Context, ownerAlias string) {
+	s.remove(TypeOwner, ownerAlias)
+}
+
+func (s *Impl) IndexService(_ context.Context, serviceName string, service openapi.ServiceDto) {
+	quicklinkTitles := make([]string, 0, len(service.Quicklinks))
+	for _, quicklink := range service.Quicklinks {
+		quicklinkTitles = append(quicklinkTitles, deref(quicklink.Title))
+	}
+	s.put(document{
+		hitType: TypeService,
+		key:     serviceName,
+		owner:   service.Owner,
+		href:    "/rest/api/v1/services/" + url.PathEscape(serviceName),
+		fields: []field{
+			newField("name", weightName, serviceName),
+			newField("description", weightDescription, deref(service.Description)),
+			newField("quicklinks", weightQuicklinks, quicklinkTitles...),
+			newField("labels", weightLabels, labelValues(service.Labels)...),
+			newField("tags", weightLabels, service.Tags...),
+		},
+	})
+}
+
+func (s *Impl) RemoveService(_ context.Context, serviceName string) {
+	s.remove(TypeService, serviceName)
+}
+
+func (s *Impl) IndexRepository(_ context.Context, key string, repository openapi.RepositoryDto) {
+	s.put(document{
+		hitType: TypeRepository,
+		key:     key,
+		owner:   repository.Owner,
+		href:    "/rest/api/v1/repositories/" + url.PathEscape(key),
+		fields: []field{
+			newField("key", weightName, key),
+			newField("description", weightDescription, deref(repository.Description)),
+			newField("url", weightUrl, repository.Url),
+			newField("labels", weightLabels, labelValues(repository.Labels)...),
+		},
+	})
+}
+
+func (s *Impl) RemoveRepository(_ context.Context, key string) {
+	s.remove(TypeRepository, key)
+}
+
+// --- queries ---
+
+func (s *Impl) Search(_ context.Context, query string, limit int) (openapi.SearchResultDto, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return openapi.SearchResultDto{}, apierrors.NewBadRequestError("search.invalid.query", "query param q must contain at least one search term", nil, s.Timestamp.Now())
+	}
+
+	s.mu.RLock()
+	hits := make([]openapi.SearchHitDto, 0)
+	for _, doc := range s.documents {
+		if hit, ok := doc.match(terms); ok {
+			hits = append(hits, hit)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Type != hits[j].Type {
+			return hits[i].Type < hits[j].Type
+		}
+		return hits[i].Key < hits[j].Key
+	})
+
+	result := openapi.SearchResultDto{
+		Hits:         hits,
+		TotalMatches: int32(len(hits)),
+	}
+	if limit > 0 && len(hits) > limit {
+		result.Hits = hits[:limit]
+	}
+	return result, nil
+}
+
+// --- internals ---
+
+func (s *Impl) put(doc document) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents[documentId(doc.hitType, doc.key)] = doc
+}
+
+func (s *Impl) remove(hitType string, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.documents, documentId(hitType, key))
+}
+
+func documentId(hitType string, key string) string {
+	return fmt.Sprintf("%s:%s", hitType, key)
+}
+
+// match requires every term to be found in some field. Each term scores for the best field it was found in.
+func (d document) match(terms []string) (openapi.SearchHitDto, bool) {
+	score := 0
+	matched := make(map[string]bool)
+	for _, term := range terms {
+		best := 0
+		bestField := ""
+		for _, f := range d.fields {
+			for _, value := range f.values {
+				if q := matchQuality(value, term) * f.weight; q > best {
+					best = q
+					bestField = f.name
+				}
+			}
+		}
+		if best == 0 {
+			return openapi.SearchHitDto{}, false
+		}
+		score += best
+		matched[bestField] = true
+	}
+
+	matchedFields := make([]string, 0, len(matched))
+	for _, f := range d.fields {
+		if matched[f.name] {
+			matchedFields = append(matchedFields, f.name)
+		}
+	}
+	return openapi.SearchHitDto{
+		Type:          d.hitType,
+		Key:           d.key,
+		Owner:         d.owner,
+		Score:         int32(score),
+		MatchedFields: matchedFields,
+		Href:          d.href,
+	}, true
+}
+
+func matchQuality(value string, term string) int {
+	if value == term {
+		return qualityExact
+	}
+	idx := strings.Index(value, term)
+	if idx < 0 {
+		return 0
+	}
+	for ; idx >= 0; idx = nextIndex(value, term, idx) {
+		if idx == 0 || isSeparator(value[idx-1]) {
+			return qualityWordStart
+		}
+	}
+	return qualityContained
+}
+
+func nextIndex(value string, term string, previous int) int {
+	idx := strings.Index(value[previous+1:], term)
+	if idx < 0 {
+		return -1
+	}
+	return previous + 1 + idx
+}
+
+func isSeparator(c byte) bool {
+	return strings.IndexByte(" -_./:@=,;()", c) >= 0
+}
+
+func newField(name string, weight int, values ...string) field {
+	lowered := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			lowered = append(lowered, strings.ToLower(value))
+		}
+	}
+	return field{name: name, weight: weight, values: lowered}
+}
+
+func labelValues(labels map[string]string) []string {
+	result := make([]string, 0, 2*len(labels))
+	for k, v := range labels {
+		result = append(result, k, v, k+"="+v)
+	}
+	return result
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"context"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/go-backend-service-common/repository/timestamp"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func ptr(in string) *string {
+	return &in
+}
+
+func tstInstance() *Impl {
+	instance := New(nil, nil, timestamp.NewNoAcorn(time.Now)).(*Impl)
+	ctx := context.Background()
+	instance.IndexOwner(ctx, "unicorns", openapi.OwnerDto{DisplayName: ptr("The Unicorn Squad")})
+	instance.IndexOwner(ctx, "dragons", openapi.OwnerDto{DisplayName: ptr("Dragon Riders")})
+	instance.IndexService(ctx, "unicorn-finder", openapi.ServiceDto{
+		Owner:       "unicorns",
+		Description: ptr("Finds unicorns in the wild"),
+		Quicklinks:  []openapi.Quicklink{{Title: ptr("Swagger UI")}},
+		Labels:      map[string]string{"tier": "backend"},
+		Tags:        []string{"critical"},
+	})
+	instance.IndexService(ctx, "dragon-feeder", openapi.ServiceDto{
+		Owner:       "dragons",
+		Description: ptr("Feeds dragons, but never unicorns"),
+	})
+	instance.IndexRepository(ctx, "unicorn-finder.implementation", openapi.RepositoryDto{
+		Owner: "unicorns",
+		Url:   "ssh://git@github.com/some-org/unicorn-finder.git",
+	})
+	return instance
+}
+
+func tstKeys(result openapi.SearchResultDto) []string {
+	keys := make([]string, 0)
+	for _, hit := range result.Hits {
+		keys = append(keys, hit.Type+":"+hit.Key)
+	}
+	return keys
+}
+
+func TestSearch_Ranking(t *testing.T) {
+	docs.Description("search ranks name matches above description matches, ties are ordered by type and key")
+
+	result, err := tstInstance().Search(context.Background(), "unicorn", 0)
+	require.Nil(t, err)
+	require.Equal(t, int32(4), result.TotalMatches)
+	require.Equal(t, []string{
+		"owner:unicorns",
+		"repository:unicorn-finder.implementation",
+		"service:unicorn-finder",
+		"service:dragon-feeder",
+	}, tstKeys(result))
+	require.Equal(t, []string{"description"}, result.Hits[3].MatchedFields)
+	require.Equal(t, "/rest/api/v1/services/dragon-feeder", result.Hits[3].Href)
+	require.Equal(t, "dragons", result.Hits[3].Owner)
+}
+
+func TestSearch_AllTermsMustMatch(t *testing.T) {
+	docs.Description("search only returns entities that contain every term")
+
+	result, err := tstInstance().Search(context.Background(), "Swagger backend", 0)
+	require.Nil(t, err)
+	require.Equal(t, []string{"service:unicorn-finder"}, tstKeys(result))
+	require.Equal(t, []string{"quicklinks", "labels"}, result.Hits[0].MatchedFields)
+
+	result, err = tstInstance().Search(context.Background(), "squad tier=backend", 0)
+	require.Nil(t, err)
+	require.Empty(t, result.Hits)
+}
+
+func TestSearch_Incremental(t *testing.T) {
+	docs.Description("search reflects index updates and removals")
+
+	instance := tstInstance()
+	instance.RemoveService(context.Background(), "dragon-feeder")
+	instance.IndexOwner(context.Background(), "dragons", openapi.OwnerDto{DisplayName: ptr("Unicorn Hunters")})
+
+	result, err := instance.Search(context.Background(), "hunters", 0)
+	require.Nil(t, err)
+	require.Equal(t, []string{"owner:dragons"}, tstKeys(result))
+
+	result, err = instance.Search(context.Background(), "feeds", 0)
+	require.Nil(t, err)
+	require.Empty(t, result.Hits)
+}
+
+func TestSearch_Limit(t *testing.T) {
+	result, err := tstInstance().Search(context.Background(), "unicorn", 2)
+	require.Nil(t, err)
+	require.Equal(t, int32(4), result.TotalMatches)
+	require.Equal(t, 2, len(result.Hits))
+}
+
+func TestSearch_EmptyQuery(t *testing.T) {
+	_, err := tstInstance().Search(context.Background(), "  ", 0)
+	require.True(t, apierrors.IsBadRequestError(err))
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/Interhyp/metadata-service/internal/acorn/repository"
 	auzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
@@ -12,6 +16,8 @@ import (
 	"github.com/Interhyp/metadata-service/api"
 	"github.com/Interhyp/metadata-service/internal/acorn/config"
 	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/service/util"
+	"github.com/Interhyp/metadata-service/internal/types"
 
 	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
 	"github.com/Interhyp/go-backend-service-common/api/apierrors"
@@ -24,7 +30,9 @@ type Impl struct {
 	Timestamp           librepo.Timestamp
 	Cache               repository.Cache
 	Updater             service.Updater
+	Owners              service.Owners
 	Repositories        service.Repositories
+	Graph               service.Graph
 }
 
 func New(
@@ -34,7 +42,9 @@ func New(
 	timestamp librepo.Timestamp,
 	cache repository.Cache,
 	updater service.Updater,
+	owners service.Owners,
 	repositories service.Repositories,
+	graph service.Graph,
 ) service.Services {
 	return &Impl{
 		Configuration:       configuration,
@@ -43,7 +53,9 @@ func New(
 		Timestamp:           timestamp,
 		Cache:               cache,
 		Updater:             updater,
+		Owners:              owners,
 		Repositories:        repositories,
+		Graph:               graph,
 	}
 }
 
@@ -60,10 +72,26 @@ func (s *Impl) Setup() error {
 	return nil
 }
 
-var initialServiceLifecycle = "experimental"
+// serviceSortFields are the fields the service list can be sorted by, the first one is the default.
+var serviceSortFields = []string{util.SortByName, util.SortByOwner, util.SortByTimeStamp}
 
-func (s *Impl) GetServices(ctx context.Context, ownerAliasFilter string) (openapi.ServiceListDto, error) {
-	stamp, err := s.Cache.GetServiceListTimestamp(ctx)
+func (s *Impl) GetServices(ctx context.Context, ownerAliasFilter string, labelSelector string, at string, page types.PageRequest) (openapi.ServiceListDto, error) {
+	selector, err := util.ParseLabelSelector(labelSelector, s.Timestamp.Now())
+	if err != nil {
+		return openapi.ServiceListDto{}, err
+	}
+
+	pager, err := util.NewPager(page, serviceSortFields, s.Timestamp.Now())
+	if err != nil {
+		return openapi.ServiceListDto{}, err
+	}
+
+	source, commitHash, err := s.Updater.ListSource(ctx, at, pager.Snapshot())
+	if err != nil {
+		return openapi.ServiceListDto{}, err
+	}
+
+	stamp, err := source.GetServiceListTimestamp(ctx)
 	if err != nil {
 		return openapi.ServiceListDto{}, err
 	}
@@ -71,30 +99,143 @@ func (s *Impl) GetServices(ctx context.Context, ownerAliasFilter string) (openap
 		Services:  make(map[string]openapi.ServiceDto),
 		TimeStamp: stamp,
 	}
-	names, err := s.Cache.GetSortedServiceNames(ctx)
+	pager.ListedAt(stamp, commitHash)
+	names, err := source.GetSortedServiceNames(ctx)
 	if err != nil {
 		return openapi.ServiceListDto{}, err
 	}
-	for _, name := range names {
-		theService, err := s.GetService(ctx, name)
+	for _, name := range pager.Keys(names) {
+		if pager.Full() {
+			break
+		}
+		theService, err := source.GetService(ctx, name)
 		if err != nil {
 			// service not found errors are ok, the cache may have been changed concurrently, just drop the entry
 			if !apierrors.IsNotFoundError(err) {
 				return openapi.ServiceListDto{}, err
 			}
 		} else {
-			if ownerAliasFilter == "" || ownerAliasFilter == theService.Owner {
-				result.Services[name] = theService
+			if (ownerAliasFilter == "" || ownerAliasFilter == theService.Owner) && selector.Matches(theService.Labels, theService.Tags) {
+				if pager.Keeps() {
+					result.Services[name] = theService
+				}
+				pager.Add(name, serviceSortValue(pager, theService))
 			}
 		}
 	}
+
+	order, nextCursor := pager.Page()
+	result.Services, order, err = util.PageEntries(result.Services, order, func(name string) (openapi.ServiceDto, error) {
+		return source.GetService(ctx, name)
+	})
+	if err != nil {
+		return openapi.ServiceListDto{}, err
+	}
+	if page.IsActive() {
+		result.Order, result.NextCursor = order, nextCursor
+	}
 	return result, nil
 }
 
+func serviceSortValue(pager *util.Pager, theService openapi.ServiceDto) string {
+	if pager.SortField() == util.SortByOwner {
+		return theService.Owner
+	}
+	return theService.TimeStamp
+}
+
 func (s *Impl) GetService(ctx context.Context, serviceName string) (openapi.ServiceDto, error) {
 	return s.Cache.GetService(ctx, serviceName)
 }
 
+func (s *Impl) GetServiceAt(ctx context.Context, serviceName string, at string) (openapi.ServiceDto, error) {
+	if at == "" {
+		return s.Cache.GetService(ctx, serviceName)
+	}
+	return s.Updater.GetServiceAt(ctx, at, serviceName)
+}
+
+func (s *Impl) GetServicePromoters(ctx context.Context, serviceName string) (openapi.ServicePromotersDto, error) {
+	theService, err := s.Cache.GetService(ctx, serviceName)
+	if err != nil {
+		return openapi.ServicePromotersDto{}, err
+	}
+
+	promoters := make([]string, 0)
+	// an owner that is missing from the cache contributes no promoters
+	if owner, err := s.Cache.GetOwner(ctx, theService.Owner); err == nil {
+		promoters = append(promoters, owner.Promoters...)
+	}
+	promoters = append(promoters, s.CustomConfiguration.GlobalPromoters()...)
+
+	result := s.Owners.ExpandUserGroups(ctx, promoters)
+	sort.Strings(result)
+	return openapi.ServicePromotersDto{Promoters: result}, nil
+}
+
+func (s *Impl) IsServicePromoter(ctx context.Context, serviceName string, username string) (bool, error) {
+	promoters, err := s.GetServicePromoters(ctx, serviceName)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(promoters.Promoters, username), nil
+}
+
+func (s *Impl) GetServiceHistory(ctx context.Context, serviceName string, page types.PageRequest) (openapi.HistoryDto, error) {
+	stop, err := util.HistoryStop(page, s.Timestamp.Now())
+	if err != nil {
+		return openapi.HistoryDto{}, err
+	}
+	entries, err := s.Updater.GetServiceHistory(ctx, serviceName, stop)
+	if err != nil {
+		return openapi.HistoryDto{}, err
+	}
+	if len(entries) == 0 {
+		return openapi.HistoryDto{}, apierrors.NewNotFoundError("service.notfound", fmt.Sprintf("service %s not found", serviceName), nil, s.Timestamp.Now())
+	}
+	return util.PageHistory(entries, page, s.Timestamp.Now())
+}
+
+func (s *Impl) GetServiceDiff(ctx context.Context, serviceName string, from string, to string) (openapi.DiffDto, error) {
+	if from == "" {
+		return openapi.DiffDto{}, apierrors.NewBadRequestError("from.missing", "from is required, a (possibly abbreviated) commit hash or an RFC3339 timestamp", nil, s.Timestamp.Now())
+	}
+
+	before, err := s.serviceOrNil(ctx, serviceName, from)
+	if err != nil {
+		return openapi.DiffDto{}, err
+	}
+	after, err := s.serviceOrNil(ctx, serviceName, to)
+	if err != nil {
+		return openapi.DiffDto{}, err
+	}
+	if before == nil && after == nil {
+		return openapi.DiffDto{}, apierrors.NewNotFoundError("service.notfound", fmt.Sprintf("service %s not found", serviceName), nil, s.Timestamp.Now())
+	}
+
+	result := openapi.DiffDto{}
+	if before != nil {
+		result.FromCommitHash = before.CommitHash
+	}
+	if after != nil {
+		result.ToCommitHash = after.CommitHash
+	}
+	result.Operations, err = util.Diff(before, after)
+	return result, err
+}
+
+// serviceOrNil reads the service as of at, or currently if at is empty. nil means it did not exist.
+func (s *Impl) serviceOrNil(ctx context.Context, serviceName string, at string) (*openapi.ServiceDto, error) {
+	service, err := s.GetServiceAt(ctx, serviceName, at)
+	if err != nil {
+		if apierrors.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &service, nil
+}
+
 func (s *Impl) CreateService(ctx context.Context, serviceName string, serviceCreateDto openapi.ServiceCreateDto) (openapi.ServiceDto, error) {
 	serviceDto := s.mapServiceCreateDtoToServiceDto(serviceCreateDto)
 	ctx = context.WithValue(ctx, "configuration", s.CustomConfiguration)
@@ -132,6 +273,10 @@ func (s *Impl) CreateService(ctx context.Context, serviceName string, serviceCre
 			}
 		}
 
+		if err := s.Graph.ValidateDependencies(subCtx, serviceName, nil, serviceDto.Spec); err != nil {
+			return err
+		}
+
 		serviceWritten, err := s.Updater.WriteService(subCtx, serviceName, serviceDto)
 		if err != nil {
 			return err
@@ -144,6 +289,7 @@ func (s *Impl) CreateService(ctx context.Context, serviceName string, serviceCre
 }
 
 func (s *Impl) mapServiceCreateDtoToServiceDto(serviceCreateDto openapi.ServiceCreateDto) openapi.ServiceDto {
+	initialLifecycle := s.CustomConfiguration.ServiceLifecycleInitial()
 	return openapi.ServiceDto{
 		AlertTarget:     serviceCreateDto.AlertTarget,
 		JiraIssue:       serviceCreateDto.JiraIssue,
@@ -152,7 +298,7 @@ func (s *Impl) mapServiceCreateDtoToServiceDto(serviceCreateDto openapi.ServiceC
 		Repositories:    serviceCreateDto.Repositories,
 		Quicklinks:      serviceCreateDto.Quicklinks,
 		Description:     serviceCreateDto.Description,
-		Lifecycle:       &initialServiceLifecycle,
+		Lifecycle:       &initialLifecycle,
 		InternetExposed: serviceCreateDto.InternetExposed,
 		Spec:            serviceCreateDto.Spec,
 		Tags:            serviceCreateDto.Tags,
@@ -214,12 +360,23 @@ func (s *Impl) UpdateService(ctx context.Context, serviceName string, serviceDto
 			}
 		}
 
+		if err := s.Graph.ValidateDependencies(subCtx, serviceName, current.Spec, serviceDto.Spec); err != nil {
+			return err
+		}
+
 		if current.TimeStamp != serviceDto.TimeStamp || current.CommitHash != serviceDto.CommitHash {
 			result = current
 			s.Logging.Logger().Ctx(ctx).Info().Printf("service %v was concurrently updated", serviceName)
 			return apierrors.NewConflictErrorWithResponse("service.conflict.concurrentlyupdated", fmt.Sprintf("service %v was concurrently updated", serviceName), nil, result, s.Timestamp.Now())
 		}
 
+		// only a rename changes the previous names
+		serviceDto.PreviousNames = current.PreviousNames
+
+		if err := s.changeLifecycle(ctx, current, &serviceDto); err != nil {
+			return err
+		}
+
 		serviceWritten, err := s.Updater.WriteService(subCtx, serviceName, serviceDto)
 		if err != nil {
 			return err
@@ -258,6 +415,26 @@ func (s *Impl) validateExistingServiceDto(ctx context.Context, serviceName strin
 	return nil
 }
 
+func (s *Impl) PatchServiceDocument(ctx context.Context, serviceName string, patch types.PatchDocument) (openapi.ServiceDto, error) {
+	current, err := s.Cache.GetService(ctx, serviceName)
+	if err != nil {
+		return openapi.ServiceDto{}, err
+	}
+
+	// the patch must supply these, so concurrent updates are detected and the commit gets a jira issue, just like a PUT
+	current.CommitHash = ""
+	current.TimeStamp = ""
+	current.JiraIssue = ""
+
+	patched := openapi.ServiceDto{}
+	if err := util.ApplyPatch(current, patch, &patched, s.Timestamp.Now()); err != nil {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("patch of service %s failed: %s", serviceName, err.Error())
+		return openapi.ServiceDto{}, err
+	}
+
+	return s.UpdateService(ctx, serviceName, patched)
+}
+
 func (s *Impl) PatchService(ctx context.Context, serviceName string, servicePatchDto openapi.ServicePatchDto) (openapi.ServiceDto, error) {
 	result, err := s.GetService(ctx, serviceName)
 	if err != nil {
@@ -297,12 +474,20 @@ func (s *Impl) PatchService(ctx context.Context, serviceName string, servicePatc
 			}
 		}
 
+		if err := s.Graph.ValidateDependencies(subCtx, serviceName, current.Spec, serviceDto.Spec); err != nil {
+			return err
+		}
+
 		if current.TimeStamp != servicePatchDto.TimeStamp || current.CommitHash != servicePatchDto.CommitHash {
 			result = current
 			s.Logging.Logger().Ctx(ctx).Info().Printf("service %v was concurrently updated", serviceName)
 			return apierrors.NewConflictErrorWithResponse("service.conflict.concurrentlyupdated", fmt.Sprintf("service %v was concurrently updated", serviceName), nil, result, s.Timestamp.Now())
 		}
 
+		if err := s.changeLifecycle(ctx, current, &serviceDto); err != nil {
+			return err
+		}
+
 		serviceWritten, err := s.Updater.WriteService(subCtx, serviceName, serviceDto)
 		if err != nil {
 			return err
@@ -359,6 +544,7 @@ func patchService(current openapi.ServiceDto, patch openapi.ServicePatchDto) ope
 		Tags:            patchStringSlice(patch.Tags, current.Tags),
 		Labels:          patchMapSlice(patch.Labels, current.Labels),
 		PostPromotes:    patchPostPromotes(patch.PostPromotes, current.PostPromotes),
+		PreviousNames:   current.PreviousNames,
 	}
 }
 
@@ -481,6 +667,243 @@ func (s *Impl) DeleteService(ctx context.Context, serviceName string, deletionIn
 	})
 }
 
+func (s *Impl) RenameService(ctx context.Context, serviceName string, renameDto openapi.ServiceRenameDto) (openapi.ServiceDto, error) {
+	if err := s.validateServiceRenameDto(ctx, serviceName, renameDto); err != nil {
+		return openapi.ServiceDto{}, err
+	}
+
+	var result openapi.ServiceDto
+	err := s.Updater.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		err := s.Updater.PerformFullUpdate(subCtx)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.Cache.GetService(subCtx, serviceName)
+		if err != nil {
+			s.Logging.Logger().Ctx(ctx).Info().Printf("service %v not found", serviceName)
+			return apierrors.NewNotFoundError("service.notfound", fmt.Sprintf("service %s not found", serviceName), nil, s.Timestamp.Now())
+		}
+
+		existing, err := s.Cache.GetService(subCtx, renameDto.NewName)
+		if err == nil {
+			s.Logging.Logger().Ctx(ctx).Info().Printf("tried to rename service %v to existing service %v", serviceName, renameDto.NewName)
+			return apierrors.NewConflictErrorWithResponse("service.conflict.alreadyexists", fmt.Sprintf("service %s already exists - cannot rename", renameDto.NewName), nil, existing, s.Timestamp.Now())
+		}
+
+		result, err = s.Updater.RenameService(subCtx, serviceName, renameDto.NewName, renameDto.JiraIssue)
+		return err
+	})
+	return result, err
+}
+
+func (s *Impl) validateServiceRenameDto(ctx context.Context, serviceName string, dto openapi.ServiceRenameDto) error {
+	messages := make([]string, 0)
+	if dto.NewName == "" {
+		messages = append(messages, "field newName is mandatory")
+	} else if dto.NewName == serviceName {
+		messages = append(messages, "field newName must differ from the current name")
+	}
+	if dto.JiraIssue == "" {
+		messages = append(messages, "field jiraIssue is mandatory")
+	}
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("service rename values invalid: %s", details)
+		return apierrors.NewBadRequestError("service.invalid.values", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+func (s *Impl) ApplyTransactionOperation(ctx context.Context, state *service.TransactionState, operation openapi.TransactionOperationDto) error {
+	serviceName := operation.Name
+	current, exists := state.Services[serviceName]
+	ctx = context.WithValue(ctx, "configuration", s.CustomConfiguration)
+
+	switch operation.Operation {
+	case types.TransactionCreate:
+		serviceCreateDto := openapi.ServiceCreateDto{}
+		if err := util.DecodeTransactionBody(operation.Body, state.JiraIssue, &serviceCreateDto, s.Timestamp.Now()); err != nil {
+			return err
+		}
+		if err := s.validateNewServiceDto(ctx, serviceName, serviceCreateDto); err != nil {
+			return err
+		}
+		if exists {
+			s.Logging.Logger().Ctx(ctx).Info().Printf("service %v already exists", serviceName)
+			return apierrors.NewConflictError("service.conflict.alreadyexists", fmt.Sprintf("service %s already exists - cannot create", serviceName), nil, s.Timestamp.Now())
+		}
+		state.Services[serviceName] = s.mapServiceCreateDtoToServiceDto(serviceCreateDto)
+	case types.TransactionUpdate:
+		serviceDto := openapi.ServiceDto{}
+		if err := util.DecodeTransactionBody(operation.Body, state.JiraIssue, &serviceDto, s.Timestamp.Now()); err != nil {
+			return err
+		}
+		if err := s.validateExistingServiceDto(ctx, serviceName, serviceDto); err != nil {
+			return err
+		}
+		if err := s.checkTransactionService(ctx, serviceName, exists, current, serviceDto.TimeStamp, serviceDto.CommitHash); err != nil {
+			return err
+		}
+		// only a rename changes the previous names
+		serviceDto.PreviousNames = current.PreviousNames
+		if err := s.changeLifecycle(ctx, current, &serviceDto); err != nil {
+			return err
+		}
+		s.writeTransactionService(state, serviceName, current, serviceDto)
+	case types.TransactionPatch:
+		servicePatchDto := openapi.ServicePatchDto{}
+		if err := util.DecodeTransactionBody(operation.Body, state.JiraIssue, &servicePatchDto, s.Timestamp.Now()); err != nil {
+			return err
+		}
+		if err := s.checkTransactionService(ctx, serviceName, exists, current, servicePatchDto.TimeStamp, servicePatchDto.CommitHash); err != nil {
+			return err
+		}
+		if err := s.validateServicePatchDto(ctx, serviceName, servicePatchDto, current); err != nil {
+			return err
+		}
+		serviceDto := patchService(current, servicePatchDto)
+		if err := s.changeLifecycle(ctx, current, &serviceDto); err != nil {
+			return err
+		}
+		s.writeTransactionService(state, serviceName, current, serviceDto)
+	case types.TransactionDelete:
+		if !exists {
+			s.Logging.Logger().Ctx(ctx).Info().Printf("service %v not found", serviceName)
+			return apierrors.NewNotFoundError("service.notfound", fmt.Sprintf("service %s not found", serviceName), nil, s.Timestamp.Now())
+		}
+		delete(state.Services, serviceName)
+	default:
+		return apierrors.NewBadRequestError("transaction.invalid.values", fmt.Sprintf("validation error: unknown operation %s", operation.Operation), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+// checkTransactionService verifies the service exists in the transaction state and was not concurrently updated.
+func (s *Impl) checkTransactionService(ctx context.Context, serviceName string, exists bool, current openapi.ServiceDto, timeStamp string, commitHash string) error {
+	if !exists {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("service %v not found", serviceName)
+		return apierrors.NewNotFoundError("service.notfound", fmt.Sprintf("service %s not found", serviceName), nil, s.Timestamp.Now())
+	}
+	if current.TimeStamp != timeStamp || current.CommitHash != commitHash {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("service %v was concurrently updated", serviceName)
+		return apierrors.NewConflictErrorWithResponse("service.conflict.concurrentlyupdated", fmt.Sprintf("service %v was concurrently updated", serviceName), nil, current, s.Timestamp.Now())
+	}
+	return nil
+}
+
+// writeTransactionService puts the service into the transaction state. Like a single update, a service that
+// changes owners takes the repositories it references along.
+func (s *Impl) writeTransactionService(state *service.TransactionState, serviceName string, current openapi.ServiceDto, serviceDto openapi.ServiceDto) {
+	if current.Owner != serviceDto.Owner {
+		for _, repoKey := range serviceDto.Repositories {
+			if repo, ok := state.Repositories[repoKey]; ok && repo.Owner == current.Owner {
+				repo.Owner = serviceDto.Owner
+				state.Repositories[repoKey] = repo
+			}
+		}
+	}
+	state.Services[serviceName] = serviceDto
+}
+
+func (s *Impl) GetDeletedServices(ctx context.Context) (openapi.DeletedListDto, error) {
+	deleted, err := s.Updater.GetDeletions(ctx, types.DeletedServices)
+	if err != nil {
+		return openapi.DeletedListDto{}, err
+	}
+	return openapi.DeletedListDto{Deleted: deleted}, nil
+}
+
+func (s *Impl) RestoreService(ctx context.Context, serviceName string, restoreDto openapi.RestoreDto) (openapi.ServiceDto, error) {
+	if err := s.validateRestoreDto(ctx, restoreDto); err != nil {
+		return openapi.ServiceDto{}, err
+	}
+	ctx = context.WithValue(ctx, "configuration", s.CustomConfiguration)
+
+	var result openapi.ServiceDto
+	err := s.Updater.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		err := s.Updater.PerformFullUpdate(subCtx)
+		if err != nil {
+			return err
+		}
+
+		current, err := s.Cache.GetService(subCtx, serviceName)
+		if err == nil {
+			result = current
+			s.Logging.Logger().Ctx(ctx).Info().Printf("service %v already exists", serviceName)
+			return apierrors.NewConflictErrorWithResponse("service.conflict.alreadyexists", fmt.Sprintf("service %s already exists - cannot restore", serviceName), nil, result, s.Timestamp.Now())
+		}
+
+		serviceDto, err := s.Updater.GetDeletedService(subCtx, serviceName)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				s.Logging.Logger().Ctx(ctx).Info().Printf("no deleted service %v", serviceName)
+				return apierrors.NewNotFoundError("service.notfound", fmt.Sprintf("no deleted service %s found", serviceName), err, s.Timestamp.Now())
+			}
+			return err
+		}
+		serviceDto.JiraIssue = restoreDto.JiraIssue
+
+		if err := s.validateRestoredServiceDto(ctx, serviceName, serviceDto); err != nil {
+			return err
+		}
+
+		_, err = s.Cache.GetOwner(subCtx, serviceDto.Owner)
+		if err != nil {
+			details := fmt.Sprintf("no such owner: %s", serviceDto.Owner)
+			s.Logging.Logger().Ctx(ctx).Info().Printf(details)
+			return apierrors.NewBadRequestError("service.invalid.missing.owner", details, err, s.Timestamp.Now())
+		}
+
+		for _, repoKey := range serviceDto.Repositories {
+			_, err = s.Cache.GetRepository(subCtx, repoKey)
+			if err != nil {
+				s.Logging.Logger().Ctx(ctx).Info().Printf("service values invalid: %s", repoKey)
+				return apierrors.NewBadRequestError("service.invalid.missing.repository", "validation error: you referenced a repository that does not exist: no such instance: "+repoKey, nil, s.Timestamp.Now())
+			}
+		}
+
+		if err := s.Graph.ValidateDependencies(subCtx, serviceName, nil, serviceDto.Spec); err != nil {
+			return err
+		}
+
+		serviceWritten, err := s.Updater.WriteService(subCtx, serviceName, serviceDto)
+		if err != nil {
+			return err
+		}
+
+		result = serviceWritten
+		return nil
+	})
+	return result, err
+}
+
+func (s *Impl) validateRestoredServiceDto(ctx context.Context, serviceName string, dto openapi.ServiceDto) error {
+	messages := make([]string, 0)
+
+	messages = validateOwner(messages, dto.Owner)
+	messages = validateDescription(messages, dto.Description)
+	messages = s.validateRepositories(ctx, messages, serviceName, dto.Repositories)
+	messages = s.validateAlertTarget(messages, dto.AlertTarget)
+	messages = validateOperationType(messages, dto.OperationType)
+
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("restored service values invalid: %s", details)
+		return apierrors.NewBadRequestError("service.invalid.values", fmt.Sprintf("validation error: the last version of the service is no longer valid: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+func (s *Impl) GetServiceRedirect(ctx context.Context, serviceName string) (string, bool) {
+	redirects, err := s.Cache.GetRedirects(ctx)
+	if err != nil {
+		return "", false
+	}
+	newName, ok := redirects.Services[serviceName]
+	return newName, ok
+}
+
 func (s *Impl) validateDeletionDto(ctx context.Context, deletionInfo openapi.DeletionDto) error {
 	messages := make([]string, 0)
 	if deletionInfo.JiraIssue == "" {
@@ -494,6 +917,19 @@ func (s *Impl) validateDeletionDto(ctx context.Context, deletionInfo openapi.Del
 	return nil
 }
 
+func (s *Impl) validateRestoreDto(ctx context.Context, restoreDto openapi.RestoreDto) error {
+	messages := make([]string, 0)
+	if restoreDto.JiraIssue == "" {
+		messages = append(messages, "field jiraIssue is mandatory for restoring")
+	}
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("restore values invalid: %s", details)
+		return apierrors.NewBadRequestError("restore.invalid.values", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
 func (s *Impl) addAllProductOwners(ctx context.Context, resultSet map[string]bool) error {
 	names, err := s.Cache.GetSortedOwnerAliases(ctx)
 	if err != nil {
@@ -560,6 +996,73 @@ func validateDescription(messages []string, description *string) []string {
 	return messages
 }
 
+// changeLifecycle checks that the lifecycle of a service may change from current to candidate, and records
+// the change in the lifecycle transitions of candidate.
+//
+// Leaving out the lifecycle keeps the current one. A service without a lifecycle may change to any known lifecycle,
+// and setting this first lifecycle is not recorded. A service whose lifecycle is no longer configured cannot change
+// its lifecycle until it is configured again, because we do not know where it may go.
+func (s *Impl) changeLifecycle(ctx context.Context, current openapi.ServiceDto, candidate *openapi.ServiceDto) error {
+	// only a lifecycle change adds transitions
+	candidate.LifecycleTransitions = current.LifecycleTransitions
+
+	if candidate.Lifecycle == nil {
+		candidate.Lifecycle = current.Lifecycle
+		return nil
+	}
+	to := *candidate.Lifecycle
+	from := ""
+	if current.Lifecycle != nil {
+		from = *current.Lifecycle
+	}
+	if from == to {
+		return nil
+	}
+
+	transitions := s.CustomConfiguration.ServiceLifecycleTransitions()
+	if _, ok := transitions[to]; !ok {
+		details := fmt.Sprintf("validation error: field lifecycle must be one of %s", strings.Join(sortedLifecycles(transitions), ", "))
+		s.Logging.Logger().Ctx(ctx).Info().Printf("service lifecycle invalid: %s", details)
+		return apierrors.NewBadRequestError("service.invalid.lifecycle", details, nil, s.Timestamp.Now())
+	}
+	allowed, known := transitions[from]
+	if from != "" && !known {
+		// the transitions from a lifecycle that is no longer configured are unknown, adding it to the configuration
+		// decides where services in it may go
+		details := fmt.Sprintf("validation error: lifecycle cannot change from %s, which is not configured", from)
+		s.Logging.Logger().Ctx(ctx).Info().Printf("service lifecycle invalid: %s", details)
+		return apierrors.NewBadRequestError("service.invalid.lifecycle", details, nil, s.Timestamp.Now())
+	}
+	if known && !slices.Contains(allowed, to) {
+		allowedList := "none"
+		if len(allowed) > 0 {
+			allowedList = strings.Join(allowed, ", ")
+		}
+		details := fmt.Sprintf("validation error: lifecycle cannot change from %s to %s, allowed are: %s", from, to, allowedList)
+		s.Logging.Logger().Ctx(ctx).Info().Printf("service lifecycle invalid: %s", details)
+		return apierrors.NewBadRequestError("service.invalid.lifecycle", details, nil, s.Timestamp.Now())
+	}
+
+	if from != "" {
+		candidate.LifecycleTransitions = append(slices.Clone(current.LifecycleTransitions), openapi.ServiceLifecycleTransitionDto{
+			From:      from,
+			To:        to,
+			TimeStamp: s.Timestamp.Now().UTC().Format(time.RFC3339),
+			JiraIssue: candidate.JiraIssue,
+		})
+	}
+	return nil
+}
+
+func sortedLifecycles(transitions map[string][]string) []string {
+	result := make([]string, 0, len(transitions))
+	for lifecycle := range transitions {
+		result = append(result, lifecycle)
+	}
+	sort.Strings(result)
+	return result
+}
+
 func (s *Impl) validAlertTarget(candidate string) bool {
 	return s.CustomConfiguration.AlertTargetRegex().MatchString(candidate)
 }
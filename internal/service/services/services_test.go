@@ -6,10 +6,13 @@ import (
 	"time"
 
 	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/repository/cache"
+	"github.com/Interhyp/metadata-service/internal/service/owners"
 	"github.com/Interhyp/metadata-service/test/mock/configmock"
 
 	"github.com/Interhyp/go-backend-service-common/api/apierrors"
 	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/go-backend-service-common/repository/logging"
 	"github.com/Interhyp/go-backend-service-common/repository/timestamp"
 	auloggingapi "github.com/StephanHCB/go-autumn-logging/api"
 	"github.com/stretchr/testify/require"
@@ -369,3 +372,99 @@ func TestValidate_OperationType(t *testing.T) {
 
 	tstValidationTestcaseAllOps(t, expectedMessage, data, create, patch)
 }
+
+func TestGetServicePromoters(t *testing.T) {
+	docs.Description("the promoters of a service are the expanded promoters of its owner")
+	ctx := context.Background()
+	theLogging := logging.New().(*logging.LoggingImpl)
+	theCache := cache.NewInMemory(nil, nil, theLogging, timestamp.NewNoAcorn(fakeNow))
+	require.Nil(t, theCache.PutOwner(ctx, "unicorns", openapi.OwnerDto{
+		Promoters: []string{"zoe", "@unicorns.leads", "@dragons.missing"},
+		Groups:    map[string][]string{"leads": {"ulli", "zoe"}},
+	}))
+	require.Nil(t, theCache.PutService(ctx, "unicorn-finder", openapi.ServiceDto{Owner: "unicorns"}))
+	require.Nil(t, theCache.PutService(ctx, "orphan", openapi.ServiceDto{Owner: "gone"}))
+	impl := &Impl{
+		CustomConfiguration: &configmock.MockConfig{},
+		Logging:             theLogging,
+		Timestamp:           timestamp.NewNoAcorn(fakeNow),
+		Cache:               theCache,
+		Owners:              &owners.Impl{Cache: theCache},
+	}
+
+	promoters, err := impl.GetServicePromoters(ctx, "unicorn-finder")
+	require.Nil(t, err)
+	require.Equal(t, []string{"ulli", "zoe"}, promoters.Promoters)
+
+	promoters, err = impl.GetServicePromoters(ctx, "orphan")
+	require.Nil(t, err)
+	require.Equal(t, []string{}, promoters.Promoters)
+
+	mayPromote, err := impl.IsServicePromoter(ctx, "unicorn-finder", "ulli")
+	require.Nil(t, err)
+	require.True(t, mayPromote)
+	mayPromote, err = impl.IsServicePromoter(ctx, "unicorn-finder", "ingo")
+	require.Nil(t, err)
+	require.False(t, mayPromote)
+
+	_, err = impl.GetServicePromoters(ctx, "unknown")
+	require.True(t, apierrors.IsNotFoundError(err))
+}
+
+func TestChangeLifecycle(t *testing.T) {
+	docs.Description("lifecycle changes follow the configured transitions and are recorded with timestamp and jira issue")
+	ctx := context.Background()
+	impl := &Impl{
+		CustomConfiguration: &configmock.MockConfig{},
+		Logging:             logging.New().(*logging.LoggingImpl),
+		Timestamp:           timestamp.NewNoAcorn(fakeNow),
+	}
+
+	current := tstCurrent()
+	candidate := tstCurrent()
+	candidate.Lifecycle = p("operational")
+	candidate.JiraIssue = "ISSUE-1"
+	require.Nil(t, impl.changeLifecycle(ctx, current, &candidate))
+	require.Equal(t, []openapi.ServiceLifecycleTransitionDto{{
+		From:      "experimental",
+		To:        "operational",
+		TimeStamp: "2022-11-06T18:14:10Z",
+		JiraIssue: "ISSUE-1",
+	}}, candidate.LifecycleTransitions)
+
+	unchanged := tstCurrent()
+	unchanged.Lifecycle = nil
+	unchanged.LifecycleTransitions = []openapi.ServiceLifecycleTransitionDto{{From: "made", To: "up"}}
+	require.Nil(t, impl.changeLifecycle(ctx, candidate, &unchanged))
+	require.Equal(t, "operational", *unchanged.Lifecycle, "leaving out the lifecycle keeps it")
+	require.Equal(t, candidate.LifecycleTransitions, unchanged.LifecycleTransitions, "transitions cannot be set by clients")
+
+	backwards := tstCurrent()
+	backwards.Lifecycle = p("experimental")
+	err := impl.changeLifecycle(ctx, candidate, &backwards)
+	require.True(t, apierrors.IsBadRequestError(err))
+	require.Equal(t, "validation error: lifecycle cannot change from operational to experimental, allowed are: deprecated", *err.(apierrors.AnnotatedError).ApiError().Details)
+
+	unknown := tstCurrent()
+	unknown.Lifecycle = p("retired")
+	err = impl.changeLifecycle(ctx, current, &unknown)
+	require.True(t, apierrors.IsBadRequestError(err))
+	require.Equal(t, "validation error: field lifecycle must be one of decommissionable, deprecated, experimental, operational", *err.(apierrors.AnnotatedError).ApiError().Details)
+
+	first := tstCurrent()
+	first.Lifecycle = nil
+	initial := tstCurrent()
+	initial.Lifecycle = p("deprecated")
+	require.Nil(t, impl.changeLifecycle(ctx, first, &initial), "services without a lifecycle may get any known one")
+	require.Nil(t, initial.LifecycleTransitions)
+
+	legacy := tstCurrent()
+	legacy.Lifecycle = p("retired")
+	err = impl.changeLifecycle(ctx, legacy, &initial)
+	require.True(t, apierrors.IsBadRequestError(err))
+	require.Equal(t, "validation error: lifecycle cannot change from retired, which is not configured", *err.(apierrors.AnnotatedError).ApiError().Details)
+
+	kept := tstCurrent()
+	kept.Lifecycle = p("retired")
+	require.Nil(t, impl.changeLifecycle(ctx, legacy, &kept), "services may keep a lifecycle that is not configured")
+}
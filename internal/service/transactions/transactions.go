@@ -0,0 +1,396 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"sort"
+	"strings"
+
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/config"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/service/util"
+	"github.com/Interhyp/metadata-service/internal/types"
+	auzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
+)
+
+var validOperations = []string{types.TransactionCreate, types.TransactionUpdate, types.TransactionPatch, types.TransactionDelete}
+
+var validKinds = []string{types.TransactionOwner, types.TransactionService, types.TransactionRepository}
+
+type Impl struct {
+	Configuration       librepo.Configuration
+	CustomConfiguration config.CustomConfiguration
+	Logging             librepo.Logging
+	Timestamp           librepo.Timestamp
+	Cache               repository.Cache
+	Updater             service.Updater
+	Owners              service.Owners
+	Services            service.Services
+	Repositories        service.Repositories
+	Graph               service.Graph
+}
+
+func New(
+	configuration librepo.Configuration,
+	customConfig config.CustomConfiguration,
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+	cache repository.Cache,
+	updater service.Updater,
+	owners service.Owners,
+	services service.Services,
+	repositories service.Repositories,
+	graph service.Graph,
+) service.Transactions {
+	return &Impl{
+		Configuration:       configuration,
+		CustomConfiguration: customConfig,
+		Logging:             logging,
+		Timestamp:           timestamp,
+		Cache:               cache,
+		Updater:             updater,
+		Owners:              owners,
+		Services:            services,
+		Repositories:        repositories,
+		Graph:               graph,
+	}
+}
+
+func (s *Impl) IsTransactions() bool {
+	return true
+}
+
+func (s *Impl) Setup() error {
+	ctx := auzerolog.AddLoggerToCtx(context.Background())
+
+	// nothing to do
+
+	s.Logging.Logger().Ctx(ctx).Info().Print("successfully set up transactions business component")
+	return nil
+}
+
+func (s *Impl) ApplyTransaction(ctx context.Context, transactionDto openapi.TransactionDto) (openapi.TransactionResultDto, error) {
+	if err := s.validateTransactionDto(ctx, transactionDto); err != nil {
+		return openapi.TransactionResultDto{}, err
+	}
+
+	result := openapi.TransactionResultDto{}
+	err := s.Updater.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		err := s.Updater.PerformFullUpdate(subCtx)
+		if err != nil {
+			return err
+		}
+
+		initial, err := s.loadState(subCtx, transactionDto.JiraIssue)
+		if err != nil {
+			return err
+		}
+		state := copyState(initial)
+
+		for i, operation := range transactionDto.Operations {
+			if err := s.applyOperation(subCtx, &state, operation); err != nil {
+				s.Logging.Logger().Ctx(ctx).Info().Printf("transaction operation %d failed: %s", i, err.Error())
+				return withOperationIndex(err, i)
+			}
+		}
+
+		changes := changesBetween(initial, state)
+		if err := s.validateReferences(subCtx, initial, state, changes); err != nil {
+			return err
+		}
+
+		description := fmt.Sprintf("apply transaction changing %d owners, %d services and %d repositories", len(changes.Owners), len(changes.Services), len(changes.Repositories))
+		written, err := s.Updater.WriteTransaction(subCtx, changes, description, transactionDto.JiraIssue)
+		if err != nil {
+			return err
+		}
+
+		result = written
+		return nil
+	})
+	return result, err
+}
+
+func (s *Impl) validateTransactionDto(ctx context.Context, dto openapi.TransactionDto) error {
+	messages := make([]string, 0)
+	if dto.JiraIssue == "" {
+		messages = append(messages, "field jiraIssue is mandatory")
+	}
+	if len(dto.Operations) == 0 {
+		messages = append(messages, "field operations must contain at least one operation")
+	}
+	for i, operation := range dto.Operations {
+		if !contains(validOperations, operation.Operation) {
+			messages = append(messages, fmt.Sprintf("operation %d: field operation must be one of %s", i, strings.Join(validOperations, ", ")))
+		}
+		if !contains(validKinds, operation.Kind) {
+			messages = append(messages, fmt.Sprintf("operation %d: field kind must be one of %s", i, strings.Join(validKinds, ", ")))
+		}
+		if operation.Name == "" {
+			messages = append(messages, fmt.Sprintf("operation %d: field name is mandatory", i))
+		}
+		if operation.Operation != types.TransactionDelete && operation.Body == nil {
+			messages = append(messages, fmt.Sprintf("operation %d: field body is mandatory for %s", i, operation.Operation))
+		}
+	}
+
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("transaction values invalid: %s", details)
+		return apierrors.NewBadRequestError("transaction.invalid.values", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+// loadState copies all cached owners, services and repositories.
+func (s *Impl) loadState(ctx context.Context, jiraIssue string) (service.TransactionState, error) {
+	state := service.TransactionState{
+		JiraIssue:    jiraIssue,
+		Owners:       make(map[string]openapi.OwnerDto),
+		Services:     make(map[string]openapi.ServiceDto),
+		Repositories: make(map[string]openapi.RepositoryDto),
+	}
+
+	ownerAliases, err := s.Cache.GetSortedOwnerAliases(ctx)
+	if err != nil {
+		return state, err
+	}
+	for _, ownerAlias := range ownerAliases {
+		if owner, err := s.Cache.GetOwner(ctx, ownerAlias); err == nil {
+			state.Owners[ownerAlias] = owner
+		}
+	}
+
+	serviceNames, err := s.Cache.GetSortedServiceNames(ctx)
+	if err != nil {
+		return state, err
+	}
+	for _, serviceName := range serviceNames {
+		if theService, err := s.Cache.GetService(ctx, serviceName); err == nil {
+			state.Services[serviceName] = theService
+		}
+	}
+
+	repoKeys, err := s.Cache.GetSortedRepositoryKeys(ctx)
+	if err != nil {
+		return state, err
+	}
+	for _, repoKey := range repoKeys {
+		if repo, err := s.Cache.GetRepository(ctx, repoKey); err == nil {
+			state.Repositories[repoKey] = repo
+		}
+	}
+	return state, nil
+}
+
+// copyState copies the maps of the state, operations replace the entities in them but never modify them.
+func copyState(state service.TransactionState) service.TransactionState {
+	return service.TransactionState{
+		JiraIssue:    state.JiraIssue,
+		Owners:       maps.Clone(state.Owners),
+		Services:     maps.Clone(state.Services),
+		Repositories: maps.Clone(state.Repositories),
+	}
+}
+
+func (s *Impl) applyOperation(ctx context.Context, state *service.TransactionState, operation openapi.TransactionOperationDto) error {
+	switch operation.Kind {
+	case types.TransactionOwner:
+		if operation.Operation == types.TransactionCreate {
+			if err := util.ValidOwnerAlias(ctx, s.CustomConfiguration, operation.Name, s.Timestamp.Now()); err != nil {
+				return err
+			}
+		}
+		return s.Owners.ApplyTransactionOperation(ctx, state, operation)
+	case types.TransactionService:
+		if operation.Operation == types.TransactionCreate {
+			if err := util.ValidServiceName(ctx, s.CustomConfiguration, operation.Name, s.Timestamp.Now()); err != nil {
+				return err
+			}
+		}
+		return s.Services.ApplyTransactionOperation(ctx, state, operation)
+	default:
+		return s.Repositories.ApplyTransactionOperation(ctx, state, operation)
+	}
+}
+
+// validateReferences checks the references between the entities the transaction results in.
+//
+// Like for the single entity operations, only the changed entities and those referencing them are checked, so
+// existing problems do not block unrelated changes.
+func (s *Impl) validateReferences(ctx context.Context, initial service.TransactionState, state service.TransactionState, changes service.TransactionChanges) error {
+	messages := make([]string, 0)
+
+	for _, ownerAlias := range sortedKeys(changes.Owners) {
+		if changes.Owners[ownerAlias] == nil {
+			if owned := ownedBy(state, ownerAlias); len(owned) > 0 {
+				messages = append(messages, fmt.Sprintf("owner %s cannot be deleted, it still has %s", ownerAlias, strings.Join(owned, ", ")))
+			}
+		}
+	}
+
+	groupsOf := func(ownerAlias string) (map[string][]string, bool) {
+		owner, ok := state.Owners[ownerAlias]
+		return owner.Groups, ok
+	}
+
+	for _, ownerAlias := range sortedKeys(changes.Owners) {
+		if owner := changes.Owners[ownerAlias]; owner != nil {
+			for _, message := range util.AddedGroupReferenceProblems(util.OwnerUserLists(initial.Owners[ownerAlias]), util.OwnerUserLists(*owner), groupsOf) {
+				messages = append(messages, fmt.Sprintf("owner %s: %s", ownerAlias, message))
+			}
+		}
+	}
+
+	for _, repoKey := range sortedKeys(changes.Repositories) {
+		if repo := changes.Repositories[repoKey]; repo != nil {
+			if _, ok := state.Owners[repo.Owner]; !ok {
+				messages = append(messages, fmt.Sprintf("repository %s: no such owner: %s", repoKey, repo.Owner))
+			}
+			for _, message := range util.AddedGroupReferenceProblems(util.RepositoryUserLists(initial.Repositories[repoKey]), util.RepositoryUserLists(*repo), groupsOf) {
+				messages = append(messages, fmt.Sprintf("repository %s: %s", repoKey, message))
+			}
+		}
+	}
+
+	for _, serviceName := range sortedKeys(state.Services) {
+		theService := state.Services[serviceName]
+		_, changed := changes.Services[serviceName]
+		if !changed && !referencesAny(theService, changes.Repositories) {
+			continue
+		}
+
+		if _, ok := state.Owners[theService.Owner]; !ok {
+			messages = append(messages, fmt.Sprintf("service %s: no such owner: %s", serviceName, theService.Owner))
+		}
+		for _, repoKey := range theService.Repositories {
+			repo, ok := state.Repositories[repoKey]
+			if !ok {
+				messages = append(messages, fmt.Sprintf("service %s: you referenced a repository that does not exist: no such instance: %s", serviceName, repoKey))
+			} else if repo.Owner != theService.Owner {
+				messages = append(messages, fmt.Sprintf("service %s: referenced repository %s belongs to owner %s, not %s", serviceName, repoKey, repo.Owner, theService.Owner))
+			}
+		}
+
+		if changed {
+			var currentSpec *openapi.ServiceSpecDto
+			if current, ok := initial.Services[serviceName]; ok {
+				currentSpec = current.Spec
+			}
+			if err := s.Graph.ValidateDependenciesIn(ctx, state.Services, serviceName, currentSpec, theService.Spec); err != nil {
+				messages = append(messages, fmt.Sprintf("service %s: %s", serviceName, strings.TrimPrefix(details(err), "validation error: ")))
+			}
+		}
+	}
+
+	if len(messages) > 0 {
+		details := strings.Join(messages, ", ")
+		s.Logging.Logger().Ctx(ctx).Info().Printf("transaction references invalid: %s", details)
+		return apierrors.NewBadRequestError("transaction.invalid.references", fmt.Sprintf("validation error: %s", details), nil, s.Timestamp.Now())
+	}
+	return nil
+}
+
+// changesBetween lists the entities that differ between the initial and the resulting state of a transaction.
+func changesBetween(initial service.TransactionState, state service.TransactionState) service.TransactionChanges {
+	return service.TransactionChanges{
+		Owners: changedEntities(initial.Owners, state.Owners, func(owner openapi.OwnerDto) openapi.OwnerDto {
+			owner.TimeStamp, owner.CommitHash, owner.JiraIssue = "", "", ""
+			return owner
+		}),
+		Services: changedEntities(initial.Services, state.Services, func(theService openapi.ServiceDto) openapi.ServiceDto {
+			theService.TimeStamp, theService.CommitHash, theService.JiraIssue = "", "", ""
+			return theService
+		}),
+		Repositories: changedEntities(initial.Repositories, state.Repositories, func(repo openapi.RepositoryDto) openapi.RepositoryDto {
+			repo.TimeStamp, repo.CommitHash, repo.JiraIssue = "", "", ""
+			repo.Type = nil
+			return repo
+		}),
+	}
+}
+
+// changedEntities compares the entities without the fields that are not written to the files.
+func changedEntities[T any](initial map[string]T, state map[string]T, written func(T) T) map[string]*T {
+	result := make(map[string]*T)
+	for name, before := range initial {
+		if _, ok := state[name]; !ok {
+			result[name] = nil
+		} else if after := state[name]; !reflect.DeepEqual(written(before), written(after)) {
+			result[name] = &after
+		}
+	}
+	for name, after := range state {
+		if _, ok := initial[name]; !ok {
+			entity := after
+			result[name] = &entity
+		}
+	}
+	return result
+}
+
+func ownedBy(state service.TransactionState, ownerAlias string) []string {
+	result := make([]string, 0)
+	for _, serviceName := range sortedKeys(state.Services) {
+		if state.Services[serviceName].Owner == ownerAlias {
+			result = append(result, "service "+serviceName)
+		}
+	}
+	for _, repoKey := range sortedKeys(state.Repositories) {
+		if state.Repositories[repoKey].Owner == ownerAlias {
+			result = append(result, "repository "+repoKey)
+		}
+	}
+	return result
+}
+
+func referencesAny(theService openapi.ServiceDto, repositories map[string]*openapi.RepositoryDto) bool {
+	for _, repoKey := range theService.Repositories {
+		if _, ok := repositories[repoKey]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// withOperationIndex prefixes the error details with the index of the operation that failed.
+func withOperationIndex(err error, index int) error {
+	annotated, ok := err.(*apierrors.AnnotatedErrorImpl)
+	if !ok {
+		return err
+	}
+	prefixed := fmt.Sprintf("operation %d: %s", index, details(annotated))
+	annotated.VApiError.Details = &prefixed
+	return annotated
+}
+
+func details(err error) string {
+	if annotated, ok := err.(*apierrors.AnnotatedErrorImpl); ok && annotated.VApiError.Details != nil {
+		return *annotated.VApiError.Details
+	}
+	return err.Error()
+}
+
+func contains(values []string, candidate string) bool {
+	for _, value := range values {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,47 @@
+package updater
+
+import (
+	"context"
+
+	"github.com/Interhyp/metadata-service/api"
+)
+
+func (s *Impl) GetDeletions(ctx context.Context, kind string) ([]openapi.DeletedEntityDto, error) {
+	var result []openapi.DeletedEntityDto
+	err := s.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		var err error
+		result, err = s.Mapper.GetDeletions(subCtx, kind)
+		return err
+	})
+	return result, err
+}
+
+func (s *Impl) GetDeletedOwner(ctx context.Context, ownerAlias string) (openapi.OwnerDto, error) {
+	var result openapi.OwnerDto
+	err := s.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		var err error
+		result, err = s.Mapper.GetDeletedOwner(subCtx, ownerAlias)
+		return err
+	})
+	return result, err
+}
+
+func (s *Impl) GetDeletedService(ctx context.Context, serviceName string) (openapi.ServiceDto, error) {
+	var result openapi.ServiceDto
+	err := s.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		var err error
+		result, err = s.Mapper.GetDeletedService(subCtx, serviceName)
+		return err
+	})
+	return result, err
+}
+
+func (s *Impl) GetDeletedRepository(ctx context.Context, key string) (openapi.RepositoryDto, error) {
+	var result openapi.RepositoryDto
+	err := s.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		var err error
+		result, err = s.Mapper.GetDeletedRepository(subCtx, key)
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,19 @@
+package updater
+
+import (
+	"context"
+
+	"github.com/Interhyp/metadata-service/api"
+)
+
+func (s *Impl) GetOwnerHistory(ctx context.Context, ownerAlias string, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error) {
+	return s.Mapper.GetOwnerHistory(ctx, ownerAlias, stop)
+}
+
+func (s *Impl) GetServiceHistory(ctx context.Context, serviceName string, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error) {
+	return s.Mapper.GetServiceHistory(ctx, serviceName, stop)
+}
+
+func (s *Impl) GetRepositoryHistory(ctx context.Context, key string, stop func(commitHash string, timeStamp string) bool) ([]openapi.HistoryEntryDto, error) {
+	return s.Mapper.GetRepositoryHistory(ctx, key, stop)
+}
@@ -14,6 +14,11 @@ import (
 // --- business logic ---
 
 func (s *Impl) WriteOwner(ctx context.Context, ownerAlias string, owner openapi.OwnerDto) (openapi.OwnerDto, error) {
+	if types.IsDryRun(ctx) {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("dry run, not writing owner %s", ownerAlias)
+		return owner, nil
+	}
+
 	result := owner
 	err := s.WithMetadataLock(ctx, func(subCtx context.Context) error {
 		ownerWritten, err := s.Mapper.WriteOwner(subCtx, ownerAlias, owner)
@@ -44,6 +49,11 @@ func (s *Impl) WriteOwner(ctx context.Context, ownerAlias string, owner openapi.
 }
 
 func (s *Impl) DeleteOwner(ctx context.Context, ownerAlias string, deletionInfo openapi.DeletionDto) error {
+	if types.IsDryRun(ctx) {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("dry run, not deleting owner %s", ownerAlias)
+		return nil
+	}
+
 	return s.WithMetadataLock(ctx, func(subCtx context.Context) error {
 		ownerWritten, err := s.Mapper.DeleteOwner(subCtx, ownerAlias, deletionInfo.JiraIssue)
 		if err != nil {
@@ -69,6 +79,89 @@ func (s *Impl) DeleteOwner(ctx context.Context, ownerAlias string, deletionInfo
 	})
 }
 
+func (s *Impl) RenameOwner(ctx context.Context, oldAlias string, newAlias string, jiraIssue string) (openapi.OwnerDto, error) {
+	if types.IsDryRun(ctx) {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("dry run, not renaming owner %s to %s", oldAlias, newAlias)
+		owner, err := s.Cache.GetOwner(ctx, oldAlias)
+		owner.JiraIssue = jiraIssue
+		return owner, err
+	}
+
+	var result openapi.OwnerDto
+	err := s.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		ownerWritten, affected, err := s.Mapper.RenameOwner(subCtx, oldAlias, newAlias, jiraIssue)
+		if err != nil {
+			if githookerror.Is(err) {
+				return s.httpErrorFromHook(err, jiraIssue)
+			}
+			return err
+		}
+		result = ownerWritten
+
+		s.fireAndForgetKafkaNotification(subCtx, repository.UpdateEvent{
+			Affected:   affected,
+			TimeStamp:  ownerWritten.TimeStamp,
+			CommitHash: ownerWritten.CommitHash,
+		})
+
+		// cache updates, everything may have changed
+		if err := s.updateOwners(subCtx); err != nil {
+			return err
+		}
+		if err := s.updateServices(subCtx); err != nil {
+			return err
+		}
+		if err := s.updateRepositories(subCtx); err != nil {
+			return err
+		}
+		s.updateRedirects(subCtx)
+		return nil
+	})
+	return result, err
+}
+
+func (s *Impl) TransferOwnership(ctx context.Context, fromOwner string, toOwner string, serviceNames []string, repoKeys []string, jiraIssue string) (openapi.OwnerTransferResultDto, error) {
+	result := openapi.OwnerTransferResultDto{
+		TargetOwner:  toOwner,
+		Services:     serviceNames,
+		Repositories: repoKeys,
+		JiraIssue:    jiraIssue,
+	}
+	if types.IsDryRun(ctx) {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("dry run, not transferring %d services and %d repositories from owner %s to owner %s", len(serviceNames), len(repoKeys), fromOwner, toOwner)
+		return result, nil
+	}
+
+	err := s.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		commitInfo, err := s.Mapper.TransferOwnership(subCtx, fromOwner, toOwner, serviceNames, repoKeys, jiraIssue)
+		if err != nil {
+			if githookerror.Is(err) {
+				return s.httpErrorFromHook(err, jiraIssue)
+			}
+			return err
+		}
+		result.CommitHash = commitInfo.CommitHash
+		result.TimeStamp = timeStamp(commitInfo.TimeStamp)
+
+		s.fireAndForgetKafkaNotification(subCtx, repository.UpdateEvent{
+			Affected: repository.EventAffects{
+				OwnerAliases:   []string{fromOwner, toOwner},
+				ServiceNames:   serviceNames,
+				RepositoryKeys: repoKeys,
+			},
+			TimeStamp:  result.TimeStamp,
+			CommitHash: result.CommitHash,
+		})
+
+		// cache updates
+		if err := s.updateServices(subCtx); err != nil {
+			return err
+		}
+		return s.updateRepositories(subCtx)
+	})
+	return result, err
+}
+
 func (s *Impl) CanDeleteOwner(ctx context.Context, ownerAlias string) bool {
 	return s.Mapper.IsOwnerEmpty(ctx, ownerAlias)
 }
@@ -168,6 +261,7 @@ func (s *Impl) updateIndividualOwners(ctx context.Context, ownerAliasesMap map[s
 func (s *Impl) removeIndividualOwner(ctx context.Context, alias string) {
 	s.Logging.Logger().Ctx(ctx).Info().Printf("owner %s is no longer current, removing it from the cache", alias)
 	s.Cache.DeleteOwner(ctx, alias)
+	s.Search.RemoveOwner(ctx, alias)
 	s.Notifier.PublishDeletion(ctx, alias, types.OwnerPayload)
 }
 
@@ -178,6 +272,7 @@ func (s *Impl) addIndividualOwner(ctx context.Context, alias string) error {
 		s.totalErrorCounter.Inc()
 	} else {
 		s.Cache.PutOwner(ctx, alias, owner)
+		s.Search.IndexOwner(ctx, alias, owner)
 		if errOnlyLog := s.Notifier.PublishCreation(ctx, alias, notifier.AsPayload(owner)); errOnlyLog != nil {
 			s.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("error publishing creation of owner %s", alias)
 		}
@@ -195,6 +290,7 @@ func (s *Impl) updateIndividualOwner(ctx context.Context, alias string) error {
 		cached, cacheErr := s.Cache.GetOwner(ctx, alias)
 
 		s.Cache.PutOwner(ctx, alias, owner)
+		s.Search.IndexOwner(ctx, alias, owner)
 		if cacheErr == nil && !equalExceptCacheInfo(cached, owner) {
 			if errOnlyLog := s.Notifier.PublishModification(ctx, alias, notifier.AsPayload(owner)); errOnlyLog != nil {
 				s.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("error publishing modification of owner %s", alias)
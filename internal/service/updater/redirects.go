@@ -0,0 +1,22 @@
+package updater
+
+import (
+	"context"
+)
+
+// updateRedirects keeps the previous redirects if they cannot be read, like the other updates it does not fail.
+func (s *Impl) updateRedirects(ctx context.Context) {
+	s.Logging.Logger().Ctx(ctx).Info().Print("updating redirects")
+
+	redirects, err := s.Mapper.GetRedirects(ctx)
+	if err != nil {
+		s.Logging.Logger().Ctx(ctx).Warn().Printf("failed to read redirects from metadata - redirects may be outdated until next run: %s", err.Error())
+		s.totalErrorCounter.Inc()
+		return
+	}
+
+	if err := s.Cache.PutRedirects(ctx, redirects); err != nil {
+		s.Logging.Logger().Ctx(ctx).Warn().Printf("failed to cache redirects - redirects may be outdated until next run: %s", err.Error())
+		s.totalErrorCounter.Inc()
+	}
+}
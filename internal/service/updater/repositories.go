@@ -15,6 +15,11 @@ import (
 // --- business logic ---
 
 func (s *Impl) WriteRepository(ctx context.Context, key string, repository openapi.RepositoryDto) (openapi.RepositoryDto, error) {
+	if types.IsDryRun(ctx) {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("dry run, not writing repository %s", key)
+		return repository, nil
+	}
+
 	result := repository
 	err := s.WithMetadataLock(ctx, func(subCtx context.Context) error {
 		current, err := s.Cache.GetRepository(ctx, key)
@@ -71,6 +76,11 @@ func (s *Impl) WriteRepository(ctx context.Context, key string, repository opena
 }
 
 func (s *Impl) DeleteRepository(ctx context.Context, key string, deletionInfo openapi.DeletionDto) error {
+	if types.IsDryRun(ctx) {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("dry run, not deleting repository %s", key)
+		return nil
+	}
+
 	return s.WithMetadataLock(ctx, func(subCtx context.Context) error {
 		repositoryWritten, err := s.Mapper.DeleteRepository(subCtx, key, deletionInfo.JiraIssue)
 		if err != nil {
@@ -96,6 +106,44 @@ func (s *Impl) DeleteRepository(ctx context.Context, key string, deletionInfo op
 	})
 }
 
+func (s *Impl) RenameRepository(ctx context.Context, oldKey string, newKey string, jiraIssue string) (openapi.RepositoryDto, error) {
+	if types.IsDryRun(ctx) {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("dry run, not renaming repository %s to %s", oldKey, newKey)
+		repo, err := s.Cache.GetRepository(ctx, oldKey)
+		repo.JiraIssue = jiraIssue
+		return repo, err
+	}
+
+	var result openapi.RepositoryDto
+	err := s.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		repositoryWritten, affected, err := s.Mapper.RenameRepository(subCtx, oldKey, newKey, jiraIssue)
+		if err != nil {
+			if githookerror.Is(err) {
+				return s.httpErrorFromHook(err, jiraIssue)
+			}
+			return err
+		}
+		result = repositoryWritten
+
+		s.fireAndForgetKafkaNotification(subCtx, repository.UpdateEvent{
+			Affected:   affected,
+			TimeStamp:  repositoryWritten.TimeStamp,
+			CommitHash: repositoryWritten.CommitHash,
+		})
+
+		// cache updates (incl. referencing services)
+		if err := s.updateServices(subCtx); err != nil {
+			return err
+		}
+		if err := s.updateRepositories(subCtx); err != nil {
+			return err
+		}
+		s.updateRedirects(subCtx)
+		return nil
+	})
+	return result, err
+}
+
 func (s *Impl) repositoryKafkaEvent(key string, timeStamp string, commitHash string) repository.UpdateEvent {
 	return repository.UpdateEvent{
 		Affected: repository.EventAffects{
@@ -175,6 +223,7 @@ func (s *Impl) updateIndividualRepositories(ctx context.Context, repositoryKeysM
 		if activity == removeExisting {
 			s.Logging.Logger().Ctx(ctx).Info().Printf("repository %s is no longer current, removing it from the cache", key)
 			s.Cache.DeleteRepository(ctx, key)
+			s.Search.RemoveRepository(ctx, key)
 			s.Notifier.PublishDeletion(ctx, key, types.RepositoryPayload)
 		} else {
 			isNew := activity == addNew
@@ -200,6 +249,7 @@ func (s *Impl) RefreshRepository(ctx context.Context, key string) error {
 	}
 
 	s.Cache.PutRepository(ctx, key, repo)
+	s.Search.IndexRepository(ctx, key, repo)
 	s.Logging.Logger().Ctx(ctx).Debug().Printf("repository %s updated in cache per request", key)
 
 	return nil
@@ -219,6 +269,7 @@ func (s *Impl) updateIndividualRepository(ctx context.Context, key string, isNew
 	} else {
 		cached, cacheErr := s.Cache.GetRepository(ctx, key)
 		s.Cache.PutRepository(ctx, key, repo)
+		s.Search.IndexRepository(ctx, key, repo)
 		if isNew {
 			err = s.Notifier.PublishCreation(ctx, key, notifier.AsPayload(repo))
 			if err != nil {
@@ -14,6 +14,11 @@ import (
 // --- business logic ---
 
 func (s *Impl) WriteService(ctx context.Context, serviceName string, service openapi.ServiceDto) (openapi.ServiceDto, error) {
+	if types.IsDryRun(ctx) {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("dry run, not writing service %s", serviceName)
+		return service, nil
+	}
+
 	result := service
 	err := s.WithMetadataLock(ctx, func(subCtx context.Context) error {
 		current, err := s.Cache.GetService(ctx, serviceName)
@@ -71,6 +76,11 @@ func (s *Impl) WriteService(ctx context.Context, serviceName string, service ope
 }
 
 func (s *Impl) DeleteService(ctx context.Context, serviceName string, deletionInfo openapi.DeletionDto) error {
+	if types.IsDryRun(ctx) {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("dry run, not deleting service %s", serviceName)
+		return nil
+	}
+
 	return s.WithMetadataLock(ctx, func(subCtx context.Context) error {
 		serviceWritten, err := s.Mapper.DeleteService(subCtx, serviceName, deletionInfo.JiraIssue)
 		if err != nil {
@@ -96,6 +106,41 @@ func (s *Impl) DeleteService(ctx context.Context, serviceName string, deletionIn
 	})
 }
 
+func (s *Impl) RenameService(ctx context.Context, oldName string, newName string, jiraIssue string) (openapi.ServiceDto, error) {
+	if types.IsDryRun(ctx) {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("dry run, not renaming service %s to %s", oldName, newName)
+		service, err := s.Cache.GetService(ctx, oldName)
+		service.JiraIssue = jiraIssue
+		return service, err
+	}
+
+	var result openapi.ServiceDto
+	err := s.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		serviceWritten, affected, err := s.Mapper.RenameService(subCtx, oldName, newName, jiraIssue)
+		if err != nil {
+			if githookerror.Is(err) {
+				return s.httpErrorFromHook(err, jiraIssue)
+			}
+			return err
+		}
+		result = serviceWritten
+
+		s.fireAndForgetKafkaNotification(subCtx, repository.UpdateEvent{
+			Affected:   affected,
+			TimeStamp:  serviceWritten.TimeStamp,
+			CommitHash: serviceWritten.CommitHash,
+		})
+
+		// cache updates (incl. dependent services)
+		if err := s.updateServices(subCtx); err != nil {
+			return err
+		}
+		s.updateRedirects(subCtx)
+		return nil
+	})
+	return result, err
+}
+
 func (s *Impl) serviceKafkaEvent(serviceName string, timeStamp string, commitHash string) repository.UpdateEvent {
 	return repository.UpdateEvent{
 		Affected: repository.EventAffects{
@@ -187,6 +232,7 @@ func (s *Impl) updateIndividualServices(ctx context.Context, serviceNamesMap map
 		if activity == removeExisting {
 			s.Logging.Logger().Ctx(ctx).Info().Printf("service %s is no longer current, removing it from the cache", name)
 			s.Cache.DeleteService(ctx, name)
+			s.Search.RemoveService(ctx, name)
 			s.Notifier.PublishDeletion(ctx, name, types.ServicePayload)
 		} else {
 			isNew := activity == addNew
@@ -212,6 +258,7 @@ func (s *Impl) RefreshService(ctx context.Context, serviceName string) error {
 	}
 
 	s.Cache.PutService(ctx, serviceName, service)
+	s.Search.IndexService(ctx, serviceName, service)
 	s.Logging.Logger().Ctx(ctx).Debug().Printf("service %s updated in cache per request", serviceName)
 
 	return nil
@@ -231,6 +278,7 @@ func (s *Impl) updateIndividualService(ctx context.Context, name string, isNew b
 	} else {
 		cached, cacheErr := s.Cache.GetService(ctx, name)
 		s.Cache.PutService(ctx, name, service)
+		s.Search.IndexService(ctx, name, service)
 		if isNew {
 			err = s.Notifier.PublishCreation(ctx, name, notifier.AsPayload(service))
 			if err != nil {
@@ -244,9 +292,22 @@ func (s *Impl) updateIndividualService(ctx context.Context, name string, isNew b
 					s.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("error publishing modification of service %s", name)
 				}
 			}
+			if cacheErr == nil && lifecycleOf(cached) != lifecycleOf(service) {
+				err = s.Notifier.PublishLifecycleChange(ctx, name, notifier.AsPayload(service))
+				if err != nil {
+					s.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("error publishing lifecycle change of service %s", name)
+				}
+			}
 
 			s.Logging.Logger().Ctx(ctx).Debug().Printf("service %s updated in cache", name)
 		}
 	}
 	return nil
 }
+
+func lifecycleOf(service openapi.ServiceDto) string {
+	if service.Lifecycle == nil {
+		return ""
+	}
+	return *service.Lifecycle
+}
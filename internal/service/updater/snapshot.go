@@ -0,0 +1,185 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/goneerror"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/repository/cache"
+	"golang.org/x/sync/singleflight"
+)
+
+// snapshotCacheSize is the number of past commits we keep fully read snapshots for.
+const snapshotCacheSize = 8
+
+type snapshots struct {
+	mu      sync.Mutex
+	entries map[string]repository.Cache
+	order   []string
+
+	// building makes concurrent requests for the same commit share one snapshot build
+	building singleflight.Group
+}
+
+func (s *Impl) GetOwnerAt(ctx context.Context, at string, ownerAlias string) (openapi.OwnerDto, error) {
+	snapshot, commitInfo, err := s.cachedSnapshotAt(ctx, at)
+	if err != nil {
+		return openapi.OwnerDto{}, err
+	}
+	if snapshot != nil {
+		return snapshot.GetOwner(ctx, ownerAlias)
+	}
+	return s.Mapper.GetOwnerAt(ctx, commitInfo.CommitHash, ownerAlias)
+}
+
+func (s *Impl) GetServiceAt(ctx context.Context, at string, serviceName string) (openapi.ServiceDto, error) {
+	snapshot, commitInfo, err := s.cachedSnapshotAt(ctx, at)
+	if err != nil {
+		return openapi.ServiceDto{}, err
+	}
+	if snapshot != nil {
+		return snapshot.GetService(ctx, serviceName)
+	}
+	return s.Mapper.GetServiceAt(ctx, commitInfo.CommitHash, serviceName)
+}
+
+func (s *Impl) GetRepositoryAt(ctx context.Context, at string, repoKey string) (openapi.RepositoryDto, error) {
+	snapshot, commitInfo, err := s.cachedSnapshotAt(ctx, at)
+	if err != nil {
+		return openapi.RepositoryDto{}, err
+	}
+	if snapshot != nil {
+		return snapshot.GetRepository(ctx, repoKey)
+	}
+	return s.Mapper.GetRepositoryAt(ctx, commitInfo.CommitHash, repoKey)
+}
+
+func (s *Impl) ListSource(ctx context.Context, at string, snapshot string) (repository.Cache, string, error) {
+	if snapshot == "" {
+		if at == "" {
+			return s.Cache, s.Mapper.HeadCommitHash(ctx), nil
+		}
+		return s.snapshotAt(ctx, at)
+	}
+
+	if at == "" && snapshot == s.Mapper.HeadCommitHash(ctx) {
+		return s.Cache, snapshot, nil
+	}
+	source, commitHash, err := s.snapshotAt(ctx, snapshot)
+	if err != nil && apierrors.IsNotFoundError(err) {
+		return nil, "", goneerror.New("page.snapshot.gone", fmt.Sprintf("the commit %s that this list was read at is gone - please start over from the first page", snapshot), err, s.Timestamp.Now())
+	}
+	return source, commitHash, err
+}
+
+func (s *Impl) snapshotAt(ctx context.Context, at string) (repository.Cache, string, error) {
+	snapshot, commitInfo, err := s.cachedSnapshotAt(ctx, at)
+	if err != nil {
+		return nil, "", err
+	}
+	if snapshot != nil {
+		return snapshot, commitInfo.CommitHash, nil
+	}
+
+	commitHash := commitInfo.CommitHash
+	built, err, _ := s.snapshots.building.Do(commitHash, func() (interface{}, error) {
+		// another request may have finished building it while we resolved the commit
+		if snapshot, ok := s.lookupSnapshot(commitHash); ok {
+			return snapshot, nil
+		}
+		snapshot, err := s.buildSnapshot(ctx, commitInfo)
+		if err != nil {
+			return nil, err
+		}
+		s.rememberSnapshot(commitHash, snapshot)
+		return snapshot, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return built.(repository.Cache), commitHash, nil
+}
+
+// cachedSnapshotAt resolves at to a commit, and returns the snapshot of that commit if we still have one, else nil.
+//
+// If the snapshot is found by at itself, only the commit hash of the returned commit info is set.
+func (s *Impl) cachedSnapshotAt(ctx context.Context, at string) (repository.Cache, repository.CommitInfo, error) {
+	if snapshot, ok := s.lookupSnapshot(strings.ToLower(at)); ok {
+		return snapshot, repository.CommitInfo{CommitHash: strings.ToLower(at)}, nil
+	}
+
+	commitInfo, err := s.Mapper.ResolveCommit(ctx, at)
+	if err != nil {
+		return nil, commitInfo, err
+	}
+
+	snapshot, _ := s.lookupSnapshot(commitInfo.CommitHash)
+	return snapshot, commitInfo, nil
+}
+
+func (s *Impl) buildSnapshot(ctx context.Context, commitInfo repository.CommitInfo) (repository.Cache, error) {
+	commitHash := commitInfo.CommitHash
+	s.Logging.Logger().Ctx(ctx).Info().Printf("reading snapshot of metadata at commit %s", commitHash)
+	owners, services, repositories, err := s.Mapper.GetAllAt(ctx, commitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := cache.NewInMemory(s.Configuration, s.CustomConfiguration, s.Logging, s.Timestamp)
+	for alias, owner := range owners {
+		if err := snapshot.PutOwner(ctx, alias, owner); err != nil {
+			return nil, err
+		}
+	}
+	for name, service := range services {
+		if err := snapshot.PutService(ctx, name, service); err != nil {
+			return nil, err
+		}
+	}
+	for key, repo := range repositories {
+		if err := snapshot.PutRepository(ctx, key, repo); err != nil {
+			return nil, err
+		}
+	}
+
+	// the lists are as of the commit, not as of when we read them
+	listTimeStamp := timeStamp(commitInfo.TimeStamp)
+	if err := snapshot.SetOwnerListTimestamp(ctx, listTimeStamp); err != nil {
+		return nil, err
+	}
+	if err := snapshot.SetServiceListTimestamp(ctx, listTimeStamp); err != nil {
+		return nil, err
+	}
+	if err := snapshot.SetRepositoryListTimestamp(ctx, listTimeStamp); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (s *Impl) lookupSnapshot(commitHash string) (repository.Cache, bool) {
+	s.snapshots.mu.Lock()
+	defer s.snapshots.mu.Unlock()
+
+	snapshot, ok := s.snapshots.entries[commitHash]
+	return snapshot, ok
+}
+
+func (s *Impl) rememberSnapshot(commitHash string, snapshot repository.Cache) {
+	s.snapshots.mu.Lock()
+	defer s.snapshots.mu.Unlock()
+
+	if s.snapshots.entries == nil {
+		s.snapshots.entries = make(map[string]repository.Cache)
+	}
+	if len(s.snapshots.order) >= snapshotCacheSize {
+		delete(s.snapshots.entries, s.snapshots.order[0])
+		s.snapshots.order = s.snapshots.order[1:]
+	}
+	s.snapshots.entries[commitHash] = snapshot
+	s.snapshots.order = append(s.snapshots.order, commitHash)
+}
@@ -0,0 +1,73 @@
+package updater
+
+import (
+	"context"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/githookerror"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/nochangeserror"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"sort"
+)
+
+// --- business logic ---
+
+func (s *Impl) WriteTransaction(ctx context.Context, changes service.TransactionChanges, description string, jiraIssue string) (openapi.TransactionResultDto, error) {
+	result := openapi.TransactionResultDto{
+		Owners:       sortedNames(changes.Owners),
+		Services:     sortedNames(changes.Services),
+		Repositories: sortedNames(changes.Repositories),
+		JiraIssue:    jiraIssue,
+	}
+	if types.IsDryRun(ctx) {
+		s.Logging.Logger().Ctx(ctx).Info().Printf("dry run, not writing changes: %s", description)
+		return result, nil
+	}
+
+	err := s.WithMetadataLock(ctx, func(subCtx context.Context) error {
+		commitInfo, err := s.Mapper.WriteTransaction(subCtx, changes, description, jiraIssue)
+		if err != nil {
+			if nochangeserror.Is(err) {
+				// there were no actual changes, this is acceptable
+				result.JiraIssue = ""
+				return nil
+			}
+			if githookerror.Is(err) {
+				return s.httpErrorFromHook(err, jiraIssue)
+			}
+			return err
+		}
+		result.CommitHash = commitInfo.CommitHash
+		result.TimeStamp = timeStamp(commitInfo.TimeStamp)
+
+		s.fireAndForgetKafkaNotification(subCtx, repository.UpdateEvent{
+			Affected: repository.EventAffects{
+				OwnerAliases:   result.Owners,
+				ServiceNames:   result.Services,
+				RepositoryKeys: result.Repositories,
+			},
+			TimeStamp:  result.TimeStamp,
+			CommitHash: result.CommitHash,
+		})
+
+		// cache updates
+		if err := s.updateOwners(subCtx); err != nil {
+			return err
+		}
+		if err := s.updateServices(subCtx); err != nil {
+			return err
+		}
+		return s.updateRepositories(subCtx)
+	})
+	return result, err
+}
+
+func sortedNames[V any](values map[string]V) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -26,9 +26,16 @@ type Impl struct {
 	Notifier            repository.Notifier
 	Mapper              service.Mapper
 	Cache               repository.Cache
+	Search              service.Search
+	Events              service.Events
 
 	mu sync.Mutex
 
+	// pendingEvents are published to Events when the metadata lock is released, so subscribers see an updated cache.
+	pendingEvents []repository.UpdateEvent
+
+	snapshots snapshots
+
 	totalErrorCounter    prometheus.Counter
 	metadataErrorCounter prometheus.Counter
 	ownerErrorCounter    *prometheus.CounterVec
@@ -45,6 +52,8 @@ func New(
 	notifier repository.Notifier,
 	mapper service.Mapper,
 	cache repository.Cache,
+	search service.Search,
+	events service.Events,
 ) service.Updater {
 	return &Impl{
 		Configuration:       configuration,
@@ -55,6 +64,8 @@ func New(
 		Notifier:            notifier,
 		Mapper:              mapper,
 		Cache:               cache,
+		Search:              search,
+		Events:              events,
 	}
 }
 
@@ -162,6 +173,8 @@ func (s *Impl) WithMetadataLock(ctx context.Context, closure func(context.Contex
 
 		subCtx := context.WithValue(ctx, lockKey, true)
 		err := closure(subCtx)
+		// commits that were made are out there even if the closure failed later on
+		s.publishPendingEventsMustHoldMutex(subCtx)
 		return err
 	} else {
 		s.Logging.Logger().Ctx(ctx).Info().Print("thread already holds metadata lock")
@@ -172,7 +185,8 @@ func (s *Impl) WithMetadataLock(ctx context.Context, closure func(context.Contex
 
 func (s *Impl) PerformFullUpdate(ctx context.Context) error {
 	return s.WithMetadataLock(ctx, func(subCtx context.Context) error {
-		if _, err := s.updateMetadata(subCtx); err != nil {
+		events, err := s.updateMetadata(subCtx)
+		if err != nil {
 			return err
 		}
 
@@ -188,6 +202,11 @@ func (s *Impl) PerformFullUpdate(ctx context.Context) error {
 			return err
 		}
 
+		s.updateRedirects(subCtx)
+
+		// not sent to kafka, but our own event stream subscribers need to know
+		s.pendingEvents = append(s.pendingEvents, events...)
+
 		return nil
 	})
 }
@@ -211,6 +230,8 @@ func (s *Impl) PerformFullUpdateWithNotifications(ctx context.Context) error {
 			return err
 		}
 
+		s.updateRedirects(subCtx)
+
 		for _, event := range events {
 			s.fireAndForgetKafkaNotification(subCtx, event)
 		}
@@ -220,6 +241,8 @@ func (s *Impl) PerformFullUpdateWithNotifications(ctx context.Context) error {
 }
 
 func (s *Impl) fireAndForgetKafkaNotification(ctx context.Context, event repository.UpdateEvent) {
+	s.pendingEvents = append(s.pendingEvents, event)
+
 	s.Logging.Logger().Ctx(ctx).Debug().Print("preparing to send kafka event")
 	err := s.Kafka.Send(ctx, event)
 	if err != nil {
@@ -229,6 +252,13 @@ func (s *Impl) fireAndForgetKafkaNotification(ctx context.Context, event reposit
 	s.Logging.Logger().Ctx(ctx).Debug().Print("successfully sent kafka event")
 }
 
+func (s *Impl) publishPendingEventsMustHoldMutex(ctx context.Context) {
+	for _, event := range s.pendingEvents {
+		s.Events.Publish(ctx, event)
+	}
+	s.pendingEvents = nil
+}
+
 func (s *Impl) kafkaReceiverCallback(event repository.UpdateEvent) {
 	ctx := context.Background()
 
@@ -0,0 +1,148 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/config"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/types"
+	auzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
+	"slices"
+	"sort"
+
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+)
+
+type Impl struct {
+	Configuration       librepo.Configuration
+	CustomConfiguration config.CustomConfiguration
+	Logging             librepo.Logging
+	Timestamp           librepo.Timestamp
+	Cache               repository.Cache
+	Owners              service.Owners
+	Repositories        service.Repositories
+}
+
+func New(
+	configuration librepo.Configuration,
+	customConfig config.CustomConfiguration,
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+	cache repository.Cache,
+	owners service.Owners,
+	repositories service.Repositories,
+) service.Users {
+	return &Impl{
+		Configuration:       configuration,
+		CustomConfiguration: customConfig,
+		Logging:             logging,
+		Timestamp:           timestamp,
+		Cache:               cache,
+		Owners:              owners,
+		Repositories:        repositories,
+	}
+}
+
+func (s *Impl) IsUsers() bool {
+	return true
+}
+
+func (s *Impl) Setup() error {
+	ctx := auzerolog.AddLoggerToCtx(context.Background())
+
+	// nothing to do
+
+	s.Logging.Logger().Ctx(ctx).Info().Print("successfully set up users business component")
+	return nil
+}
+
+func (s *Impl) GetUser(ctx context.Context, username string) (openapi.UserDto, error) {
+	result := openapi.UserDto{
+		Username:       username,
+		Owners:         make([]openapi.UserOwnerDto, 0),
+		Groups:         make([]string, 0),
+		Repositories:   make([]openapi.UserRepositoryDto, 0),
+		GlobalPromoter: slices.Contains(s.Owners.ExpandUserGroups(ctx, s.CustomConfiguration.GlobalPromoters()), username),
+	}
+
+	aliases, err := s.Cache.GetSortedOwnerAliases(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, alias := range aliases {
+		owner, err := s.Cache.GetOwner(ctx, alias)
+		if err != nil {
+			// owner not found errors are ok, the cache may have been changed concurrently, just drop the entry
+			if apierrors.IsNotFoundError(err) {
+				continue
+			}
+			return result, err
+		}
+
+		roles := make([]string, 0)
+		if slices.Contains(s.Owners.ExpandUserGroups(ctx, owner.Members), username) {
+			roles = append(roles, types.UserRoleMember)
+		}
+		if owner.ProductOwner != nil && *owner.ProductOwner == username {
+			roles = append(roles, types.UserRoleProductOwner)
+		}
+		if slices.Contains(s.Owners.ExpandUserGroups(ctx, owner.Promoters), username) {
+			roles = append(roles, types.UserRolePromoter)
+		}
+		if len(roles) > 0 {
+			result.Owners = append(result.Owners, openapi.UserOwnerDto{Owner: alias, Roles: roles})
+		}
+
+		for groupName := range owner.Groups {
+			groupRef := fmt.Sprintf("@%s.%s", alias, groupName)
+			if slices.Contains(s.Owners.ExpandUserGroups(ctx, []string{groupRef}), username) {
+				result.Groups = append(result.Groups, groupRef)
+			}
+		}
+	}
+	sort.Strings(result.Groups)
+
+	keys, err := s.Cache.GetSortedRepositoryKeys(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, key := range keys {
+		// expands approvers and watchers
+		repo, err := s.Repositories.GetRepository(ctx, key)
+		if err != nil {
+			// repository not found errors are ok, the cache may have been changed concurrently, just drop the entry
+			if apierrors.IsNotFoundError(err) {
+				continue
+			}
+			return result, err
+		}
+
+		roles := make([]string, 0)
+		if containsApprover(repo.Configuration, username) {
+			roles = append(roles, types.UserRoleApprover)
+		}
+		if repo.Configuration != nil && slices.Contains(repo.Configuration.Watchers, username) {
+			roles = append(roles, types.UserRoleWatcher)
+		}
+		if len(roles) > 0 {
+			result.Repositories = append(result.Repositories, openapi.UserRepositoryDto{Repository: key, Roles: roles})
+		}
+	}
+
+	return result, nil
+}
+
+func containsApprover(configuration *openapi.RepositoryConfigurationDto, username string) bool {
+	if configuration == nil {
+		return false
+	}
+	for _, approvers := range configuration.Approvers {
+		if slices.Contains(approvers, username) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Interhyp/metadata-service/api"
+)
+
+const (
+	DiffOpAdd     = "add"
+	DiffOpRemove  = "remove"
+	DiffOpReplace = "replace"
+)
+
+// Diff lists the field level changes that turn before into after, in the style of a JSON patch.
+//
+// Entities are compared in their json representation, paths are JSON pointers into it. Commit hash, timestamp
+// and jira issue are left out, they change with every commit.
+// A nil entity did not exist, its counterpart is then added or removed as a whole under the empty path.
+//
+// Lists are compared by position. Removals from the end of a list are listed last element first, so the
+// operations can be applied in order.
+func Diff(before interface{}, after interface{}) ([]openapi.DiffOperationDto, error) {
+	beforeDocument, err := document(before)
+	if err != nil {
+		return nil, err
+	}
+	afterDocument, err := document(after)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]openapi.DiffOperationDto, 0)
+	diffValues("", beforeDocument, afterDocument, &result)
+	return result, nil
+}
+
+// versionFields are set from the git history rather than stored, so they differ between any two versions.
+var versionFields = []string{"commitHash", "timeStamp", "jiraIssue"}
+
+func document(entity interface{}) (interface{}, error) {
+	if entity == nil || (reflect.ValueOf(entity).Kind() == reflect.Pointer && reflect.ValueOf(entity).IsNil()) {
+		return nil, nil
+	}
+
+	marshalled, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err := json.Unmarshal(marshalled, &result); err != nil {
+		return nil, err
+	}
+	if fields, ok := result.(map[string]interface{}); ok {
+		for _, field := range versionFields {
+			delete(fields, field)
+		}
+	}
+	return result, nil
+}
+
+func diffValues(path string, before interface{}, after interface{}, result *[]openapi.DiffOperationDto) {
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	if before == nil {
+		*result = append(*result, openapi.DiffOperationDto{Op: DiffOpAdd, Path: path, Value: after})
+		return
+	}
+	if after == nil {
+		*result = append(*result, openapi.DiffOperationDto{Op: DiffOpRemove, Path: path, OldValue: before})
+		return
+	}
+
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		diffMaps(path, beforeMap, afterMap, result)
+		return
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList {
+		diffLists(path, beforeList, afterList, result)
+		return
+	}
+
+	*result = append(*result, openapi.DiffOperationDto{Op: DiffOpReplace, Path: path, OldValue: before, Value: after})
+}
+
+func diffMaps(path string, before map[string]interface{}, after map[string]interface{}, result *[]openapi.DiffOperationDto) {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		diffValues(path+"/"+escapePointerToken(key), before[key], after[key], result)
+	}
+}
+
+func diffLists(path string, before []interface{}, after []interface{}, result *[]openapi.DiffOperationDto) {
+	common := min(len(before), len(after))
+	for i := 0; i < common; i++ {
+		diffValues(path+"/"+strconv.Itoa(i), before[i], after[i], result)
+	}
+	for i := common; i < len(after); i++ {
+		*result = append(*result, openapi.DiffOperationDto{Op: DiffOpAdd, Path: path + "/" + strconv.Itoa(i), Value: after[i]})
+	}
+	for i := len(before) - 1; i >= common; i-- {
+		*result = append(*result, openapi.DiffOperationDto{Op: DiffOpRemove, Path: path + "/" + strconv.Itoa(i), OldValue: before[i]})
+	}
+}
+
+// escapePointerToken escapes a map key for use in a JSON pointer, see RFC 6901.
+//
+// Ref protection patterns and group references often contain slashes.
+func escapePointerToken(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,85 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/stretchr/testify/require"
+)
+
+func tstDiffRepository(approvers []string, refMatcher string) *openapi.RepositoryDto {
+	return &openapi.RepositoryDto{
+		Owner:      "some-owner",
+		Url:        "ssh://git@some-git-host/some-service.git",
+		Mainline:   "main",
+		CommitHash: "6c8ac2c35791edf9979623c717a243fc53400000",
+		TimeStamp:  "2022-11-06T18:14:10Z",
+		Configuration: &openapi.RepositoryConfigurationDto{
+			Approvers: map[string][]string{"feature/*": approvers},
+			RequireConditions: map[string]openapi.ConditionReferenceDto{
+				"snyk": {RefMatcher: refMatcher},
+			},
+		},
+	}
+}
+
+func TestDiff_Unchanged(t *testing.T) {
+	docs.Description("commit hash and timestamp change with every commit and do not show up in a diff")
+	before := tstDiffRepository([]string{"a"}, "main")
+	after := tstDiffRepository([]string{"a"}, "main")
+	after.CommitHash = "c"
+	after.TimeStamp = "2022-11-07T18:14:10Z"
+
+	ops, err := Diff(before, after)
+	require.Nil(t, err)
+	require.Empty(t, ops)
+}
+
+func TestDiff_Fields(t *testing.T) {
+	docs.Description("nested changes are listed with escaped JSON pointers, list elements are compared by position")
+	before := tstDiffRepository([]string{"a", "b", "c"}, "main")
+	after := tstDiffRepository([]string{"a", "x"}, "master")
+	after.Configuration.RefProtections = &openapi.RefProtections{}
+	after.Owner = "other-owner"
+
+	ops, err := Diff(before, after)
+	require.Nil(t, err)
+	require.Equal(t, []openapi.DiffOperationDto{
+		{Op: DiffOpReplace, Path: "/configuration/approvers/feature~1*/1", OldValue: "b", Value: "x"},
+		{Op: DiffOpRemove, Path: "/configuration/approvers/feature~1*/2", OldValue: "c"},
+		{Op: DiffOpAdd, Path: "/configuration/refProtections", Value: map[string]interface{}{}},
+		{Op: DiffOpReplace, Path: "/configuration/requireConditions/snyk/refMatcher", OldValue: "main", Value: "master"},
+		{Op: DiffOpReplace, Path: "/owner", OldValue: "some-owner", Value: "other-owner"},
+	}, ops)
+}
+
+func TestDiff_Lists(t *testing.T) {
+	docs.Description("removals from the end of a list are listed last element first")
+	ops, err := Diff(tstDiffRepository([]string{"a", "b", "c"}, "main"), tstDiffRepository([]string{"a"}, "main"))
+	require.Nil(t, err)
+	require.Equal(t, 2, len(ops))
+	require.Equal(t, "/configuration/approvers/feature~1*/2", ops[0].Path)
+	require.Equal(t, "/configuration/approvers/feature~1*/1", ops[1].Path)
+
+	ops, err = Diff(tstDiffRepository([]string{"a"}, "main"), tstDiffRepository([]string{"a", "b"}, "main"))
+	require.Nil(t, err)
+	require.Equal(t, []openapi.DiffOperationDto{{Op: DiffOpAdd, Path: "/configuration/approvers/feature~1*/1", Value: "b"}}, ops)
+}
+
+func TestDiff_Missing(t *testing.T) {
+	docs.Description("an entity that did not exist is added or removed as a whole")
+	var missing *openapi.RepositoryDto
+
+	ops, err := Diff(missing, tstDiffRepository([]string{"a"}, "main"))
+	require.Nil(t, err)
+	require.Equal(t, 1, len(ops))
+	require.Equal(t, DiffOpAdd, ops[0].Op)
+	require.Equal(t, "", ops[0].Path)
+	require.Equal(t, "some-owner", ops[0].Value.(map[string]interface{})["owner"])
+
+	ops, err = Diff(tstDiffRepository([]string{"a"}, "main"), nil)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(ops))
+	require.Equal(t, DiffOpRemove, ops[0].Op)
+}
@@ -0,0 +1,221 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"gopkg.in/yaml.v3"
+)
+
+// userListKeys are the keys of the fields that hold users and group references, at any depth.
+var userListKeys = map[string]bool{
+	"members":    true,
+	"promoters":  true,
+	"approvers":  true,
+	"watchers":   true,
+	"exemptions": true,
+}
+
+// GroupsOf gives the groups of an owner, and false if there is no such owner.
+type GroupsOf func(ownerAlias string) (map[string][]string, bool)
+
+// GroupReferenceProblem describes what is wrong with a single entry of a user list, or returns "" if nothing is.
+//
+// Usernames are always fine. Group references must have the form @owner.group, and name an existing owner and one
+// of its groups.
+func GroupReferenceProblem(userOrGroup string, groupsOf GroupsOf) string {
+	isGroup, groupOwner, groupName := ParseGroupOwnerAndGroupName(userOrGroup)
+	if !isGroup {
+		if strings.HasPrefix(userOrGroup, "@") {
+			return fmt.Sprintf("%s is not a valid group reference, it must have the form @owner.group", userOrGroup)
+		}
+		return ""
+	}
+	groups, ok := groupsOf(groupOwner)
+	if !ok {
+		return fmt.Sprintf("group reference %s points at owner %s, which does not exist", userOrGroup, groupOwner)
+	}
+	if _, ok := groups[groupName]; !ok {
+		return fmt.Sprintf("group reference %s points at group %s, which owner %s does not have", userOrGroup, groupName, groupOwner)
+	}
+	return ""
+}
+
+// AddedGroupReferenceProblems checks the entries of the user lists in candidate that are not in the same list of
+// current, and returns a message for each one with a problem, prefixed by the field.
+//
+// Use the zero value for current to check all entries. Entries that were already there are not checked again,
+// so existing problems do not block unrelated changes.
+func AddedGroupReferenceProblems(current map[string][]string, candidate map[string][]string, groupsOf GroupsOf) []string {
+	messages := make([]string, 0)
+	for _, field := range SortedUserListFields(candidate) {
+		for _, userOrGroup := range candidate[field] {
+			if slices.Contains(current[field], userOrGroup) {
+				continue
+			}
+			if problem := GroupReferenceProblem(userOrGroup, groupsOf); problem != "" {
+				messages = append(messages, fmt.Sprintf("field %s: %s", field, problem))
+			}
+		}
+	}
+	return messages
+}
+
+// OwnerUserLists gives the lists of users and group references of an owner by field.
+func OwnerUserLists(owner openapi.OwnerDto) map[string][]string {
+	result := make(map[string][]string)
+	addUserList(result, "members", owner.Members)
+	addUserList(result, "promoters", owner.Promoters)
+	return result
+}
+
+// RepositoryUserLists gives the lists of users and group references of a repository by field, that is the
+// approvers, the watchers, and the exemptions of ref protections and conditions.
+func RepositoryUserLists(repository openapi.RepositoryDto) map[string][]string {
+	result := make(map[string][]string)
+	config := repository.Configuration
+	if config == nil {
+		return result
+	}
+	for name, approvers := range config.Approvers {
+		addUserList(result, "configuration.approvers."+name, approvers)
+	}
+	addUserList(result, "configuration.watchers", config.Watchers)
+	if config.RefProtections != nil {
+		if branches := config.RefProtections.Branches; branches != nil {
+			addProtectedRefs(result, "configuration.refProtections.branches.requirePR", branches.RequirePR)
+			addProtectedRefs(result, "configuration.refProtections.branches.preventAllChanges", branches.PreventAllChanges)
+			addProtectedRefs(result, "configuration.refProtections.branches.preventCreation", branches.PreventCreation)
+			addProtectedRefs(result, "configuration.refProtections.branches.preventDeletion", branches.PreventDeletion)
+			addProtectedRefs(result, "configuration.refProtections.branches.preventPush", branches.PreventPush)
+			addProtectedRefs(result, "configuration.refProtections.branches.preventForcePush", branches.PreventForcePush)
+		}
+		if tags := config.RefProtections.Tags; tags != nil {
+			addProtectedRefs(result, "configuration.refProtections.tags.preventAllChanges", tags.PreventAllChanges)
+			addProtectedRefs(result, "configuration.refProtections.tags.preventCreation", tags.PreventCreation)
+			addProtectedRefs(result, "configuration.refProtections.tags.preventDeletion", tags.PreventDeletion)
+			addProtectedRefs(result, "configuration.refProtections.tags.preventForcePush", tags.PreventForcePush)
+		}
+	}
+	for name, condition := range config.RequireConditions {
+		addUserList(result, "configuration.requireConditions."+name+".exemptions", condition.Exemptions)
+	}
+	if config.RequireSignature != nil {
+		addUserList(result, "configuration.requireSignature.exemptions", config.RequireSignature.Exemptions)
+	}
+	return result
+}
+
+// SortedUserListFields gives the fields of user lists in a stable order.
+func SortedUserListFields(userLists map[string][]string) []string {
+	fields := make([]string, 0, len(userLists))
+	for field := range userLists {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func addProtectedRefs(result map[string][]string, field string, protectedRefs []openapi.ProtectedRef) {
+	for i, protectedRef := range protectedRefs {
+		addUserList(result, fmt.Sprintf("%s[%d].exemptions", field, i), protectedRef.Exemptions)
+	}
+}
+
+func addUserList(result map[string][]string, field string, userList []string) {
+	if len(userList) > 0 {
+		result[field] = userList
+	}
+}
+
+// CachedGroupsOf looks up the groups of owners in the cache.
+func CachedGroupsOf(ctx context.Context, cache repository.Cache) GroupsOf {
+	return func(ownerAlias string) (map[string][]string, bool) {
+		owner, err := cache.GetOwner(ctx, ownerAlias)
+		if err != nil {
+			return nil, false
+		}
+		return owner.Groups, true
+	}
+}
+
+// UserListEntries finds the scalar values below the user list keys of a parsed yaml file, in document order.
+func UserListEntries(node *yaml.Node) []*yaml.Node {
+	return userListEntries(node, false)
+}
+
+func userListEntries(node *yaml.Node, inUserList bool) []*yaml.Node {
+	result := make([]*yaml.Node, 0)
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			result = append(result, userListEntries(child, inUserList)...)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			result = append(result, userListEntries(value, inUserList || userListKeys[key.Value])...)
+		}
+	case yaml.ScalarNode:
+		if inUserList {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// RenameGroupOwner replaces the group references to oldAlias in the user lists of a yaml file by references to
+// newAlias, and leaves everything else as it is, including free text that happens to look like a group reference.
+//
+// Files that cannot be parsed are returned unchanged.
+func RenameGroupOwner(contents []byte, oldAlias string, newAlias string) []byte {
+	root := yaml.Node{}
+	if err := yaml.Unmarshal(contents, &root); err != nil {
+		return contents
+	}
+
+	oldPrefix := "@" + oldAlias + "."
+	lines := strings.SplitAfter(string(contents), "\n")
+	entries := UserListEntries(&root)
+	changed := false
+	// backwards, so replacing an entry does not move the entries before it on the same line
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if _, groupOwner, _ := ParseGroupOwnerAndGroupName(entry.Value); groupOwner != oldAlias {
+			continue
+		}
+		if entry.Line < 1 || entry.Line > len(lines) {
+			continue
+		}
+		line := lines[entry.Line-1]
+		start := byteOffset(line, entry.Column-1)
+		if entry.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+			start++
+		}
+		if start > len(line) || !strings.HasPrefix(line[start:], oldPrefix) {
+			continue
+		}
+		lines[entry.Line-1] = line[:start] + "@" + newAlias + "." + line[start+len(oldPrefix):]
+		changed = true
+	}
+	if !changed {
+		return contents
+	}
+	return []byte(strings.Join(lines, ""))
+}
+
+// byteOffset converts a column counted in characters to an offset in bytes.
+func byteOffset(line string, column int) int {
+	for offset := range line {
+		if column == 0 {
+			return offset
+		}
+		column--
+	}
+	return len(line)
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func tstGroupsOf(ownerAlias string) (map[string][]string, bool) {
+	if ownerAlias == "some-owner" {
+		return map[string][]string{"users": {"some-user"}}, true
+	}
+	return nil, false
+}
+
+func TestGroupReferenceProblem(t *testing.T) {
+	require.Equal(t, "", GroupReferenceProblem("some-user", tstGroupsOf))
+	require.Equal(t, "", GroupReferenceProblem("@some-owner.users", tstGroupsOf))
+	require.Equal(t, "group reference @some-owner.admins points at group admins, which owner some-owner does not have", GroupReferenceProblem("@some-owner.admins", tstGroupsOf))
+	require.Equal(t, "group reference @unknown.users points at owner unknown, which does not exist", GroupReferenceProblem("@unknown.users", tstGroupsOf))
+	require.Equal(t, "@users is not a valid group reference, it must have the form @owner.group", GroupReferenceProblem("@users", tstGroupsOf))
+}
+
+func TestAddedGroupReferenceProblems(t *testing.T) {
+	current := openapi.RepositoryDto{
+		Configuration: &openapi.RepositoryConfigurationDto{
+			Watchers: []string{"@unknown.users"},
+		},
+	}
+	candidate := openapi.RepositoryDto{
+		Configuration: &openapi.RepositoryConfigurationDto{
+			Approvers: map[string][]string{"testing": {"@unknown.admins"}},
+			Watchers:  []string{"@unknown.users", "@some-owner.admins"},
+			RequireSignature: &openapi.ConditionReferenceDto{
+				Exemptions: []string{"@some-owner.users"},
+			},
+		},
+	}
+
+	require.Equal(t, []string{
+		"field configuration.approvers.testing: group reference @unknown.admins points at owner unknown, which does not exist",
+		"field configuration.watchers: group reference @some-owner.admins points at group admins, which owner some-owner does not have",
+	}, AddedGroupReferenceProblems(RepositoryUserLists(current), RepositoryUserLists(candidate), tstGroupsOf))
+
+	require.Equal(t, 3, len(AddedGroupReferenceProblems(nil, RepositoryUserLists(candidate), tstGroupsOf)))
+}
+
+func TestRenameGroupOwner(t *testing.T) {
+	contents := `description: ask @old.admins or see "@old.users"
+quicklinks:
+  - title: '@old.users board'
+members:
+  - "@old.users"
+  - '@old.admins'
+  - someone
+configuration:
+  approvers:
+    testing: [ '@old.users', "@old.admins", "@older.users" ]
+  refProtections:
+    branches:
+      requirePR:
+        - pattern: main
+          exemptions: ["@old.users"]
+`
+	expected := `description: ask @old.admins or see "@old.users"
+quicklinks:
+  - title: '@old.users board'
+members:
+  - "@newer.users"
+  - '@newer.admins'
+  - someone
+configuration:
+  approvers:
+    testing: [ '@newer.users', "@newer.admins", "@older.users" ]
+  refProtections:
+    branches:
+      requirePR:
+        - pattern: main
+          exemptions: ["@newer.users"]
+`
+	require.Equal(t, expected, string(RenameGroupOwner([]byte(contents), "old", "newer")))
+}
+
+func TestRenameGroupOwner_Unchanged(t *testing.T) {
+	contents := "members:\n  - someone\n"
+	require.Equal(t, contents, string(RenameGroupOwner([]byte(contents), "old", "newer")))
+
+	broken := "members: [\n"
+	require.Equal(t, broken, string(RenameGroupOwner([]byte(broken), "old", "newer")))
+}
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"time"
+
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
+)
+
+// historySortFields are the fields a history can be sorted by. Histories are listed newest first by default.
+var historySortFields = []string{SortByTimeStamp}
+
+const defaultHistorySort = "-" + SortByTimeStamp
+
+// HistoryStop tells a history walk, which goes newest first, when it has found the entries of the requested page
+// and one more, which is all PageHistory needs to cut the page and the next cursor.
+//
+// nil means the whole history is needed, because no limit was requested or the history is listed oldest first.
+func HistoryStop(page types.PageRequest, now time.Time) (func(commitHash string, timeStamp string) bool, error) {
+	if page.Sort == "" {
+		page.Sort = defaultHistorySort
+	}
+
+	pager, err := NewPager(page, historySortFields, now)
+	if err != nil {
+		return nil, err
+	}
+	if pager.limit == 0 || !pager.descending {
+		return nil, nil
+	}
+
+	found := 0
+	return func(commitHash string, timeStamp string) bool {
+		if pager.cursor == nil || pager.isAfterCursor(commitHash, timeStamp) {
+			found++
+		}
+		return found > pager.limit
+	}, nil
+}
+
+// PageHistory cuts the requested page from a history, which must be ordered newest first.
+//
+// Entries are identified by their commit hash, so cursors stay valid when new commits are added.
+func PageHistory(entries []openapi.HistoryEntryDto, page types.PageRequest, now time.Time) (openapi.HistoryDto, error) {
+	if page.Sort == "" {
+		page.Sort = defaultHistorySort
+	}
+
+	listTimeStamp := now.UTC().Format(time.RFC3339)
+	if len(entries) > 0 {
+		listTimeStamp = entries[0].TimeStamp
+	}
+
+	pager, err := NewPager(page, historySortFields, now)
+	if err != nil {
+		return openapi.HistoryDto{}, err
+	}
+	pager.ListedAt(listTimeStamp, "")
+
+	byCommitHash := make(map[string]openapi.HistoryEntryDto, len(entries))
+	for _, entry := range entries {
+		byCommitHash[entry.CommitHash] = entry
+		pager.Add(entry.CommitHash, entry.TimeStamp)
+	}
+
+	keys, nextCursor := pager.Page()
+	result := openapi.HistoryDto{
+		History:    make([]openapi.HistoryEntryDto, 0, len(keys)),
+		NextCursor: nextCursor,
+	}
+	for _, key := range keys {
+		result.History = append(result.History, byCommitHash[key])
+	}
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+var tstHistory = []openapi.HistoryEntryDto{
+	{CommitHash: "c3", TimeStamp: "2022-11-06T18:03:00Z"},
+	{CommitHash: "c2", TimeStamp: "2022-11-06T18:02:00Z"},
+	{CommitHash: "c1", TimeStamp: "2022-11-06T18:01:00Z"},
+	{CommitHash: "c0", TimeStamp: "2022-11-06T18:00:00Z"},
+}
+
+// tstWalkHistory simulates a history walk that ends when stop says so.
+func tstWalkHistory(stop func(commitHash string, timeStamp string) bool) []openapi.HistoryEntryDto {
+	for i, entry := range tstHistory {
+		if stop != nil && stop(entry.CommitHash, entry.TimeStamp) {
+			return tstHistory[:i+1]
+		}
+	}
+	return tstHistory
+}
+
+func TestHistoryStop_FindsPageAndOneMore(t *testing.T) {
+	page := types.PageRequest{Limit: 1}
+	stop, err := HistoryStop(page, tstPageNow)
+	require.Nil(t, err)
+	walked := tstWalkHistory(stop)
+	require.Equal(t, 2, len(walked))
+
+	first, err := PageHistory(walked, page, tstPageNow)
+	require.Nil(t, err)
+	require.Equal(t, "c3", first.History[0].CommitHash)
+	require.NotNil(t, first.NextCursor)
+
+	page.Cursor = *first.NextCursor
+	stop, err = HistoryStop(page, tstPageNow)
+	require.Nil(t, err)
+	walked = tstWalkHistory(stop)
+	require.Equal(t, 3, len(walked))
+
+	second, err := PageHistory(walked, page, tstPageNow)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(second.History))
+	require.Equal(t, "c2", second.History[0].CommitHash)
+	require.NotNil(t, second.NextCursor)
+}
+
+func TestHistoryStop_WholeHistory(t *testing.T) {
+	stop, err := HistoryStop(types.PageRequest{}, tstPageNow)
+	require.Nil(t, err)
+	require.Nil(t, stop)
+
+	stop, err = HistoryStop(types.PageRequest{Limit: 1, Sort: SortByTimeStamp}, tstPageNow)
+	require.Nil(t, err)
+	require.Nil(t, stop)
+}
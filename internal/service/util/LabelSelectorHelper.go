@@ -0,0 +1,191 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+)
+
+// TagsSelectorKey is the selector key that matches against the tags of an entry instead of its labels.
+const TagsSelectorKey = "tags"
+
+const (
+	selectorOpEquals    = "="
+	selectorOpNotEquals = "!="
+	selectorOpIn        = "in"
+	selectorOpNotIn     = "notin"
+	selectorOpExists    = "exists"
+	selectorOpNotExists = "!exists"
+)
+
+type selectorRequirement struct {
+	key      string
+	operator string
+	values   []string
+}
+
+// LabelSelector is a parsed kubernetes style label selector. All requirements must match.
+//
+// The zero value has no requirements and matches everything.
+type LabelSelector struct {
+	requirements []selectorRequirement
+}
+
+// ParseLabelSelector parses a comma separated list of requirements of the forms
+// key=value, key==value, key!=value, key in (a,b), key notin (a,b), key and !key.
+//
+// Requirements for the key "tags" are evaluated against the tags: tags=a requires tag a to be present,
+// tags in (a,b) requires any of the tags, and tags alone requires at least one tag.
+func ParseLabelSelector(selector string, now time.Time) (LabelSelector, error) {
+	result := LabelSelector{requirements: make([]selectorRequirement, 0)}
+
+	for _, clause := range splitSelectorClauses(selector) {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		requirement, err := parseSelectorRequirement(clause)
+		if err != nil {
+			return LabelSelector{}, apierrors.NewBadRequestError("labelselector.invalid", fmt.Sprintf("invalid label selector requirement '%s': %s", clause, err.Error()), nil, now)
+		}
+		result.requirements = append(result.requirements, requirement)
+	}
+	return result, nil
+}
+
+// Matches is true if the labels and tags satisfy all requirements.
+func (s LabelSelector) Matches(labels map[string]string, tags []string) bool {
+	for _, requirement := range s.requirements {
+		if requirement.key == TagsSelectorKey {
+			if !requirement.matchesTags(tags) {
+				return false
+			}
+		} else {
+			if !requirement.matchesLabels(labels) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r selectorRequirement) matchesLabels(labels map[string]string) bool {
+	value, exists := labels[r.key]
+	switch r.operator {
+	case selectorOpExists:
+		return exists
+	case selectorOpNotExists:
+		return !exists
+	case selectorOpEquals, selectorOpIn:
+		return exists && sliceContains(r.values, value)
+	case selectorOpNotEquals, selectorOpNotIn:
+		return !exists || !sliceContains(r.values, value)
+	}
+	return false
+}
+
+func (r selectorRequirement) matchesTags(tags []string) bool {
+	switch r.operator {
+	case selectorOpExists:
+		return len(tags) > 0
+	case selectorOpNotExists:
+		return len(tags) == 0
+	}
+
+	anyPresent := false
+	for _, value := range r.values {
+		if sliceContains(tags, value) {
+			anyPresent = true
+		}
+	}
+	if r.operator == selectorOpNotEquals || r.operator == selectorOpNotIn {
+		return !anyPresent
+	}
+	return anyPresent
+}
+
+// splitSelectorClauses splits at commas that are not inside a value set in parentheses.
+func splitSelectorClauses(selector string) []string {
+	result := make([]string, 0)
+	depth := 0
+	start := 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(result, selector[start:])
+}
+
+func parseSelectorRequirement(clause string) (selectorRequirement, error) {
+	if strings.HasPrefix(clause, "!") {
+		key := strings.TrimSpace(strings.TrimPrefix(clause, "!"))
+		if err := validSelectorKey(key); err != nil {
+			return selectorRequirement{}, err
+		}
+		return selectorRequirement{key: key, operator: selectorOpNotExists}, nil
+	}
+
+	if idx := strings.Index(clause, "!="); idx >= 0 {
+		return newSelectorValueRequirement(clause[:idx], selectorOpNotEquals, clause[idx+2:])
+	}
+	if idx := strings.Index(clause, "=="); idx >= 0 {
+		return newSelectorValueRequirement(clause[:idx], selectorOpEquals, clause[idx+2:])
+	}
+	if idx := strings.Index(clause, "="); idx >= 0 {
+		return newSelectorValueRequirement(clause[:idx], selectorOpEquals, clause[idx+1:])
+	}
+
+	fields := strings.Fields(clause)
+	if len(fields) == 1 {
+		if err := validSelectorKey(fields[0]); err != nil {
+			return selectorRequirement{}, err
+		}
+		return selectorRequirement{key: fields[0], operator: selectorOpExists}, nil
+	}
+	if len(fields) >= 2 && (fields[1] == selectorOpIn || fields[1] == selectorOpNotIn) {
+		key := fields[0]
+		if err := validSelectorKey(key); err != nil {
+			return selectorRequirement{}, err
+		}
+		set := strings.TrimSpace(strings.Join(fields[2:], " "))
+		if !strings.HasPrefix(set, "(") || !strings.HasSuffix(set, ")") {
+			return selectorRequirement{}, fmt.Errorf("value set must be enclosed in parentheses")
+		}
+		values := make([]string, 0)
+		for _, value := range strings.Split(set[1:len(set)-1], ",") {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return selectorRequirement{}, fmt.Errorf("value set must not contain empty values")
+			}
+			values = append(values, value)
+		}
+		return selectorRequirement{key: key, operator: fields[1], values: values}, nil
+	}
+	return selectorRequirement{}, fmt.Errorf("expected one of key=value, key!=value, key in (a,b), key notin (a,b), key or !key")
+}
+
+func newSelectorValueRequirement(key string, operator string, value string) (selectorRequirement, error) {
+	key = strings.TrimSpace(key)
+	if err := validSelectorKey(key); err != nil {
+		return selectorRequirement{}, err
+	}
+	return selectorRequirement{key: key, operator: operator, values: []string{strings.TrimSpace(value)}}, nil
+}
+
+func validSelectorKey(key string) error {
+	if key == "" || strings.ContainsAny(key, " !=(),") {
+		return fmt.Errorf("key must be non empty and must not contain spaces or any of !=(),")
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package util
+
+import (
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var tstSelectorNow = time.Date(2022, 11, 6, 18, 14, 10, 0, time.UTC)
+
+func tstMatches(t *testing.T, selector string, labels map[string]string, tags []string) bool {
+	parsed, err := ParseLabelSelector(selector, tstSelectorNow)
+	require.Nil(t, err)
+	return parsed.Matches(labels, tags)
+}
+
+func TestLabelSelector_Empty(t *testing.T) {
+	require.True(t, tstMatches(t, "", nil, nil))
+	require.True(t, tstMatches(t, " , ", map[string]string{"team": "unicorns"}, nil))
+}
+
+func TestLabelSelector_Labels(t *testing.T) {
+	labels := map[string]string{"team": "unicorns", "tier": "backend"}
+
+	require.True(t, tstMatches(t, "team=unicorns", labels, nil))
+	require.True(t, tstMatches(t, "team==unicorns", labels, nil))
+	require.False(t, tstMatches(t, "team=dragons", labels, nil))
+	require.True(t, tstMatches(t, "team!=dragons", labels, nil))
+	require.True(t, tstMatches(t, "stage!=prod", labels, nil))
+	require.False(t, tstMatches(t, "team!=unicorns", labels, nil))
+	require.True(t, tstMatches(t, "team in (dragons, unicorns)", labels, nil))
+	require.False(t, tstMatches(t, "team notin (dragons,unicorns)", labels, nil))
+	require.True(t, tstMatches(t, "stage notin (prod)", labels, nil))
+	require.True(t, tstMatches(t, "tier", labels, nil))
+	require.False(t, tstMatches(t, "stage", labels, nil))
+	require.True(t, tstMatches(t, "!stage", labels, nil))
+	require.False(t, tstMatches(t, "!tier", labels, nil))
+	require.True(t, tstMatches(t, "team in (unicorns,dragons),tier=backend,!stage", labels, nil))
+	require.False(t, tstMatches(t, "team in (unicorns,dragons),tier=frontend", labels, nil))
+}
+
+func TestLabelSelector_Tags(t *testing.T) {
+	tags := []string{"payments", "critical"}
+
+	require.True(t, tstMatches(t, "tags=critical", nil, tags))
+	require.False(t, tstMatches(t, "tags=legacy", nil, tags))
+	require.True(t, tstMatches(t, "tags!=legacy", nil, tags))
+	require.False(t, tstMatches(t, "tags!=critical", nil, tags))
+	require.True(t, tstMatches(t, "tags in (legacy,payments)", nil, tags))
+	require.False(t, tstMatches(t, "tags notin (legacy,payments)", nil, tags))
+	require.True(t, tstMatches(t, "tags", nil, tags))
+	require.False(t, tstMatches(t, "!tags", nil, tags))
+	require.True(t, tstMatches(t, "!tags", map[string]string{"tags": "label, not tags"}, nil))
+}
+
+func TestLabelSelector_Invalid(t *testing.T) {
+	for _, selector := range []string{
+		"=unicorns",
+		"!",
+		"team in unicorns",
+		"team in (unicorns,)",
+		"team unicorns",
+		"team notin",
+	} {
+		_, err := ParseLabelSelector(selector, tstSelectorNow)
+		require.True(t, apierrors.IsBadRequestError(err), selector)
+	}
+}
@@ -0,0 +1,42 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/internal/acorn/config"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+)
+
+// ValidOwnerAlias checks an owner alias against the configured rules, and gives a bad request error if it breaks them.
+func ValidOwnerAlias(ctx context.Context, configuration config.CustomConfiguration, owner string, now time.Time) apierrors.AnnotatedError {
+	if configuration.OwnerAliasPermittedRegex().MatchString(owner) &&
+		!configuration.OwnerAliasProhibitedRegex().MatchString(owner) &&
+		uint16(len(owner)) <= configuration.OwnerAliasMaxLength() {
+		return nil
+	}
+
+	aulogging.Logger.Ctx(ctx).Info().Printf("owner alias %v invalid", url.QueryEscape(owner))
+	permitted := configuration.OwnerAliasPermittedRegex().String()
+	prohibited := configuration.OwnerAliasProhibitedRegex().String()
+	maxLength := configuration.OwnerAliasMaxLength()
+	return apierrors.NewBadRequestError("owner.invalid.alias", fmt.Sprintf("owner alias must match %s, is not allowed to match %s and may have up to %d characters", permitted, prohibited, maxLength), nil, now)
+}
+
+// ValidServiceName checks a service name against the configured rules, and gives a bad request error if it breaks them.
+func ValidServiceName(ctx context.Context, configuration config.CustomConfiguration, name string, now time.Time) apierrors.AnnotatedError {
+	if configuration.ServiceNamePermittedRegex().MatchString(name) &&
+		!configuration.ServiceNameProhibitedRegex().MatchString(name) &&
+		uint16(len(name)) <= configuration.ServiceNameMaxLength() {
+		return nil
+	}
+
+	aulogging.Logger.Ctx(ctx).Info().Printf("service name %v invalid", url.QueryEscape(name))
+	permitted := configuration.ServiceNamePermittedRegex().String()
+	prohibited := configuration.ServiceNameProhibitedRegex().String()
+	maxLength := configuration.ServiceNameMaxLength()
+	return apierrors.NewBadRequestError("service.invalid.name", fmt.Sprintf("service name must match %s, is not allowed to match %s and may have up to %d characters", permitted, prohibited, maxLength), nil, now)
+}
@@ -0,0 +1,278 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/goneerror"
+	"github.com/Interhyp/metadata-service/internal/types"
+)
+
+const (
+	SortByName      = "name"
+	SortByOwner     = "owner"
+	SortByTimeStamp = "timeStamp"
+)
+
+const MaxPageLimit = 1000
+
+// cursorRetention is how long a cursor stays valid after it was issued.
+//
+// Cursors carry their position and the commit the list was read at, so later pages are read from the same
+// snapshot even if the cache has been refreshed in the meantime. But after this time we consider the snapshot
+// gone, because the client is most likely iterating over something it no longer knows.
+var cursorRetention = 24 * time.Hour
+
+type pageCursor struct {
+	ListTimeStamp string `json:"t"`
+	IssuedAt      string `json:"i,omitempty"`
+	CommitHash    string `json:"c,omitempty"`
+	Sort          string `json:"s"`
+	Key           string `json:"k"`
+	Value         string `json:"v"`
+}
+
+type pageEntry struct {
+	key   string
+	value string
+}
+
+// Pager implements keyset pagination over a list of entries identified by their unique key.
+//
+// Read the list from the commit given by Snapshot and tell the pager about it through ListedAt. Then feed it
+// the candidate keys in ascending key order through Keys, Add each entry that passes the filters together
+// with its sort value, and obtain the selected keys from Page.
+type Pager struct {
+	limit         int
+	field         string
+	descending    bool
+	listTimeStamp string
+	commitHash    string
+	now           time.Time
+	cursor        *pageCursor
+	entries       []pageEntry
+}
+
+// NewPager validates the page request against the sort fields supported by the list.
+//
+// The first entry of sortFields is the default sort field.
+func NewPager(page types.PageRequest, sortFields []string, now time.Time) (*Pager, error) {
+	pager := &Pager{
+		limit:   page.Limit,
+		field:   sortFields[0],
+		now:     now,
+		entries: make([]pageEntry, 0),
+	}
+
+	if page.Limit < 0 || page.Limit > MaxPageLimit {
+		return nil, apierrors.NewBadRequestError("page.invalid.limit", fmt.Sprintf("limit must be between 0 and %d, 0 for no limit", MaxPageLimit), nil, now)
+	}
+
+	if page.Sort != "" {
+		field := strings.TrimPrefix(page.Sort, "-")
+		if !sliceContains(sortFields, field) {
+			return nil, apierrors.NewBadRequestError("page.invalid.sort", fmt.Sprintf("sort must be one of %v, optionally prefixed with - for descending order", sortFields), nil, now)
+		}
+		pager.field = field
+		pager.descending = strings.HasPrefix(page.Sort, "-")
+	}
+
+	if page.Cursor != "" {
+		cursor, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return nil, apierrors.NewBadRequestError("page.invalid.cursor", "cursor is malformed - it must be passed on exactly as obtained from a previous page", err, now)
+		}
+		if cursor.Sort != pager.sortParam() {
+			return nil, apierrors.NewBadRequestError("page.invalid.cursor", "cursor was issued for a different sort order - please repeat the sort parameter of the first page", nil, now)
+		}
+		// lists read at a past commit have an old list timestamp, so retention counts from when the cursor was issued
+		issuedAt := cursor.IssuedAt
+		if issuedAt == "" {
+			issuedAt = cursor.ListTimeStamp
+		}
+		issued, err := time.Parse(time.RFC3339, issuedAt)
+		if err != nil {
+			return nil, apierrors.NewBadRequestError("page.invalid.cursor", "cursor is malformed - it must be passed on exactly as obtained from a previous page", err, now)
+		}
+		if now.Sub(issued) > cursorRetention {
+			return nil, goneerror.New("page.cursor.expired", fmt.Sprintf("the list snapshot of %s that this cursor refers to is gone - please start over from the first page", cursor.ListTimeStamp), nil, now)
+		}
+		pager.cursor = &cursor
+	}
+
+	return pager, nil
+}
+
+// Snapshot is the commit hash the previous page was read at, or empty for a first page.
+func (p *Pager) Snapshot() string {
+	if p.cursor == nil {
+		return ""
+	}
+	return p.cursor.CommitHash
+}
+
+// ListedAt records the list timestamp and the commit hash the list was read at, to be carried on by the next cursor.
+func (p *Pager) ListedAt(listTimeStamp string, commitHash string) {
+	p.listTimeStamp = listTimeStamp
+	p.commitHash = commitHash
+}
+
+// SortField is the field this pager sorts by.
+func (p *Pager) SortField() string {
+	return p.field
+}
+
+// Keys orders the sorted candidate keys for iteration.
+//
+// When sorting by name, keys that lie before the cursor position are skipped, so callers do not need to load them.
+func (p *Pager) Keys(sortedKeys []string) []string {
+	if p.field != SortByName {
+		return sortedKeys
+	}
+
+	result := make([]string, 0, len(sortedKeys))
+	for i := range sortedKeys {
+		key := sortedKeys[i]
+		if p.descending {
+			key = sortedKeys[len(sortedKeys)-1-i]
+		}
+		if p.cursor == nil || p.isAfterCursor(key, key) {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// Add records an entry that passed all filters.
+func (p *Pager) Add(key string, sortValue string) {
+	if p.field == SortByName {
+		sortValue = key
+	}
+	p.entries = append(p.entries, pageEntry{key: key, value: sortValue})
+}
+
+// Keeps is true if the entries added can be kept for the page.
+//
+// For other sort fields than name, the page is only known once all entries have been added, so do not keep them
+// while scanning the list, but load the entries of the page afterwards, see PageEntries.
+func (p *Pager) Keeps() bool {
+	return p.field == SortByName
+}
+
+// Full is true once enough entries have been added to fill the page when iterating in sort order.
+//
+// This only applies to sorting by name, for other fields all entries must be added before a page can be cut.
+func (p *Pager) Full() bool {
+	return p.field == SortByName && p.limit > 0 && len(p.entries) > p.limit
+}
+
+// Page returns the keys of the requested page in sort order, and the cursor for the next page,
+// or nil if this is the last page.
+func (p *Pager) Page() ([]string, *string) {
+	sort.SliceStable(p.entries, func(i, j int) bool {
+		return p.less(p.entries[i], p.entries[j])
+	})
+
+	selected := make([]pageEntry, 0, len(p.entries))
+	for _, entry := range p.entries {
+		if p.cursor == nil || p.isAfterCursor(entry.key, entry.value) {
+			selected = append(selected, entry)
+		}
+	}
+
+	var nextCursor *string
+	if p.limit > 0 && len(selected) > p.limit {
+		selected = selected[:p.limit]
+		last := selected[len(selected)-1]
+		encoded := encodeCursor(pageCursor{
+			ListTimeStamp: p.listTimeStamp,
+			IssuedAt:      p.now.UTC().Format(time.RFC3339),
+			CommitHash:    p.commitHash,
+			Sort:          p.sortParam(),
+			Key:           last.key,
+			Value:         last.value,
+		})
+		nextCursor = &encoded
+	}
+
+	keys := make([]string, len(selected))
+	for i, entry := range selected {
+		keys[i] = entry.key
+	}
+	return keys, nextCursor
+}
+
+// PageEntries returns the entries for the keys of a page, in a map, together with the keys that are present.
+//
+// Entries that were not kept while scanning the list are loaded. Entries that are no longer found are dropped,
+// the cache may have been changed concurrently.
+func PageEntries[E any](entries map[string]E, keys []string, load func(key string) (E, error)) (map[string]E, []string, error) {
+	result := make(map[string]E, len(keys))
+	present := make([]string, 0, len(keys))
+	for _, key := range keys {
+		entry, ok := entries[key]
+		if !ok {
+			var err error
+			entry, err = load(key)
+			if err != nil {
+				if apierrors.IsNotFoundError(err) {
+					continue
+				}
+				return nil, nil, err
+			}
+		}
+		result[key] = entry
+		present = append(present, key)
+	}
+	return result, present, nil
+}
+
+func (p *Pager) sortParam() string {
+	if p.descending {
+		return "-" + p.field
+	}
+	return p.field
+}
+
+func (p *Pager) less(a pageEntry, b pageEntry) bool {
+	if a.value != b.value {
+		return (a.value < b.value) != p.descending
+	}
+	if a.key != b.key {
+		return (a.key < b.key) != p.descending
+	}
+	return false
+}
+
+func (p *Pager) isAfterCursor(key string, value string) bool {
+	return p.less(pageEntry{key: p.cursor.Key, value: p.cursor.Value}, pageEntry{key: key, value: value})
+}
+
+func encodeCursor(cursor pageCursor) string {
+	jsonBytes, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(jsonBytes)
+}
+
+func decodeCursor(encoded string) (pageCursor, error) {
+	cursor := pageCursor{}
+	jsonBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(jsonBytes, &cursor)
+	return cursor, err
+}
+
+func sliceContains[T comparable](haystack []T, needle T) bool {
+	for _, e := range haystack {
+		if e == needle {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package util
+
+import (
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/goneerror"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var tstPageNow = time.Date(2022, 11, 6, 18, 14, 10, 0, time.UTC)
+
+const tstListTimeStamp = "2022-11-06T18:00:00Z"
+
+const tstCommitHash = "6c8d1bd2b3a5e7c9f0a1b2c3d4e5f60718293a4b"
+
+var tstPageSortFields = []string{SortByName, SortByOwner}
+
+var tstPageEntries = map[string]string{
+	"alpha":   "owner-b",
+	"bravo":   "owner-a",
+	"charlie": "owner-b",
+	"delta":   "owner-a",
+	"echo":    "owner-c",
+}
+
+func tstPage(t *testing.T, page types.PageRequest) ([]string, *string) {
+	pager, err := NewPager(page, tstPageSortFields, tstPageNow)
+	require.Nil(t, err)
+	pager.ListedAt(tstListTimeStamp, tstCommitHash)
+	for _, key := range pager.Keys([]string{"alpha", "bravo", "charlie", "delta", "echo"}) {
+		if pager.Full() {
+			break
+		}
+		pager.Add(key, tstPageEntries[key])
+	}
+	return pager.Page()
+}
+
+func tstAllPages(t *testing.T, limit int, sort string) [][]string {
+	result := make([][]string, 0)
+	cursor := ""
+	for {
+		keys, next := tstPage(t, types.PageRequest{Limit: limit, Cursor: cursor, Sort: sort})
+		result = append(result, keys)
+		if next == nil {
+			return result
+		}
+		cursor = *next
+	}
+}
+
+func TestPager_NoLimit(t *testing.T) {
+	keys, next := tstPage(t, types.PageRequest{})
+	require.Equal(t, []string{"alpha", "bravo", "charlie", "delta", "echo"}, keys)
+	require.Nil(t, next)
+}
+
+func TestPager_ByName(t *testing.T) {
+	require.Equal(t, [][]string{{"alpha", "bravo"}, {"charlie", "delta"}, {"echo"}}, tstAllPages(t, 2, ""))
+	require.Equal(t, [][]string{{"echo", "delta"}, {"charlie", "bravo"}, {"alpha"}}, tstAllPages(t, 2, "-name"))
+}
+
+func TestPager_ByOwner(t *testing.T) {
+	require.Equal(t, [][]string{{"bravo", "delta"}, {"alpha", "charlie"}, {"echo"}}, tstAllPages(t, 2, "owner"))
+	require.Equal(t, [][]string{{"echo", "charlie", "alpha"}, {"delta", "bravo"}}, tstAllPages(t, 3, "-owner"))
+}
+
+func TestPager_CursorSurvivesChangedList(t *testing.T) {
+	_, next := tstPage(t, types.PageRequest{Limit: 2})
+	require.NotNil(t, next)
+
+	pager, err := NewPager(types.PageRequest{Limit: 2, Cursor: *next}, tstPageSortFields, tstPageNow)
+	require.Nil(t, err)
+	pager.ListedAt("2022-11-06T18:10:00Z", "")
+	for _, key := range pager.Keys([]string{"bravo", "bravo2", "delta"}) {
+		pager.Add(key, "")
+	}
+	keys, nextAgain := pager.Page()
+	require.Equal(t, []string{"bravo2", "delta"}, keys)
+	require.Nil(t, nextAgain)
+}
+
+func TestPager_Invalid(t *testing.T) {
+	_, err := NewPager(types.PageRequest{Limit: MaxPageLimit + 1}, tstPageSortFields, tstPageNow)
+	require.True(t, apierrors.IsBadRequestError(err))
+	require.Equal(t, "limit must be between 0 and 1000, 0 for no limit", *err.(apierrors.AnnotatedError).ApiError().Details)
+
+	_, err = NewPager(types.PageRequest{Sort: "timeStamp"}, tstPageSortFields, tstPageNow)
+	require.True(t, apierrors.IsBadRequestError(err))
+
+	_, err = NewPager(types.PageRequest{Cursor: "not a cursor"}, tstPageSortFields, tstPageNow)
+	require.True(t, apierrors.IsBadRequestError(err))
+
+	_, next := tstPage(t, types.PageRequest{Limit: 1, Sort: "owner"})
+	_, err = NewPager(types.PageRequest{Limit: 1, Cursor: *next}, tstPageSortFields, tstPageNow)
+	require.True(t, apierrors.IsBadRequestError(err))
+}
+
+func TestPager_Expired(t *testing.T) {
+	_, next := tstPage(t, types.PageRequest{Limit: 1})
+	_, err := NewPager(types.PageRequest{Limit: 1, Cursor: *next}, tstPageSortFields, tstPageNow.Add(25*time.Hour))
+	require.True(t, goneerror.Is(err))
+	require.False(t, apierrors.IsBadRequestError(err))
+}
+
+func TestPager_OldListTimeStampNotExpired(t *testing.T) {
+	pager, err := NewPager(types.PageRequest{Limit: 1}, tstPageSortFields, tstPageNow)
+	require.Nil(t, err)
+	pager.ListedAt("2020-01-01T00:00:00Z", tstCommitHash)
+	for _, key := range pager.Keys([]string{"alpha", "bravo"}) {
+		pager.Add(key, "")
+	}
+	_, next := pager.Page()
+	require.NotNil(t, next)
+
+	_, err = NewPager(types.PageRequest{Limit: 1, Cursor: *next}, tstPageSortFields, tstPageNow.Add(time.Hour))
+	require.Nil(t, err)
+}
+
+func TestPager_CursorCarriesSnapshot(t *testing.T) {
+	_, next := tstPage(t, types.PageRequest{Limit: 2, Sort: "-owner"})
+	require.NotNil(t, next)
+
+	pager, err := NewPager(types.PageRequest{Limit: 2, Cursor: *next, Sort: "-owner"}, tstPageSortFields, tstPageNow)
+	require.Nil(t, err)
+	require.Equal(t, tstCommitHash, pager.Snapshot())
+	require.False(t, pager.Keeps())
+
+	first, err := NewPager(types.PageRequest{Limit: 2}, tstPageSortFields, tstPageNow)
+	require.Nil(t, err)
+	require.Equal(t, "", first.Snapshot())
+	require.True(t, first.Keeps())
+}
+
+func TestPageEntries(t *testing.T) {
+	kept := map[string]string{"alpha": "kept"}
+	load := func(key string) (string, error) {
+		if key == "charlie" {
+			return "", apierrors.NewNotFoundError("tst.notfound", "gone", nil, tstPageNow)
+		}
+		return "loaded", nil
+	}
+
+	entries, keys, err := PageEntries(kept, []string{"alpha", "bravo", "charlie"}, load)
+	require.Nil(t, err)
+	require.Equal(t, []string{"alpha", "bravo"}, keys)
+	require.Equal(t, map[string]string{"alpha": "kept", "bravo": "loaded"}, entries)
+}
@@ -0,0 +1,348 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/internal/types"
+)
+
+type jsonPatchOperation struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// errPatchTestFailed distinguishes a failed test operation, the patch is fine but the entry is not in the expected state.
+type errPatchTestFailed struct {
+	path string
+}
+
+func (e errPatchTestFailed) Error() string {
+	return fmt.Sprintf("test operation failed for path %s", e.path)
+}
+
+// ApplyPatch applies a merge patch or json patch to the json representation of current and decodes the result into
+// result, which must be a pointer.
+//
+// An invalid patch gives a bad request error, a failed test operation a conflict error.
+func ApplyPatch(current interface{}, patch types.PatchDocument, result interface{}, now time.Time) error {
+	marshalled, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	var document interface{}
+	if err := json.Unmarshal(marshalled, &document); err != nil {
+		return err
+	}
+
+	switch patch.MediaType {
+	case types.MergePatchMediaType:
+		var mergePatch interface{}
+		if err := json.Unmarshal(patch.Body, &mergePatch); err != nil {
+			return apierrors.NewBadRequestError("patch.invalid", "merge patch failed to parse", err, now)
+		}
+		document = applyMergePatch(document, mergePatch)
+	case types.JsonPatchMediaType:
+		var operations []jsonPatchOperation
+		if err := json.Unmarshal(patch.Body, &operations); err != nil {
+			return apierrors.NewBadRequestError("patch.invalid", "json patch failed to parse, must be an array of operations", err, now)
+		}
+		for i, operation := range operations {
+			document, err = applyJsonPatchOperation(document, operation)
+			if err != nil {
+				if testFailed, ok := err.(errPatchTestFailed); ok {
+					return apierrors.NewConflictError("patch.conflict.testfailed", fmt.Sprintf("operation %d: %s", i, testFailed.Error()), nil, now)
+				}
+				return apierrors.NewBadRequestError("patch.invalid", fmt.Sprintf("operation %d: %s", i, err.Error()), err, now)
+			}
+		}
+	default:
+		return apierrors.NewBadRequestError("patch.invalid", fmt.Sprintf("unsupported patch media type %s", patch.MediaType), nil, now)
+	}
+
+	patched, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(patched, result); err != nil {
+		return apierrors.NewBadRequestError("patch.invalid", "patched entry failed to parse", err, now)
+	}
+	return nil
+}
+
+// applyMergePatch implements RFC 7386. null removes a field, objects are merged recursively, anything else replaces.
+func applyMergePatch(target interface{}, patch interface{}) interface{} {
+	patchFields, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetFields, ok := target.(map[string]interface{})
+	if !ok {
+		targetFields = make(map[string]interface{})
+	}
+	for key, value := range patchFields {
+		if value == nil {
+			delete(targetFields, key)
+		} else {
+			targetFields[key] = applyMergePatch(targetFields[key], value)
+		}
+	}
+	return targetFields
+}
+
+// applyJsonPatchOperation implements a single operation of RFC 6902.
+func applyJsonPatchOperation(document interface{}, operation jsonPatchOperation) (interface{}, error) {
+	if operation.Path == nil {
+		return nil, fmt.Errorf("path is missing")
+	}
+	path, err := parsePointer(*operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return nil, fmt.Errorf("value is missing")
+		}
+		var value interface{}
+		if err := json.Unmarshal(*operation.Value, &value); err != nil {
+			return nil, err
+		}
+		if operation.Op == "add" {
+			return addAt(document, path, value)
+		}
+		if operation.Op == "replace" {
+			return replaceAt(document, path, value)
+		}
+		actual, err := getAt(document, path)
+		if err != nil || !reflect.DeepEqual(actual, value) {
+			return nil, errPatchTestFailed{path: *operation.Path}
+		}
+		return document, nil
+	case "remove":
+		return removeAt(document, path)
+	case "move", "copy":
+		if operation.From == nil {
+			return nil, fmt.Errorf("from is missing")
+		}
+		from, err := parsePointer(*operation.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := getAt(document, from)
+		if err != nil {
+			return nil, err
+		}
+		if operation.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, fmt.Errorf("cannot move %s into itself", *operation.From)
+			}
+			document, err = removeAt(document, from)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopy(value)
+		}
+		return addAt(document, path, value)
+	default:
+		return nil, fmt.Errorf("unsupported op '%s'", operation.Op)
+	}
+}
+
+// parsePointer splits a JSON pointer into its unescaped reference tokens, see RFC 6901.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %s must be empty or start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix []string, tokens []string) bool {
+	if len(prefix) > len(tokens) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != tokens[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func getAt(node interface{}, tokens []string) (interface{}, error) {
+	for _, token := range tokens {
+		switch typed := node.(type) {
+		case map[string]interface{}:
+			child, ok := typed[token]
+			if !ok {
+				return nil, fmt.Errorf("field %s does not exist", token)
+			}
+			node = child
+		case []interface{}:
+			index, err := listIndex(token, len(typed)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = typed[index]
+		default:
+			return nil, fmt.Errorf("cannot descend into %s, it is neither an object nor an array", token)
+		}
+	}
+	return node, nil
+}
+
+// updateAt replaces the node at the parent of the path with the result of leaf, which gets the last token.
+func updateAt(node interface{}, tokens []string, leaf func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return leaf(node, tokens[0])
+	}
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		child, ok := typed[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("field %s does not exist", tokens[0])
+		}
+		updated, err := updateAt(child, tokens[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		typed[tokens[0]] = updated
+		return typed, nil
+	case []interface{}:
+		index, err := listIndex(tokens[0], len(typed)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := updateAt(typed[index], tokens[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		typed[index] = updated
+		return typed, nil
+	default:
+		return nil, fmt.Errorf("cannot descend into %s, it is neither an object nor an array", tokens[0])
+	}
+}
+
+func addAt(document interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return updateAt(document, tokens, func(parent interface{}, token string) (interface{}, error) {
+		switch typed := parent.(type) {
+		case map[string]interface{}:
+			typed[token] = value
+			return typed, nil
+		case []interface{}:
+			index := len(typed)
+			if token != "-" {
+				var err error
+				index, err = listIndex(token, len(typed))
+				if err != nil {
+					return nil, err
+				}
+			}
+			result := make([]interface{}, 0, len(typed)+1)
+			result = append(result, typed[:index]...)
+			result = append(result, value)
+			return append(result, typed[index:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot add %s, parent is neither an object nor an array", token)
+		}
+	})
+}
+
+func removeAt(document interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole entry")
+	}
+	return updateAt(document, tokens, func(parent interface{}, token string) (interface{}, error) {
+		switch typed := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := typed[token]; !ok {
+				return nil, fmt.Errorf("field %s does not exist", token)
+			}
+			delete(typed, token)
+			return typed, nil
+		case []interface{}:
+			index, err := listIndex(token, len(typed)-1)
+			if err != nil {
+				return nil, err
+			}
+			result := make([]interface{}, 0, len(typed)-1)
+			result = append(result, typed[:index]...)
+			return append(result, typed[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot remove %s, parent is neither an object nor an array", token)
+		}
+	})
+}
+
+func replaceAt(document interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return updateAt(document, tokens, func(parent interface{}, token string) (interface{}, error) {
+		switch typed := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := typed[token]; !ok {
+				return nil, fmt.Errorf("field %s does not exist", token)
+			}
+			typed[token] = value
+			return typed, nil
+		case []interface{}:
+			index, err := listIndex(token, len(typed)-1)
+			if err != nil {
+				return nil, err
+			}
+			typed[index] = value
+			return typed, nil
+		default:
+			return nil, fmt.Errorf("cannot replace %s, parent is neither an object nor an array", token)
+		}
+	})
+}
+
+// listIndex parses an array index, which must be between 0 and maxIndex inclusive.
+func listIndex(token string, maxIndex int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > maxIndex || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("array index %s is invalid or out of bounds", token)
+	}
+	return index, nil
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for key, child := range typed {
+			result[key] = deepCopy(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for i, child := range typed {
+			result[i] = deepCopy(child)
+		}
+		return result
+	default:
+		return value
+	}
+}
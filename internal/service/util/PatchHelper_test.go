@@ -0,0 +1,94 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+var tstPatchNow = time.Date(2022, 11, 6, 18, 14, 10, 0, time.UTC)
+
+func tstPatchOwner() openapi.OwnerDto {
+	return openapi.OwnerDto{
+		Contact:   "somebody@some-organisation.com",
+		Members:   []string{"a", "b"},
+		Groups:    map[string][]string{"admins": {"a"}, "users": {"a", "b"}},
+		Promoters: []string{"a"},
+	}
+}
+
+func tstApplyPatch(t *testing.T, mediaType string, patch string) (openapi.OwnerDto, error) {
+	result := openapi.OwnerDto{}
+	err := ApplyPatch(tstPatchOwner(), types.PatchDocument{MediaType: mediaType, Body: []byte(patch)}, &result, tstPatchNow)
+	return result, err
+}
+
+func TestApplyPatch_MergePatch(t *testing.T) {
+	docs.Description("merge patches remove fields and map keys with null, clear lists and merge objects")
+	result, err := tstApplyPatch(t, types.MergePatchMediaType, `{"groups":{"admins":null,"ops":["c"]},"promoters":[],"contact":"x"}`)
+	require.Nil(t, err)
+	require.Equal(t, "x", result.Contact)
+	require.Equal(t, map[string][]string{"users": {"a", "b"}, "ops": {"c"}}, result.Groups)
+	require.Empty(t, result.Promoters)
+	require.Equal(t, []string{"a", "b"}, result.Members)
+
+	result, err = tstApplyPatch(t, types.MergePatchMediaType, `{"members":null}`)
+	require.Nil(t, err)
+	require.Nil(t, result.Members)
+}
+
+func TestApplyPatch_JsonPatch(t *testing.T) {
+	docs.Description("json patches append to lists, remove single map keys and move values")
+	result, err := tstApplyPatch(t, types.JsonPatchMediaType, `[
+		{"op":"add","path":"/members/-","value":"c"},
+		{"op":"add","path":"/members/0","value":"z"},
+		{"op":"remove","path":"/groups/admins"},
+		{"op":"copy","from":"/members","path":"/promoters"},
+		{"op":"move","from":"/groups/users","path":"/groups/people"},
+		{"op":"replace","path":"/contact","value":"x"},
+		{"op":"test","path":"/contact","value":"x"}
+	]`)
+	require.Nil(t, err)
+	require.Equal(t, []string{"z", "a", "b", "c"}, result.Members)
+	require.Equal(t, []string{"z", "a", "b", "c"}, result.Promoters)
+	require.Equal(t, map[string][]string{"people": {"a", "b"}}, result.Groups)
+	require.Equal(t, "x", result.Contact)
+}
+
+func TestApplyPatch_JsonPatchEscaping(t *testing.T) {
+	docs.Description("json pointers unescape ~1 to / and ~0 to ~")
+	result, err := tstApplyPatch(t, types.JsonPatchMediaType, `[{"op":"add","path":"/groups/a~1b~0c","value":["x"]}]`)
+	require.Nil(t, err)
+	require.Equal(t, []string{"x"}, result.Groups["a/b~c"])
+}
+
+func TestApplyPatch_Invalid(t *testing.T) {
+	docs.Description("invalid patches are bad requests, failed tests are conflicts")
+	for _, patch := range []string{
+		`{"op":"add"}`,
+		`[{"op":"add","path":"/members/5","value":"c"}]`,
+		`[{"op":"remove","path":"/groups/nobody"}]`,
+		`[{"op":"replace","path":"/displayName","value":"x"}]`,
+		`[{"op":"frobnicate","path":"/contact"}]`,
+		`[{"op":"add","path":"members","value":"c"}]`,
+		`[{"op":"move","from":"/groups","path":"/groups/x"}]`,
+		`[{"op":"add","path":"/members/01","value":"c"}]`,
+	} {
+		_, err := tstApplyPatch(t, types.JsonPatchMediaType, patch)
+		require.True(t, apierrors.IsBadRequestError(err), patch)
+	}
+
+	_, err := tstApplyPatch(t, types.MergePatchMediaType, `{"contact":`)
+	require.True(t, apierrors.IsBadRequestError(err))
+
+	_, err = tstApplyPatch(t, types.MergePatchMediaType, `{"members":"a"}`)
+	require.True(t, apierrors.IsBadRequestError(err))
+
+	_, err = tstApplyPatch(t, types.JsonPatchMediaType, `[{"op":"test","path":"/contact","value":"x"}]`)
+	require.True(t, apierrors.IsConflictError(err))
+}
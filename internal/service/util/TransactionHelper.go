@@ -0,0 +1,35 @@
+package util
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+)
+
+// DecodeTransactionBody decodes the body of a transaction operation into result, which must be a pointer.
+//
+// If the body does not give a jira issue, the one of the transaction is used. A body that does not fit result
+// gives a bad request error.
+func DecodeTransactionBody(body map[string]interface{}, jiraIssue string, result interface{}, now time.Time) error {
+	if body == nil {
+		return apierrors.NewBadRequestError("transaction.invalid.body", "body is mandatory", nil, now)
+	}
+
+	withJiraIssue := make(map[string]interface{}, len(body)+1)
+	for k, v := range body {
+		withJiraIssue[k] = v
+	}
+	if issue, ok := withJiraIssue["jiraIssue"]; !ok || issue == "" {
+		withJiraIssue["jiraIssue"] = jiraIssue
+	}
+
+	marshalled, err := json.Marshal(withJiraIssue)
+	if err != nil {
+		return apierrors.NewBadRequestError("transaction.invalid.body", "body failed to parse", err, now)
+	}
+	if err := json.Unmarshal(marshalled, result); err != nil {
+		return apierrors.NewBadRequestError("transaction.invalid.body", "body failed to parse", err, now)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeTransactionBody_DefaultsJiraIssue(t *testing.T) {
+	docs.Description("the jira issue of the transaction is used unless the body gives one")
+	result := openapi.OwnerCreateDto{}
+	err := DecodeTransactionBody(map[string]interface{}{"contact": "x"}, "ISSUE-1", &result, tstPatchNow)
+	require.Nil(t, err)
+	require.Equal(t, "x", result.Contact)
+	require.Equal(t, "ISSUE-1", result.JiraIssue)
+
+	result = openapi.OwnerCreateDto{}
+	err = DecodeTransactionBody(map[string]interface{}{"contact": "x", "jiraIssue": "ISSUE-2"}, "ISSUE-1", &result, tstPatchNow)
+	require.Nil(t, err)
+	require.Equal(t, "ISSUE-2", result.JiraIssue)
+}
+
+func TestDecodeTransactionBody_Invalid(t *testing.T) {
+	docs.Description("a missing body or one that does not fit the dto is a bad request")
+	result := openapi.OwnerCreateDto{}
+	err := DecodeTransactionBody(nil, "ISSUE-1", &result, tstPatchNow)
+	require.True(t, apierrors.IsBadRequestError(err))
+
+	err = DecodeTransactionBody(map[string]interface{}{"contact": 42}, "ISSUE-1", &result, tstPatchNow)
+	require.True(t, apierrors.IsBadRequestError(err))
+}
@@ -121,7 +121,7 @@ func (h *Impl) processGitHubCheckSuiteEvent(
 	case "requested":
 		fallthrough
 	case "rerequested":
-		return h.Check.PerformValidationCheckRun(ctx, event.GetRepo().GetOwner().GetLogin(), event.GetRepo().GetName(), event.GetCheckSuite().GetHeadSHA())
+		return h.Check.PerformValidationCheckRun(ctx, event.GetRepo().GetOwner().GetLogin(), event.GetRepo().GetName(), pullRequestForHead(event.GetCheckSuite().PullRequests, event.GetCheckSuite().GetHeadSHA()), event.GetCheckSuite().GetHeadSHA())
 	}
 	return nil
 }
@@ -132,10 +132,23 @@ func (h *Impl) processGitHubCheckRunEvent(
 ) error {
 	switch event.GetAction() {
 	case "rerequested":
-		return h.Check.PerformValidationCheckRun(ctx, event.GetRepo().GetOwner().GetLogin(), event.GetRepo().GetName(), event.GetCheckRun().GetHeadSHA())
+		return h.Check.PerformValidationCheckRun(ctx, event.GetRepo().GetOwner().GetLogin(), event.GetRepo().GetName(), pullRequestForHead(event.GetCheckRun().PullRequests, event.GetCheckRun().GetHeadSHA()), event.GetCheckRun().GetHeadSHA())
 	case "requested_action":
 		return h.Check.PerformRequestedAction(ctx, event.GetRequestedAction().Identifier, event.GetCheckRun(), event.GetSender())
 	}
 
 	return nil
 }
+
+// pullRequestForHead picks the pull request whose head is the commit being checked, so the check can compare against
+// its base. If several have this head, such as pull requests into different branches, the first one is used.
+//
+// nil if there is none, for example for a push to a branch without a pull request, and then everything is validated.
+func pullRequestForHead(pullRequests []*github.PullRequest, headSha string) *github.PullRequest {
+	for _, pullRequest := range pullRequests {
+		if pullRequest.GetHead().GetSHA() == headSha && pullRequest.GetBase().GetSHA() != "" {
+			return pullRequest
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package webhookshandler
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v70/github"
+	"github.com/stretchr/testify/require"
+)
+
+func tstPullRequest(number int, headSha string, baseSha string) *github.PullRequest {
+	return &github.PullRequest{
+		Number: github.Ptr(number),
+		Head:   &github.PullRequestBranch{SHA: github.Ptr(headSha)},
+		Base:   &github.PullRequestBranch{SHA: github.Ptr(baseSha)},
+	}
+}
+
+func TestPullRequestForHead(t *testing.T) {
+	pullRequests := []*github.PullRequest{
+		tstPullRequest(1, "other-head", "base-1"),
+		tstPullRequest(2, "head", "base-2"),
+		tstPullRequest(3, "head", "base-3"),
+	}
+
+	require.Equal(t, 2, pullRequestForHead(pullRequests, "head").GetNumber())
+	require.Nil(t, pullRequestForHead(pullRequests, "unknown-head"))
+	require.Nil(t, pullRequestForHead(nil, "head"))
+}
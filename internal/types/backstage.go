@@ -0,0 +1,57 @@
+package types
+
+// BackstageEntity is an entity of the Backstage software catalog, see
+// https://backstage.io/docs/features/software-catalog/descriptor-format.
+//
+// Spec is one of BackstageGroupSpec, BackstageComponentSpec or BackstageApiSpec, depending on Kind.
+type BackstageEntity struct {
+	ApiVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   BackstageMetadata `yaml:"metadata"`
+	Spec       interface{}       `yaml:"spec"`
+}
+
+type BackstageMetadata struct {
+	Name        string            `yaml:"name"`
+	Title       string            `yaml:"title,omitempty"`
+	Description string            `yaml:"description,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Tags        []string          `yaml:"tags,omitempty"`
+	Links       []BackstageLink   `yaml:"links,omitempty"`
+}
+
+type BackstageLink struct {
+	Url   string `yaml:"url"`
+	Title string `yaml:"title,omitempty"`
+}
+
+type BackstageGroupSpec struct {
+	Type     string                 `yaml:"type"`
+	Profile  *BackstageGroupProfile `yaml:"profile,omitempty"`
+	Children []string               `yaml:"children"`
+	Members  []string               `yaml:"members,omitempty"`
+}
+
+type BackstageGroupProfile struct {
+	DisplayName string `yaml:"displayName,omitempty"`
+	Email       string `yaml:"email,omitempty"`
+}
+
+type BackstageComponentSpec struct {
+	Type         string   `yaml:"type"`
+	Lifecycle    string   `yaml:"lifecycle"`
+	Owner        string   `yaml:"owner"`
+	System       string   `yaml:"system,omitempty"`
+	DependsOn    []string `yaml:"dependsOn,omitempty"`
+	ProvidesApis []string `yaml:"providesApis,omitempty"`
+	ConsumesApis []string `yaml:"consumesApis,omitempty"`
+}
+
+type BackstageApiSpec struct {
+	Type       string `yaml:"type"`
+	Lifecycle  string `yaml:"lifecycle"`
+	Owner      string `yaml:"owner"`
+	System     string `yaml:"system,omitempty"`
+	Definition string `yaml:"definition"`
+}
@@ -0,0 +1,8 @@
+package types
+
+// kinds of entities that can be listed as deleted and restored, as used in the url
+const (
+	DeletedOwners       = "owners"
+	DeletedServices     = "services"
+	DeletedRepositories = "repositories"
+)
@@ -0,0 +1,19 @@
+package types
+
+import "context"
+
+type dryRunKey struct{}
+
+// WithDryRun marks a write request as a dry run.
+//
+// A dry run goes through all validations, but the Updater does not write, commit or push anything,
+// and the caches are left alone.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun is true if the request was marked with WithDryRun.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
@@ -0,0 +1,15 @@
+package types
+
+// EventFilter selects the update events a subscriber of the event stream receives.
+//
+// An event matches if it affects any of the listed owners, services or repositories. An empty filter matches
+// all events.
+type EventFilter struct {
+	OwnerAliases   []string
+	ServiceNames   []string
+	RepositoryKeys []string
+}
+
+func (f EventFilter) IsEmpty() bool {
+	return len(f.OwnerAliases) == 0 && len(f.ServiceNames) == 0 && len(f.RepositoryKeys) == 0
+}
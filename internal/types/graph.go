@@ -0,0 +1,30 @@
+package types
+
+// GraphDirection selects which relations are followed when a dependency graph is rooted at a service.
+type GraphDirection string
+
+const (
+	// GraphDirectionBoth follows dependencies and dependents.
+	GraphDirectionBoth GraphDirection = "both"
+	// GraphDirectionDependencies follows what a service depends on, and the providers of the apis it consumes.
+	GraphDirectionDependencies GraphDirection = "dependencies"
+	// GraphDirectionDependents follows who depends on a service, and the consumers of the apis it provides.
+	GraphDirectionDependents GraphDirection = "dependents"
+)
+
+func (d GraphDirection) IsValid() bool {
+	return d == GraphDirectionBoth || d == GraphDirectionDependencies || d == GraphDirectionDependents
+}
+
+const (
+	GraphNodeTypeService = "service"
+	GraphNodeTypeApi     = "api"
+	// GraphNodeTypeUnknown is a service that is referenced in dependsOn but does not exist.
+	GraphNodeTypeUnknown = "unknown"
+)
+
+const (
+	GraphEdgeTypeDependsOn   = "dependsOn"
+	GraphEdgeTypeProvidesApi = "providesApi"
+	GraphEdgeTypeConsumesApi = "consumesApi"
+)
@@ -0,0 +1,18 @@
+package types
+
+// PageRequest holds the paging and sorting parameters of a list request.
+//
+// The zero value requests the complete, unsorted list, which is what clients got before paging was introduced.
+type PageRequest struct {
+	// Limit is the maximum number of entries to return. 0 means no limit.
+	Limit int
+	// Cursor is the opaque cursor obtained from a previous page, or empty for the first page.
+	Cursor string
+	// Sort is the field to sort by, optionally prefixed with '-' for descending order.
+	Sort string
+}
+
+// IsActive is true if any paging or sorting was requested.
+func (p PageRequest) IsActive() bool {
+	return p.Limit > 0 || p.Cursor != "" || p.Sort != ""
+}
@@ -0,0 +1,15 @@
+package types
+
+const (
+	// MergePatchMediaType is the content type of a JSON merge patch, see RFC 7386.
+	MergePatchMediaType = "application/merge-patch+json"
+	// JsonPatchMediaType is the content type of a JSON patch, see RFC 6902.
+	JsonPatchMediaType = "application/json-patch+json"
+)
+
+// PatchDocument is a standard patch document, to be applied to the json representation of an entry.
+type PatchDocument struct {
+	// MediaType is MergePatchMediaType or JsonPatchMediaType.
+	MediaType string
+	Body      []byte
+}
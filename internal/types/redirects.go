@@ -0,0 +1,45 @@
+package types
+
+// RedirectsPath is the location of the redirects file in the metadata repository.
+//
+// It is outside owners/, so it is not validated as an owner, service or repository.
+const RedirectsPath = "redirects.yaml"
+
+// Redirects maps the former names of renamed entities to their current names.
+type Redirects struct {
+	Owners       map[string]string `yaml:"owners,omitempty" json:"owners,omitempty"`
+	Services     map[string]string `yaml:"services,omitempty" json:"services,omitempty"`
+	Repositories map[string]string `yaml:"repositories,omitempty" json:"repositories,omitempty"`
+}
+
+// AddOwner records that an owner was renamed, see addRedirect.
+func (r *Redirects) AddOwner(from string, to string) {
+	r.Owners = addRedirect(r.Owners, from, to)
+}
+
+// AddService records that a service was renamed, see addRedirect.
+func (r *Redirects) AddService(from string, to string) {
+	r.Services = addRedirect(r.Services, from, to)
+}
+
+// AddRepository records that a repository was renamed, see addRedirect.
+func (r *Redirects) AddRepository(from string, to string) {
+	r.Repositories = addRedirect(r.Repositories, from, to)
+}
+
+// addRedirect adds a redirect, keeping earlier redirects to the old name pointing to the current name.
+//
+// A redirect for the new name is dropped, it is in use again.
+func addRedirect(redirects map[string]string, from string, to string) map[string]string {
+	if redirects == nil {
+		redirects = make(map[string]string)
+	}
+	for former, current := range redirects {
+		if current == from {
+			redirects[former] = to
+		}
+	}
+	redirects[from] = to
+	delete(redirects, to)
+	return redirects
+}
@@ -0,0 +1,16 @@
+package types
+
+// operations of a transaction
+const (
+	TransactionCreate = "create"
+	TransactionUpdate = "update"
+	TransactionPatch  = "patch"
+	TransactionDelete = "delete"
+)
+
+// kinds of entities a transaction operation can change
+const (
+	TransactionOwner      = "owner"
+	TransactionService    = "service"
+	TransactionRepository = "repository"
+)
@@ -23,6 +23,7 @@ const (
 	CreatedEvent NotificationEventType = iota
 	ModifiedEvent
 	DeletedEvent
+	LifecycleChangedEvent
 )
 
 func (p NotificationEventType) String() string {
@@ -33,6 +34,8 @@ func (p NotificationEventType) String() string {
 		return "MODIFIED"
 	case DeletedEvent:
 		return "DELETED"
+	case LifecycleChangedEvent:
+		return "LIFECYCLE_CHANGED"
 	default:
 		return ""
 	}
@@ -0,0 +1,20 @@
+package types
+
+// roles of a user in an owner
+const (
+	UserRoleMember       = "member"
+	UserRoleProductOwner = "productOwner"
+	UserRolePromoter     = "promoter"
+)
+
+// roles of a user in a repository
+const (
+	UserRoleApprover = "approver"
+	UserRoleWatcher  = "watcher"
+)
+
+// kinds of entities that contain group references
+const (
+	GroupReferenceInOwner      = "owner"
+	GroupReferenceInRepository = "repository"
+)
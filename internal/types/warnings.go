@@ -0,0 +1,46 @@
+package types
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+type warningsKey struct{}
+
+type warnings struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// WithWarnings lets a write request collect problems that do not fail it, see AddWarning.
+//
+// The controller reports them to the caller with the response.
+func WithWarnings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, warningsKey{}, &warnings{})
+}
+
+// AddWarning records a problem for the response. It is dropped unless the request was marked with WithWarnings.
+func AddWarning(ctx context.Context, message string) {
+	collected, ok := ctx.Value(warningsKey{}).(*warnings)
+	if !ok {
+		return
+	}
+	collected.mu.Lock()
+	defer collected.mu.Unlock()
+	// validations may run more than once for a request, e.g. on retries
+	if !slices.Contains(collected.messages, message) {
+		collected.messages = append(collected.messages, message)
+	}
+}
+
+// Warnings lists the problems recorded with AddWarning, in the order they were recorded.
+func Warnings(ctx context.Context) []string {
+	collected, ok := ctx.Value(warningsKey{}).(*warnings)
+	if !ok {
+		return nil
+	}
+	collected.mu.Lock()
+	defer collected.mu.Unlock()
+	return slices.Clone(collected.messages)
+}
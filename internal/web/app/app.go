@@ -22,17 +22,31 @@ import (
 	"github.com/Interhyp/metadata-service/internal/repository/kafka"
 	"github.com/Interhyp/metadata-service/internal/repository/metadata"
 	"github.com/Interhyp/metadata-service/internal/repository/notifier"
+	"github.com/Interhyp/metadata-service/internal/service/backstage"
 	"github.com/Interhyp/metadata-service/internal/service/check"
+	"github.com/Interhyp/metadata-service/internal/service/events"
+	"github.com/Interhyp/metadata-service/internal/service/graph"
 	"github.com/Interhyp/metadata-service/internal/service/mapper"
 	"github.com/Interhyp/metadata-service/internal/service/owners"
 	"github.com/Interhyp/metadata-service/internal/service/repositories"
+	"github.com/Interhyp/metadata-service/internal/service/search"
 	"github.com/Interhyp/metadata-service/internal/service/services"
+	"github.com/Interhyp/metadata-service/internal/service/transactions"
 	"github.com/Interhyp/metadata-service/internal/service/trigger"
 	"github.com/Interhyp/metadata-service/internal/service/updater"
+	"github.com/Interhyp/metadata-service/internal/service/users"
 	"github.com/Interhyp/metadata-service/internal/service/webhookshandler"
+	"github.com/Interhyp/metadata-service/internal/web/controller/deletedctl"
+	"github.com/Interhyp/metadata-service/internal/web/controller/eventctl"
+	"github.com/Interhyp/metadata-service/internal/web/controller/exportctl"
+	"github.com/Interhyp/metadata-service/internal/web/controller/graphctl"
 	"github.com/Interhyp/metadata-service/internal/web/controller/ownerctl"
+	"github.com/Interhyp/metadata-service/internal/web/controller/reportctl"
 	"github.com/Interhyp/metadata-service/internal/web/controller/repositoryctl"
+	"github.com/Interhyp/metadata-service/internal/web/controller/searchctl"
 	"github.com/Interhyp/metadata-service/internal/web/controller/servicectl"
+	"github.com/Interhyp/metadata-service/internal/web/controller/transactionctl"
+	"github.com/Interhyp/metadata-service/internal/web/controller/userctl"
 	"github.com/Interhyp/metadata-service/internal/web/controller/webhookctl"
 	"github.com/Interhyp/metadata-service/internal/web/server"
 	aurestrecorder "github.com/StephanHCB/go-autumn-restclient/implementation/recorder"
@@ -68,15 +82,29 @@ type ApplicationImpl struct {
 	Owners          service.Owners
 	Services        service.Services
 	Repositories    service.Repositories
+	Search          service.Search
+	Events          service.Events
+	Graph           service.Graph
+	Backstage       service.Backstage
+	Transactions    service.Transactions
+	Users           service.Users
 	WebhooksHandler service.WebhooksHandler
 
 	// controllers (incoming connectors)
-	HealthCtl     libcontroller.HealthController
-	SwaggerCtl    libcontroller.SwaggerController
-	OwnerCtl      controller.OwnerController
-	ServiceCtl    controller.ServiceController
-	RepositoryCtl controller.RepositoryController
-	WebhookCtl    controller.WebhookController
+	HealthCtl      libcontroller.HealthController
+	SwaggerCtl     libcontroller.SwaggerController
+	OwnerCtl       controller.OwnerController
+	ServiceCtl     controller.ServiceController
+	RepositoryCtl  controller.RepositoryController
+	SearchCtl      controller.SearchController
+	EventCtl       controller.EventController
+	GraphCtl       controller.GraphController
+	ExportCtl      controller.ExportController
+	TransactionCtl controller.TransactionController
+	DeletedCtl     controller.DeletedController
+	UserCtl        controller.UserController
+	ReportCtl      controller.ReportController
+	WebhookCtl     controller.WebhookController
 
 	// server/web stack
 	Server application.Server
@@ -214,7 +242,17 @@ func (a *ApplicationImpl) ConstructServices() error {
 		return err
 	}
 
-	a.Updater = updater.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Kafka, a.Notifier, a.Mapper, a.Cache)
+	a.Search = search.New(a.Config, a.Logging, a.Timestamp)
+	if err := a.Search.Setup(); err != nil {
+		return err
+	}
+
+	a.Events = events.New(a.Config, a.Logging, a.Timestamp)
+	if err := a.Events.Setup(); err != nil {
+		return err
+	}
+
+	a.Updater = updater.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Kafka, a.Notifier, a.Mapper, a.Cache, a.Search, a.Events)
 	if err := a.Updater.Setup(); err != nil {
 		return err
 	}
@@ -233,11 +271,31 @@ func (a *ApplicationImpl) ConstructServices() error {
 		return err
 	}
 
-	a.Services = services.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Cache, a.Updater, a.Repositories)
+	a.Graph = graph.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Cache)
+	if err := a.Graph.Setup(); err != nil {
+		return err
+	}
+
+	a.Services = services.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Cache, a.Updater, a.Owners, a.Repositories, a.Graph)
 	if err := a.Services.Setup(); err != nil {
 		return err
 	}
 
+	a.Backstage = backstage.New(a.Config, a.Logging, a.Timestamp, a.Cache)
+	if err := a.Backstage.Setup(); err != nil {
+		return err
+	}
+
+	a.Transactions = transactions.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Cache, a.Updater, a.Owners, a.Services, a.Repositories, a.Graph)
+	if err := a.Transactions.Setup(); err != nil {
+		return err
+	}
+
+	a.Users = users.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Cache, a.Owners, a.Repositories)
+	if err := a.Users.Setup(); err != nil {
+		return err
+	}
+
 	a.Validator = check.New(a.Config, a.Repositories, a.Github, a.AuthProvider, a.Timestamp)
 
 	if a.WebhooksHandler == nil {
@@ -255,10 +313,18 @@ func (a *ApplicationImpl) ConstructControllers() error {
 	a.OwnerCtl = ownerctl.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Owners)
 	a.ServiceCtl = servicectl.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Services)
 	a.RepositoryCtl = repositoryctl.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Repositories)
+	a.SearchCtl = searchctl.New(a.Logging, a.Timestamp, a.Search)
+	a.EventCtl = eventctl.New(a.Logging, a.Timestamp, a.Events)
+	a.GraphCtl = graphctl.New(a.Logging, a.Timestamp, a.Graph)
+	a.ExportCtl = exportctl.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Backstage)
+	a.TransactionCtl = transactionctl.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Transactions)
+	a.DeletedCtl = deletedctl.New(a.Config, a.CustomConfig, a.Logging, a.Timestamp, a.Owners, a.Services, a.Repositories)
+	a.UserCtl = userctl.New(a.Logging, a.Timestamp, a.Users)
+	a.ReportCtl = reportctl.New(a.Logging, a.Timestamp, a.Owners)
 	a.WebhookCtl = webhookctl.New(a.Logging, a.Timestamp, a.WebhooksHandler)
 
 	a.Server = server.New(a.Config, a.CustomConfig, a.Logging, a.IdentityProvider,
-		a.HealthCtl, a.SwaggerCtl, a.OwnerCtl, a.ServiceCtl, a.RepositoryCtl, a.WebhookCtl)
+		a.HealthCtl, a.SwaggerCtl, a.OwnerCtl, a.ServiceCtl, a.RepositoryCtl, a.SearchCtl, a.EventCtl, a.GraphCtl, a.ExportCtl, a.TransactionCtl, a.DeletedCtl, a.UserCtl, a.ReportCtl, a.WebhookCtl)
 	if err := a.Server.Setup(); err != nil {
 		return err
 	}
@@ -0,0 +1,165 @@
+package deletedctl
+
+import (
+	"context"
+	"fmt"
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/go-backend-service-common/web/middleware/security"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/config"
+	"github.com/Interhyp/metadata-service/internal/acorn/controller"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	serviceutil "github.com/Interhyp/metadata-service/internal/service/util"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"github.com/Interhyp/metadata-service/internal/web/util"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/url"
+)
+
+type Impl struct {
+	Configuration       librepo.Configuration
+	CustomConfiguration config.CustomConfiguration
+	Logging             librepo.Logging
+	Timestamp           librepo.Timestamp
+	Owners              service.Owners
+	Services            service.Services
+	Repositories        service.Repositories
+}
+
+func New(
+	configuration librepo.Configuration,
+	customConfig config.CustomConfiguration,
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+	owners service.Owners,
+	services service.Services,
+	repositories service.Repositories,
+) controller.DeletedController {
+	return &Impl{
+		Configuration:       configuration,
+		CustomConfiguration: customConfig,
+		Logging:             logging,
+		Timestamp:           timestamp,
+		Owners:              owners,
+		Services:            services,
+		Repositories:        repositories,
+	}
+}
+
+func (c *Impl) IsDeletedController() bool {
+	return true
+}
+
+func (c *Impl) WireUp(_ context.Context, router chi.Router) {
+	kindEndpoint := "/rest/api/v1/deleted/{kind}"
+
+	router.Get(kindEndpoint, c.GetDeleted)
+	router.Post(kindEndpoint+"/{name}/restore", c.Restore)
+}
+
+// --- handlers ---
+
+func (c *Impl) GetDeleted(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	kind := util.StringPathParam(r, "kind")
+
+	var deleted openapi.DeletedListDto
+	var err error
+	switch kind {
+	case types.DeletedOwners:
+		deleted, err = c.Owners.GetDeletedOwners(ctx)
+	case types.DeletedServices:
+		deleted, err = c.Services.GetDeletedServices(ctx)
+	case types.DeletedRepositories:
+		deleted, err = c.Repositories.GetDeletedRepositories(ctx)
+	default:
+		err = c.invalidKind(ctx, kind)
+	}
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+	} else {
+		util.Success(ctx, w, r, deleted)
+	}
+}
+
+func (c *Impl) Restore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := security.IsAuthenticated(ctx, "anonymous tried Restore", c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsUnauthorisedError)
+		return
+	}
+	if err := security.HasGroup(ctx, c.CustomConfiguration.AuthGroupWrite(), fmt.Sprintf("%s tried Restore", security.Subject(ctx)), c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
+		return
+	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	kind := util.StringPathParam(r, "kind")
+	name := util.StringPathParam(r, "name")
+	if err := c.validName(ctx, kind, name); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	restoreDto, err := c.parseBodyToRestoreDto(ctx, r)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	var restored interface{}
+	switch kind {
+	case types.DeletedOwners:
+		restored, err = c.Owners.RestoreOwner(ctx, name, restoreDto)
+	case types.DeletedServices:
+		restored, err = c.Services.RestoreService(ctx, name, restoreDto)
+	default:
+		restored, err = c.Repositories.RestoreRepository(ctx, name, restoreDto)
+	}
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err,
+			apierrors.IsBadRequestError,
+			apierrors.IsNotFoundError,
+			apierrors.IsConflictError,
+			apierrors.IsBadGatewayError)
+	} else {
+		util.Success(ctx, w, r, restored)
+	}
+}
+
+// --- helpers
+
+func (c *Impl) invalidKind(ctx context.Context, kind string) apierrors.AnnotatedError {
+	c.Logging.Logger().Ctx(ctx).Info().Printf("kind parameter %v invalid", url.QueryEscape(kind))
+	return apierrors.NewBadRequestError("kind.invalid", fmt.Sprintf("kind must be one of %s, %s or %s", types.DeletedOwners, types.DeletedServices, types.DeletedRepositories), nil, c.Timestamp.Now())
+}
+
+// validName checks the name of the entity to restore against the current rules, they may have changed since its deletion.
+func (c *Impl) validName(ctx context.Context, kind string, name string) apierrors.AnnotatedError {
+	switch kind {
+	case types.DeletedOwners:
+		return serviceutil.ValidOwnerAlias(ctx, c.CustomConfiguration, name, c.Timestamp.Now())
+	case types.DeletedServices:
+		return serviceutil.ValidServiceName(ctx, c.CustomConfiguration, name, c.Timestamp.Now())
+	case types.DeletedRepositories:
+		return c.Repositories.ValidRepositoryKey(ctx, name)
+	default:
+		return c.invalidKind(ctx, kind)
+	}
+}
+
+func (c *Impl) parseBodyToRestoreDto(ctx context.Context, r *http.Request) (openapi.RestoreDto, error) {
+	dto := openapi.RestoreDto{}
+	err := util.ParseBody(r, &dto)
+	if err != nil {
+		c.Logging.Logger().Ctx(ctx).Info().Printf("restore body invalid: %s", err.Error())
+		return openapi.RestoreDto{}, apierrors.NewBadRequestError("restore.invalid.body", "body failed to parse", err, c.Timestamp.Now())
+	}
+	return dto, nil
+}
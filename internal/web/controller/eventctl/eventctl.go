@@ -0,0 +1,142 @@
+package eventctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/internal/acorn/controller"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/goneerror"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"github.com/Interhyp/metadata-service/internal/web/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-http-utils/headers"
+	"io"
+	"net/http"
+	"time"
+)
+
+const ownerParam = "owner"
+const serviceParam = "service"
+const repositoryParam = "repository"
+
+// lastEventIdParam is an alternative to the Last-Event-ID header, browsers cannot set headers on the first connect.
+const lastEventIdParam = "lastEventId"
+
+const lastEventIdHeader = "Last-Event-ID"
+
+const contentTypeEventStream = "text/event-stream"
+
+const eventType = "update"
+
+// keepaliveInterval is how often we send a comment on an idle stream, so proxies do not close it.
+const keepaliveInterval = 15 * time.Second
+
+type Impl struct {
+	Logging   librepo.Logging
+	Timestamp librepo.Timestamp
+	Events    service.Events
+}
+
+func New(
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+	events service.Events,
+) controller.EventController {
+	return &Impl{
+		Logging:   logging,
+		Timestamp: timestamp,
+		Events:    events,
+	}
+}
+
+func (c *Impl) IsEventController() bool {
+	return true
+}
+
+func (c *Impl) WireUp(_ context.Context, router chi.Router) {
+	router.Get("/rest/api/v1/events", c.GetEvents)
+}
+
+// --- handlers ---
+
+func (c *Impl) GetEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	lastEventId := r.Header.Get(lastEventIdHeader)
+	if lastEventId == "" {
+		lastEventId = util.StringQueryParam(r, lastEventIdParam)
+	}
+	filter := types.EventFilter{
+		OwnerAliases:   r.URL.Query()[ownerParam],
+		ServiceNames:   r.URL.Query()[serviceParam],
+		RepositoryKeys: r.URL.Query()[repositoryParam],
+	}
+
+	events, unsubscribe, err := c.Events.Subscribe(ctx, lastEventId, filter)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, goneerror.Is)
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set(headers.ContentType, contentTypeEventStream)
+	w.Header().Set(headers.CacheControl, "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // do not let nginx buffer the stream
+	w.WriteHeader(http.StatusOK)
+
+	// the stream is exempt from the request timeout, but the write timeout of the server applies to every response
+	responseController := http.NewResponseController(w)
+	if err := responseController.SetWriteDeadline(time.Time{}); err != nil {
+		c.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Print("failed to clear the write deadline of the event stream, it ends with the write timeout")
+	}
+	if err := writeAndFlush(w, responseController, ": connected\n\n"); err != nil {
+		return
+	}
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepalive.C:
+			if err := writeAndFlush(w, responseController, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				// we fell behind and were dropped, the client resumes from the last event it received
+				return
+			}
+			message, err := formatEvent(event)
+			if err != nil {
+				c.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("failed to format event for commit %s", event.CommitHash)
+				return
+			}
+			if err := writeAndFlush(w, responseController, message); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// --- helpers
+
+func formatEvent(event repository.UpdateEvent) (string, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", event.CommitHash, eventType, data), nil
+}
+
+func writeAndFlush(w io.Writer, responseController *http.ResponseController, message string) error {
+	if _, err := io.WriteString(w, message); err != nil {
+		return err
+	}
+	return responseController.Flush()
+}
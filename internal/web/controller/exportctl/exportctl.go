@@ -0,0 +1,61 @@
+package exportctl
+
+import (
+	"context"
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/internal/acorn/config"
+	"github.com/Interhyp/metadata-service/internal/acorn/controller"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/web/util"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+const ownerParam = "owner"
+
+type Impl struct {
+	Configuration       librepo.Configuration
+	CustomConfiguration config.CustomConfiguration
+	Logging             librepo.Logging
+	Timestamp           librepo.Timestamp
+	Backstage           service.Backstage
+}
+
+func New(
+	configuration librepo.Configuration,
+	customConfig config.CustomConfiguration,
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+	backstage service.Backstage,
+) controller.ExportController {
+	return &Impl{
+		Configuration:       configuration,
+		CustomConfiguration: customConfig,
+		Logging:             logging,
+		Timestamp:           timestamp,
+		Backstage:           backstage,
+	}
+}
+
+func (c *Impl) IsExportController() bool {
+	return true
+}
+
+func (c *Impl) WireUp(_ context.Context, router chi.Router) {
+	router.Get("/rest/api/v1/export/backstage", c.GetBackstageExport)
+}
+
+// --- handlers ---
+
+func (c *Impl) GetBackstageExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ownerAlias := util.StringQueryParam(r, ownerParam)
+
+	entities, err := c.Backstage.GetEntities(ctx, ownerAlias)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsNotFoundError)
+	} else {
+		util.SuccessYamlStream(ctx, w, r, entities, c.CustomConfiguration.YamlIndentation(), c.Timestamp)
+	}
+}
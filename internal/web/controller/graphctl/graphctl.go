@@ -0,0 +1,105 @@
+package graphctl
+
+import (
+	"context"
+	"fmt"
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/internal/acorn/controller"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"github.com/Interhyp/metadata-service/internal/web/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-http-utils/headers"
+	"io"
+	"net/http"
+)
+
+const serviceParam = "service"
+const depthParam = "depth"
+const directionParam = "direction"
+const formatParam = "format"
+
+const (
+	formatJson    = "json"
+	formatDot     = "dot"
+	formatMermaid = "mermaid"
+)
+
+const contentTypeGraphviz = "text/vnd.graphviz; charset=utf-8"
+
+// there is no registered media type for mermaid
+const contentTypeMermaid = "text/plain; charset=utf-8"
+
+type Impl struct {
+	Logging   librepo.Logging
+	Timestamp librepo.Timestamp
+	Graph     service.Graph
+}
+
+func New(
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+	graph service.Graph,
+) controller.GraphController {
+	return &Impl{
+		Logging:   logging,
+		Timestamp: timestamp,
+		Graph:     graph,
+	}
+}
+
+func (c *Impl) IsGraphController() bool {
+	return true
+}
+
+func (c *Impl) WireUp(_ context.Context, router chi.Router) {
+	router.Get("/rest/api/v1/graph", c.GetGraph)
+}
+
+// --- handlers ---
+
+func (c *Impl) GetGraph(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rootService := util.StringQueryParam(r, serviceParam)
+	depth, err := util.PositiveIntQueryParam(ctx, r, depthParam, 0, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+	direction := types.GraphDirectionBoth
+	if param := util.StringQueryParam(r, directionParam); param != "" {
+		direction = types.GraphDirection(param)
+	}
+	format := util.StringQueryParam(r, formatParam)
+	if format != "" && format != formatJson && format != formatDot && format != formatMermaid {
+		err := apierrors.NewBadRequestError("invalid.query.param", fmt.Sprintf("query param %s must be one of %s, %s or %s", formatParam, formatJson, formatDot, formatMermaid), nil, c.Timestamp.Now())
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	result, err := c.Graph.GetGraph(ctx, rootService, depth, direction)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError, apierrors.IsNotFoundError)
+		return
+	}
+
+	switch format {
+	case formatDot:
+		c.successText(ctx, w, contentTypeGraphviz, renderDot(result))
+	case formatMermaid:
+		c.successText(ctx, w, contentTypeMermaid, renderMermaid(result))
+	default:
+		util.Success(ctx, w, r, result)
+	}
+}
+
+// --- helpers
+
+func (c *Impl) successText(ctx context.Context, w http.ResponseWriter, contentType string, text string) {
+	w.Header().Set(headers.ContentType, contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.WriteString(w, text); err != nil {
+		c.Logging.Logger().Ctx(ctx).Warn().WithErr(err).Printf("error while writing graph response: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package graphctl
+
+import (
+	"fmt"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"strings"
+)
+
+// renderDot renders the graph in the Graphviz DOT language.
+//
+// Apis are ellipses, unknown services are dashed, and dependsOn edges that are part of a cycle are red.
+func renderDot(graph openapi.GraphDto) string {
+	inCycle := cycleEdges(graph)
+
+	var b strings.Builder
+	b.WriteString("digraph services {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, node := range graph.Nodes {
+		attributes := fmt.Sprintf("label=%s, shape=box", dotQuote(node.Name))
+		switch node.Type {
+		case types.GraphNodeTypeApi:
+			attributes = fmt.Sprintf("label=%s, shape=ellipse", dotQuote(node.Name))
+		case types.GraphNodeTypeUnknown:
+			attributes += ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(node.Id), attributes)
+	}
+	for i, edge := range graph.Edges {
+		attributes := fmt.Sprintf("label=%s", dotQuote(edge.Type))
+		if inCycle[i] {
+			attributes += ", color=red"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(edge.From), dotQuote(edge.To), attributes)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// renderMermaid renders the graph as a Mermaid flowchart.
+//
+// Mermaid node ids are restricted, so nodes are numbered in order. Apis are stadium shaped, unknown services are
+// dashed, and dependsOn edges that are part of a cycle are thick.
+func renderMermaid(graph openapi.GraphDto) string {
+	inCycle := cycleEdges(graph)
+
+	ids := make(map[string]string, len(graph.Nodes))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, node := range graph.Nodes {
+		ids[node.Id] = fmt.Sprintf("n%d", i)
+		switch node.Type {
+		case types.GraphNodeTypeApi:
+			fmt.Fprintf(&b, "  %s([%s])\n", ids[node.Id], mermaidQuote(node.Name))
+		case types.GraphNodeTypeUnknown:
+			fmt.Fprintf(&b, "  %s[%s]:::unknown\n", ids[node.Id], mermaidQuote(node.Name))
+		default:
+			fmt.Fprintf(&b, "  %s[%s]\n", ids[node.Id], mermaidQuote(node.Name))
+		}
+	}
+	for i, edge := range graph.Edges {
+		arrow := "-->"
+		if inCycle[i] {
+			arrow = "==>"
+		}
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", ids[edge.From], arrow, edge.Type, ids[edge.To])
+	}
+	b.WriteString("  classDef unknown stroke-dasharray: 5 5\n")
+	return b.String()
+}
+
+// cycleEdges marks the dependsOn edges between two services of the same cycle, by index.
+func cycleEdges(graph openapi.GraphDto) map[int]bool {
+	cycleOf := make(map[string]int)
+	for i, cycle := range graph.Cycles {
+		for _, name := range cycle {
+			cycleOf[types.GraphNodeTypeService+":"+name] = i + 1
+		}
+	}
+
+	result := make(map[int]bool)
+	for i, edge := range graph.Edges {
+		if edge.Type == types.GraphEdgeTypeDependsOn && cycleOf[edge.From] != 0 && cycleOf[edge.From] == cycleOf[edge.To] {
+			result[i] = true
+		}
+	}
+	return result
+}
+
+func dotQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func mermaidQuote(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, "#quot;") + `"`
+}
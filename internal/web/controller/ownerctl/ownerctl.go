@@ -2,7 +2,6 @@ package ownerctl
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
 	"github.com/Interhyp/go-backend-service-common/api/apierrors"
@@ -10,13 +9,20 @@ import (
 	"github.com/Interhyp/metadata-service/api"
 	"github.com/Interhyp/metadata-service/internal/acorn/config"
 	"github.com/Interhyp/metadata-service/internal/acorn/controller"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/goneerror"
 	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	serviceutil "github.com/Interhyp/metadata-service/internal/service/util"
+	"github.com/Interhyp/metadata-service/internal/types"
 	"github.com/Interhyp/metadata-service/internal/web/util"
 	"github.com/go-chi/chi/v5"
 	"net/http"
 	"net/url"
 )
 
+const atParam = "at"
+const fromParam = "from"
+const toParam = "to"
+
 type Impl struct {
 	Configuration       librepo.Configuration
 	CustomConfiguration config.CustomConfiguration
@@ -55,6 +61,10 @@ func (c *Impl) WireUp(_ context.Context, router chi.Router) {
 	router.Put(ownerEndpoint, c.UpdateOwner)
 	router.Patch(ownerEndpoint, c.PatchOwner)
 	router.Delete(ownerEndpoint, c.DeleteOwner)
+	router.Post(ownerEndpoint+"/rename", c.RenameOwner)
+	router.Post(ownerEndpoint+"/transfer", c.TransferOwnership)
+	router.Get(ownerEndpoint+"/history", c.GetOwnerHistory)
+	router.Get(ownerEndpoint+"/diff", c.GetOwnerDiff)
 }
 
 // --- handlers ---
@@ -62,9 +72,17 @@ func (c *Impl) WireUp(_ context.Context, router chi.Router) {
 func (c *Impl) GetOwners(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	owners, err := c.Owners.GetOwners(ctx)
+	page, err := util.PageRequestQueryParams(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	at := util.StringQueryParam(r, atParam)
+
+	owners, err := c.Owners.GetOwners(ctx, at, page)
 	if err != nil {
-		apierrors.HandleError(ctx, w, r, err)
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError, apierrors.IsNotFoundError, goneerror.Is)
 	} else {
 		util.Success(ctx, w, r, owners)
 	}
@@ -73,12 +91,51 @@ func (c *Impl) GetOwners(w http.ResponseWriter, r *http.Request) {
 func (c *Impl) GetSingleOwner(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	owner := util.StringPathParam(r, "owner")
+	at := util.StringQueryParam(r, atParam)
 
-	ownerDto, err := c.Owners.GetOwner(ctx, owner)
+	ownerDto, err := c.Owners.GetOwnerAt(ctx, owner, at)
+	if err != nil && at == "" && apierrors.IsNotFoundError(err) {
+		if newAlias, ok := c.Owners.GetOwnerRedirect(ctx, owner); ok {
+			util.MovedPermanently(ctx, w, r, "/rest/api/v1/owners/"+url.PathEscape(newAlias))
+			return
+		}
+	}
 	if err != nil {
-		apierrors.HandleError(ctx, w, r, err, apierrors.IsNotFoundError)
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsNotFoundError, apierrors.IsBadRequestError)
 	} else {
-		util.Success(ctx, w, r, ownerDto)
+		c.successEntity(ctx, w, r, ownerDto, http.StatusOK)
+	}
+}
+
+func (c *Impl) GetOwnerHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	owner := util.StringPathParam(r, "owner")
+
+	page, err := util.PageRequestQueryParams(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	history, err := c.Owners.GetOwnerHistory(ctx, owner, page)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError, apierrors.IsNotFoundError, goneerror.Is)
+	} else {
+		util.Success(ctx, w, r, history)
+	}
+}
+
+func (c *Impl) GetOwnerDiff(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	owner := util.StringPathParam(r, "owner")
+	from := util.StringQueryParam(r, fromParam)
+	to := util.StringQueryParam(r, toParam)
+
+	diff, err := c.Owners.GetOwnerDiff(ctx, owner, from, to)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError, apierrors.IsNotFoundError)
+	} else {
+		util.Success(ctx, w, r, diff)
 	}
 }
 
@@ -92,9 +149,14 @@ func (c *Impl) CreateOwner(w http.ResponseWriter, r *http.Request) {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
 		return
 	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
 
 	alias := util.StringPathParam(r, "owner")
-	if err := c.validOwnerAlias(ctx, alias); err != nil {
+	if err := serviceutil.ValidOwnerAlias(ctx, c.CustomConfiguration, alias, c.Timestamp.Now()); err != nil {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
 		return
 	}
@@ -111,7 +173,11 @@ func (c *Impl) CreateOwner(w http.ResponseWriter, r *http.Request) {
 			apierrors.IsConflictError,
 			apierrors.IsBadGatewayError)
 	} else {
-		util.SuccessWithStatus(ctx, w, r, ownerWritten, http.StatusCreated)
+		if types.IsDryRun(ctx) {
+			c.successEntity(ctx, w, r, ownerWritten, http.StatusOK)
+		} else {
+			c.successEntity(ctx, w, r, ownerWritten, http.StatusCreated)
+		}
 	}
 }
 
@@ -125,6 +191,11 @@ func (c *Impl) UpdateOwner(w http.ResponseWriter, r *http.Request) {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
 		return
 	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
 
 	alias := util.StringPathParam(r, "owner")
 	ownerDto, err := c.parseBodyToOwnerDto(ctx, r)
@@ -141,7 +212,7 @@ func (c *Impl) UpdateOwner(w http.ResponseWriter, r *http.Request) {
 			apierrors.IsConflictError,
 			apierrors.IsBadGatewayError)
 	} else {
-		util.Success(ctx, w, r, ownerWritten)
+		c.successEntity(ctx, w, r, ownerWritten, http.StatusOK)
 	}
 }
 
@@ -155,15 +226,14 @@ func (c *Impl) PatchOwner(w http.ResponseWriter, r *http.Request) {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
 		return
 	}
-
-	alias := util.StringPathParam(r, "owner")
-	ownerPatch, err := c.parseBodyToOwnerPatchDto(ctx, r)
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
 	if err != nil {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
 		return
 	}
 
-	ownerWritten, err := c.Owners.PatchOwner(ctx, alias, ownerPatch)
+	alias := util.StringPathParam(r, "owner")
+	ownerWritten, err := c.patchOwner(ctx, r, alias)
 	if err != nil {
 		apierrors.HandleError(ctx, w, r, err,
 			apierrors.IsBadRequestError,
@@ -171,10 +241,27 @@ func (c *Impl) PatchOwner(w http.ResponseWriter, r *http.Request) {
 			apierrors.IsConflictError,
 			apierrors.IsBadGatewayError)
 	} else {
-		util.Success(ctx, w, r, ownerWritten)
+		c.successEntity(ctx, w, r, ownerWritten, http.StatusOK)
 	}
 }
 
+// patchOwner accepts our own patch format as well as merge patches and json patches.
+func (c *Impl) patchOwner(ctx context.Context, r *http.Request, alias string) (openapi.OwnerDto, error) {
+	patchDocument, isPatchDocument, err := util.PatchDocumentBody(ctx, r, c.Timestamp)
+	if err != nil {
+		return openapi.OwnerDto{}, err
+	}
+	if isPatchDocument {
+		return c.Owners.PatchOwnerDocument(ctx, alias, patchDocument)
+	}
+
+	ownerPatch, err := c.parseBodyToOwnerPatchDto(ctx, r)
+	if err != nil {
+		return openapi.OwnerDto{}, err
+	}
+	return c.Owners.PatchOwner(ctx, alias, ownerPatch)
+}
+
 func (c *Impl) DeleteOwner(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	if err := security.IsAuthenticated(ctx, "anonymous tried DeleteOwner", c.Timestamp.Now()); err != nil {
@@ -185,6 +272,11 @@ func (c *Impl) DeleteOwner(w http.ResponseWriter, r *http.Request) {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
 		return
 	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
 
 	alias := util.StringPathParam(r, "owner")
 	info, err := util.ParseBodyToDeletionDto(ctx, r, c.Timestamp.Now())
@@ -200,31 +292,103 @@ func (c *Impl) DeleteOwner(w http.ResponseWriter, r *http.Request) {
 			apierrors.IsNotFoundError,
 			apierrors.IsConflictError,
 			apierrors.IsBadGatewayError)
+	} else if types.IsDryRun(ctx) {
+		// nothing was deleted, show what would have been
+		ownerDto, err := c.Owners.GetOwner(ctx, alias)
+		if err != nil {
+			apierrors.HandleError(ctx, w, r, err, apierrors.IsNotFoundError)
+			return
+		}
+		c.successEntity(ctx, w, r, ownerDto, http.StatusOK)
 	} else {
 		util.SuccessNoBody(ctx, w, r, http.StatusNoContent)
 	}
 }
 
-// --- helpers
+func (c *Impl) RenameOwner(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := security.IsAuthenticated(ctx, "anonymous tried RenameOwner", c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsUnauthorisedError)
+		return
+	}
+	if err := security.HasGroup(ctx, c.CustomConfiguration.AuthGroupWrite(), fmt.Sprintf("%s tried RenameOwner", security.Subject(ctx)), c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
+		return
+	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
 
-func (c *Impl) validOwnerAlias(ctx context.Context, owner string) apierrors.AnnotatedError {
-	if c.CustomConfiguration.OwnerAliasPermittedRegex().MatchString(owner) &&
-		!c.CustomConfiguration.OwnerAliasProhibitedRegex().MatchString(owner) &&
-		uint16(len(owner)) <= c.CustomConfiguration.OwnerAliasMaxLength() {
-		return nil
+	alias := util.StringPathParam(r, "owner")
+	renameDto, err := c.parseBodyToOwnerRenameDto(ctx, r)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+	if err := serviceutil.ValidOwnerAlias(ctx, c.CustomConfiguration, renameDto.NewAlias, c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
 	}
 
-	c.Logging.Logger().Ctx(ctx).Info().Printf("owner parameter %v invalid", url.QueryEscape(owner))
-	permitted := c.CustomConfiguration.OwnerAliasPermittedRegex().String()
-	prohibited := c.CustomConfiguration.OwnerAliasProhibitedRegex().String()
-	maxLength := c.CustomConfiguration.OwnerAliasMaxLength()
-	return apierrors.NewBadRequestError("owner.invalid.alias", fmt.Sprintf("owner alias must match %s, is not allowed to match %s and may have up to %d characters", permitted, prohibited, maxLength), nil, c.Timestamp.Now())
+	ownerWritten, err := c.Owners.RenameOwner(ctx, alias, renameDto)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err,
+			apierrors.IsBadRequestError,
+			apierrors.IsNotFoundError,
+			apierrors.IsConflictError,
+			apierrors.IsBadGatewayError)
+	} else {
+		c.successEntity(ctx, w, r, ownerWritten, http.StatusOK)
+	}
+}
+
+func (c *Impl) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := security.IsAuthenticated(ctx, "anonymous tried TransferOwnership", c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsUnauthorisedError)
+		return
+	}
+	if err := security.HasGroup(ctx, c.CustomConfiguration.AuthGroupWrite(), fmt.Sprintf("%s tried TransferOwnership", security.Subject(ctx)), c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
+		return
+	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	alias := util.StringPathParam(r, "owner")
+	transferDto, err := c.parseBodyToOwnerTransferDto(ctx, r)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	result, err := c.Owners.TransferOwnership(ctx, alias, transferDto)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err,
+			apierrors.IsBadRequestError,
+			apierrors.IsNotFoundError,
+			apierrors.IsConflictError,
+			apierrors.IsBadGatewayError)
+	} else {
+		util.Success(ctx, w, r, result)
+	}
+}
+
+// --- helpers
+
+// successEntity answers with the owner as yaml if the client asks for it, as json otherwise.
+func (c *Impl) successEntity(ctx context.Context, w http.ResponseWriter, r *http.Request, ownerDto interface{}, status int) {
+	util.SuccessEntity(ctx, w, r, ownerDto, status, c.CustomConfiguration.YamlIndentation(), c.Timestamp)
 }
 
 func (c *Impl) parseBodyToOwnerDto(ctx context.Context, r *http.Request) (openapi.OwnerDto, error) {
-	decoder := json.NewDecoder(r.Body)
 	dto := openapi.OwnerDto{}
-	err := decoder.Decode(&dto)
+	err := util.ParseBody(r, &dto)
 	if err != nil {
 		c.Logging.Logger().Ctx(ctx).Info().Printf("owner body invalid: %s", err.Error())
 		return openapi.OwnerDto{}, apierrors.NewBadRequestError("owner.invalid.body", "body failed to parse", err, c.Timestamp.Now())
@@ -233,9 +397,8 @@ func (c *Impl) parseBodyToOwnerDto(ctx context.Context, r *http.Request) (openap
 }
 
 func (c *Impl) parseBodyToOwnerCreateDto(ctx context.Context, r *http.Request) (openapi.OwnerCreateDto, error) {
-	decoder := json.NewDecoder(r.Body)
 	dto := openapi.OwnerCreateDto{}
-	err := decoder.Decode(&dto)
+	err := util.ParseBody(r, &dto)
 	if err != nil {
 		c.Logging.Logger().Ctx(ctx).Info().Printf("owner body invalid: %s", err.Error())
 		return openapi.OwnerCreateDto{}, apierrors.NewBadRequestError("owner.invalid.body", "body failed to parse", err, c.Timestamp.Now())
@@ -244,12 +407,31 @@ func (c *Impl) parseBodyToOwnerCreateDto(ctx context.Context, r *http.Request) (
 }
 
 func (c *Impl) parseBodyToOwnerPatchDto(ctx context.Context, r *http.Request) (openapi.OwnerPatchDto, error) {
-	decoder := json.NewDecoder(r.Body)
 	dto := openapi.OwnerPatchDto{}
-	err := decoder.Decode(&dto)
+	err := util.ParseBody(r, &dto)
 	if err != nil {
 		c.Logging.Logger().Ctx(ctx).Info().Printf("owner body invalid: %s", err.Error())
 		return openapi.OwnerPatchDto{}, apierrors.NewBadRequestError("owner.invalid.body", "body failed to parse", err, c.Timestamp.Now())
 	}
 	return dto, nil
 }
+
+func (c *Impl) parseBodyToOwnerRenameDto(ctx context.Context, r *http.Request) (openapi.OwnerRenameDto, error) {
+	dto := openapi.OwnerRenameDto{}
+	err := util.ParseBody(r, &dto)
+	if err != nil {
+		c.Logging.Logger().Ctx(ctx).Info().Printf("owner rename body invalid: %s", err.Error())
+		return openapi.OwnerRenameDto{}, apierrors.NewBadRequestError("owner.invalid.body", "body failed to parse", err, c.Timestamp.Now())
+	}
+	return dto, nil
+}
+
+func (c *Impl) parseBodyToOwnerTransferDto(ctx context.Context, r *http.Request) (openapi.OwnerTransferDto, error) {
+	dto := openapi.OwnerTransferDto{}
+	err := util.ParseBody(r, &dto)
+	if err != nil {
+		c.Logging.Logger().Ctx(ctx).Info().Printf("owner transfer body invalid: %s", err.Error())
+		return openapi.OwnerTransferDto{}, apierrors.NewBadRequestError("owner.invalid.body", "body failed to parse", err, c.Timestamp.Now())
+	}
+	return dto, nil
+}
@@ -0,0 +1,51 @@
+package reportctl
+
+import (
+	"context"
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/internal/acorn/controller"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/web/util"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Impl struct {
+	Logging   librepo.Logging
+	Timestamp librepo.Timestamp
+	Owners    service.Owners
+}
+
+func New(
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+	owners service.Owners,
+) controller.ReportController {
+	return &Impl{
+		Logging:   logging,
+		Timestamp: timestamp,
+		Owners:    owners,
+	}
+}
+
+func (c *Impl) IsReportController() bool {
+	return true
+}
+
+func (c *Impl) WireUp(_ context.Context, router chi.Router) {
+	router.Get("/rest/api/v1/reports/broken-group-references", c.GetBrokenGroupReferences)
+}
+
+// --- handlers ---
+
+func (c *Impl) GetBrokenGroupReferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	references, err := c.Owners.GetBrokenGroupReferences(ctx)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err)
+	} else {
+		util.Success(ctx, w, r, references)
+	}
+}
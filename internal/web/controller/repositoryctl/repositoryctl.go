@@ -2,7 +2,6 @@ package repositoryctl
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
 	"github.com/Interhyp/go-backend-service-common/api/apierrors"
@@ -10,10 +9,13 @@ import (
 	"github.com/Interhyp/metadata-service/api"
 	"github.com/Interhyp/metadata-service/internal/acorn/config"
 	"github.com/Interhyp/metadata-service/internal/acorn/controller"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/goneerror"
 	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/types"
 	"github.com/Interhyp/metadata-service/internal/web/util"
 	"github.com/go-chi/chi/v5"
 	"net/http"
+	"net/url"
 )
 
 const ownerParam = "owner"
@@ -21,6 +23,10 @@ const serviceParam = "service"
 const nameParam = "name"
 const typeParam = "type"
 const urlParam = "url"
+const labelSelectorParam = "labelSelector"
+const atParam = "at"
+const fromParam = "from"
+const toParam = "to"
 
 type Impl struct {
 	Configuration       librepo.Configuration
@@ -55,11 +61,16 @@ func (c *Impl) WireUp(_ context.Context, router chi.Router) {
 	repositoryEndpoint := baseEndpoint + "/{repository}"
 
 	router.Get(baseEndpoint, c.GetRepositories)
+	router.Patch(baseEndpoint, c.PatchRepositories)
 	router.Get(repositoryEndpoint, c.GetSingleRepository)
 	router.Post(repositoryEndpoint, c.CreateRepository)
 	router.Put(repositoryEndpoint, c.UpdateRepository)
 	router.Patch(repositoryEndpoint, c.PatchRepository)
 	router.Delete(repositoryEndpoint, c.DeleteRepository)
+	router.Post(repositoryEndpoint+"/rename", c.RenameRepository)
+	router.Get(repositoryEndpoint+"/services", c.GetRepositoryServices)
+	router.Get(repositoryEndpoint+"/history", c.GetRepositoryHistory)
+	router.Get(repositoryEndpoint+"/diff", c.GetRepositoryDiff)
 }
 
 // --- handlers ---
@@ -71,17 +82,24 @@ func (c *Impl) GetRepositories(w http.ResponseWriter, r *http.Request) {
 	nameFilter := util.StringQueryParam(r, nameParam)
 	typeFilter := util.StringQueryParam(r, typeParam)
 	urlFilter := util.StringQueryParam(r, urlParam)
+	labelSelector := util.StringQueryParam(r, labelSelectorParam)
+	at := util.StringQueryParam(r, atParam)
+	page, err := util.PageRequestQueryParams(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
 
 	repositories, err := c.Repositories.GetRepositories(ctx,
 		ownerAliasFilter, serviceNameFilter,
 		nameFilter, typeFilter,
-		urlFilter)
+		urlFilter, labelSelector, at, page)
 	if err != nil {
-		if apierrors.IsNotFoundError(err) {
+		if apierrors.IsNotFoundError(err) && at == "" {
 			// acceptable case - no matching repositories, so return empty list
 			util.Success(ctx, w, r, repositories)
 		} else {
-			apierrors.HandleError(ctx, w, r, err)
+			apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError, apierrors.IsNotFoundError, goneerror.Is)
 		}
 	} else {
 		util.Success(ctx, w, r, repositories)
@@ -91,12 +109,63 @@ func (c *Impl) GetRepositories(w http.ResponseWriter, r *http.Request) {
 func (c *Impl) GetSingleRepository(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	key := util.StringPathParam(r, "repository")
+	at := util.StringQueryParam(r, atParam)
+
+	repositoryDto, err := c.Repositories.GetRepositoryAt(ctx, key, at)
+	if err != nil && at == "" && apierrors.IsNotFoundError(err) {
+		if newKey, ok := c.Repositories.GetRepositoryRedirect(ctx, key); ok {
+			util.MovedPermanently(ctx, w, r, "/rest/api/v1/repositories/"+url.PathEscape(newKey))
+			return
+		}
+	}
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsNotFoundError, apierrors.IsBadRequestError)
+	} else {
+		c.successEntity(ctx, w, r, repositoryDto, http.StatusOK)
+	}
+}
+
+func (c *Impl) GetRepositoryHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := util.StringPathParam(r, "repository")
+
+	page, err := util.PageRequestQueryParams(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	history, err := c.Repositories.GetRepositoryHistory(ctx, key, page)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError, apierrors.IsNotFoundError, goneerror.Is)
+	} else {
+		util.Success(ctx, w, r, history)
+	}
+}
+
+func (c *Impl) GetRepositoryServices(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := util.StringPathParam(r, "repository")
 
-	repositoryDto, err := c.Repositories.GetRepository(ctx, key)
+	services, err := c.Repositories.GetRepositoryServices(ctx, key)
 	if err != nil {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsNotFoundError)
 	} else {
-		util.Success(ctx, w, r, repositoryDto)
+		util.Success(ctx, w, r, services)
+	}
+}
+
+func (c *Impl) GetRepositoryDiff(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := util.StringPathParam(r, "repository")
+	from := util.StringQueryParam(r, fromParam)
+	to := util.StringQueryParam(r, toParam)
+
+	diff, err := c.Repositories.GetRepositoryDiff(ctx, key, from, to)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError, apierrors.IsNotFoundError)
+	} else {
+		util.Success(ctx, w, r, diff)
 	}
 }
 
@@ -110,6 +179,11 @@ func (c *Impl) CreateRepository(w http.ResponseWriter, r *http.Request) {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
 		return
 	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
 
 	key := util.StringPathParam(r, "repository")
 	if err := c.Repositories.ValidRepositoryKey(ctx, key); err != nil {
@@ -129,7 +203,11 @@ func (c *Impl) CreateRepository(w http.ResponseWriter, r *http.Request) {
 			apierrors.IsConflictError,
 			apierrors.IsBadGatewayError)
 	} else {
-		util.SuccessWithStatus(ctx, w, r, repositoryWritten, http.StatusCreated)
+		if types.IsDryRun(ctx) {
+			c.successEntity(ctx, w, r, repositoryWritten, http.StatusOK)
+		} else {
+			c.successEntity(ctx, w, r, repositoryWritten, http.StatusCreated)
+		}
 	}
 }
 
@@ -143,6 +221,11 @@ func (c *Impl) UpdateRepository(w http.ResponseWriter, r *http.Request) {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
 		return
 	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
 
 	key := util.StringPathParam(r, "repository")
 	repositoryDto, err := c.parseBodyToRepositoryDto(ctx, r)
@@ -159,7 +242,7 @@ func (c *Impl) UpdateRepository(w http.ResponseWriter, r *http.Request) {
 			apierrors.IsConflictError,
 			apierrors.IsBadGatewayError)
 	} else {
-		util.Success(ctx, w, r, repositoryWritten)
+		c.successEntity(ctx, w, r, repositoryWritten, http.StatusOK)
 	}
 }
 
@@ -173,23 +256,75 @@ func (c *Impl) PatchRepository(w http.ResponseWriter, r *http.Request) {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
 		return
 	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
 
 	key := util.StringPathParam(r, "repository")
+	repositoryWritten, err := c.patchRepository(ctx, r, key)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err,
+			apierrors.IsBadRequestError,
+			apierrors.IsNotFoundError,
+			apierrors.IsConflictError,
+			apierrors.IsBadGatewayError)
+	} else {
+		c.successEntity(ctx, w, r, repositoryWritten, http.StatusOK)
+	}
+}
+
+// patchRepository accepts our own patch format as well as merge patches and json patches.
+func (c *Impl) patchRepository(ctx context.Context, r *http.Request, key string) (openapi.RepositoryDto, error) {
+	patchDocument, isPatchDocument, err := util.PatchDocumentBody(ctx, r, c.Timestamp)
+	if err != nil {
+		return openapi.RepositoryDto{}, err
+	}
+	if isPatchDocument {
+		return c.Repositories.PatchRepositoryDocument(ctx, key, patchDocument)
+	}
+
+	repositoryPatch, err := c.parseBodyToRepositoryPatchDto(ctx, r)
+	if err != nil {
+		return openapi.RepositoryDto{}, err
+	}
+	return c.Repositories.PatchRepository(ctx, key, repositoryPatch)
+}
+
+func (c *Impl) PatchRepositories(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := security.IsAuthenticated(ctx, "anonymous tried PatchRepositories", c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsUnauthorisedError)
+		return
+	}
+	if err := security.HasGroup(ctx, c.CustomConfiguration.AuthGroupWrite(), fmt.Sprintf("%s tried PatchRepositories", security.Subject(ctx)), c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
+		return
+	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	ownerAliasFilter := util.StringQueryParam(r, ownerParam)
+	typeFilter := util.StringQueryParam(r, typeParam)
+	labelSelector := util.StringQueryParam(r, labelSelectorParam)
 	repositoryPatch, err := c.parseBodyToRepositoryPatchDto(ctx, r)
 	if err != nil {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
 		return
 	}
 
-	repositoryWritten, err := c.Repositories.PatchRepository(ctx, key, repositoryPatch)
+	result, err := c.Repositories.PatchRepositories(ctx, ownerAliasFilter, typeFilter, labelSelector, repositoryPatch)
 	if err != nil {
 		apierrors.HandleError(ctx, w, r, err,
 			apierrors.IsBadRequestError,
-			apierrors.IsNotFoundError,
 			apierrors.IsConflictError,
 			apierrors.IsBadGatewayError)
 	} else {
-		util.Success(ctx, w, r, repositoryWritten)
+		util.Success(ctx, w, r, result)
 	}
 }
 
@@ -203,6 +338,11 @@ func (c *Impl) DeleteRepository(w http.ResponseWriter, r *http.Request) {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
 		return
 	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
 
 	key := util.StringPathParam(r, "repository")
 	info, err := util.ParseBodyToDeletionDto(ctx, r, c.Timestamp.Now())
@@ -218,17 +358,68 @@ func (c *Impl) DeleteRepository(w http.ResponseWriter, r *http.Request) {
 			apierrors.IsNotFoundError,
 			apierrors.IsConflictError,
 			apierrors.IsBadGatewayError)
+	} else if types.IsDryRun(ctx) {
+		// nothing was deleted, show what would have been
+		repositoryDto, err := c.Repositories.GetRepository(ctx, key)
+		if err != nil {
+			apierrors.HandleError(ctx, w, r, err, apierrors.IsNotFoundError)
+			return
+		}
+		c.successEntity(ctx, w, r, repositoryDto, http.StatusOK)
 	} else {
 		util.SuccessNoBody(ctx, w, r, http.StatusNoContent)
 	}
 }
 
+func (c *Impl) RenameRepository(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := security.IsAuthenticated(ctx, "anonymous tried RenameRepository", c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsUnauthorisedError)
+		return
+	}
+	if err := security.HasGroup(ctx, c.CustomConfiguration.AuthGroupWrite(), fmt.Sprintf("%s tried RenameRepository", security.Subject(ctx)), c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
+		return
+	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	key := util.StringPathParam(r, "repository")
+	renameDto, err := c.parseBodyToRepositoryRenameDto(ctx, r)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+	if err := c.Repositories.ValidRepositoryKey(ctx, renameDto.NewKey); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	repositoryWritten, err := c.Repositories.RenameRepository(ctx, key, renameDto)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err,
+			apierrors.IsBadRequestError,
+			apierrors.IsNotFoundError,
+			apierrors.IsConflictError,
+			apierrors.IsBadGatewayError)
+	} else {
+		c.successEntity(ctx, w, r, repositoryWritten, http.StatusOK)
+	}
+}
+
 // --- helpers
 
+// successEntity answers with the repository as yaml if the client asks for it, as json otherwise.
+func (c *Impl) successEntity(ctx context.Context, w http.ResponseWriter, r *http.Request, repositoryDto interface{}, status int) {
+	util.SuccessEntity(ctx, w, r, repositoryDto, status, c.CustomConfiguration.YamlIndentation(), c.Timestamp)
+}
+
 func (c *Impl) parseBodyToRepositoryDto(ctx context.Context, r *http.Request) (openapi.RepositoryDto, error) {
-	decoder := json.NewDecoder(r.Body)
 	dto := openapi.RepositoryDto{}
-	err := decoder.Decode(&dto)
+	err := util.ParseBody(r, &dto)
 	if err != nil {
 		c.Logging.Logger().Ctx(ctx).Info().Printf("repository body invalid: %s", err.Error())
 		return openapi.RepositoryDto{}, apierrors.NewBadRequestError("repository.invalid.body", "body failed to parse", err, c.Timestamp.Now())
@@ -236,9 +427,8 @@ func (c *Impl) parseBodyToRepositoryDto(ctx context.Context, r *http.Request) (o
 	return dto, nil
 }
 func (c *Impl) parseBodyToRepositoryCreateDto(ctx context.Context, r *http.Request) (openapi.RepositoryCreateDto, error) {
-	decoder := json.NewDecoder(r.Body)
 	dto := openapi.RepositoryCreateDto{}
-	err := decoder.Decode(&dto)
+	err := util.ParseBody(r, &dto)
 	if err != nil {
 		c.Logging.Logger().Ctx(ctx).Info().Printf("repository body invalid: %s", err.Error())
 		return openapi.RepositoryCreateDto{}, apierrors.NewBadRequestError("repository.invalid.body", "body failed to parse", err, c.Timestamp.Now())
@@ -247,12 +437,21 @@ func (c *Impl) parseBodyToRepositoryCreateDto(ctx context.Context, r *http.Reque
 }
 
 func (c *Impl) parseBodyToRepositoryPatchDto(ctx context.Context, r *http.Request) (openapi.RepositoryPatchDto, error) {
-	decoder := json.NewDecoder(r.Body)
 	dto := openapi.RepositoryPatchDto{}
-	err := decoder.Decode(&dto)
+	err := util.ParseBody(r, &dto)
 	if err != nil {
 		c.Logging.Logger().Ctx(ctx).Info().Printf("repository body invalid: %s", err.Error())
 		return openapi.RepositoryPatchDto{}, apierrors.NewBadRequestError("repository.invalid.body", "body failed to parse", err, c.Timestamp.Now())
 	}
 	return dto, nil
 }
+
+func (c *Impl) parseBodyToRepositoryRenameDto(ctx context.Context, r *http.Request) (openapi.RepositoryRenameDto, error) {
+	dto := openapi.RepositoryRenameDto{}
+	err := util.ParseBody(r, &dto)
+	if err != nil {
+		c.Logging.Logger().Ctx(ctx).Info().Printf("repository rename body invalid: %s", err.Error())
+		return openapi.RepositoryRenameDto{}, apierrors.NewBadRequestError("repository.invalid.body", "body failed to parse", err, c.Timestamp.Now())
+	}
+	return dto, nil
+}
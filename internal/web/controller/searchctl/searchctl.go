@@ -0,0 +1,62 @@
+package searchctl
+
+import (
+	"context"
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/internal/acorn/controller"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/web/util"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+const queryParam = "q"
+const limitParam = "limit"
+
+const defaultLimit = 50
+
+type Impl struct {
+	Logging   librepo.Logging
+	Timestamp librepo.Timestamp
+	Search    service.Search
+}
+
+func New(
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+	search service.Search,
+) controller.SearchController {
+	return &Impl{
+		Logging:   logging,
+		Timestamp: timestamp,
+		Search:    search,
+	}
+}
+
+func (c *Impl) IsSearchController() bool {
+	return true
+}
+
+func (c *Impl) WireUp(_ context.Context, router chi.Router) {
+	router.Get("/rest/api/v1/search", c.GetSearch)
+}
+
+// --- handlers ---
+
+func (c *Impl) GetSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := util.StringQueryParam(r, queryParam)
+	limit, err := util.PositiveIntQueryParam(ctx, r, limitParam, defaultLimit, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	result, err := c.Search.Search(ctx, query, limit)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+	} else {
+		util.Success(ctx, w, r, result)
+	}
+}
@@ -2,13 +2,17 @@ package servicectl
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/Interhyp/metadata-service/api"
 	"github.com/Interhyp/metadata-service/internal/acorn/config"
 	"github.com/Interhyp/metadata-service/internal/acorn/controller"
+	"github.com/Interhyp/metadata-service/internal/acorn/errors/goneerror"
 	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	serviceutil "github.com/Interhyp/metadata-service/internal/service/util"
+	"github.com/Interhyp/metadata-service/internal/types"
 	"net/http"
+	"net/url"
+	"strings"
 
 	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
 	"github.com/Interhyp/go-backend-service-common/api/apierrors"
@@ -18,6 +22,10 @@ import (
 )
 
 const ownerParam = "owner"
+const labelSelectorParam = "labelSelector"
+const atParam = "at"
+const fromParam = "from"
+const toParam = "to"
 
 type Impl struct {
 	Configuration       librepo.Configuration
@@ -58,7 +66,11 @@ func (c *Impl) WireUp(_ context.Context, router chi.Router) {
 	router.Put(serviceEndpoint, c.UpdateService)
 	router.Patch(serviceEndpoint, c.PatchService)
 	router.Delete(serviceEndpoint, c.DeleteService)
+	router.Post(serviceEndpoint+"/rename", c.RenameService)
 	router.Get(promotersEndpoint, c.GetServicePromoters)
+	router.Get(promotersEndpoint+"/{user}", c.GetServicePromoterCheck)
+	router.Get(serviceEndpoint+"/history", c.GetServiceHistory)
+	router.Get(serviceEndpoint+"/diff", c.GetServiceDiff)
 }
 
 // --- handlers ---
@@ -66,10 +78,18 @@ func (c *Impl) WireUp(_ context.Context, router chi.Router) {
 func (c *Impl) GetServices(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	ownerAliasFilter := util.StringQueryParam(r, ownerParam)
+	labelSelector := util.StringQueryParam(r, labelSelectorParam)
+	at := util.StringQueryParam(r, atParam)
 
-	services, err := c.Services.GetServices(ctx, ownerAliasFilter)
+	page, err := util.PageRequestQueryParams(ctx, r, c.Timestamp)
 	if err != nil {
-		apierrors.HandleError(ctx, w, r, err)
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	services, err := c.Services.GetServices(ctx, ownerAliasFilter, labelSelector, at, page)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError, apierrors.IsNotFoundError, goneerror.Is)
 	} else {
 		util.Success(ctx, w, r, services)
 	}
@@ -78,12 +98,51 @@ func (c *Impl) GetServices(w http.ResponseWriter, r *http.Request) {
 func (c *Impl) GetSingleService(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	serviceName := util.StringPathParam(r, "service")
+	at := util.StringQueryParam(r, atParam)
+
+	serviceDto, err := c.Services.GetServiceAt(ctx, serviceName, at)
+	if err != nil && at == "" && apierrors.IsNotFoundError(err) {
+		if newName, ok := c.Services.GetServiceRedirect(ctx, serviceName); ok {
+			util.MovedPermanently(ctx, w, r, "/rest/api/v1/services/"+url.PathEscape(newName))
+			return
+		}
+	}
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsNotFoundError, apierrors.IsBadRequestError)
+	} else {
+		c.successEntity(ctx, w, r, serviceDto, http.StatusOK)
+	}
+}
+
+func (c *Impl) GetServiceHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	serviceName := util.StringPathParam(r, "service")
 
-	serviceDto, err := c.Services.GetService(ctx, serviceName)
+	page, err := util.PageRequestQueryParams(ctx, r, c.Timestamp)
 	if err != nil {
-		apierrors.HandleError(ctx, w, r, err, apierrors.IsNotFoundError)
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	history, err := c.Services.GetServiceHistory(ctx, serviceName, page)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError, apierrors.IsNotFoundError, goneerror.Is)
+	} else {
+		util.Success(ctx, w, r, history)
+	}
+}
+
+func (c *Impl) GetServiceDiff(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	serviceName := util.StringPathParam(r, "service")
+	from := util.StringQueryParam(r, fromParam)
+	to := util.StringQueryParam(r, toParam)
+
+	diff, err := c.Services.GetServiceDiff(ctx, serviceName, from, to)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError, apierrors.IsNotFoundError)
 	} else {
-		util.Success(ctx, w, r, serviceDto)
+		util.Success(ctx, w, r, diff)
 	}
 }
 
@@ -97,9 +156,16 @@ func (c *Impl) CreateService(w http.ResponseWriter, r *http.Request) {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
 		return
 	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+	// problems with added dependencies do not fail the write, they are reported with the response
+	ctx = types.WithWarnings(ctx)
 
 	name := util.StringPathParam(r, "service")
-	if err := c.validServiceName(ctx, name); err != nil {
+	if err := serviceutil.ValidServiceName(ctx, c.CustomConfiguration, name, c.Timestamp.Now()); err != nil {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
 		return
 	}
@@ -116,7 +182,11 @@ func (c *Impl) CreateService(w http.ResponseWriter, r *http.Request) {
 			apierrors.IsConflictError,
 			apierrors.IsBadGatewayError)
 	} else {
-		util.SuccessWithStatus(ctx, w, r, serviceWritten, http.StatusCreated)
+		if types.IsDryRun(ctx) {
+			c.successEntity(ctx, w, r, serviceWritten, http.StatusOK)
+		} else {
+			c.successEntity(ctx, w, r, serviceWritten, http.StatusCreated)
+		}
 	}
 }
 
@@ -130,6 +200,13 @@ func (c *Impl) UpdateService(w http.ResponseWriter, r *http.Request) {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
 		return
 	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+	// problems with added dependencies do not fail the write, they are reported with the response
+	ctx = types.WithWarnings(ctx)
 
 	name := util.StringPathParam(r, "service")
 	serviceDto, err := c.parseBodyToServiceDto(ctx, r)
@@ -146,7 +223,7 @@ func (c *Impl) UpdateService(w http.ResponseWriter, r *http.Request) {
 			apierrors.IsConflictError,
 			apierrors.IsBadGatewayError)
 	} else {
-		util.Success(ctx, w, r, serviceWritten)
+		c.successEntity(ctx, w, r, serviceWritten, http.StatusOK)
 	}
 }
 
@@ -160,15 +237,16 @@ func (c *Impl) PatchService(w http.ResponseWriter, r *http.Request) {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
 		return
 	}
-
-	name := util.StringPathParam(r, "service")
-	servicePatch, err := c.parseBodyToServicePatchDto(ctx, r)
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
 	if err != nil {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
 		return
 	}
+	// problems with added dependencies do not fail the write, they are reported with the response
+	ctx = types.WithWarnings(ctx)
 
-	serviceWritten, err := c.Services.PatchService(ctx, name, servicePatch)
+	name := util.StringPathParam(r, "service")
+	serviceWritten, err := c.patchService(ctx, r, name)
 	if err != nil {
 		apierrors.HandleError(ctx, w, r, err,
 			apierrors.IsBadRequestError,
@@ -176,8 +254,25 @@ func (c *Impl) PatchService(w http.ResponseWriter, r *http.Request) {
 			apierrors.IsConflictError,
 			apierrors.IsBadGatewayError)
 	} else {
-		util.Success(ctx, w, r, serviceWritten)
+		c.successEntity(ctx, w, r, serviceWritten, http.StatusOK)
+	}
+}
+
+// patchService accepts our own patch format as well as merge patches and json patches.
+func (c *Impl) patchService(ctx context.Context, r *http.Request, name string) (openapi.ServiceDto, error) {
+	patchDocument, isPatchDocument, err := util.PatchDocumentBody(ctx, r, c.Timestamp)
+	if err != nil {
+		return openapi.ServiceDto{}, err
+	}
+	if isPatchDocument {
+		return c.Services.PatchServiceDocument(ctx, name, patchDocument)
 	}
+
+	servicePatch, err := c.parseBodyToServicePatchDto(ctx, r)
+	if err != nil {
+		return openapi.ServiceDto{}, err
+	}
+	return c.Services.PatchService(ctx, name, servicePatch)
 }
 
 func (c *Impl) DeleteService(w http.ResponseWriter, r *http.Request) {
@@ -190,6 +285,11 @@ func (c *Impl) DeleteService(w http.ResponseWriter, r *http.Request) {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
 		return
 	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
 
 	name := util.StringPathParam(r, "service")
 	info, err := util.ParseBodyToDeletionDto(ctx, r, c.Timestamp.Now())
@@ -205,69 +305,154 @@ func (c *Impl) DeleteService(w http.ResponseWriter, r *http.Request) {
 			apierrors.IsNotFoundError,
 			apierrors.IsConflictError,
 			apierrors.IsBadGatewayError)
+	} else if types.IsDryRun(ctx) {
+		// nothing was deleted, show what would have been
+		serviceDto, err := c.Services.GetService(ctx, name)
+		if err != nil {
+			apierrors.HandleError(ctx, w, r, err, apierrors.IsNotFoundError)
+			return
+		}
+		c.successEntity(ctx, w, r, serviceDto, http.StatusOK)
 	} else {
 		util.SuccessNoBody(ctx, w, r, http.StatusNoContent)
 	}
 }
 
+func (c *Impl) RenameService(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := security.IsAuthenticated(ctx, "anonymous tried RenameService", c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsUnauthorisedError)
+		return
+	}
+	if err := security.HasGroup(ctx, c.CustomConfiguration.AuthGroupWrite(), fmt.Sprintf("%s tried RenameService", security.Subject(ctx)), c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
+		return
+	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	name := util.StringPathParam(r, "service")
+	renameDto, err := c.parseBodyToServiceRenameDto(ctx, r)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+	if err := serviceutil.ValidServiceName(ctx, c.CustomConfiguration, renameDto.NewName, c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	serviceWritten, err := c.Services.RenameService(ctx, name, renameDto)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err,
+			apierrors.IsBadRequestError,
+			apierrors.IsNotFoundError,
+			apierrors.IsConflictError,
+			apierrors.IsBadGatewayError)
+	} else {
+		c.successEntity(ctx, w, r, serviceWritten, http.StatusOK)
+	}
+}
+
 func (c *Impl) GetServicePromoters(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	serviceName := util.StringPathParam(r, "service")
 
-	_, err := c.Services.GetService(ctx, serviceName)
+	promoters, err := c.Services.GetServicePromoters(ctx, serviceName)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsNotFoundError)
+	} else {
+		util.Success(ctx, w, r, promoters)
+	}
+}
+
+func (c *Impl) GetServicePromoterCheck(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	serviceName := util.StringPathParam(r, "service")
+	username := util.StringPathParam(r, "user")
+
+	mayPromote, err := c.Services.IsServicePromoter(ctx, serviceName, username)
 	if err != nil {
 		apierrors.HandleError(ctx, w, r, err, apierrors.IsNotFoundError)
 	} else {
-		util.Success(ctx, w, r, openapi.ServicePromotersDto{Promoters: []string{}})
+		util.Success(ctx, w, r, openapi.ServicePromoterCheckDto{User: username, MayPromote: mayPromote})
 	}
 }
 
 // --- helpers
 
-func (c *Impl) validServiceName(ctx context.Context, name string) apierrors.AnnotatedError {
-	if c.CustomConfiguration.ServiceNamePermittedRegex().MatchString(name) &&
-		!c.CustomConfiguration.ServiceNameProhibitedRegex().MatchString(name) &&
-		uint16(len(name)) <= c.CustomConfiguration.ServiceNameMaxLength() {
-		return nil
+// successEntity answers with the service as yaml if the client asks for it, as json otherwise.
+//
+// The yaml refers to repositories the way the service files in the metadata repository do.
+func (c *Impl) successEntity(ctx context.Context, w http.ResponseWriter, r *http.Request, serviceDto openapi.ServiceDto, status int) {
+	if util.AcceptsYaml(r) {
+		serviceDto.Repositories = replaceInRepositoryKeys(serviceDto.Repositories, ".", "/")
 	}
+	util.SuccessEntity(ctx, w, r, serviceDto, status, c.CustomConfiguration.YamlIndentation(), c.Timestamp)
+}
 
-	c.Logging.Logger().Ctx(ctx).Info().Printf("service parameter %v invalid", name)
-	permitted := c.CustomConfiguration.ServiceNamePermittedRegex().String()
-	prohibited := c.CustomConfiguration.ServiceNameProhibitedRegex().String()
-	maxLength := c.CustomConfiguration.ServiceNameMaxLength()
-	return apierrors.NewBadRequestError("service.invalid.name", fmt.Sprintf("service name must match %s, is not allowed to match %s and may have up to %d characters", permitted, prohibited, maxLength), nil, c.Timestamp.Now())
+// fromYamlRepositoryKeys converts the repository references of a yaml body, see successEntity.
+func fromYamlRepositoryKeys(r *http.Request, repositoryKeys []string) []string {
+	if !util.HasYamlBody(r) {
+		return repositoryKeys
+	}
+	return replaceInRepositoryKeys(repositoryKeys, "/", ".")
+}
+
+func replaceInRepositoryKeys(repositoryKeys []string, from string, to string) []string {
+	if repositoryKeys == nil {
+		return nil
+	}
+	result := make([]string, len(repositoryKeys))
+	for i, key := range repositoryKeys {
+		result[i] = strings.ReplaceAll(key, from, to)
+	}
+	return result
 }
 
 func (c *Impl) parseBodyToServiceDto(ctx context.Context, r *http.Request) (openapi.ServiceDto, error) {
-	decoder := json.NewDecoder(r.Body)
 	dto := openapi.ServiceDto{}
-	err := decoder.Decode(&dto)
+	err := util.ParseBody(r, &dto)
 	if err != nil {
 		c.Logging.Logger().Ctx(ctx).Info().Printf("service body invalid: %s", err.Error())
 		return openapi.ServiceDto{}, apierrors.NewBadRequestError("service.invalid.body", "body failed to parse", err, c.Timestamp.Now())
 
 	}
+	dto.Repositories = fromYamlRepositoryKeys(r, dto.Repositories)
 	return dto, nil
 }
 
 func (c *Impl) parseBodyToServiceCreateDto(ctx context.Context, r *http.Request) (openapi.ServiceCreateDto, error) {
-	decoder := json.NewDecoder(r.Body)
 	dto := openapi.ServiceCreateDto{}
-	err := decoder.Decode(&dto)
+	err := util.ParseBody(r, &dto)
 	if err != nil {
 		c.Logging.Logger().Ctx(ctx).Info().Printf("service body invalid: %s", err.Error())
 		return openapi.ServiceCreateDto{}, apierrors.NewBadRequestError("service.invalid.body", "body failed to parse", err, c.Timestamp.Now())
 	}
+	dto.Repositories = fromYamlRepositoryKeys(r, dto.Repositories)
 	return dto, nil
 }
 
 func (c *Impl) parseBodyToServicePatchDto(ctx context.Context, r *http.Request) (openapi.ServicePatchDto, error) {
-	decoder := json.NewDecoder(r.Body)
 	dto := openapi.ServicePatchDto{}
-	err := decoder.Decode(&dto)
+	err := util.ParseBody(r, &dto)
 	if err != nil {
 		c.Logging.Logger().Ctx(ctx).Info().Printf("service body invalid: %s", err.Error())
 		return openapi.ServicePatchDto{}, apierrors.NewBadRequestError("service.invalid.body", "body failed to parse", err, c.Timestamp.Now())
 	}
+	dto.Repositories = fromYamlRepositoryKeys(r, dto.Repositories)
+	return dto, nil
+}
+
+func (c *Impl) parseBodyToServiceRenameDto(ctx context.Context, r *http.Request) (openapi.ServiceRenameDto, error) {
+	dto := openapi.ServiceRenameDto{}
+	err := util.ParseBody(r, &dto)
+	if err != nil {
+		c.Logging.Logger().Ctx(ctx).Info().Printf("service rename body invalid: %s", err.Error())
+		return openapi.ServiceRenameDto{}, apierrors.NewBadRequestError("service.invalid.body", "body failed to parse", err, c.Timestamp.Now())
+	}
 	return dto, nil
 }
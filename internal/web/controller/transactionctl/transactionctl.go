@@ -0,0 +1,96 @@
+package transactionctl
+
+import (
+	"context"
+	"fmt"
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/go-backend-service-common/web/middleware/security"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/acorn/config"
+	"github.com/Interhyp/metadata-service/internal/acorn/controller"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/web/util"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Impl struct {
+	Configuration       librepo.Configuration
+	CustomConfiguration config.CustomConfiguration
+	Logging             librepo.Logging
+	Timestamp           librepo.Timestamp
+	Transactions        service.Transactions
+}
+
+func New(
+	configuration librepo.Configuration,
+	customConfig config.CustomConfiguration,
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+	transactions service.Transactions,
+) controller.TransactionController {
+	return &Impl{
+		Configuration:       configuration,
+		CustomConfiguration: customConfig,
+		Logging:             logging,
+		Timestamp:           timestamp,
+		Transactions:        transactions,
+	}
+}
+
+func (c *Impl) IsTransactionController() bool {
+	return true
+}
+
+func (c *Impl) WireUp(_ context.Context, router chi.Router) {
+	router.Post("/rest/api/v1/transactions", c.ApplyTransaction)
+}
+
+// --- handlers ---
+
+func (c *Impl) ApplyTransaction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if err := security.IsAuthenticated(ctx, "anonymous tried ApplyTransaction", c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsUnauthorisedError)
+		return
+	}
+	if err := security.HasGroup(ctx, c.CustomConfiguration.AuthGroupWrite(), fmt.Sprintf("%s tried ApplyTransaction", security.Subject(ctx)), c.Timestamp.Now()); err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsForbiddenError)
+		return
+	}
+	ctx, err := util.DryRunQueryParam(ctx, r, c.Timestamp)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	transactionDto, err := c.parseBodyToTransactionDto(ctx, r)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err, apierrors.IsBadRequestError)
+		return
+	}
+
+	result, err := c.Transactions.ApplyTransaction(ctx, transactionDto)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err,
+			apierrors.IsBadRequestError,
+			apierrors.IsNotFoundError,
+			apierrors.IsConflictError,
+			apierrors.IsBadGatewayError)
+	} else {
+		util.Success(ctx, w, r, result)
+	}
+}
+
+// --- helpers
+
+func (c *Impl) parseBodyToTransactionDto(ctx context.Context, r *http.Request) (openapi.TransactionDto, error) {
+	dto := openapi.TransactionDto{}
+	err := util.ParseBody(r, &dto)
+	if err != nil {
+		c.Logging.Logger().Ctx(ctx).Info().Printf("transaction body invalid: %s", err.Error())
+		return openapi.TransactionDto{}, apierrors.NewBadRequestError("transaction.invalid.body", "body failed to parse", err, c.Timestamp.Now())
+	}
+	return dto, nil
+}
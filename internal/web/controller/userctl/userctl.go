@@ -0,0 +1,52 @@
+package userctl
+
+import (
+	"context"
+	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
+	"github.com/Interhyp/go-backend-service-common/api/apierrors"
+	"github.com/Interhyp/metadata-service/internal/acorn/controller"
+	"github.com/Interhyp/metadata-service/internal/acorn/service"
+	"github.com/Interhyp/metadata-service/internal/web/util"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type Impl struct {
+	Logging   librepo.Logging
+	Timestamp librepo.Timestamp
+	Users     service.Users
+}
+
+func New(
+	logging librepo.Logging,
+	timestamp librepo.Timestamp,
+	users service.Users,
+) controller.UserController {
+	return &Impl{
+		Logging:   logging,
+		Timestamp: timestamp,
+		Users:     users,
+	}
+}
+
+func (c *Impl) IsUserController() bool {
+	return true
+}
+
+func (c *Impl) WireUp(_ context.Context, router chi.Router) {
+	router.Get("/rest/api/v1/users/{user}", c.GetUser)
+}
+
+// --- handlers ---
+
+func (c *Impl) GetUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	username := util.StringPathParam(r, "user")
+
+	user, err := c.Users.GetUser(ctx, username)
+	if err != nil {
+		apierrors.HandleError(ctx, w, r, err)
+	} else {
+		util.Success(ctx, w, r, user)
+	}
+}
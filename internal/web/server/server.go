@@ -7,6 +7,7 @@ import (
 	libcontroller "github.com/Interhyp/go-backend-service-common/acorns/controller"
 	librepo "github.com/Interhyp/go-backend-service-common/acorns/repository"
 	libmiddleware "github.com/Interhyp/go-backend-service-common/web/middleware"
+	"github.com/Interhyp/go-backend-service-common/web/middleware/cancellogger"
 	"github.com/Interhyp/go-backend-service-common/web/middleware/requestlogging"
 	"github.com/Interhyp/go-backend-service-common/web/middleware/security"
 	"github.com/Interhyp/metadata-service/internal/acorn/application"
@@ -35,6 +36,14 @@ type Impl struct {
 	OwnerCtl            controller.OwnerController
 	ServiceCtl          controller.ServiceController
 	RepositoryCtl       controller.RepositoryController
+	SearchCtl           controller.SearchController
+	EventCtl            controller.EventController
+	GraphCtl            controller.GraphController
+	ExportCtl           controller.ExportController
+	TransactionCtl      controller.TransactionController
+	DeletedCtl          controller.DeletedController
+	UserCtl             controller.UserController
+	ReportCtl           controller.ReportController
 	WebhookCtl          controller.WebhookController
 
 	Router chi.Router
@@ -43,6 +52,16 @@ type Impl struct {
 	ServerReadTimeoutSeconds  int
 	ServerWriteTimeoutSeconds int
 	ServerIdleTimeoutSeconds  int
+
+	// streams ends the responses of the streaming routes when the server shuts down
+	streams     context.Context
+	stopStreams context.CancelFunc
+}
+
+// streamingRoutes keep their response open for as long as the client listens. They are exempt from the request
+// timeout, and end when the server shuts down instead.
+var streamingRoutes = map[string]bool{
+	"GET /rest/api/v1/events": true,
 }
 
 func New(
@@ -55,6 +74,14 @@ func New(
 	ownerCtl controller.OwnerController,
 	serviceCtl controller.ServiceController,
 	repositoryCtl controller.RepositoryController,
+	searchCtl controller.SearchController,
+	eventCtl controller.EventController,
+	graphCtl controller.GraphController,
+	exportCtl controller.ExportController,
+	transactionCtl controller.TransactionController,
+	deletedCtl controller.DeletedController,
+	userCtl controller.UserController,
+	reportCtl controller.ReportController,
 	webhookCtl controller.WebhookController,
 ) application.Server {
 	return &Impl{
@@ -67,6 +94,14 @@ func New(
 		OwnerCtl:            ownerCtl,
 		ServiceCtl:          serviceCtl,
 		RepositoryCtl:       repositoryCtl,
+		SearchCtl:           searchCtl,
+		EventCtl:            eventCtl,
+		GraphCtl:            graphCtl,
+		ExportCtl:           exportCtl,
+		TransactionCtl:      transactionCtl,
+		DeletedCtl:          deletedCtl,
+		UserCtl:             userCtl,
+		ReportCtl:           reportCtl,
 		WebhookCtl:          webhookCtl,
 
 		RequestTimeoutSeconds:     60,
@@ -93,14 +128,15 @@ func (s *Impl) WireUp(ctx context.Context) {
 	if s.Router == nil {
 		s.Logging.Logger().Ctx(ctx).Info().Print("creating router and setting up filter chain")
 		s.Router = chi.NewRouter()
+		s.streams, s.stopStreams = context.WithCancel(context.Background())
 
 		keysetPEM := s.IdentityProvider.GetKeySet(ctx)
 
+		// no RequestTimeoutSeconds, the request timeout of the stack cannot exempt the streaming routes, see requestTimeout
 		options := libmiddleware.MiddlewareStackOptions{
 			ElasticApmEnabled:          s.CustomConfiguration.ElasticApmEnabled(),
 			PlainLogging:               s.Configuration.PlainLogging(),
 			CorsAllowOrigin:            "*", // CORS ok for unauthorized requests
-			RequestTimeoutSeconds:      s.RequestTimeoutSeconds,
 			HasJwtIdTokenAuthorization: true,
 			JwtPublicKeyPEMs:           keysetPEM,
 			HasBasicAuthAuthorization:  true,
@@ -116,6 +152,13 @@ func (s *Impl) WireUp(ctx context.Context) {
 				"GET /rest/api/v1/owners.*",
 				"GET /rest/api/v1/services.*",
 				"GET /rest/api/v1/repositories.*",
+				"GET /rest/api/v1/search.*",
+				"GET /rest/api/v1/events.*",
+				"GET /rest/api/v1/graph.*",
+				"GET /rest/api/v1/export/.*",
+				"GET /rest/api/v1/deleted/.*",
+				"GET /rest/api/v1/users/.*",
+				"GET /rest/api/v1/reports/.*",
 				"POST /webhooks/.*",
 				// health (provides just up)
 				"GET /",
@@ -137,6 +180,8 @@ func (s *Impl) WireUp(ctx context.Context) {
 		if err != nil {
 			aulogging.Logger.Ctx(ctx).Fatal().WithErr(err).Printf("failed to set up middleware stack - BAILING OUT: %s", err.Error())
 		}
+		s.Router.Use(s.requestTimeout)
+		s.Router.Use(cancellogger.ConstructContextCancellationLoggerMiddleware("RequestTimeout"))
 	}
 
 	s.HealthCtl.WireUp(ctx, s.Router)
@@ -144,9 +189,36 @@ func (s *Impl) WireUp(ctx context.Context) {
 	s.OwnerCtl.WireUp(ctx, s.Router)
 	s.ServiceCtl.WireUp(ctx, s.Router)
 	s.RepositoryCtl.WireUp(ctx, s.Router)
+	s.SearchCtl.WireUp(ctx, s.Router)
+	s.EventCtl.WireUp(ctx, s.Router)
+	s.GraphCtl.WireUp(ctx, s.Router)
+	s.ExportCtl.WireUp(ctx, s.Router)
+	s.TransactionCtl.WireUp(ctx, s.Router)
+	s.DeletedCtl.WireUp(ctx, s.Router)
+	s.UserCtl.WireUp(ctx, s.Router)
+	s.ReportCtl.WireUp(ctx, s.Router)
 	s.WebhookCtl.WireUp(ctx, s.Router)
 }
 
+// requestTimeout limits the time a request may take, except on the streaming routes, which last until the server
+// shuts down.
+func (s *Impl) requestTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if streamingRoutes[r.Method+" "+r.URL.Path] {
+			ctx, cancel = context.WithCancel(r.Context())
+			stop := context.AfterFunc(s.streams, cancel)
+			defer stop()
+		} else {
+			ctx, cancel = context.WithTimeout(r.Context(), time.Duration(s.RequestTimeoutSeconds)*time.Second)
+		}
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (s *Impl) NewServer(ctx context.Context, address string, router http.Handler) *http.Server {
 	return &http.Server{
 		Addr:         address,
@@ -185,6 +257,10 @@ func (s *Impl) Run() error {
 
 	srvMain := s.CreateMainServer(ctx)
 	srvMetrics := s.CreateMetricsServer(ctx)
+	if s.stopStreams != nil {
+		// Shutdown waits for open responses, but streams never finish on their own
+		srvMain.RegisterOnShutdown(s.stopStreams)
+	}
 
 	go func() {
 		<-sig // wait for signal notification
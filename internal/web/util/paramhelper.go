@@ -7,10 +7,15 @@ import (
 	"github.com/Interhyp/go-backend-service-common/acorns/repository"
 	"github.com/Interhyp/go-backend-service-common/api/apierrors"
 	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
 	aulogging "github.com/StephanHCB/go-autumn-logging"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-http-utils/headers"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -32,6 +37,63 @@ func NonEmptyStringPathParam(_ context.Context, r *http.Request, key string, tim
 	return param, nil
 }
 
+func PageRequestQueryParams(ctx context.Context, r *http.Request, timestamp repository.Timestamp) (types.PageRequest, error) {
+	page := types.PageRequest{
+		Cursor: StringQueryParam(r, "cursor"),
+		Sort:   StringQueryParam(r, "sort"),
+	}
+	limit, err := PositiveIntQueryParam(ctx, r, "limit", 0, timestamp)
+	if err != nil {
+		return types.PageRequest{}, err
+	}
+	page.Limit = limit
+	return page, nil
+}
+
+func PositiveIntQueryParam(_ context.Context, r *http.Request, key string, defaultValue int, timestamp repository.Timestamp) (int, error) {
+	param := StringQueryParam(r, key)
+	if param == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(param)
+	if err != nil || parsed < 1 {
+		return 0, apierrors.NewBadRequestError("invalid.query.param", fmt.Sprintf("query param %s must be a positive integer", key), err, timestamp.Now())
+	}
+	return parsed, nil
+}
+
+// DryRunQueryParam marks the context as a dry run if the dryRun query parameter is true, see types.WithDryRun.
+func DryRunQueryParam(ctx context.Context, r *http.Request, timestamp repository.Timestamp) (context.Context, error) {
+	param := StringQueryParam(r, "dryRun")
+	if param == "" {
+		return ctx, nil
+	}
+	dryRun, err := strconv.ParseBool(param)
+	if err != nil {
+		return ctx, apierrors.NewBadRequestError("invalid.query.param", "query param dryRun must be true or false", err, timestamp.Now())
+	}
+	if dryRun {
+		return types.WithDryRun(ctx), nil
+	}
+	return ctx, nil
+}
+
+// PatchDocumentBody reads the body as a merge patch or json patch if the request has one of their content types.
+//
+// isPatchDocument is false for any other content type, the body is then left for the caller to parse.
+func PatchDocumentBody(_ context.Context, r *http.Request, timestamp repository.Timestamp) (patch types.PatchDocument, isPatchDocument bool, err error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(headers.ContentType))
+	if mediaType != types.MergePatchMediaType && mediaType != types.JsonPatchMediaType {
+		return types.PatchDocument{}, false, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return types.PatchDocument{}, true, apierrors.NewBadRequestError("patch.invalid", "failed to read body", err, timestamp.Now())
+	}
+	return types.PatchDocument{MediaType: mediaType, Body: body}, true, nil
+}
+
 func ParseBodyToDeletionDto(ctx context.Context, r *http.Request, timestamp time.Time) (openapi.DeletionDto, error) {
 	decoder := json.NewDecoder(r.Body)
 	dto := openapi.DeletionDto{}
@@ -5,12 +5,17 @@ import (
 	"encoding/json"
 	"github.com/Interhyp/go-backend-service-common/web/util/media"
 	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
 	aulogging "github.com/StephanHCB/go-autumn-logging"
 	"github.com/go-http-utils/headers"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// warningValueEscaper escapes a warning message for the quoted-string of a Warning header.
+var warningValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
 func Success(ctx context.Context, w http.ResponseWriter, _ *http.Request, response interface{}) {
 	w.Header().Set(headers.ContentType, media.ContentTypeApplicationJson)
 	WriteJson(ctx, w, response)
@@ -26,6 +31,24 @@ func SuccessNoBody(ctx context.Context, w http.ResponseWriter, _ *http.Request,
 	w.WriteHeader(status)
 }
 
+// setWarningHeaders adds a Warning header with the miscellaneous persistent warning code 299 for each problem
+// recorded with types.AddWarning. It must be called before the status is written.
+func setWarningHeaders(ctx context.Context, w http.ResponseWriter) {
+	for _, message := range types.Warnings(ctx) {
+		w.Header().Add(headers.Warning, `299 - "`+warningValueEscaper.Replace(message)+`"`)
+	}
+}
+
+// MovedPermanently redirects the client to the new path of a renamed entity, keeping the query parameters.
+func MovedPermanently(_ context.Context, w http.ResponseWriter, r *http.Request, path string) {
+	location := path
+	if r.URL.RawQuery != "" {
+		location += "?" + r.URL.RawQuery
+	}
+	w.Header().Set(headers.Location, location)
+	w.WriteHeader(http.StatusMovedPermanently)
+}
+
 func UnexpectedErrorHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, err error, timeStamp time.Time) {
 	aulogging.Logger.Ctx(ctx).Error().WithErr(err).Printf("unexpected error")
 	ErrorHandler(ctx, w, r, "unknown", http.StatusInternalServerError, err.Error(), timeStamp)
@@ -0,0 +1,175 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/Interhyp/go-backend-service-common/acorns/repository"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	"github.com/go-http-utils/headers"
+	"gopkg.in/yaml.v3"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const ContentTypeApplicationYaml = "application/yaml"
+
+// yamlMediaTypes are the media types we treat as yaml, the others are in use in the wild.
+var yamlMediaTypes = map[string]bool{
+	ContentTypeApplicationYaml: true,
+	"application/x-yaml":       true,
+	"text/yaml":                true,
+}
+
+// Headers that carry the fields that are not part of the yaml representation (json name -> header).
+//
+// The yaml of an entity is exactly what the metadata repository contains, so these travel next to it.
+const (
+	HeaderOwner      = "X-Metadata-Owner"
+	HeaderCommitHash = "X-Metadata-Commit-Hash"
+	HeaderTimeStamp  = "X-Metadata-Time-Stamp"
+	HeaderJiraIssue  = "X-Metadata-Jira-Issue"
+)
+
+var yamlVersionHeaders = map[string]string{
+	"owner":      HeaderOwner,
+	"commitHash": HeaderCommitHash,
+	"timeStamp":  HeaderTimeStamp,
+	"jiraIssue":  HeaderJiraIssue,
+}
+
+// AcceptsYaml is true if the Accept header asks for yaml before json.
+func AcceptsYaml(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get(headers.Accept), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		if yamlMediaTypes[mediaType] {
+			return true
+		}
+		if mediaType == "application/json" || mediaType == "application/*" || mediaType == "*/*" {
+			return false
+		}
+	}
+	return false
+}
+
+// HasYamlBody is true if the Content-Type of the request is yaml.
+func HasYamlBody(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(headers.ContentType))
+	return yamlMediaTypes[mediaType]
+}
+
+// ParseBody decodes the body into dto, as yaml or json depending on the Content-Type.
+//
+// For yaml, the fields that are not part of the yaml representation are taken from the request headers.
+func ParseBody(r *http.Request, dto interface{}) error {
+	if !HasYamlBody(r) {
+		return json.NewDecoder(r.Body).Decode(dto)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(body, dto); err != nil {
+		return err
+	}
+
+	versionFields := make(map[string]string)
+	for field, header := range yamlVersionHeaders {
+		if value := r.Header.Get(header); value != "" {
+			versionFields[field] = value
+		}
+	}
+	if len(versionFields) == 0 {
+		return nil
+	}
+	// dtos without some of these fields simply ignore them
+	versionJson, err := json.Marshal(versionFields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(versionJson, dto)
+}
+
+// SuccessEntity writes an owner, service or repository as yaml if the client accepts it, as json otherwise.
+//
+// The yaml uses the same indentation the metadata repository is written with. Problems recorded for the request with
+// types.AddWarning are sent as Warning headers.
+func SuccessEntity(ctx context.Context, w http.ResponseWriter, r *http.Request, entity interface{}, status int, indentation int, timestamp repository.Timestamp) {
+	setWarningHeaders(ctx, w)
+	if !AcceptsYaml(r) {
+		SuccessWithStatus(ctx, w, r, entity, status)
+		return
+	}
+
+	yamlBytes, err := marshalYaml(indentation, entity)
+	if err != nil {
+		UnexpectedErrorHandler(ctx, w, r, err, timestamp.Now())
+		return
+	}
+
+	if err := setVersionHeaders(w, entity); err != nil {
+		UnexpectedErrorHandler(ctx, w, r, err, timestamp.Now())
+		return
+	}
+	w.Header().Set(headers.ContentType, ContentTypeApplicationYaml)
+	w.WriteHeader(status)
+	if _, err := w.Write(yamlBytes); err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Printf("error while writing yaml response: %v", err)
+	}
+}
+
+// SuccessYamlStream writes the documents as a yaml stream, separated by ---.
+func SuccessYamlStream[T any](ctx context.Context, w http.ResponseWriter, r *http.Request, documents []T, indentation int, timestamp repository.Timestamp) {
+	yamlBytes, err := marshalYaml(indentation, documents...)
+	if err != nil {
+		UnexpectedErrorHandler(ctx, w, r, err, timestamp.Now())
+		return
+	}
+
+	w.Header().Set(headers.ContentType, ContentTypeApplicationYaml)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(yamlBytes); err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Printf("error while writing yaml response: %v", err)
+	}
+}
+
+func setVersionHeaders(w http.ResponseWriter, entity interface{}) error {
+	jsonBytes, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+		return err
+	}
+
+	for field, header := range yamlVersionHeaders {
+		if value, ok := fields[field].(string); ok && value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+	return nil
+}
+
+func marshalYaml[T any](indentation int, documents ...T) ([]byte, error) {
+	if len(documents) == 0 {
+		// the encoder cannot close an empty stream
+		return []byte{}, nil
+	}
+	buf := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(indentation)
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, err
+		}
+	}
+	err := encoder.Close()
+	return buf.Bytes(), err
+}
@@ -0,0 +1,226 @@
+package acceptance
+
+import (
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func tstRestore() openapi.RestoreDto {
+	return openapi.RestoreDto{
+		JiraIssue: "ISSUE-2345",
+	}
+}
+
+// tstDeleteBeforeRestore deletes an entity through the api, so it shows up in the history of deletions.
+func tstDeleteBeforeRestore(t *testing.T, relativeUrl string) {
+	body := tstDelete()
+	response, err := tstPerformDelete(relativeUrl, tstValidAdminToken(), &body)
+	tstAssertNoBody(t, response, err, http.StatusNoContent)
+	kafkaImpl.Reset()
+}
+
+// list deleted
+
+func TestGETDeleted_None(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the deleted services while nothing has been deleted")
+	response, err := tstPerformGet("/rest/api/v1/deleted/services", token)
+
+	docs.Then("Then the request is successful and the list is empty")
+	tstAssert(t, response, err, http.StatusOK, "deleted-none.json")
+}
+
+func TestGETDeleted_Repositories(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a repository that has been deleted")
+	tstDeleteBeforeRestore(t, "/rest/api/v1/repositories/whatever.implementation")
+
+	docs.When("When an unauthenticated user requests the deleted repositories")
+	response, err := tstPerformGet("/rest/api/v1/deleted/repositories", tstUnauthenticated())
+
+	docs.Then("Then the request is successful and the deletion is listed with its commit and jira issue")
+	tstAssert(t, response, err, http.StatusOK, "deleted-repositories.json")
+
+	docs.Then("And it is not listed among the deleted services")
+	response, err = tstPerformGet("/rest/api/v1/deleted/services", tstUnauthenticated())
+	tstAssert(t, response, err, http.StatusOK, "deleted-none.json")
+}
+
+func TestGETDeleted_InvalidKind(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the deleted entities of an unknown kind")
+	response, err := tstPerformGet("/rest/api/v1/deleted/unicorns", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "deleted-invalid-kind.json")
+}
+
+// restore
+
+func TestPOSTRestore_Repository(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a repository that has been deleted and an authenticated admin user")
+	tstDeleteBeforeRestore(t, "/rest/api/v1/repositories/whatever.implementation")
+	token := tstValidAdminToken()
+
+	docs.When("When they restore the repository")
+	body := tstRestore()
+	response, err := tstPerformPost("/rest/api/v1/deleted/repositories/whatever.implementation/restore", token, &body)
+
+	docs.Then("Then the request is successful and the response is the restored repository")
+	tstAssert(t, response, err, http.StatusOK, "restore-repository.json")
+
+	docs.Then("And the repository has been written from its last version, committed and pushed")
+	filename := "owners/some-owner/repositories/whatever.implementation.yaml"
+	require.Contains(t, metadataImpl.ReadContents(filename), "url: ssh://git@bitbucket.some-organisation.com:7999/PROJECT/whatever.git")
+	require.True(t, metadataImpl.FilesCommitted[filename])
+	require.True(t, metadataImpl.Pushed)
+
+	docs.Then("And a kafka message notifying other instances of the update has been sent")
+	require.Equal(t, 1, len(kafkaImpl.Recording))
+
+	docs.Then("And the repository is no longer listed as deleted")
+	response, err = tstPerformGet("/rest/api/v1/deleted/repositories", tstUnauthenticated())
+	tstAssert(t, response, err, http.StatusOK, "deleted-none.json")
+}
+
+func TestPOSTRestore_Service(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a service that has been deleted and an authenticated admin user")
+	tstDeleteBeforeRestore(t, "/rest/api/v1/services/some-service-backend")
+	token := tstValidAdminToken()
+
+	docs.When("When they restore the service")
+	body := tstRestore()
+	response, err := tstPerformPost("/rest/api/v1/deleted/services/some-service-backend/restore", token, &body)
+
+	docs.Then("Then the request is successful and the response is the restored service under its old owner")
+	tstAssert(t, response, err, http.StatusOK, "restore-service.json")
+
+	docs.Then("And the service has been written with its repository references, committed and pushed")
+	filename := "owners/some-owner/services/some-service-backend.yaml"
+	require.Contains(t, metadataImpl.ReadContents(filename), "some-service-backend/helm-deployment")
+	require.True(t, metadataImpl.FilesCommitted[filename])
+	require.True(t, metadataImpl.Pushed)
+}
+
+func TestPOSTRestore_Owner(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an owner that has been deleted and an authenticated admin user")
+	tstDeleteBeforeRestore(t, "/rest/api/v1/owners/deleteme")
+	token := tstValidAdminToken()
+
+	docs.When("When they restore the owner")
+	body := tstRestore()
+	response, err := tstPerformPost("/rest/api/v1/deleted/owners/deleteme/restore", token, &body)
+
+	docs.Then("Then the request is successful and the response is the restored owner")
+	tstAssert(t, response, err, http.StatusOK, "restore-owner.json")
+
+	docs.Then("And the owner has been committed and pushed")
+	require.True(t, metadataImpl.FilesCommitted["owners/deleteme/owner.info.yaml"])
+	require.True(t, metadataImpl.Pushed)
+}
+
+func TestPOSTRestore_NoLongerValid(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a deleted service whose repository has been deleted after it")
+	tstDeleteBeforeRestore(t, "/rest/api/v1/services/some-service-backend")
+	tstDeleteBeforeRestore(t, "/rest/api/v1/repositories/some-service-backend.helm-deployment")
+	metadataImpl.FilesWritten = make(map[string]bool)
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to restore the service")
+	body := tstRestore()
+	response, err := tstPerformPost("/rest/api/v1/deleted/services/some-service-backend/restore", token, &body)
+
+	docs.Then("Then the request fails because the service would reference a missing repository")
+	tstAssert(t, response, err, http.StatusBadRequest, "restore-service-missing-repository.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
+func TestPOSTRestore_NotDeleted(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to restore a repository that was never deleted")
+	body := tstRestore()
+	response, err := tstPerformPost("/rest/api/v1/deleted/repositories/never-there.implementation/restore", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "restore-notfound.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+}
+
+func TestPOSTRestore_AlreadyExists(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to restore an owner that exists")
+	body := tstRestore()
+	response, err := tstPerformPost("/rest/api/v1/deleted/owners/deleteme/restore", token, &body)
+
+	docs.Then("Then the request fails with a conflict and the current owner")
+	tstAssert(t, response, err, http.StatusConflict, "restore-conflict.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+}
+
+func TestPOSTRestore_InvalidValues(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a repository that has been deleted and an authenticated admin user")
+	tstDeleteBeforeRestore(t, "/rest/api/v1/repositories/whatever.implementation")
+	metadataImpl.FilesWritten = make(map[string]bool)
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to restore it without a jira issue")
+	body := openapi.RestoreDto{}
+	response, err := tstPerformPost("/rest/api/v1/deleted/repositories/whatever.implementation/restore", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "restore-invalid-values.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+}
+
+func TestPOSTRestore_NonAdminToken(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a user with a valid token without the admin role")
+	token := tstValidUserToken()
+
+	docs.When("When they attempt to restore a repository")
+	body := tstRestore()
+	response, err := tstPerformPost("/rest/api/v1/deleted/repositories/whatever.implementation/restore", token, &body)
+
+	docs.Then("Then the request is denied")
+	tstAssert(t, response, err, http.StatusForbidden, "forbidden.json")
+}
@@ -0,0 +1,114 @@
+package acceptance
+
+import (
+	"bufio"
+	"context"
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// events
+
+type tstEventStream struct {
+	response *http.Response
+	reader   *bufio.Reader
+	cancel   context.CancelFunc
+}
+
+// tstOpenEventStream connects to the event stream and waits until the subscription is in place.
+func tstOpenEventStream(t *testing.T, relativeUrlWithLeadingSlash string) tstEventStream {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+relativeUrlWithLeadingSlash, nil)
+	require.Nil(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	stream := tstEventStream{response: response, reader: bufio.NewReader(response.Body), cancel: cancel}
+	require.Equal(t, []string{": connected"}, stream.next(t))
+	return stream
+}
+
+// next reads the lines of the next message on the stream.
+func (s tstEventStream) next(t *testing.T) []string {
+	result := make([]string, 0)
+	for {
+		line, err := s.reader.ReadString('\n')
+		require.Nil(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return result
+		}
+		result = append(result, line)
+	}
+}
+
+func (s tstEventStream) close() {
+	s.cancel()
+	_ = s.response.Body.Close()
+}
+
+func TestGETEvents_Filtered(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user subscribed to the events of an owner")
+	stream := tstOpenEventStream(t, "/rest/api/v1/events?owner=events-owner-wanted")
+	defer stream.close()
+
+	docs.When("When an admin user creates another owner and then that owner")
+	tstCreateOwnerForEvents(t, "events-owner-unwanted")
+	tstCreateOwnerForEvents(t, "events-owner-wanted")
+
+	docs.Then("Then only the event for the owner is received, with the commit hash as its id")
+	require.Equal(t, []string{
+		"id: 6c8ac2c35791edf9979623c717a2430000000000",
+		"event: update",
+		"data: " + tstOwnerExpectedKafka("events-owner-wanted"),
+	}, stream.next(t))
+}
+
+func TestGETEvents_ResumeUnknown(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they attempt to resume the event stream after an event that is not known")
+	response, err := tstPerformWithHeaders(http.MethodGet, "/rest/api/v1/events", token, map[string]string{
+		"Last-Event-ID": "0000000000000000000000000000000000000000",
+	}, nil)
+
+	docs.Then("Then the request fails with gone and the error response is as expected")
+	tstAssert(t, response, err, http.StatusGone, "events-resume-gone.json")
+}
+
+func TestGETEvents_Resume(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an event for a new owner has been sent")
+	tstCreateOwnerForEvents(t, "events-owner-before")
+
+	docs.When("When an unauthenticated user resumes the event stream after that event")
+	stream := tstOpenEventStream(t, "/rest/api/v1/events?lastEventId=6c8ac2c35791edf9979623c717a2430000000000")
+	defer stream.close()
+
+	docs.Then("Then nothing is replayed and the next event received is that of a later change")
+	tstCreateOwnerForEvents(t, "events-owner-after")
+	require.Equal(t, []string{
+		"id: 6c8ac2c35791edf9979623c717a2430000000000",
+		"event: update",
+		"data: " + tstOwnerExpectedKafka("events-owner-after"),
+	}, stream.next(t))
+}
+
+func tstCreateOwnerForEvents(t *testing.T, alias string) {
+	body := tstOwner()
+	response, err := tstPerformPost("/rest/api/v1/owners/"+alias, tstValidAdminToken(), &body)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, response.status)
+}
@@ -0,0 +1,102 @@
+package acceptance
+
+import (
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+// export
+
+func TestGETBackstageExport_Success(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a service that depends on another service and provides an api")
+	body := tstServiceUnchangedPatch()
+	body.Lifecycle = ptr("operational")
+	body.Spec = &openapi.ServiceSpecDto{
+		System:       ptr("some-system"),
+		DependsOn:    []string{"some-service-backend-with-expandable-groups"},
+		ProvidesApis: []string{"some-api"},
+	}
+	patched, err := tstPerformPatch("/rest/api/v1/services/some-service-backend", tstValidAdminToken(), &body)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, patched.status)
+
+	docs.When("When an unauthenticated user requests the Backstage export for the owner")
+	response, err := tstPerformGet("/rest/api/v1/export/backstage?owner=some-owner", tstUnauthenticated())
+
+	docs.Then("Then the request is successful and the response is the expected yaml stream")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	require.Equal(t, "application/yaml", response.contentType)
+	require.Equal(t, `apiVersion: backstage.io/v1alpha1
+kind: Group
+metadata:
+    name: some-owner
+spec:
+    type: team
+    profile:
+        email: somebody@some-organisation.com
+    children: []
+---
+apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+    name: some-service-backend
+    annotations:
+        backstage.io/source-location: url:https://bitbucket.some-organisation.com/PROJECT/some-service-backend/tree/master/
+    links:
+        - url: /swagger-ui/index.html
+          title: Swagger UI
+spec:
+    type: service
+    lifecycle: operational
+    owner: group:some-owner
+    system: some-system
+    dependsOn:
+        - component:some-service-backend-with-expandable-groups
+    providesApis:
+        - some-api
+---
+apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+    name: some-service-backend-with-expandable-groups
+    annotations:
+        backstage.io/source-location: url:https://bitbucket.some-organisation.com/PROJECT/some-service-backend/tree/master/
+    links:
+        - url: /swagger-ui/index.html
+          title: Swagger UI
+spec:
+    type: service
+    lifecycle: unknown
+    owner: group:some-owner
+---
+apiVersion: backstage.io/v1alpha1
+kind: API
+metadata:
+    name: some-api
+spec:
+    type: unknown
+    lifecycle: operational
+    owner: group:some-owner
+    system: some-system
+    definition: provided by component:some-service-backend, the definition is not known to the metadata service
+`, response.body)
+}
+
+func TestGETBackstageExport_UnknownOwner(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the Backstage export for an owner that does not exist")
+	response, err := tstPerformGet("/rest/api/v1/export/backstage?owner=does-not-exist", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "export-backstage-owner-notfound.json")
+}
@@ -0,0 +1,118 @@
+package acceptance
+
+import (
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+// graph
+
+func TestGETGraph_Success(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a service that depends on another service and consumes an api it provides")
+	tstPatchServiceSpecForGraph(t, "some-service-backend-with-expandable-groups", &openapi.ServiceSpecDto{
+		ProvidesApis: []string{"some-api"},
+	})
+	tstPatchServiceSpecForGraph(t, "some-service-backend", &openapi.ServiceSpecDto{
+		System:       ptr("some-system"),
+		DependsOn:    []string{"some-service-backend-with-expandable-groups"},
+		ConsumesApis: []string{"some-api"},
+	})
+
+	docs.When("When an unauthenticated user requests the dependency graph")
+	response, err := tstPerformGet("/rest/api/v1/graph", tstUnauthenticated())
+
+	docs.Then("Then the request is successful and the response contains all nodes and edges")
+	tstAssert(t, response, err, http.StatusOK, "graph.json")
+}
+
+func TestGETGraph_RootedDot(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a service that depends on another service")
+	tstPatchServiceSpecForGraph(t, "some-service-backend", &openapi.ServiceSpecDto{
+		DependsOn: []string{"some-service-backend-with-expandable-groups"},
+	})
+
+	docs.When("When an unauthenticated user requests the dependents of the other service as Graphviz DOT")
+	response, err := tstPerformGet("/rest/api/v1/graph?service=some-service-backend-with-expandable-groups&direction=dependents&depth=1&format=dot", tstUnauthenticated())
+
+	docs.Then("Then the request is successful and the response is the expected DOT")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	require.Equal(t, "text/vnd.graphviz; charset=utf-8", response.contentType)
+	require.Equal(t, `digraph services {
+  rankdir=LR;
+  "service:some-service-backend" [label="some-service-backend", shape=box];
+  "service:some-service-backend-with-expandable-groups" [label="some-service-backend-with-expandable-groups", shape=box];
+  "service:some-service-backend" -> "service:some-service-backend-with-expandable-groups" [label="dependsOn"];
+}
+`, response.body)
+}
+
+func TestGETGraph_Mermaid(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a service that provides an api")
+	tstPatchServiceSpecForGraph(t, "some-service-backend", &openapi.ServiceSpecDto{
+		ProvidesApis: []string{"some-api"},
+	})
+
+	docs.When("When an unauthenticated user requests the graph of that service as Mermaid")
+	response, err := tstPerformGet("/rest/api/v1/graph?service=some-service-backend&format=mermaid", tstUnauthenticated())
+
+	docs.Then("Then the request is successful and the response is the expected flowchart")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	require.Equal(t, `flowchart LR
+  n0(["some-api"])
+  n1["some-service-backend"]
+  n1 -->|providesApi| n0
+  classDef unknown stroke-dasharray: 5 5
+`, response.body)
+}
+
+func TestGETGraph_UnknownService(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the graph rooted at a service that does not exist")
+	response, err := tstPerformGet("/rest/api/v1/graph?service=does-not-exist", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "graph-service-notfound.json")
+}
+
+func TestGETGraph_DependencyProblems(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a service that depends on another service")
+	tstPatchServiceSpecForGraph(t, "some-service-backend", &openapi.ServiceSpecDto{
+		DependsOn: []string{"some-service-backend-with-expandable-groups"},
+	})
+
+	docs.Given("And the other service was patched to depend on the first one and on a service that does not exist")
+	tstPatchServiceSpecForGraph(t, "some-service-backend-with-expandable-groups", &openapi.ServiceSpecDto{
+		DependsOn: []string{"some-service-backend", "does-not-exist"},
+	})
+
+	docs.When("When an unauthenticated user requests the dependency graph")
+	response, err := tstPerformGet("/rest/api/v1/graph", tstUnauthenticated())
+
+	docs.Then("Then the request is successful and the response reports the cycle and the unknown service")
+	tstAssert(t, response, err, http.StatusOK, "graph-dependency-problems.json")
+}
+
+func tstPatchServiceSpecForGraph(t *testing.T, name string, spec *openapi.ServiceSpecDto) {
+	body := tstServiceUnchangedPatch()
+	body.Spec = spec
+	response, err := tstPerformPatch("/rest/api/v1/services/"+name, tstValidAdminToken(), &body)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+}
@@ -1,8 +1,10 @@
 package acceptance
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/metadata-service/api"
 	"github.com/Interhyp/metadata-service/internal/types"
 	"github.com/stretchr/testify/require"
 	"net/http"
@@ -24,6 +26,55 @@ func TestGETOwners_Success(t *testing.T) {
 	tstAssert(t, response, err, http.StatusOK, "owners.json")
 }
 
+func TestGETOwners_Paginated(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the first page of owners with limit 1")
+	response, err := tstPerformGet("/rest/api/v1/owners?limit=1", token)
+
+	docs.Then("Then the request is successful and the response contains the first owner and a cursor")
+	tstAssert(t, response, err, http.StatusOK, "owners-page-1.json")
+
+	docs.When("When they request the next page using the cursor")
+	page := openapi.OwnerListDto{}
+	require.Nil(t, json.Unmarshal([]byte(response.body), &page))
+	require.NotNil(t, page.NextCursor)
+	response, err = tstPerformGet("/rest/api/v1/owners?limit=1&cursor="+*page.NextCursor, token)
+
+	docs.Then("Then the request is successful and the response contains the last owner and no cursor")
+	tstAssert(t, response, err, http.StatusOK, "owners-page-2.json")
+}
+
+func TestGETOwners_InvalidLimit(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the list of owners with an invalid limit")
+	response, err := tstPerformGet("/rest/api/v1/owners?limit=many", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "owners-invalid-limit.json")
+}
+
+func TestGETOwners_CursorExpired(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request a page with a cursor for a list snapshot that is gone")
+	cursor := base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2022-11-01T00:00:00Z","i":"2022-11-01T00:00:00Z","s":"name","k":"deleteme","v":"deleteme"}`))
+	response, err := tstPerformGet("/rest/api/v1/owners?limit=1&cursor="+cursor, token)
+
+	docs.Then("Then the request fails with gone and the error response is as expected")
+	tstAssert(t, response, err, http.StatusGone, "owners-cursor-expired.json")
+}
+
 // get owner
 
 func TestGETOwner_Success(t *testing.T) {
@@ -52,6 +103,71 @@ func TestGETOwner_NotFound(t *testing.T) {
 	tstAssert(t, response, err, http.StatusNotFound, "owner-notfound-migration-excellence.json")
 }
 
+func TestGETOwner_AtCommit(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request a single existing owner at an abbreviated commit hash")
+	response, err := tstPerformGet("/rest/api/v1/owners/some-owner?at=6c8ac2c3", token)
+
+	docs.Then("Then the request is successful and the response is the owner as of that commit")
+	tstAssert(t, response, err, http.StatusOK, "owner.json")
+}
+
+func TestGETOwners_AtTimeBeforeHistory(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the list of owners at a time before the first commit")
+	response, err := tstPerformGet("/rest/api/v1/owners?at=2020-01-01T00:00:00Z", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "owners-at-notfound.json")
+}
+
+func TestGETOwnerHistory_Success(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the history of an existing owner")
+	response, err := tstPerformGet("/rest/api/v1/owners/some-owner/history", token)
+
+	docs.Then("Then the request is successful and the response lists the commits that changed the owner")
+	tstAssert(t, response, err, http.StatusOK, "owner-history.json")
+}
+
+func TestGETOwnerHistory_NotFound(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the history of an owner that never existed")
+	response, err := tstPerformGet("/rest/api/v1/owners/migration-excellence/history", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "owner-history-notfound.json")
+}
+
+func TestGETOwnerDiff_NotFound(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the diff of an owner that exists at neither commit")
+	response, err := tstPerformGet("/rest/api/v1/owners/migration-excellence/diff?from=6c8ac2c3", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "owner-diff-notfound.json")
+}
+
 // create owner
 
 func TestPOSTOwner_Success(t *testing.T) {
@@ -88,6 +204,50 @@ func TestPOSTOwner_Success(t *testing.T) {
 	hasSentNotification(t, "receivesOwner", "post-owner-success", types.CreatedEvent, types.OwnerPayload, &payload)
 }
 
+func TestPOSTOwner_DryRun(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they request a dry run of the creation of a valid owner that does not exist")
+	body := tstOwner()
+	response, err := tstPerformPost("/rest/api/v1/owners/post-owner-success?dryRun=true", token, &body)
+
+	docs.Then("Then the request is successful and the response is the owner as it would have been created")
+	tstAssert(t, response, err, http.StatusOK, "owner-create-dryrun.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+	require.False(t, metadataImpl.Pushed)
+
+	docs.Then("And the owner has not been cached")
+	readAgain, err := tstPerformGet("/rest/api/v1/owners/post-owner-success", tstUnauthenticated())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusNotFound, readAgain.status)
+
+	docs.Then("And no kafka messages have been sent")
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
+func TestPOSTOwner_DryRunInvalid(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they request the creation of an owner with a dryRun value that is not a boolean")
+	body := tstOwner()
+	response, err := tstPerformPost("/rest/api/v1/owners/post-owner-success?dryRun=maybe", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "owner-dryrun-invalid.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+}
+
 func TestPOSTOwner_InvalidAlias(t *testing.T) {
 	tstReset()
 
@@ -562,6 +722,36 @@ func TestPATCHOwner_Success(t *testing.T) {
 	hasSentNotification(t, "receivesOwner", "some-owner", types.ModifiedEvent, types.OwnerPayload, &payload)
 }
 
+func TestPATCHOwner_MergePatch(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they send a merge patch that removes a field and a single group of an existing owner")
+	response, err := tstPerformPatchDocument("/rest/api/v1/owners/some-owner", token, "application/merge-patch+json", `{
+		"contact": "somebody@some-organisation.com",
+		"productOwner": "kschlangenheldt",
+		"teamsChannelURL": null,
+		"groups": {"users": null, "admins": ["some-user"]},
+		"commitHash": "6c8ac2c35791edf9979623c717a243fc53400000",
+		"timeStamp": "2022-11-06T18:14:10Z",
+		"jiraIssue": "ISSUE-2345"
+	}`)
+
+	docs.Then("Then the request is successful and the response is as expected")
+	tstAssert(t, response, err, http.StatusOK, "owner-merge-patch.json")
+
+	docs.Then("And the owner has been correctly written, committed and pushed")
+	filename := "owners/some-owner/owner.info.yaml"
+	written := metadataImpl.ReadContents(filename)
+	require.NotContains(t, written, "teamsChannelURL")
+	require.NotContains(t, written, "users")
+	require.Contains(t, written, "admins")
+	require.True(t, metadataImpl.FilesCommitted[filename])
+	require.True(t, metadataImpl.Pushed)
+}
+
 func TestPATCHOwner_NoChangeSuccess(t *testing.T) {
 	tstReset()
 
@@ -760,6 +950,42 @@ func TestPATCHOwner_GitHookDeclined(t *testing.T) {
 	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
 }
 
+func TestPATCHOwner_BrokenGroupReference(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they patch an owner to have members referencing a group it does not have")
+	body := tstOwnerPatch()
+	body.Members = []string{"@some-owner.admins"}
+	response, err := tstPerformPatch("/rest/api/v1/owners/some-owner", token, &body)
+
+	docs.Then("Then the request fails and the error response names the field and the reference")
+	tstAssert(t, response, err, http.StatusBadRequest, "owner-patch-broken-group-reference.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPATCHOwner_OwnGroupReference(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they patch an owner to add a group and reference it in its members in the same request")
+	body := tstOwnerPatch()
+	body.Groups = map[string][]string{"users": {"some-other-user"}, "admins": {"some-user"}}
+	body.Members = []string{"@some-owner.admins"}
+	response, err := tstPerformPatch("/rest/api/v1/owners/some-owner", token, &body)
+
+	docs.Then("Then the request is successful")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+}
+
 // delete owner
 
 func TestDELETEOwner_Success(t *testing.T) {
@@ -905,6 +1131,20 @@ func TestDELETEOwner_StillHasStuffConflict(t *testing.T) {
 	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
 }
 
+func TestDELETEOwner_DryRunStillHasStuffConflict(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they request a dry run of the deletion of an owner that still owns a service")
+	body := tstDelete()
+	response, err := tstPerformDelete("/rest/api/v1/owners/some-owner?dryRun=true", token, &body)
+
+	docs.Then("Then the request fails with the same error as a real deletion")
+	tstAssert(t, response, err, http.StatusConflict, "owner-delete-conflict.json")
+}
+
 func TestDELETEOwner_GitServerDown(t *testing.T) {
 	tstReset()
 
@@ -944,3 +1184,371 @@ func TestDELETEOwner_GitHookDeclined(t *testing.T) {
 	require.Equal(t, 0, len(metadataImpl.FilesWritten))
 	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
 }
+
+// rename owner
+
+func tstOwnerRename(newAlias string) openapi.OwnerRenameDto {
+	return openapi.OwnerRenameDto{
+		NewAlias:  newAlias,
+		JiraIssue: "ISSUE-2345",
+	}
+}
+
+func TestPOSTOwnerRename_Success(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they rename an existing owner to an alias that is not in use")
+	body := tstOwnerRename("renamed-owner")
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/rename", token, &body)
+
+	docs.Then("Then the request is successful and the response is the owner under its new alias")
+	tstAssert(t, response, err, http.StatusOK, "owner-rename.json")
+
+	docs.Then("And all files of the owner have been moved in a single commit and pushed")
+	require.Equal(t, "<notfound>", metadataImpl.ReadContents("owners/some-owner/owner.info.yaml"))
+	require.True(t, metadataImpl.FilesCommitted["owners/some-owner/owner.info.yaml"])
+	require.True(t, metadataImpl.FilesCommitted["owners/renamed-owner/owner.info.yaml"])
+	require.True(t, metadataImpl.FilesCommitted["owners/renamed-owner/services/some-service-backend.yaml"])
+	require.True(t, metadataImpl.FilesCommitted["owners/renamed-owner/repositories/karma-wrapper.helm-chart.yaml"])
+	require.False(t, metadataImpl.FilesCommitted["owners/deleteme/owner.info.yaml"])
+	require.True(t, metadataImpl.Pushed)
+
+	docs.Then("And group references to the owner have been rewritten")
+	repositoryYaml := metadataImpl.ReadContents("owners/renamed-owner/repositories/some-service-backend-with-expandable-groups.helm-deployment.yaml")
+	require.Contains(t, repositoryYaml, "'@renamed-owner.users'")
+	require.NotContains(t, repositoryYaml, "@some-owner.")
+
+	docs.Then("And the old alias has been recorded as a redirect")
+	require.Equal(t, "owners:\n    some-owner: renamed-owner\n", metadataImpl.ReadContents("redirects.yaml"))
+
+	docs.Then("And services and repositories can be read with their new owner")
+	readAgain, err := tstPerformGet("/rest/api/v1/services/some-service-backend", tstUnauthenticated())
+	require.Nil(t, err)
+	require.Contains(t, readAgain.body, `"owner":"renamed-owner"`)
+
+	docs.Then("And reading the owner under its old alias redirects to the new alias")
+	redirected, err := tstPerformGetNoRedirect("/rest/api/v1/owners/some-owner", tstUnauthenticated())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusMovedPermanently, redirected.status)
+	require.Equal(t, "/rest/api/v1/owners/renamed-owner", redirected.location)
+
+	docs.Then("And a single kafka message for all affected entities has been sent")
+	require.Equal(t, 1, len(kafkaImpl.Recording))
+	require.Equal(t, []string{"some-owner", "renamed-owner"}, kafkaImpl.Recording[0].Affected.OwnerAliases)
+	require.Contains(t, kafkaImpl.Recording[0].Affected.ServiceNames, "some-service-backend")
+	require.Contains(t, kafkaImpl.Recording[0].Affected.RepositoryKeys, "karma-wrapper.helm-chart")
+
+	docs.Then("And notifications about the deletion of the old and the creation of the new owner have been sent")
+	hasSentNotification(t, "receivesDelete", "some-owner", types.DeletedEvent, types.OwnerPayload, nil)
+	hasSentNotification(t, "receivesOwner", "some-owner", types.DeletedEvent, types.OwnerPayload, nil)
+}
+
+func TestPOSTOwnerRename_DryRun(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they request a dry run of renaming an existing owner")
+	body := tstOwnerRename("renamed-owner")
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/rename?dryRun=true", token, &body)
+
+	docs.Then("Then the request is successful")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
+func TestPOSTOwnerRename_DoesNotExist(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to rename an owner that does not exist")
+	body := tstOwnerRename("renamed-owner")
+	response, err := tstPerformPost("/rest/api/v1/owners/does-not-exist/rename", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "owner-notfound.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTOwnerRename_Conflict(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to rename an owner to the alias of another existing owner")
+	body := tstOwnerRename("deleteme")
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/rename", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusConflict, "owner-rename-conflict.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTOwnerRename_InvalidValues(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to rename an owner without a jira issue")
+	body := tstOwnerRename("renamed-owner")
+	body.JiraIssue = ""
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/rename", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "owner-rename-invalid-values.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTOwnerRename_InvalidAlias(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to rename an owner to an invalid alias")
+	body := tstOwnerRename("Renamed_Owner")
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/rename", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "owner-invalid-alias.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTOwnerRename_NonAdminToken(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a user with a valid token without the admin role")
+	token := tstValidUserToken()
+
+	docs.When("When they attempt to rename an owner")
+	body := tstOwnerRename("renamed-owner")
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/rename", token, &body)
+
+	docs.Then("Then the request is denied")
+	tstAssert(t, response, err, http.StatusForbidden, "forbidden.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+// transfer ownership
+
+func tstOwnerTransfer(targetOwner string, services []string, repositories []string) openapi.OwnerTransferDto {
+	return openapi.OwnerTransferDto{
+		TargetOwner:  targetOwner,
+		Services:     services,
+		Repositories: repositories,
+		JiraIssue:    "ISSUE-2345",
+	}
+}
+
+func TestPOSTOwnerTransfer_Selected(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they transfer a service and an unreferenced repository to another owner")
+	body := tstOwnerTransfer("deleteme", []string{"some-service-backend"}, []string{"karma-wrapper.helm-chart"})
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/transfer", token, &body)
+
+	docs.Then("Then the request is successful and the response lists everything that was transferred")
+	tstAssert(t, response, err, http.StatusOK, "owner-transfer.json")
+
+	docs.Then("And the service has taken its repositories along in a single commit, which has been pushed")
+	require.Equal(t, "<notfound>", metadataImpl.ReadContents("owners/some-owner/services/some-service-backend.yaml"))
+	require.True(t, metadataImpl.FilesCommitted["owners/deleteme/services/some-service-backend.yaml"])
+	require.True(t, metadataImpl.FilesCommitted["owners/deleteme/repositories/some-service-backend.helm-deployment.yaml"])
+	require.True(t, metadataImpl.FilesCommitted["owners/deleteme/repositories/some-service-backend.implementation.yaml"])
+	require.True(t, metadataImpl.FilesCommitted["owners/deleteme/repositories/karma-wrapper.helm-chart.yaml"])
+	require.False(t, metadataImpl.FilesCommitted["owners/deleteme/repositories/whatever.implementation.yaml"])
+	require.True(t, metadataImpl.Pushed)
+
+	docs.Then("And the service can be read with its new owner")
+	readAgain, err := tstPerformGet("/rest/api/v1/services/some-service-backend", tstUnauthenticated())
+	require.Nil(t, err)
+	require.Contains(t, readAgain.body, `"owner":"deleteme"`)
+
+	docs.Then("And a single kafka message for all affected entities has been sent")
+	require.Equal(t, 1, len(kafkaImpl.Recording))
+	require.Equal(t, []string{"some-owner", "deleteme"}, kafkaImpl.Recording[0].Affected.OwnerAliases)
+	require.Equal(t, []string{"some-service-backend"}, kafkaImpl.Recording[0].Affected.ServiceNames)
+	require.Equal(t, []string{"karma-wrapper.helm-chart", "some-service-backend.helm-deployment", "some-service-backend.implementation"}, kafkaImpl.Recording[0].Affected.RepositoryKeys)
+}
+
+func TestPOSTOwnerTransfer_All(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they transfer all services and repositories of an owner to another owner")
+	body := openapi.OwnerTransferDto{TargetOwner: "deleteme", All: true, JiraIssue: "ISSUE-2345"}
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/transfer", token, &body)
+
+	docs.Then("Then the request is successful and the response lists everything that was transferred")
+	tstAssert(t, response, err, http.StatusOK, "owner-transfer-all.json")
+
+	docs.Then("And the owner no longer has any services or repositories")
+	services, err := tstPerformGet("/rest/api/v1/services?owner=some-owner", tstUnauthenticated())
+	require.Nil(t, err)
+	require.NotContains(t, services.body, `"owner":"some-owner"`)
+	repositories, err := tstPerformGet("/rest/api/v1/repositories?owner=some-owner", tstUnauthenticated())
+	require.Nil(t, err)
+	require.NotContains(t, repositories.body, `"owner":"some-owner"`)
+
+	docs.Then("And a single commit has been pushed")
+	require.Equal(t, 1, len(kafkaImpl.Recording))
+	require.True(t, metadataImpl.Pushed)
+}
+
+func TestPOSTOwnerTransfer_DryRun(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they request a dry run of transferring a service to another owner")
+	body := tstOwnerTransfer("deleteme", []string{"some-service-backend"}, nil)
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/transfer?dryRun=true", token, &body)
+
+	docs.Then("Then the request is successful and the response previews what would be transferred")
+	tstAssert(t, response, err, http.StatusOK, "owner-transfer-dryrun.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
+func TestPOSTOwnerTransfer_Referenced(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to transfer a repository that is referenced by a service that stays")
+	body := tstOwnerTransfer("deleteme", nil, []string{"some-service-backend.helm-deployment", "whatever.implementation"})
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/transfer", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusConflict, "owner-transfer-referenced.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTOwnerTransfer_NotOwned(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to transfer a service that does not exist")
+	body := tstOwnerTransfer("deleteme", []string{"unicorn"}, nil)
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/transfer", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "owner-transfer-not-owned.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTOwnerTransfer_InvalidValues(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt a transfer that combines all with a selection and has no jira issue")
+	body := tstOwnerTransfer("deleteme", []string{"some-service-backend"}, nil)
+	body.All = true
+	body.JiraIssue = ""
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/transfer", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "owner-transfer-invalid-values.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTOwnerTransfer_MissingTarget(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to transfer a service to an owner that does not exist")
+	body := tstOwnerTransfer("unicorn", []string{"some-service-backend"}, nil)
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/transfer", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "owner-transfer-missing-target.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTOwnerTransfer_DoesNotExist(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to transfer from an owner that does not exist")
+	body := tstOwnerTransfer("deleteme", []string{"some-service-backend"}, nil)
+	response, err := tstPerformPost("/rest/api/v1/owners/does-not-exist/transfer", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "owner-notfound.json")
+}
+
+func TestPOSTOwnerTransfer_NonAdminToken(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a user with a valid token without the admin role")
+	token := tstValidUserToken()
+
+	docs.When("When they attempt to transfer a service to another owner")
+	body := tstOwnerTransfer("deleteme", []string{"some-service-backend"}, nil)
+	response, err := tstPerformPost("/rest/api/v1/owners/some-owner/transfer", token, &body)
+
+	docs.Then("Then the request is denied")
+	tstAssert(t, response, err, http.StatusForbidden, "forbidden.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
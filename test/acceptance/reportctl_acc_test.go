@@ -0,0 +1,43 @@
+package acceptance
+
+import (
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+// broken group references
+
+func TestGETBrokenGroupReferences_None(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the broken group references while all group references resolve")
+	response, err := tstPerformGet("/rest/api/v1/reports/broken-group-references", token)
+
+	docs.Then("Then the request is successful and the response lists no references")
+	tstAssert(t, response, err, http.StatusOK, "broken-group-references-none.json")
+}
+
+func TestGETBrokenGroupReferences_GroupRemoved(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.Given("And an owner group that is referenced by a repository has been removed")
+	body := tstOwnerPatch()
+	body.Groups = map[string][]string{"admins": {"some-user"}}
+	response, err := tstPerformPatch("/rest/api/v1/owners/some-owner", tstValidAdminToken(), &body)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+
+	docs.When("When they request the broken group references")
+	response, err = tstPerformGet("/rest/api/v1/reports/broken-group-references", token)
+
+	docs.Then("Then the request is successful and the response lists the references to the removed group")
+	tstAssert(t, response, err, http.StatusOK, "broken-group-references-group-removed.json")
+}
@@ -3,9 +3,11 @@ package acceptance
 import (
 	"encoding/json"
 	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/metadata-service/api"
 	"github.com/Interhyp/metadata-service/internal/types"
 	"github.com/stretchr/testify/require"
 	"net/http"
+	"net/url"
 	"testing"
 )
 
@@ -76,6 +78,32 @@ func TestGETRepositories_Filtered_Type(t *testing.T) {
 	tstAssert(t, response, err, http.StatusOK, "repositories-filtered-type.json")
 }
 
+func TestGETRepositories_LabelSelector(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the list of repositories filtered by type and a label selector all repositories match")
+	response, err := tstPerformGet("/rest/api/v1/repositories?type=implementation&labelSelector="+url.QueryEscape("!team,tags notin (legacy)"), token)
+
+	docs.Then("Then the request is successful and the response is the same as without the label selector")
+	tstAssert(t, response, err, http.StatusOK, "repositories-filtered-type.json")
+}
+
+func TestGETRepositories_Paginated(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the first page of repositories filtered by type with limit 1")
+	response, err := tstPerformGet("/rest/api/v1/repositories?type=implementation&limit=1", token)
+
+	docs.Then("Then the request is successful and the response contains the first matching repository and a cursor")
+	tstAssert(t, response, err, http.StatusOK, "repositories-page-1.json")
+}
+
 // get repository
 
 func TestGETRepository_Success(t *testing.T) {
@@ -117,6 +145,117 @@ func TestGETRepository_NotFound(t *testing.T) {
 	tstAssert(t, response, err, http.StatusNotFound, "repository-notfound.json")
 }
 
+func TestGETRepository_AtCommit(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request a single existing repository at a full commit hash")
+	response, err := tstPerformGet("/rest/api/v1/repositories/some-service-backend.helm-deployment?at=6c8ac2c35791edf9979623c717a243fc53400000", token)
+
+	docs.Then("Then the request is successful and the response is the repository as of that commit")
+	tstAssert(t, response, err, http.StatusOK, "repository.json")
+}
+
+func TestGETRepository_AtUnknownCommit(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request a single existing repository at an unknown commit hash")
+	response, err := tstPerformGet("/rest/api/v1/repositories/some-service-backend.helm-deployment?at=deadbeef", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "repository-at-notfound.json")
+}
+
+func TestGETRepositoryHistory_Success(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the history of an existing repository")
+	response, err := tstPerformGet("/rest/api/v1/repositories/some-service-backend.helm-deployment/history", token)
+
+	docs.Then("Then the request is successful and the response lists the commits that changed the repository")
+	tstAssert(t, response, err, http.StatusOK, "repository-history.json")
+}
+
+func TestGETRepositoryHistory_NotFound(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the history of a repository that never existed")
+	response, err := tstPerformGet("/rest/api/v1/repositories/unicorn.helm-chart/history", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "repository-history-notfound.json")
+}
+
+func TestGETRepositoryServices_Success(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the services of a repository that is referenced by two services")
+	response, err := tstPerformGet("/rest/api/v1/repositories/some-service-backend.implementation/services", token)
+
+	docs.Then("Then the request is successful and the response lists both services")
+	tstAssert(t, response, err, http.StatusOK, "repository-services.json")
+}
+
+func TestGETRepositoryServices_None(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the services of a repository that no service references")
+	response, err := tstPerformGet("/rest/api/v1/repositories/whatever.implementation/services", token)
+
+	docs.Then("Then the request is successful and the response lists no services")
+	tstAssert(t, response, err, http.StatusOK, "repository-services-none.json")
+}
+
+func TestGETRepositoryServices_NotFound(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the services of a repository that does not exist")
+	response, err := tstPerformGet("/rest/api/v1/repositories/unicorn.helm-chart/services", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "repository-services-notfound.json")
+}
+
+func TestGETRepositoryDiff_AfterPatch(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.Given("And a repository that has not changed since the original commit")
+	response, err := tstPerformGet("/rest/api/v1/repositories/karma-wrapper.helm-chart/diff?from=6c8ac2c3", token)
+	tstAssert(t, response, err, http.StatusOK, "repository-diff-unchanged.json")
+
+	docs.When("When an admin patches the repository")
+	body := tstRepositoryPatch()
+	_, err = tstPerformPatch("/rest/api/v1/repositories/karma-wrapper.helm-chart", tstValidAdminToken(), &body)
+	require.Nil(t, err)
+
+	docs.Then("Then the diff since the original commit lists the changed fields")
+	response, err = tstPerformGet("/rest/api/v1/repositories/karma-wrapper.helm-chart/diff?from=6c8ac2c3", token)
+	tstAssert(t, response, err, http.StatusOK, "repository-diff-patched.json")
+}
+
 // create repository
 
 func TestPOSTRepository_Success(t *testing.T) {
@@ -210,6 +349,25 @@ func TestPOSTRepository_InvalidValues(t *testing.T) {
 	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
 }
 
+func TestPOSTRepository_BrokenGroupReference(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they request the creation of a repository with watchers referencing a group of an owner that does not exist")
+	body := tstRepository()
+	body.Configuration.Watchers = []string{"some-user", "@unknown.users"}
+	response, err := tstPerformPost("/rest/api/v1/repositories/post-repository-broken-group.api", token, &body)
+
+	docs.Then("Then the request fails and the error response names the field and the reference")
+	tstAssert(t, response, err, http.StatusBadRequest, "repository-create-broken-group-reference.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
 func TestPOSTRepository_NonexistentOwner(t *testing.T) {
 	tstReset()
 
@@ -684,6 +842,72 @@ func TestPATCHRepository_Success(t *testing.T) {
 	hasSentNotification(t, "receivesRepository", "karma-wrapper.helm-chart", types.ModifiedEvent, types.RepositoryPayload, &payload)
 }
 
+func TestPATCHRepository_JsonPatch(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they send a json patch that replaces the configuration of an existing repository and extends it")
+	response, err := tstPerformPatchDocument("/rest/api/v1/repositories/karma-wrapper.helm-chart", token, "application/json-patch+json", `[
+		{"op": "test", "path": "/type", "value": "helm-chart"},
+		{"op": "replace", "path": "/mainline", "value": "master"},
+		{"op": "replace", "path": "/configuration", "value": {"branchNameRegex": "testing_.*", "approvers": {"testing": ["some-user"]}}},
+		{"op": "add", "path": "/configuration/approvers/testing/-", "value": "some-other-user"},
+		{"op": "add", "path": "/configuration/requireConditions", "value": {"snyk-key": {"refMatcher": "master"}}},
+		{"op": "replace", "path": "/commitHash", "value": "6c8ac2c35791edf9979623c717a243fc53400000"},
+		{"op": "replace", "path": "/timeStamp", "value": "2022-11-06T18:14:10Z"},
+		{"op": "replace", "path": "/jiraIssue", "value": "ISSUE-2345"}
+	]`)
+
+	docs.Then("Then the request is successful and the response is as expected")
+	tstAssert(t, response, err, http.StatusOK, "repository-json-patch.json")
+
+	docs.Then("And the repository has been committed and pushed")
+	filename := "owners/some-owner/repositories/karma-wrapper.helm-chart.yaml"
+	require.Contains(t, metadataImpl.ReadContents(filename), "some-other-user")
+	require.True(t, metadataImpl.FilesCommitted[filename])
+	require.True(t, metadataImpl.Pushed)
+}
+
+func TestPATCHRepository_MergePatchMissingJiraIssue(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they send a merge patch that does not set commit hash, timestamp and jira issue")
+	response, err := tstPerformPatchDocument("/rest/api/v1/repositories/karma-wrapper.helm-chart", token, "application/merge-patch+json", `{"mainline": "main"}`)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "repository-merge-patch-invalid-values.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+}
+
+func TestPATCHRepository_DryRun(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they request a dry run of a valid patch of an existing repository")
+	body := tstRepositoryPatch()
+	response, err := tstPerformPatch("/rest/api/v1/repositories/karma-wrapper.helm-chart?dryRun=true", token, &body)
+
+	docs.Then("Then the request is successful and the response is the patched repository as it would have been written")
+	tstAssert(t, response, err, http.StatusOK, "repository-patch-dryrun.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+	require.False(t, metadataImpl.Pushed)
+
+	docs.Then("And no kafka messages have been sent")
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
 func TestPATCHRepository_NoChangeSuccess(t *testing.T) {
 	tstReset()
 
@@ -955,6 +1179,184 @@ func TestPATCHRepository_ChangeOwnerReferenced(t *testing.T) {
 	require.Equal(t, 0, len(kafkaImpl.Recording))
 }
 
+// bulk patch repositories
+
+func tstRepositoryBulkPatch() openapi.RepositoryPatchDto {
+	return openapi.RepositoryPatchDto{
+		Configuration: &openapi.RepositoryConfigurationPatchDto{
+			RequireConditions: map[string]openapi.ConditionReferenceDto{
+				"snyk-key": {RefMatcher: "master"},
+			},
+		},
+		JiraIssue: "ISSUE-2345",
+	}
+}
+
+func TestPATCHRepositories_SuccessByType(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they bulk patch all repositories of a type")
+	body := tstRepositoryBulkPatch()
+	response, err := tstPerformPatch("/rest/api/v1/repositories?type=helm-deployment", token, &body)
+
+	docs.Then("Then the request is successful and the response lists the changed repositories with their diffs")
+	tstAssert(t, response, err, http.StatusOK, "repositories-bulk-patch.json")
+
+	docs.Then("And exactly the matching repositories have been written, committed and pushed")
+	require.Equal(t, 3, len(metadataImpl.FilesCommitted))
+	for _, filename := range []string{
+		"owners/some-owner/repositories/some-service-backend.helm-deployment.yaml",
+		"owners/some-owner/repositories/some-service-backend-with-expandable-groups.helm-deployment.yaml",
+		"owners/some-owner/repositories/whatever.helm-deployment.yaml",
+	} {
+		require.Contains(t, metadataImpl.ReadContents(filename), "snyk-key")
+		require.True(t, metadataImpl.FilesCommitted[filename])
+	}
+	require.True(t, metadataImpl.Pushed)
+
+	docs.Then("And a single kafka message notifying other instances of the update has been sent")
+	require.Equal(t, 1, len(kafkaImpl.Recording))
+	require.Equal(t, []string{
+		"some-service-backend-with-expandable-groups.helm-deployment",
+		"some-service-backend.helm-deployment",
+		"whatever.helm-deployment",
+	}, kafkaImpl.Recording[0].Affected.RepositoryKeys)
+}
+
+func TestPATCHRepositories_SuccessByOwnerAndLabels(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they bulk patch the repositories of an owner selected by a label selector that matches none")
+	body := tstRepositoryBulkPatch()
+	response, err := tstPerformPatch("/rest/api/v1/repositories?owner=some-owner&labelSelector=nosuchlabel%3Dvalue", token, &body)
+
+	docs.Then("Then the request is successful and the response lists no repositories")
+	tstAssert(t, response, err, http.StatusOK, "repositories-bulk-patch-none.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.False(t, metadataImpl.Pushed)
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
+func TestPATCHRepositories_DryRun(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they request a dry run of a bulk patch of all repositories of a type")
+	body := tstRepositoryBulkPatch()
+	response, err := tstPerformPatch("/rest/api/v1/repositories?type=helm-deployment&dryRun=true", token, &body)
+
+	docs.Then("Then the request is successful and the response previews the changed repositories with their diffs")
+	tstAssert(t, response, err, http.StatusOK, "repositories-bulk-patch-dryrun.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+	require.False(t, metadataImpl.Pushed)
+
+	docs.Then("And no kafka messages have been sent")
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
+func TestPATCHRepositories_InvalidValues(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt a bulk patch without a filter that also changes the owner")
+	body := tstRepositoryBulkPatch()
+	body.Owner = ptr("deleteme")
+	response, err := tstPerformPatch("/rest/api/v1/repositories", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "repositories-bulk-patch-invalid-values.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+}
+
+func TestPATCHRepositories_OptimisticLockingFields(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt a bulk patch with a commit hash and a timestamp")
+	body := tstRepositoryBulkPatch()
+	body.CommitHash = "6c8ac2c35791edf9979623c717a243fc53400000"
+	body.TimeStamp = "2022-11-06T18:14:10Z"
+	response, err := tstPerformPatch("/rest/api/v1/repositories?type=helm-deployment", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "repositories-bulk-patch-optimistic-locking-fields.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+}
+
+func TestPATCHRepositories_InvalidResult(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt a bulk patch that makes the matching repositories invalid")
+	body := tstRepositoryBulkPatch()
+	body.Mainline = ptr("")
+	response, err := tstPerformPatch("/rest/api/v1/repositories?type=implementation", token, &body)
+
+	docs.Then("Then the request fails and the error response names every invalid repository")
+	tstAssert(t, response, err, http.StatusBadRequest, "repositories-bulk-patch-invalid-result.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+}
+
+func TestPATCHRepositories_BrokenGroupReference(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt a bulk patch that adds watchers referencing a group of an owner that does not exist")
+	body := tstRepositoryBulkPatch()
+	body.Configuration.Watchers = []string{"@unknown.users"}
+	response, err := tstPerformPatch("/rest/api/v1/repositories?type=helm-deployment", token, &body)
+
+	docs.Then("Then the request fails and the error response names every matching repository, the field and the reference")
+	tstAssert(t, response, err, http.StatusBadRequest, "repositories-bulk-patch-broken-group-reference.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+}
+
+func TestPATCHRepositories_NonAdminToken(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a user with a valid token without the admin role")
+	token := tstValidUserToken()
+
+	docs.When("When they attempt a bulk patch of repositories")
+	body := tstRepositoryBulkPatch()
+	response, err := tstPerformPatch("/rest/api/v1/repositories?type=helm-deployment", token, &body)
+
+	docs.Then("Then the request is denied")
+	tstAssert(t, response, err, http.StatusForbidden, "forbidden.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+}
+
 // delete repository
 
 func TestDELETERepository_Success(t *testing.T) {
@@ -1142,3 +1544,146 @@ func TestDELETERepository_Referenced(t *testing.T) {
 	docs.Then("And no kafka messages have been sent")
 	require.Equal(t, 0, len(kafkaImpl.Recording))
 }
+
+// rename repository
+
+func tstRepositoryRename(newKey string) openapi.RepositoryRenameDto {
+	return openapi.RepositoryRenameDto{
+		NewKey:    newKey,
+		JiraIssue: "ISSUE-2345",
+	}
+}
+
+func TestPOSTRepositoryRename_Success(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they rename a repository referenced by two services to a key that is not in use")
+	body := tstRepositoryRename("renamed-backend.implementation")
+	response, err := tstPerformPost("/rest/api/v1/repositories/some-service-backend.implementation/rename", token, &body)
+
+	docs.Then("Then the request is successful and the response is the repository under its new key")
+	tstAssert(t, response, err, http.StatusOK, "repository-rename.json")
+
+	docs.Then("And the repository has been moved and the references rewritten in a single commit and pushed")
+	require.Equal(t, "<notfound>", metadataImpl.ReadContents("owners/some-owner/repositories/some-service-backend.implementation.yaml"))
+	require.True(t, metadataImpl.FilesCommitted["owners/some-owner/repositories/renamed-backend.implementation.yaml"])
+	require.Contains(t, metadataImpl.ReadContents("owners/some-owner/repositories/renamed-backend.implementation.yaml"), "previousNames:\n    - some-service-backend.implementation\n")
+	require.Contains(t, metadataImpl.ReadContents("owners/some-owner/services/some-service-backend.yaml"), "- renamed-backend/implementation\n")
+	require.Contains(t, metadataImpl.ReadContents("owners/some-owner/services/some-service-backend-with-expandable-groups.yaml"), "- renamed-backend/implementation\n")
+	require.True(t, metadataImpl.Pushed)
+
+	docs.Then("And the old key has been recorded as a redirect")
+	require.Equal(t, "repositories:\n    some-service-backend.implementation: renamed-backend.implementation\n", metadataImpl.ReadContents("redirects.yaml"))
+
+	docs.Then("And the services refer to the new key")
+	readAgain, err := tstPerformGet("/rest/api/v1/services/some-service-backend", tstUnauthenticated())
+	require.Nil(t, err)
+	require.Contains(t, readAgain.body, `"renamed-backend.implementation"`)
+
+	docs.Then("And reading the repository under its old key redirects to the new key")
+	redirected, err := tstPerformGetNoRedirect("/rest/api/v1/repositories/some-service-backend.implementation", tstUnauthenticated())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusMovedPermanently, redirected.status)
+	require.Equal(t, "/rest/api/v1/repositories/renamed-backend.implementation", redirected.location)
+
+	docs.Then("And a single kafka message for the repository and the referencing services has been sent")
+	require.Equal(t, 1, len(kafkaImpl.Recording))
+	require.Equal(t, []string{"some-service-backend.implementation", "renamed-backend.implementation"}, kafkaImpl.Recording[0].Affected.RepositoryKeys)
+	require.Equal(t, []string{"some-service-backend", "some-service-backend-with-expandable-groups"}, kafkaImpl.Recording[0].Affected.ServiceNames)
+}
+
+func TestPOSTRepositoryRename_DryRun(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they request a dry run of renaming an existing repository")
+	body := tstRepositoryRename("renamed-backend.implementation")
+	response, err := tstPerformPost("/rest/api/v1/repositories/some-service-backend.implementation/rename?dryRun=true", token, &body)
+
+	docs.Then("Then the request is successful")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
+func TestPOSTRepositoryRename_DoesNotExist(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to rename a repository that does not exist")
+	body := tstRepositoryRename("renamed-backend.implementation")
+	response, err := tstPerformPost("/rest/api/v1/repositories/unicorn.helm-chart/rename", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "repository-notfound.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTRepositoryRename_Conflict(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to rename a repository to the key of another existing repository")
+	body := tstRepositoryRename("whatever.implementation")
+	response, err := tstPerformPost("/rest/api/v1/repositories/some-service-backend.implementation/rename", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusConflict, "repository-rename-conflict.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTRepositoryRename_InvalidValues(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to rename a repository without a jira issue")
+	body := tstRepositoryRename("renamed-backend.implementation")
+	body.JiraIssue = ""
+	response, err := tstPerformPost("/rest/api/v1/repositories/some-service-backend.implementation/rename", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "repository-rename-invalid-values.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTRepositoryRename_InvalidKey(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to rename a repository to an invalid key")
+	body := tstRepositoryRename("renamed-backend.unknown-type")
+	response, err := tstPerformPost("/rest/api/v1/repositories/some-service-backend.implementation/rename", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "repository-invalid-key.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
@@ -0,0 +1,53 @@
+package acceptance
+
+import (
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+// search
+
+func TestGETSearch_Success(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they search for a term that matches services and repositories")
+	response, err := tstPerformGet("/rest/api/v1/search?q=backend&limit=3", token)
+
+	docs.Then("Then the request is successful and the response contains the best hits")
+	tstAssert(t, response, err, http.StatusOK, "search-backend.json")
+}
+
+func TestGETSearch_AfterCreate(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they create an owner and then search for it")
+	body := tstOwner()
+	created, err := tstPerformPost("/rest/api/v1/owners/search-owner-created", token, &body)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, created.status)
+	response, err := tstPerformGet("/rest/api/v1/search?q=search-owner", tstUnauthenticated())
+
+	docs.Then("Then the request is successful and the new owner is found")
+	tstAssert(t, response, err, http.StatusOK, "search-after-create.json")
+}
+
+func TestGETSearch_MissingQuery(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they search without any terms")
+	response, err := tstPerformGet("/rest/api/v1/search", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "search-missing-query.json")
+}
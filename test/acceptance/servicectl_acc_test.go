@@ -5,10 +5,12 @@ import (
 	"github.com/Interhyp/metadata-service/api"
 	"github.com/Interhyp/metadata-service/internal/types"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
 
 	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/go-http-utils/headers"
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,6 +29,58 @@ func TestGETServices_Success(t *testing.T) {
 	tstAssert(t, response, err, http.StatusOK, "services.json")
 }
 
+func TestGETServices_LabelSelector(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the list of services filtered by a label selector no service matches")
+	response, err := tstPerformGet("/rest/api/v1/services?labelSelector="+url.QueryEscape("team in (unicorns,dragons)"), token)
+
+	docs.Then("Then the request is successful and the response contains an empty result")
+	tstAssert(t, response, err, http.StatusOK, "services-labelselector-empty.json")
+}
+
+func TestGETServices_InvalidLabelSelector(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the list of services filtered by a malformed label selector")
+	response, err := tstPerformGet("/rest/api/v1/services?labelSelector="+url.QueryEscape("team in unicorns"), token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "services-labelselector-invalid.json")
+}
+
+func TestGETServices_SortedByOwner(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the list of services sorted by owner in descending order")
+	response, err := tstPerformGet("/rest/api/v1/services?sort=-owner", token)
+
+	docs.Then("Then the request is successful and the response contains the order")
+	tstAssert(t, response, err, http.StatusOK, "services-sorted-owner.json")
+}
+
+func TestGETServices_InvalidSort(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the list of services sorted by an unsupported field")
+	response, err := tstPerformGet("/rest/api/v1/services?sort=alertTarget", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "services-invalid-sort.json")
+}
+
 // get service
 
 func TestGETService_Success(t *testing.T) {
@@ -55,6 +109,71 @@ func TestGETService_NotFound(t *testing.T) {
 	tstAssert(t, response, err, http.StatusNotFound, "service-notfound.json")
 }
 
+func TestGETServices_AtTime(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the list of services at the current time")
+	response, err := tstPerformGet("/rest/api/v1/services?at=2022-11-06T18:14:10Z", token)
+
+	docs.Then("Then the request is successful and the response is the list as of the newest commit")
+	tstAssert(t, response, err, http.StatusOK, "services.json")
+}
+
+func TestGETService_AtInvalid(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request a single service at a value that is neither a commit hash nor a timestamp")
+	response, err := tstPerformGet("/rest/api/v1/services/some-service-backend?at=yesterday", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "service-at-invalid.json")
+}
+
+func TestGETServiceHistory_Success(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the history of an existing service")
+	response, err := tstPerformGet("/rest/api/v1/services/some-service-backend/history", token)
+
+	docs.Then("Then the request is successful and the response lists the commits that changed the service")
+	tstAssert(t, response, err, http.StatusOK, "service-history.json")
+}
+
+func TestGETServiceHistory_InvalidSort(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the history of a service sorted by a field other than timeStamp")
+	response, err := tstPerformGet("/rest/api/v1/services/some-service-backend/history?sort=name", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "service-history-invalid-sort.json")
+}
+
+func TestGETServiceDiff_MissingFrom(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request the diff of a service without a from commit")
+	response, err := tstPerformGet("/rest/api/v1/services/some-service-backend/diff", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "service-diff-missing-from.json")
+}
+
 // create service
 
 func TestPOSTService_Success(t *testing.T) {
@@ -389,6 +508,93 @@ func TestPUTService_Success(t *testing.T) {
 	hasSentNotification(t, "receivesService", "some-service-backend", types.ModifiedEvent, types.ServicePayload, &payload)
 }
 
+func TestPUTService_UnknownDependency(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they update a service to depend on a service that does not exist")
+	body := tstService("some-service-backend")
+	body.Spec = &openapi.ServiceSpecDto{
+		DependsOn: []string{"does-not-exist"},
+	}
+	response, err := tstPerformPut("/rest/api/v1/services/some-service-backend", token, &body)
+
+	docs.Then("Then the request is successful and the response warns about the unknown service")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	require.Equal(t, []string{`299 - "dependsOn references unknown service does-not-exist"`}, response.header.Values("Warning"))
+
+	docs.Then("And the service has been written, committed and pushed")
+	filename := "owners/some-owner/services/some-service-backend.yaml"
+	require.True(t, metadataImpl.FilesCommitted[filename])
+	require.True(t, metadataImpl.Pushed)
+}
+
+func TestPUTService_Yaml(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they perform a valid update of an existing service with the yaml of a service file, asking for yaml back")
+	response, err := tstPerformWithHeaders(http.MethodPut, "/rest/api/v1/services/some-service-backend", token, map[string]string{
+		headers.ContentType:      "application/yaml",
+		headers.Accept:           "application/yaml",
+		"X-Metadata-Owner":       "some-owner",
+		"X-Metadata-Commit-Hash": "6c8ac2c35791edf9979623c717a243fc53400000",
+		"X-Metadata-Time-Stamp":  "2022-11-06T18:14:10Z",
+		"X-Metadata-Jira-Issue":  "ISSUE-2345",
+	}, []byte(tstServiceExpectedYaml("some-service-backend")))
+
+	docs.Then("Then the request is successful and the response is the yaml as written to the metadata repository")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	require.Equal(t, "application/yaml", response.contentType)
+	require.Equal(t, tstServiceExpectedYaml("some-service-backend"), response.body)
+	require.Equal(t, "some-owner", response.header.Get("X-Metadata-Owner"))
+	require.Equal(t, "6c8ac2c35791edf9979623c717a2430000000000", response.header.Get("X-Metadata-Commit-Hash"))
+	require.Equal(t, "2022-11-06T18:14:10Z", response.header.Get("X-Metadata-Time-Stamp"))
+
+	docs.Then("And the service has been correctly written, committed and pushed")
+	filename := "owners/some-owner/services/some-service-backend.yaml"
+	require.Equal(t, tstServiceExpectedYaml("some-service-backend"), metadataImpl.ReadContents(filename))
+	require.True(t, metadataImpl.FilesCommitted[filename])
+	require.True(t, metadataImpl.Pushed)
+
+	docs.Then("And the service can be read again as json and as yaml")
+	readAgain, err := tstPerformGet("/rest/api/v1/services/some-service-backend", tstUnauthenticated())
+	tstAssert(t, readAgain, err, http.StatusOK, "service-update.json")
+	readAgainYaml, err := tstPerformWithHeaders(http.MethodGet, "/rest/api/v1/services/some-service-backend", tstUnauthenticated(), map[string]string{
+		headers.Accept: "application/yaml, application/json;q=0.5",
+	}, nil)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, readAgainYaml.status)
+	require.Equal(t, tstServiceExpectedYaml("some-service-backend"), readAgainYaml.body)
+}
+
+func TestPUTService_YamlMissingHeaders(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt an update of an existing service with yaml, but without the commit hash and timestamp headers")
+	response, err := tstPerformWithHeaders(http.MethodPut, "/rest/api/v1/services/some-service-backend", token, map[string]string{
+		headers.ContentType:     "application/yaml",
+		"X-Metadata-Owner":      "some-owner",
+		"X-Metadata-Jira-Issue": "ISSUE-2345",
+	}, []byte(tstServiceExpectedYaml("some-service-backend")))
+
+	docs.Then("Then the request fails and the error response is json as usual")
+	tstAssert(t, response, err, http.StatusBadRequest, "service-update-yaml-invalid-values.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
 func TestPUTService_NoChangeSuccess(t *testing.T) {
 	tstReset()
 
@@ -776,6 +982,26 @@ func TestPATCHService_Success(t *testing.T) {
 	hasSentNotification(t, "receivesService", "some-service-backend", types.ModifiedEvent, types.ServicePayload, &payload)
 }
 
+func TestPATCHService_JsonPatchTestFailed(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they send a json patch whose test operation does not match the current service")
+	response, err := tstPerformPatchDocument("/rest/api/v1/services/some-service-backend", token, "application/json-patch+json", `[
+		{"op": "test", "path": "/alertTarget", "value": "https://webhook.com/elsewhere"},
+		{"op": "remove", "path": "/quicklinks"}
+	]`)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusConflict, "service-json-patch-test-failed.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
 func TestPATCHService_NoChangeSuccess(t *testing.T) {
 	tstReset()
 
@@ -1076,6 +1302,165 @@ func TestPATCHService_ChangeSpec(t *testing.T) {
 	tstAssert(t, readAgain, err, http.StatusOK, "service-patch-spec.json")
 }
 
+// tstPatchServiceLifecycle sets the lifecycle of a service through the api and returns the patched service.
+func tstPatchServiceLifecycle(t *testing.T, serviceName string, lifecycle string) openapi.ServiceDto {
+	current, err := tstPerformGet("/rest/api/v1/services/"+serviceName, tstUnauthenticated())
+	require.Nil(t, err)
+	currentDto := openapi.ServiceDto{}
+	require.Nil(t, json.Unmarshal([]byte(current.body), &currentDto))
+
+	body := openapi.ServicePatchDto{
+		TimeStamp:  currentDto.TimeStamp,
+		CommitHash: currentDto.CommitHash,
+		JiraIssue:  "ISSUE-2345",
+		Lifecycle:  ptr(lifecycle),
+	}
+	response, err := tstPerformPatch("/rest/api/v1/services/"+serviceName, tstValidAdminToken(), &body)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	patched := openapi.ServiceDto{}
+	require.Nil(t, json.Unmarshal([]byte(response.body), &patched))
+	return patched
+}
+
+func TestPATCHService_ChangeLifecycle(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an experimental service and an authenticated admin user")
+	current := tstPatchServiceLifecycle(t, "some-service-backend", "experimental")
+	kafkaImpl.Reset()
+	token := tstValidAdminToken()
+
+	docs.When("When they change its lifecycle to operational")
+	body := openapi.ServicePatchDto{
+		TimeStamp:  current.TimeStamp,
+		CommitHash: current.CommitHash,
+		JiraIssue:  "ISSUE-2346",
+		Lifecycle:  ptr("operational"),
+	}
+	response, err := tstPerformPatch("/rest/api/v1/services/some-service-backend", token, &body)
+
+	docs.Then("Then the request is successful and the response includes the recorded transition")
+	tstAssert(t, response, err, http.StatusOK, "service-patch-lifecycle.json")
+
+	docs.Then("And the transition has been written to the service, committed and pushed")
+	filename := "owners/some-owner/services/some-service-backend.yaml"
+	require.Contains(t, metadataImpl.ReadContents(filename), `lifecycle: operational
+lifecycleTransitions:
+    - from: experimental
+      to: operational
+      timeStamp: "2022-11-06T18:14:10Z"
+      jiraIssue: ISSUE-2346
+`)
+	require.True(t, metadataImpl.FilesCommitted[filename])
+	require.True(t, metadataImpl.Pushed)
+
+	docs.Then("And a lifecycle change notification has been sent to the subscribed consumers")
+	readAgain, err := tstPerformGet("/rest/api/v1/services/some-service-backend", tstUnauthenticated())
+	require.Nil(t, err)
+	payload := openapi.NotificationPayload{}
+	require.Nil(t, json.Unmarshal([]byte(`{"Service":`+readAgain.body+`}`), &payload))
+	hasSentNotification(t, "receivesLifecycle", "some-service-backend", types.LifecycleChangedEvent, types.ServicePayload, &payload)
+}
+
+func TestPATCHService_LifecycleNotAllowed(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an operational service and an authenticated admin user")
+	current := tstPatchServiceLifecycle(t, "some-service-backend", "operational")
+	metadataImpl.FilesWritten = make(map[string]bool)
+	kafkaImpl.Reset()
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to change its lifecycle back to experimental")
+	body := openapi.ServicePatchDto{
+		TimeStamp:  current.TimeStamp,
+		CommitHash: current.CommitHash,
+		JiraIssue:  "ISSUE-2346",
+		Lifecycle:  ptr("experimental"),
+	}
+	response, err := tstPerformPatch("/rest/api/v1/services/some-service-backend", token, &body)
+
+	docs.Then("Then the request fails and the error response names the allowed transitions")
+	tstAssert(t, response, err, http.StatusBadRequest, "service-patch-lifecycle-not-allowed.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
+func TestPATCHService_UnknownLifecycle(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to set a lifecycle that is not configured")
+	body := tstServicePatch()
+	body.Lifecycle = ptr("retired")
+	response, err := tstPerformPatch("/rest/api/v1/services/some-service-backend", token, &body)
+
+	docs.Then("Then the request fails and the error response names the known lifecycles")
+	tstAssert(t, response, err, http.StatusBadRequest, "service-patch-lifecycle-unknown.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+}
+
+func TestPATCHService_DependsOnDeprecated(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a deprecated service and an authenticated admin user")
+	tstPatchServiceLifecycle(t, "some-service-backend-with-expandable-groups", "deprecated")
+	metadataImpl.FilesWritten = make(map[string]bool)
+	kafkaImpl.Reset()
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to make another service depend on it")
+	body := tstServicePatch()
+	body.Spec = &openapi.ServiceSpecDto{
+		DependsOn: []string{"some-service-backend-with-expandable-groups"},
+	}
+	response, err := tstPerformPatch("/rest/api/v1/services/some-service-backend", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "service-patch-dependson-deprecated.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
+func TestPATCHService_DependencyProblems(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a service that depends on another service and an authenticated admin user")
+	tstPatchServiceSpecForGraph(t, "some-service-backend", &openapi.ServiceSpecDto{
+		DependsOn: []string{"some-service-backend-with-expandable-groups"},
+	})
+	token := tstValidAdminToken()
+
+	docs.When("When they make the other service depend on the first one and on a service that does not exist")
+	body := tstServiceUnchangedPatch()
+	body.Spec = &openapi.ServiceSpecDto{
+		DependsOn: []string{"some-service-backend", "does-not-exist"},
+	}
+	response, err := tstPerformPatch("/rest/api/v1/services/some-service-backend-with-expandable-groups", token, &body)
+
+	docs.Then("Then the request is successful and the response warns about the cycle and the unknown service")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+	require.Equal(t, []string{
+		`299 - "dependsOn some-service-backend creates a cycle: some-service-backend-with-expandable-groups -> some-service-backend -> some-service-backend-with-expandable-groups"`,
+		`299 - "dependsOn references unknown service does-not-exist"`,
+	}, response.header.Values("Warning"))
+
+	docs.Then("And the service has been written, committed and pushed")
+	filename := "owners/some-owner/services/some-service-backend-with-expandable-groups.yaml"
+	require.True(t, metadataImpl.FilesCommitted[filename])
+	require.True(t, metadataImpl.Pushed)
+}
+
 func TestPATCHService_ImplementationCrossrefAllowed(t *testing.T) {
 	tstReset()
 
@@ -1246,6 +1631,31 @@ func TestDELETEService_Success(t *testing.T) {
 	hasSentNotification(t, "receivesService", "some-service-backend", types.DeletedEvent, types.ServicePayload, nil)
 }
 
+func TestDELETEService_DryRun(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they request a dry run of the deletion of an existing service")
+	body := tstDelete()
+	response, err := tstPerformDelete("/rest/api/v1/services/some-service-backend?dryRun=true", token, &body)
+
+	docs.Then("Then the request is successful and the response is the service that would have been deleted")
+	tstAssert(t, response, err, http.StatusOK, "service-delete-dryrun.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+	require.False(t, metadataImpl.Pushed)
+
+	docs.Then("And the service can still be read")
+	readAgain, err := tstPerformGet("/rest/api/v1/services/some-service-backend", tstUnauthenticated())
+	tstAssert(t, readAgain, err, http.StatusOK, "service-delete-dryrun.json")
+
+	docs.Then("And no kafka messages have been sent")
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
 func TestDELETEService_DoesNotExist(t *testing.T) {
 	tstReset()
 
@@ -1405,3 +1815,186 @@ func TestGETServicePromoters_NotFound(t *testing.T) {
 	docs.Then("Then the request fails and the error response is as expected")
 	tstAssert(t, response, err, http.StatusNotFound, "service-notfound.json")
 }
+
+func TestGETServicePromoterCheck_Promoter(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they ask whether a member of a globally configured promoter group may promote an existing service")
+	response, err := tstPerformGet("/rest/api/v1/services/some-service-backend/promoters/some-other-user", token)
+
+	docs.Then("Then the request is successful and the user may promote")
+	tstAssert(t, response, err, http.StatusOK, "service-promoter-check.json")
+}
+
+func TestGETServicePromoterCheck_NoPromoter(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they ask whether a user who is no promoter may promote an existing service")
+	response, err := tstPerformGet("/rest/api/v1/services/some-service-backend/promoters/unicorn", token)
+
+	docs.Then("Then the request is successful and the user may not promote")
+	tstAssert(t, response, err, http.StatusOK, "service-promoter-check-denied.json")
+}
+
+func TestGETServicePromoterCheck_NotFound(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they ask about the promoters of a service that does not exist")
+	response, err := tstPerformGet("/rest/api/v1/services/unicorn/promoters/some-other-user", token)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "service-notfound.json")
+}
+
+// rename service
+
+func tstServiceRename(newName string) openapi.ServiceRenameDto {
+	return openapi.ServiceRenameDto{
+		NewName:   newName,
+		JiraIssue: "ISSUE-2345",
+	}
+}
+
+func TestPOSTServiceRename_Success(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a service that another service depends on")
+	tstPatchServiceSpecForGraph(t, "some-service-backend", &openapi.ServiceSpecDto{
+		DependsOn: []string{"some-service-backend-with-expandable-groups"},
+	})
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they rename the service to a name that is not in use")
+	body := tstServiceRename("renamed-backend")
+	response, err := tstPerformPost("/rest/api/v1/services/some-service-backend-with-expandable-groups/rename", token, &body)
+
+	docs.Then("Then the request is successful and the response is the service under its new name")
+	tstAssert(t, response, err, http.StatusOK, "service-rename.json")
+
+	docs.Then("And the service has been moved and the dependency rewritten in a single commit and pushed")
+	require.Equal(t, "<notfound>", metadataImpl.ReadContents("owners/some-owner/services/some-service-backend-with-expandable-groups.yaml"))
+	require.True(t, metadataImpl.FilesCommitted["owners/some-owner/services/renamed-backend.yaml"])
+	require.Contains(t, metadataImpl.ReadContents("owners/some-owner/services/renamed-backend.yaml"), "previousNames:\n    - some-service-backend-with-expandable-groups\n")
+	require.Contains(t, metadataImpl.ReadContents("owners/some-owner/services/some-service-backend.yaml"), "dependsOn:\n        - renamed-backend\n")
+	require.True(t, metadataImpl.Pushed)
+
+	docs.Then("And the old name has been recorded as a redirect")
+	require.Equal(t, "services:\n    some-service-backend-with-expandable-groups: renamed-backend\n", metadataImpl.ReadContents("redirects.yaml"))
+
+	docs.Then("And reading the service under its old name redirects to the new name")
+	redirected, err := tstPerformGetNoRedirect("/rest/api/v1/services/some-service-backend-with-expandable-groups", tstUnauthenticated())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusMovedPermanently, redirected.status)
+	require.Equal(t, "/rest/api/v1/services/renamed-backend", redirected.location)
+
+	docs.Then("And a kafka message for the renamed and the dependent service has been sent")
+	require.Equal(t, 2, len(kafkaImpl.Recording))
+	require.Equal(t, []string{"some-service-backend-with-expandable-groups", "renamed-backend", "some-service-backend"}, kafkaImpl.Recording[1].Affected.ServiceNames)
+
+	docs.Then("And notifications about the deletion of the old and the creation of the new service have been sent")
+	hasSentNotification(t, "receivesDelete", "some-service-backend-with-expandable-groups", types.DeletedEvent, types.ServicePayload, nil)
+}
+
+func TestPOSTServiceRename_DryRun(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they request a dry run of renaming an existing service")
+	body := tstServiceRename("renamed-backend")
+	response, err := tstPerformPost("/rest/api/v1/services/some-service-backend/rename?dryRun=true", token, &body)
+
+	docs.Then("Then the request is successful")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.status)
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
+func TestPOSTServiceRename_DoesNotExist(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to rename a service that does not exist")
+	body := tstServiceRename("renamed-backend")
+	response, err := tstPerformPost("/rest/api/v1/services/unicorn/rename", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusNotFound, "service-notfound.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTServiceRename_Conflict(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to rename a service to the name of another existing service")
+	body := tstServiceRename("some-service-backend")
+	response, err := tstPerformPost("/rest/api/v1/services/some-service-backend-with-expandable-groups/rename", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusConflict, "service-rename-conflict.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTServiceRename_InvalidValues(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to rename a service without a jira issue")
+	body := tstServiceRename("renamed-backend")
+	body.JiraIssue = ""
+	response, err := tstPerformPost("/rest/api/v1/services/some-service-backend/rename", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "service-rename-invalid-values.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTServiceRename_InvalidName(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they attempt to rename a service to an invalid name")
+	body := tstServiceRename("renamed-service")
+	response, err := tstPerformPost("/rest/api/v1/services/some-service-backend/rename", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "service-invalid-name.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
@@ -0,0 +1,373 @@
+package acceptance
+
+import (
+	"encoding/json"
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+// apply transaction
+
+func TestPOSTTransaction_CreateServiceWithRepositories(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they create a service together with its repositories in one transaction, service first")
+	body := tstTransactionCreateService("transacted")
+	response, err := tstPerformPost("/rest/api/v1/transactions", token, &body)
+
+	docs.Then("Then the request is successful and the response lists everything that was created")
+	tstAssert(t, response, err, http.StatusOK, "transaction-create.json")
+
+	docs.Then("And all files have been written in a single commit, which has been pushed")
+	require.Equal(t, tstServiceExpectedYaml("transacted"), metadataImpl.ReadContents("owners/some-owner/services/transacted.yaml"))
+	require.True(t, metadataImpl.FilesCommitted["owners/some-owner/services/transacted.yaml"])
+	require.True(t, metadataImpl.FilesCommitted["owners/some-owner/repositories/transacted.helm-deployment.yaml"])
+	require.True(t, metadataImpl.FilesCommitted["owners/some-owner/repositories/transacted.implementation.yaml"])
+	require.True(t, metadataImpl.Pushed)
+
+	docs.Then("And the service can be read")
+	readAgain, err := tstPerformGet("/rest/api/v1/services/transacted", tstUnauthenticated())
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, readAgain.status)
+
+	docs.Then("And a single kafka message for all affected entities has been sent")
+	require.Equal(t, 1, len(kafkaImpl.Recording))
+	require.Equal(t, []string{"transacted"}, kafkaImpl.Recording[0].Affected.ServiceNames)
+	require.Equal(t, []string{"transacted.helm-deployment", "transacted.implementation"}, kafkaImpl.Recording[0].Affected.RepositoryKeys)
+}
+
+func TestPOSTTransaction_ChangeOwner(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they move two services sharing a repository to another owner, patch an unreferenced repository and delete another one in one transaction")
+	body := openapi.TransactionDto{
+		JiraIssue: "ISSUE-2345",
+		Operations: []openapi.TransactionOperationDto{
+			tstTransactionChangeServiceOwner("some-service-backend", "deleteme"),
+			tstTransactionChangeServiceOwner("some-service-backend-with-expandable-groups", "deleteme"),
+			{
+				Operation: types.TransactionPatch,
+				Kind:      types.TransactionRepository,
+				Name:      "karma-wrapper.helm-chart",
+				Body: tstTransactionBody(openapi.RepositoryPatchDto{
+					Mainline:   ptr("main"),
+					TimeStamp:  "2022-11-06T18:14:10Z",
+					CommitHash: "6c8ac2c35791edf9979623c717a243fc53400000",
+				}),
+			},
+			{
+				Operation: types.TransactionDelete,
+				Kind:      types.TransactionRepository,
+				Name:      "whatever.implementation",
+			},
+		},
+	}
+	response, err := tstPerformPost("/rest/api/v1/transactions", token, &body)
+
+	docs.Then("Then the request is successful and the response lists everything that was changed")
+	tstAssert(t, response, err, http.StatusOK, "transaction-change-owner.json")
+
+	docs.Then("And the services have taken their repositories along")
+	require.Equal(t, "<notfound>", metadataImpl.ReadContents("owners/some-owner/services/some-service-backend.yaml"))
+	require.True(t, metadataImpl.FilesCommitted["owners/deleteme/services/some-service-backend.yaml"])
+	require.True(t, metadataImpl.FilesCommitted["owners/deleteme/services/some-service-backend-with-expandable-groups.yaml"])
+	require.True(t, metadataImpl.FilesCommitted["owners/deleteme/repositories/some-service-backend.helm-deployment.yaml"])
+	require.True(t, metadataImpl.FilesCommitted["owners/deleteme/repositories/some-service-backend.implementation.yaml"])
+	require.True(t, metadataImpl.FilesCommitted["owners/deleteme/repositories/some-service-backend-with-expandable-groups.helm-deployment.yaml"])
+
+	docs.Then("And the other repositories have been changed in the same commit")
+	require.True(t, metadataImpl.FilesCommitted["owners/some-owner/repositories/karma-wrapper.helm-chart.yaml"])
+	require.Equal(t, "<notfound>", metadataImpl.ReadContents("owners/some-owner/repositories/whatever.implementation.yaml"))
+	require.True(t, metadataImpl.Pushed)
+	require.Equal(t, 1, len(kafkaImpl.Recording))
+}
+
+func TestPOSTTransaction_RepositoryOwnerMismatch(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they move a service to another owner, but not the other service that also references one of its repositories")
+	body := openapi.TransactionDto{
+		JiraIssue: "ISSUE-2345",
+		Operations: []openapi.TransactionOperationDto{
+			tstTransactionChangeServiceOwner("some-service-backend", "deleteme"),
+		},
+	}
+	response, err := tstPerformPost("/rest/api/v1/transactions", token, &body)
+
+	docs.Then("Then the request fails and the error response names the service whose repository now belongs to another owner")
+	tstAssert(t, response, err, http.StatusBadRequest, "transaction-repository-owner-mismatch.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTTransaction_DryRun(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they request a dry run of creating a service together with its repositories")
+	body := tstTransactionCreateService("transacted")
+	response, err := tstPerformPost("/rest/api/v1/transactions?dryRun=true", token, &body)
+
+	docs.Then("Then the request is successful and the response previews what would be created")
+	tstAssert(t, response, err, http.StatusOK, "transaction-create-dryrun.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
+func TestPOSTTransaction_OperationFails(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they send a transaction whose last operation deletes a service that does not exist")
+	body := tstTransactionCreateService("transacted")
+	body.Operations = append(body.Operations, openapi.TransactionOperationDto{
+		Operation: types.TransactionDelete,
+		Kind:      types.TransactionService,
+		Name:      "does-not-exist",
+	})
+	response, err := tstPerformPost("/rest/api/v1/transactions", token, &body)
+
+	docs.Then("Then the request fails and the error response names the operation")
+	tstAssert(t, response, err, http.StatusNotFound, "transaction-operation-notfound.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+	require.Equal(t, 0, len(kafkaImpl.Recording))
+}
+
+func TestPOSTTransaction_OperationInvalid(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they send a transaction with an operation that does not pass validation")
+	body := tstTransactionCreateService("transacted")
+	body.Operations[0].Body["alertTarget"] = ""
+	response, err := tstPerformPost("/rest/api/v1/transactions", token, &body)
+
+	docs.Then("Then the request fails and the error response names the operation")
+	tstAssert(t, response, err, http.StatusBadRequest, "transaction-operation-invalid.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTTransaction_MissingReferences(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they create a service without the repositories it references, and delete its owner")
+	body := tstTransactionCreateService("transacted")
+	body.Operations[0].Body["owner"] = "deleteme"
+	body.Operations = []openapi.TransactionOperationDto{
+		body.Operations[0],
+		{
+			Operation: types.TransactionDelete,
+			Kind:      types.TransactionOwner,
+			Name:      "deleteme",
+		},
+	}
+	response, err := tstPerformPost("/rest/api/v1/transactions", token, &body)
+
+	docs.Then("Then the request fails and the error response lists all broken references in the resulting state")
+	tstAssert(t, response, err, http.StatusBadRequest, "transaction-references.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTTransaction_BrokenGroupReference(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they create a repository with watchers referencing a group of an owner that does not exist")
+	body := tstTransactionCreateService("transacted")
+	body.Operations[1].Body["configuration"] = map[string]interface{}{"watchers": []string{"@unknown.users"}}
+	response, err := tstPerformPost("/rest/api/v1/transactions", token, &body)
+
+	docs.Then("Then the request fails and the error response names the repository, the field and the reference")
+	tstAssert(t, response, err, http.StatusBadRequest, "transaction-broken-group-reference.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTTransaction_ConcurrentlyUpdated(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they send a transaction that updates a service based on an outdated commit hash")
+	body := openapi.TransactionDto{
+		JiraIssue: "ISSUE-2345",
+		Operations: []openapi.TransactionOperationDto{
+			{
+				Operation: types.TransactionPatch,
+				Kind:      types.TransactionService,
+				Name:      "some-service-backend",
+				Body: tstTransactionBody(openapi.ServicePatchDto{
+					Description: ptr("changed"),
+					TimeStamp:   "2022-11-06T18:14:10Z",
+					CommitHash:  "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+				}),
+			},
+		},
+	}
+	response, err := tstPerformPost("/rest/api/v1/transactions", token, &body)
+
+	docs.Then("Then the request fails with a conflict")
+	require.Nil(t, err)
+	require.Equal(t, http.StatusConflict, response.status)
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTTransaction_InvalidValues(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an authenticated admin user")
+	token := tstValidAdminToken()
+
+	docs.When("When they send a transaction without jira issue and with an unknown kind of entity")
+	body := openapi.TransactionDto{
+		Operations: []openapi.TransactionOperationDto{
+			{
+				Operation: types.TransactionDelete,
+				Kind:      "team",
+				Name:      "some-owner",
+			},
+		},
+	}
+	response, err := tstPerformPost("/rest/api/v1/transactions", token, &body)
+
+	docs.Then("Then the request fails and the error response is as expected")
+	tstAssert(t, response, err, http.StatusBadRequest, "transaction-invalid-values.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+func TestPOSTTransaction_NonAdminToken(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a user with a valid token without the admin role")
+	token := tstValidUserToken()
+
+	docs.When("When they attempt to apply a transaction")
+	body := tstTransactionCreateService("transacted")
+	response, err := tstPerformPost("/rest/api/v1/transactions", token, &body)
+
+	docs.Then("Then the request is denied")
+	tstAssert(t, response, err, http.StatusForbidden, "forbidden.json")
+
+	docs.Then("And no changes have been made in the metadata repository")
+	require.Equal(t, 0, len(metadataImpl.FilesWritten))
+	require.Equal(t, 0, len(metadataImpl.FilesCommitted))
+}
+
+// helper functions
+
+// tstTransactionCreateService creates a service and its repositories, the service comes first and has no jira issue
+// of its own.
+func tstTransactionCreateService(name string) openapi.TransactionDto {
+	serviceDto := tstService(name)
+	repositoryDto := tstRepository()
+	return openapi.TransactionDto{
+		JiraIssue: "ISSUE-2345",
+		Operations: []openapi.TransactionOperationDto{
+			{
+				Operation: types.TransactionCreate,
+				Kind:      types.TransactionService,
+				Name:      name,
+				Body: tstTransactionBody(openapi.ServiceCreateDto{
+					Owner:           serviceDto.Owner,
+					Quicklinks:      serviceDto.Quicklinks,
+					Repositories:    serviceDto.Repositories,
+					AlertTarget:     serviceDto.AlertTarget,
+					InternetExposed: serviceDto.InternetExposed,
+				}),
+			},
+			{
+				Operation: types.TransactionCreate,
+				Kind:      types.TransactionRepository,
+				Name:      name + ".helm-deployment",
+				Body: tstTransactionBody(openapi.RepositoryCreateDto{
+					Owner:    repositoryDto.Owner,
+					Url:      repositoryDto.Url,
+					Mainline: repositoryDto.Mainline,
+				}),
+			},
+			{
+				Operation: types.TransactionCreate,
+				Kind:      types.TransactionRepository,
+				Name:      name + ".implementation",
+				Body: tstTransactionBody(openapi.RepositoryCreateDto{
+					Owner:    repositoryDto.Owner,
+					Url:      repositoryDto.Url,
+					Mainline: repositoryDto.Mainline,
+				}),
+			},
+		},
+	}
+}
+
+func tstTransactionChangeServiceOwner(name string, owner string) openapi.TransactionOperationDto {
+	return openapi.TransactionOperationDto{
+		Operation: types.TransactionPatch,
+		Kind:      types.TransactionService,
+		Name:      name,
+		Body: tstTransactionBody(openapi.ServicePatchDto{
+			Owner:      ptr(owner),
+			TimeStamp:  "2022-11-06T18:14:10Z",
+			CommitHash: "6c8ac2c35791edf9979623c717a243fc53400000",
+		}),
+	}
+}
+
+// tstTransactionBody converts a dto into the body of a transaction operation, dropping empty fields.
+func tstTransactionBody(dto interface{}) map[string]interface{} {
+	marshalled, _ := json.Marshal(dto)
+	body := make(map[string]interface{})
+	_ = json.Unmarshal(marshalled, &body)
+	for k, v := range body {
+		if v == "" {
+			delete(body, k)
+		}
+	}
+	return body
+}
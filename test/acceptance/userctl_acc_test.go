@@ -0,0 +1,61 @@
+package acceptance
+
+import (
+	"github.com/Interhyp/go-backend-service-common/docs"
+	"net/http"
+	"testing"
+)
+
+// get user
+
+func TestGETUser_GroupMember(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request a user who is only referenced through an owner group")
+	response, err := tstPerformGet("/rest/api/v1/users/a-very-special-user", token)
+
+	docs.Then("Then the request is successful and the group references are resolved in the response")
+	tstAssert(t, response, err, http.StatusOK, "user-group-member.json")
+}
+
+func TestGETUser_ProductOwner(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request a user who is the product owner of owners")
+	response, err := tstPerformGet("/rest/api/v1/users/kschlangenheldt", token)
+
+	docs.Then("Then the request is successful and the response lists the owners")
+	tstAssert(t, response, err, http.StatusOK, "user-product-owner.json")
+}
+
+func TestGETUser_Approver(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request a user who is a direct approver of a repository")
+	response, err := tstPerformGet("/rest/api/v1/users/some-user", token)
+
+	docs.Then("Then the request is successful and the response lists the repository")
+	tstAssert(t, response, err, http.StatusOK, "user-approver.json")
+}
+
+func TestGETUser_Unknown(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given an unauthenticated user")
+	token := tstUnauthenticated()
+
+	docs.When("When they request a user who is not referenced anywhere")
+	response, err := tstPerformGet("/rest/api/v1/users/unicorn", token)
+
+	docs.Then("Then the request is successful and the response lists nothing")
+	tstAssert(t, response, err, http.StatusOK, "user-unknown.json")
+}
@@ -235,6 +235,8 @@ func tstShutdown() {
 
 func tstReset() {
 	metadataImpl.Reset()
+	// bring the caches back in line with the reset metadata repository, so tests do not see each other's changes
+	_ = application.Updater.PerformFullUpdate(appCtx)
 	kafkaImpl.Reset()
 	for _, client := range notifierImpl.Clients {
 		client.(*notifiermock.NotifierClientMock).Reset()
@@ -79,6 +79,7 @@ type tstWebResponse struct {
 	body        string
 	contentType string
 	location    string
+	header      http.Header
 }
 
 func tstWebResponseFromResponse(response *http.Response) (tstWebResponse, error) {
@@ -104,6 +105,7 @@ func tstWebResponseFromResponse(response *http.Response) (tstWebResponse, error)
 		body:        string(body),
 		contentType: ct,
 		location:    loc,
+		header:      response.Header,
 	}, nil
 }
 
@@ -111,6 +113,30 @@ func tstPerformGet(relativeUrlWithLeadingSlash string, bearerToken string) (tstW
 	return tstPerformNoBody(http.MethodGet, relativeUrlWithLeadingSlash, bearerToken)
 }
 
+// tstPerformGetNoRedirect is tstPerformGet, but it does not follow redirects.
+func tstPerformGetNoRedirect(relativeUrlWithLeadingSlash string, bearerToken string) (tstWebResponse, error) {
+	if ts == nil {
+		return tstWebResponse{}, errors.New("test web server was not initialized")
+	}
+	request, err := http.NewRequest(http.MethodGet, ts.URL+relativeUrlWithLeadingSlash, nil)
+	if err != nil {
+		return tstWebResponse{}, err
+	}
+	if bearerToken != "" {
+		request.Header.Set(headers.Authorization, "Bearer "+bearerToken)
+	}
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return tstWebResponse{}, err
+	}
+	return tstWebResponseFromResponse(response)
+}
+
 func tstPerformDeleteNoBody(relativeUrlWithLeadingSlash string, bearerToken string) (tstWebResponse, error) {
 	return tstPerformNoBody(http.MethodDelete, relativeUrlWithLeadingSlash, bearerToken)
 }
@@ -158,6 +184,22 @@ func tstPerformWithBody(method string, relativeUrlWithLeadingSlash string, beare
 }
 
 func tstPerformRawWithBody(method string, relativeUrlWithLeadingSlash string, bearerToken string, bodyBytes []byte) (tstWebResponse, error) {
+	return tstPerformRawWithContentType(method, relativeUrlWithLeadingSlash, bearerToken, "", bodyBytes)
+}
+
+func tstPerformPatchDocument(relativeUrlWithLeadingSlash string, bearerToken string, contentType string, body string) (tstWebResponse, error) {
+	return tstPerformRawWithContentType(http.MethodPatch, relativeUrlWithLeadingSlash, bearerToken, contentType, []byte(body))
+}
+
+func tstPerformRawWithContentType(method string, relativeUrlWithLeadingSlash string, bearerToken string, contentType string, bodyBytes []byte) (tstWebResponse, error) {
+	requestHeaders := make(map[string]string)
+	if contentType != "" {
+		requestHeaders[headers.ContentType] = contentType
+	}
+	return tstPerformWithHeaders(method, relativeUrlWithLeadingSlash, bearerToken, requestHeaders, bodyBytes)
+}
+
+func tstPerformWithHeaders(method string, relativeUrlWithLeadingSlash string, bearerToken string, requestHeaders map[string]string, bodyBytes []byte) (tstWebResponse, error) {
 	if ts == nil {
 		return tstWebResponse{}, errors.New("test web server was not initialized")
 	}
@@ -168,6 +210,9 @@ func tstPerformRawWithBody(method string, relativeUrlWithLeadingSlash string, be
 	if bearerToken != "" {
 		request.Header.Set(headers.Authorization, "Bearer "+bearerToken)
 	}
+	for key, value := range requestHeaders {
+		request.Header.Set(key, value)
+	}
 	response, err := http.DefaultClient.Do(request)
 	if err != nil {
 		return tstWebResponse{}, err
@@ -2,9 +2,13 @@ package acceptance
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/Interhyp/go-backend-service-common/docs"
+	"github.com/Interhyp/metadata-service/internal/acorn/repository"
+	"github.com/Interhyp/metadata-service/internal/service/check"
+	"github.com/go-git/go-billy/v5"
 	"github.com/go-http-utils/headers"
 	"github.com/go-playground/webhooks/v6/github"
 	"github.com/stretchr/testify/require"
@@ -33,6 +37,41 @@ func TestPOSTWebhookGitHub_CheckSuite_Success(t *testing.T) {
 	tstAssertNoBody(t, response, err, http.StatusNoContent)
 }
 
+func TestPOSTWebhookGitHub_CheckSuite_PullRequest_Success(t *testing.T) {
+	tstReset()
+
+	docs.Given("Given a pull request that adds a repository, on a base branch that moved on since")
+	baseSha := "c608f5c195adb6607b46c67ce446c97174a062d0"
+	mergeBaseSha := "4d1e9b2a7c3f5e8d0b6a9c2f1e4d7b0a3c5e8f21"
+	checker := application.Validator.(*check.Impl)
+	originalCheckout := checker.CheckoutFunction
+	defer func() {
+		checker.CheckoutFunction = originalCheckout
+	}()
+	checker.CheckoutFunction = func(ctx context.Context, authProvider repository.AuthProvider, repoUrl, sha string) (billy.Filesystem, error) {
+		fileSys, err := originalCheckout(ctx, authProvider, repoUrl, sha)
+		if err == nil && sha == mergeBaseSha {
+			err = fileSys.Remove("owners/some-owner/repositories/karma-wrapper.helm-chart.yaml")
+		}
+		return fileSys, err
+	}
+
+	docs.When("When GitHub sends a webhook for a check suite of the pull request")
+	bodyBytes := createGithubPullRequestCheckSuitePayload(baseSha, "a800c51995d3f3ee0ca110fa5fd93a772eaff381")
+
+	request, err := http.NewRequest(http.MethodPost, ts.URL+"/webhooks/vcs/github", bytes.NewReader(bodyBytes))
+	require.Nil(t, err)
+	request.Header.Set("X-GitHub-Event", string(github.CheckSuiteEvent))
+	request.Header.Set(headers.ContentType, "application/json")
+	rawResponse, err := http.DefaultClient.Do(request)
+	require.Nil(t, err)
+	response, err := tstWebResponseFromResponse(rawResponse)
+	require.Nil(t, err)
+
+	docs.Then("Then the request is successful, only the changed files are validated and the changes are summarized")
+	tstAssertNoBody(t, response, err, http.StatusNoContent)
+}
+
 func TestPOSTWebhookGitHub_InvalidCheckSuitePayload(t *testing.T) {
 	tstReset()
 
@@ -56,3 +95,8 @@ func createGithubCheckSuitePayload(sha string) github.CheckSuitePayload {
 	_ = json.Unmarshal([]byte(s), &data)
 	return data
 }
+
+// createGithubPullRequestCheckSuitePayload is raw json, because the payload types do not have the base of the pull requests.
+func createGithubPullRequestCheckSuitePayload(baseSha string, sha string) []byte {
+	return []byte(fmt.Sprintf(`{"action": "requested", "check_suite": {"head_sha": "%s", "pull_requests": [{"number": 42, "head": {"sha": "%s"}, "base": {"sha": "%s"}}], "app": {"created_at": "2025-04-04T00:00:00Z","updated_at": "2025-04-04T00:00:00Z"}}, "repository": {"name": "some-repo", "ssh_url": "ssh://git@github.com:Someorg/some-service-deployment.git", "owner": {"login": "some-org"}}}`, sha, sha, baseSha))
+}
@@ -3,6 +3,7 @@ package ownersmock
 import (
 	"context"
 	"github.com/Interhyp/metadata-service/api"
+	"github.com/Interhyp/metadata-service/internal/types"
 )
 
 type Mock struct {
@@ -92,3 +93,11 @@ func (s *Mock) PutRepository(ctx context.Context, key string, entry openapi.Repo
 func (s *Mock) DeleteRepository(ctx context.Context, key string) error {
 	return nil
 }
+
+func (s *Mock) GetRedirects(ctx context.Context) (types.Redirects, error) {
+	return types.Redirects{}, nil
+}
+
+func (s *Mock) PutRedirects(ctx context.Context, redirects types.Redirects) error {
+	return nil
+}
@@ -127,6 +127,10 @@ func (c *MockConfig) AuthGroupWrite() string {
 	panic("implement me")
 }
 
+func (c *MockConfig) GlobalPromoters() []string {
+	return []string{}
+}
+
 func (c *MockConfig) UpdateJobIntervalCronPart() string {
 	//TODO implement me
 	panic("implement me")
@@ -205,6 +209,23 @@ func (c *MockConfig) RepositoryKeySeparator() string {
 	panic("implement me")
 }
 
+func (c *MockConfig) ServiceLifecycleInitial() string {
+	return "experimental"
+}
+
+func (c *MockConfig) ServiceLifecycleTransitions() map[string][]string {
+	return map[string][]string{
+		"experimental":     {"operational", "deprecated"},
+		"operational":      {"deprecated"},
+		"deprecated":       {"operational", "decommissionable"},
+		"decommissionable": {"deprecated"},
+	}
+}
+
+func (c *MockConfig) ServiceLifecycleNoNewDependents() []string {
+	return []string{"deprecated", "decommissionable"}
+}
+
 func (c *MockConfig) MetadataRepoMainline() string {
 	//TODO implement me
 	panic("implement me")
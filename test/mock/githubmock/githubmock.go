@@ -16,6 +16,10 @@ func (this *GitHubMock) ConcludeCheckRun(ctx context.Context, owner, repoName, c
 	return nil
 }
 
+func (this *GitHubMock) GetChangedFiles(ctx context.Context, owner, repoName string, pullRequest int, base, head string) (repository.ChangedFiles, error) {
+	return repository.ChangedFiles{}, nil
+}
+
 func (this *GitHubMock) GetUser(ctx context.Context, username string) (*github.User, error) {
 	return &github.User{
 		Email: github.Ptr("some-email"),
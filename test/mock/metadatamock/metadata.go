@@ -8,6 +8,7 @@ import (
 	auzerolog "github.com/StephanHCB/go-autumn-logging-zerolog"
 	"io"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 
 	"github.com/Interhyp/go-backend-service-common/api/apierrors"
 	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
 )
 import _ "github.com/go-git/go-git/v5"
 
@@ -23,6 +25,9 @@ type Impl struct {
 	Fs  billy.Filesystem
 	Now func() time.Time
 
+	// origFs is the original commit, which writes do not change
+	origFs billy.Filesystem
+
 	FilesWritten   map[string]bool
 	FilesCommitted map[string]bool
 	Pushed         bool
@@ -31,6 +36,9 @@ type Impl struct {
 	SimulateRemoteFailure      bool
 	SimulateConcurrencyFailure bool
 	SimulateUnchangedFailure   bool
+
+	pendingDeletions   map[string][]byte
+	committedDeletions []repository.FileChange
 }
 
 func New() repository.Metadata {
@@ -86,8 +94,14 @@ func (r *Impl) Clone(ctx context.Context) error {
 		return err
 	}
 	r.Fs = fs
+	r.origFs, err = checkoutmock.New()
+	if err != nil {
+		return err
+	}
 	r.FilesCommitted = make(map[string]bool)
 	r.FilesWritten = make(map[string]bool)
+	r.pendingDeletions = make(map[string][]byte)
+	r.committedDeletions = make([]repository.FileChange, 0)
 	r.SimulateRemoteFailure = false
 	r.SimulateConcurrencyFailure = false
 	r.SimulateUnchangedFailure = false
@@ -117,9 +131,35 @@ func (r *Impl) Commit(ctx context.Context, message string) (repository.CommitInf
 	r.FilesCommitted = r.FilesWritten
 	commitInfo.CommitHash = newCommitHash
 	commitInfo.Message = message
+	r.commitDeletions(commitInfo)
 	return commitInfo, nil
 }
 
+// commitDeletions records the files deleted since the last commit for FileDeletions, newest first.
+func (r *Impl) commitDeletions(commitInfo repository.CommitInfo) {
+	filesChanged := make([]string, 0, len(r.FilesWritten))
+	for filename := range r.FilesWritten {
+		filesChanged = append(filesChanged, filename)
+	}
+	sort.Strings(filesChanged)
+
+	deletions := make([]repository.FileChange, 0, len(r.pendingDeletions))
+	for _, filename := range filesChanged {
+		if contents, ok := r.pendingDeletions[filename]; ok {
+			deletion := repository.FileChange{
+				CommitInfo: commitInfo,
+				Author:     "Some Body",
+				BeforePath: filename,
+				Before:     contents,
+			}
+			deletion.FilesChanged = filesChanged
+			deletions = append(deletions, deletion)
+		}
+	}
+	r.committedDeletions = append(deletions, r.committedDeletions...)
+	r.pendingDeletions = make(map[string][]byte)
+}
+
 func (r *Impl) Push(ctx context.Context) error {
 	if r.SimulateRemoteFailure {
 		return apierrors.NewBadGatewayError("downstream.unavailable", "the git server is currently unavailable or failed to service the request", nil, r.Now())
@@ -149,6 +189,10 @@ func (r *Impl) IsCommitKnown(hash string) bool {
 	return false
 }
 
+func (r *Impl) HeadCommitHash() string {
+	return ""
+}
+
 func (r *Impl) Stat(filename string) (os.FileInfo, error) {
 	return r.Fs.Stat(filename)
 }
@@ -198,7 +242,12 @@ func (r *Impl) WriteFile(filename string, contents []byte) error {
 }
 
 func (r *Impl) DeleteFile(filename string) error {
-	err := r.Fs.Remove(filename)
+	contents, _, err := r.ReadFile(filename)
+	if err == nil {
+		r.pendingDeletions[filename] = contents
+	}
+
+	err = r.Fs.Remove(filename)
 	if err != nil {
 		return err
 	}
@@ -210,6 +259,92 @@ func (r *Impl) MkdirAll(path string) error {
 	return r.Fs.MkdirAll(path, 0755)
 }
 
+// the mock history consists of just the original commit, which has the file system contents as of the last reset
+
+func (r *Impl) ResolveCommitHash(ctx context.Context, hashPrefix string) (repository.CommitInfo, error) {
+	if hashPrefix == "" || !strings.HasPrefix(origCommitHash, hashPrefix) {
+		return repository.CommitInfo{}, fmt.Errorf("commit %s not found: %w", hashPrefix, os.ErrNotExist)
+	}
+	return r.origCommitInfo(), nil
+}
+
+func (r *Impl) ResolveCommitTime(ctx context.Context, at time.Time) (repository.CommitInfo, error) {
+	if at.Before(r.Now()) {
+		return repository.CommitInfo{}, fmt.Errorf("no commit at or before %s: %w", at.Format(time.RFC3339), os.ErrNotExist)
+	}
+	return r.origCommitInfo(), nil
+}
+
+func (r *Impl) ReadDirAt(ctx context.Context, commitHash string, path string) ([]os.FileInfo, error) {
+	if commitHash != origCommitHash {
+		return nil, fmt.Errorf("commit %s not found: %w", commitHash, os.ErrNotExist)
+	}
+	return r.origFs.ReadDir(path)
+}
+
+func (r *Impl) StatAt(ctx context.Context, commitHash string, filename string) (os.FileInfo, error) {
+	if commitHash != origCommitHash {
+		return nil, fmt.Errorf("commit %s not found: %w", commitHash, os.ErrNotExist)
+	}
+	return r.origFs.Stat(filename)
+}
+
+func (r *Impl) ReadFileAt(ctx context.Context, commitHash string, filename string) ([]byte, repository.CommitInfo, error) {
+	if commitHash != origCommitHash {
+		return nil, repository.CommitInfo{}, fmt.Errorf("commit %s not found: %w", commitHash, os.ErrNotExist)
+	}
+	data, err := util.ReadFile(r.origFs, filename)
+	return data, r.origCommitInfo(), err
+}
+
+// FileHistory reports every matching file as created by the original commit.
+func (r *Impl) FileHistory(ctx context.Context, matches func(path string) bool, stop func(change repository.FileChange) bool) ([]repository.FileChange, error) {
+	result := make([]repository.FileChange, 0)
+	err := util.Walk(r.Fs, "owners", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !matches(path) {
+			return err
+		}
+		data, _, err := r.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		change := repository.FileChange{
+			CommitInfo: r.origCommitInfo(),
+			Author:     "Some Body",
+			AfterPath:  path,
+			After:      data,
+		}
+		change.FilesChanged = []string{path}
+		result = append(result, change)
+		return nil
+	})
+	for i, change := range result {
+		if stop != nil && stop(change) {
+			return result[:i+1], err
+		}
+	}
+	return result, err
+}
+
+// FileDeletions reports the deletions committed since the last reset, the original commit deletes nothing.
+func (r *Impl) FileDeletions(ctx context.Context, matches func(path string) bool) ([]repository.FileChange, error) {
+	result := make([]repository.FileChange, 0)
+	for _, deletion := range r.committedDeletions {
+		if matches(deletion.BeforePath) {
+			result = append(result, deletion)
+		}
+	}
+	return result, nil
+}
+
+func (r *Impl) origCommitInfo() repository.CommitInfo {
+	return repository.CommitInfo{
+		CommitHash: origCommitHash,
+		TimeStamp:  r.Now(),
+		Message:    origCommitMessage,
+	}
+}
+
 // reset for the next test
 
 func (r *Impl) Reset() {